@@ -4,22 +4,51 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"company.com/matchengine/internal/config"
+	"company.com/matchengine/internal/event"
+	httphandler "company.com/matchengine/internal/handler/http"
 	"company.com/matchengine/internal/middleware"
+	apiserver "company.com/matchengine/internal/server"
+	"company.com/matchengine/internal/service/matching"
+	"company.com/matchengine/internal/telemetry"
+	"company.com/matchengine/pkg/engine/order"
 )
 
 func main() {
 	// Initialize logger
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
+		Level: getLogLevel(os.Getenv("LOG_LEVEL")),
 	}))
 	slog.SetDefault(logger)
 
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Error("config error", "error", err)
+		os.Exit(1)
+	}
+
+	// Export traces over OTLP when an endpoint is configured
+	shutdownTracing, err := telemetry.Setup(context.Background(), os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"))
+	if err != nil {
+		logger.Error("tracing setup error", "error", err)
+		os.Exit(1)
+	}
+
+	// Give orders IDs that sort by creation when asked to, UUIDs otherwise
+	if os.Getenv("ORDER_IDS") == "sequential" {
+		order.SetIDGenerator(order.NewSequentialGenerator(nil))
+	}
+
 	// Initialize server
 	mux := http.NewServeMux()
 
@@ -30,33 +59,167 @@ func main() {
 		fmt.Fprintf(w, `{"status":"ok","timestamp":"%s"}`, time.Now().Format(time.RFC3339))
 	})
 
+	// POST fill notifications to the callback URLs orders carry
+	webhooks := event.NewWebhookPublisher(event.WebhookConfig{}, logger)
+
+	// Debug mode mounts the support endpoints and checks books after every
+	// order, failing the order that leaves one out of price order
+	debug := debugEndpointsEnabled(os.Getenv("ENVIRONMENT"), os.Getenv("ENABLE_DEBUG_ENDPOINTS"))
+
+	// Register API routes
+	opts := []matching.Option{
+		matching.WithLogger(logger),
+		matching.WithEventPublisher(webhooks),
+		matching.WithMaxInFlight(getMaxInFlight(os.Getenv("MAX_IN_FLIGHT_ORDERS"))),
+		matching.WithRetryBackoff(getRetryBackoff(os.Getenv("RETRY_BACKOFF"))),
+		matching.WithIngestQueue(
+			getIngestQueueSize(os.Getenv("INGEST_QUEUE_SIZE")),
+			getIngestWorkers(os.Getenv("INGEST_WORKERS")),
+		),
+		matching.WithMessageQuota(getMessageQuota(os.Getenv("MESSAGE_QUOTA_PER_SECOND"))),
+		matching.WithPaperAccounts(getPaperAccounts(os.Getenv("PAPER_ACCOUNTS"))...),
+	}
+	if debug || invariantChecksEnabled(os.Getenv("BOOK_INVARIANT_CHECKS")) {
+		opts = append(opts, matching.WithInvariantChecks(debug))
+	}
+	service := matching.NewService(opts...)
+	api := httphandler.NewHandler(service, logger)
+	api.RegisterRoutes(mux)
+
+	// Admin actions such as flattening accounts need ADMIN_TOKEN and are
+	// left out without one
+	if adminToken := os.Getenv("ADMIN_TOKEN"); adminToken != "" {
+		api.RegisterAdminRoutes(mux, adminToken)
+	}
+
+	// Mount the support debug endpoints outside production, or in
+	// production when explicitly enabled; they always need ADMIN_TOKEN
+	if debug {
+		if adminToken := os.Getenv("ADMIN_TOKEN"); adminToken != "" {
+			api.RegisterDebugRoutes(mux, adminToken)
+			logger.Info("debug endpoints enabled")
+		} else {
+			logger.Warn("debug endpoints disabled: no ADMIN_TOKEN configured")
+		}
+	}
+
+	// In debug mode, check every book's structure periodically and log
+	// any corruption found
+	if interval := getValidateInterval(os.Getenv("BOOK_VALIDATE_INTERVAL")); debug && interval > 0 {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for range ticker.C {
+				if err := service.ValidateBooks(context.Background()); err != nil {
+					logger.Error("order book validation failed", "error", err)
+				}
+			}
+		}()
+	}
+
+	// Cancel orders resting past their symbol's order_ttl or their own expiry
+	go func() {
+		ticker := time.NewTicker(getExpiryInterval(os.Getenv("ORDER_EXPIRY_INTERVAL")))
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := service.ExpireOrders(context.Background()); err != nil {
+				logger.Error("order expiry failed", "error", err)
+			}
+		}
+	}()
+
+	// Register the symbol universe from a file, reloaded on SIGHUP
+	symbolsFile := os.Getenv("SYMBOLS_FILE")
+	if symbolsFile != "" {
+		if err := loadSymbols(symbolsFile, service); err != nil {
+			logger.Error("symbols file error", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("symbols loaded", "file", symbolsFile)
+	}
+
 	// Add middleware
 	handler := middleware.Chain(
 		mux,
 		middleware.Logger(logger),
 		middleware.Recovery(logger),
+		middleware.MaxBodySize(getMaxBodyBytes(os.Getenv("MAX_BODY_BYTES"))),
+		middleware.Timeout(getRequestTimeout(os.Getenv("REQUEST_TIMEOUT")), service.RetryBackoff()),
+		middleware.Gzip(middleware.DefaultGzipMinSize),
+		middleware.Tracing(),
+		middleware.RequestID(),
 	)
 
-	// Configure server
-	server := &http.Server{
-		Addr:         ":8080", // Hardcoded for testing
-		Handler:      handler,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	// Serve HTTPS when a certificate is configured. Plaintext is for local
+	// development, or production behind a proxy that terminates TLS, and
+	// must be asked for there.
+	var certs *apiserver.CertReloader
+	if cfg.Server.TLS.Enabled() {
+		if certs, err = apiserver.NewCertReloader(cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile); err != nil {
+			logger.Error("tls certificate error", "error", err)
+			os.Exit(1)
+		}
+	} else if !plaintextAllowed(os.Getenv("ENVIRONMENT"), os.Getenv("ALLOW_PLAINTEXT")) {
+		logger.Error("no TLS certificate configured: set TLS_CERT_FILE and TLS_KEY_FILE, or ALLOW_PLAINTEXT=true")
+		os.Exit(1)
+	}
+
+	// Configure server: timeouts, keep-alives, TLS and HTTP/2 from SERVER_*
+	// and TLS_* settings
+	server, err := apiserver.New(cfg.Server, handler, certs)
+	if err != nil {
+		logger.Error("server setup error", "error", err)
+		os.Exit(1)
+	}
+	listener, err := apiserver.Listen(cfg.Server)
+	if err != nil {
+		logger.Error("server listen error", "error", err)
+		os.Exit(1)
 	}
 
 	// Server run context
 	serverCtx, serverStopCtx := context.WithCancel(context.Background())
 
+	// Reload the TLS certificate and the symbols file on SIGHUP, keeping the
+	// current ones when the new files are invalid. Open connections are left
+	// alone; only new handshakes see a new certificate.
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+
+	go func() {
+		for range reload {
+			if certs != nil {
+				if err := certs.Reload(); err != nil {
+					logger.Error("tls certificate reload failed", "error", err)
+				} else {
+					logger.Info("tls certificate reloaded", "file", cfg.Server.TLS.CertFile)
+				}
+			}
+			if symbolsFile == "" {
+				if certs == nil {
+					logger.Warn("SIGHUP ignored: no SYMBOLS_FILE or TLS certificate configured")
+				}
+				continue
+			}
+			if err := loadSymbols(symbolsFile, service); err != nil {
+				logger.Error("symbols reload failed", "error", err)
+				continue
+			}
+			logger.Info("symbols reloaded", "file", symbolsFile)
+		}
+	}()
+
 	// Listen for syscall signals for process to interrupt/quit
 	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
 
 	go func() {
 		<-sig
 		logger.Info("Shutting down server...")
 
+		// Fail readiness probes so traffic drains away before shutdown
+		service.Drain()
+
 		// Shutdown signal with grace period of 30 seconds
 		shutdownCtx, cancel := context.WithTimeout(serverCtx, 30*time.Second)
 		defer cancel()
@@ -73,12 +236,24 @@ func main() {
 			logger.Error("server shutdown error", "error", err)
 		}
 
+		// Deliver the fill notifications still queued
+		webhooks.Close()
+
+		// Flush spans still waiting in the batcher
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			logger.Error("tracing shutdown error", "error", err)
+		}
+
 		serverStopCtx()
 	}()
 
 	// Start server
-	logger.Info("Starting server...", "port", "8080")
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	logger.Info("Starting server...", "port", cfg.Server.Port, "tls", certs != nil, "h2c", cfg.Server.H2C)
+	serve := server.Serve
+	if certs != nil {
+		serve = func(l net.Listener) error { return server.ServeTLS(l, "", "") }
+	}
+	if err := serve(listener); err != nil && err != http.ErrServerClosed {
 		logger.Error("server error", "error", err)
 		os.Exit(1)
 	}
@@ -101,3 +276,107 @@ func getLogLevel(level string) slog.Level {
 		return slog.LevelInfo
 	}
 }
+
+func getMaxBodyBytes(value string) int64 {
+	if limit, err := strconv.ParseInt(value, 10, 64); err == nil && limit > 0 {
+		return limit
+	}
+	return middleware.DefaultMaxBodyBytes
+}
+
+func getRequestTimeout(value string) time.Duration {
+	if timeout, err := time.ParseDuration(value); err == nil && timeout > 0 {
+		return timeout
+	}
+	return middleware.DefaultRequestTimeout
+}
+
+func getMaxInFlight(value string) int {
+	if limit, err := strconv.Atoi(value); err == nil && limit > 0 {
+		return limit
+	}
+	return 0
+}
+
+// getRetryBackoff returns zero, leaving the service's default, unless value
+// is a positive duration
+func getRetryBackoff(value string) time.Duration {
+	if backoff, err := time.ParseDuration(value); err == nil && backoff > 0 {
+		return backoff
+	}
+	return 0
+}
+
+func getIngestQueueSize(value string) int {
+	if size, err := strconv.Atoi(value); err == nil && size > 0 {
+		return size
+	}
+	return 0
+}
+
+func getIngestWorkers(value string) int {
+	if workers, err := strconv.Atoi(value); err == nil && workers > 0 {
+		return workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+func getMessageQuota(value string) int {
+	if quota, err := strconv.Atoi(value); err == nil && quota > 0 {
+		return quota
+	}
+	return 0
+}
+
+func getPaperAccounts(value string) []string {
+	var accounts []string
+	for _, account := range strings.Split(value, ",") {
+		if account = strings.TrimSpace(account); account != "" {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts
+}
+
+func getValidateInterval(value string) time.Duration {
+	if interval, err := time.ParseDuration(value); err == nil && interval > 0 {
+		return interval
+	}
+	return 0
+}
+
+func getExpiryInterval(value string) time.Duration {
+	if interval, err := time.ParseDuration(value); err == nil && interval > 0 {
+		return interval
+	}
+	return time.Second
+}
+
+func debugEndpointsEnabled(environment, flag string) bool {
+	if enabled, err := strconv.ParseBool(flag); err == nil {
+		return enabled
+	}
+	return environment != "production"
+}
+
+// invariantChecksEnabled reports whether BOOK_INVARIANT_CHECKS asks for the
+// book checks outside debug mode, where violations are only logged
+func invariantChecksEnabled(flag string) bool {
+	enabled, _ := strconv.ParseBool(flag)
+	return enabled
+}
+
+func plaintextAllowed(environment, flag string) bool {
+	if allowed, err := strconv.ParseBool(flag); err == nil {
+		return allowed
+	}
+	return environment != "production"
+}
+
+func loadSymbols(path string, service *matching.Service) error {
+	file, err := config.LoadSymbols(path)
+	if err != nil {
+		return err
+	}
+	return file.Apply(service)
+}
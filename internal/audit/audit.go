@@ -0,0 +1,55 @@
+// Package audit records the state transitions of orders for compliance
+package audit
+
+import (
+	"time"
+
+	"company.com/matchengine/internal/event"
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+// Entry is one state transition of an order
+type Entry struct {
+	// Event is what caused the transition
+	Event  event.Type   `json:"event"`
+	Status order.Status `json:"status"`
+	// Filled is the order's total filled quantity after the transition
+	Filled order.Decimal `json:"filled"`
+	// FillPrice and FillQuantity describe the trade behind a fill
+	FillPrice    *order.Decimal `json:"fill_price,omitempty"`
+	FillQuantity *order.Decimal `json:"fill_quantity,omitempty"`
+	// Sequence is the book's sequence number after the transition
+	Sequence  uint64    `json:"sequence"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// FromUpdate describes an order book update as an audit entry
+func FromUpdate(u orderbook.Update) Entry {
+	e := event.FromUpdate(u)
+	precision := order.PrecisionFor(u.Order.Symbol)
+
+	entry := Entry{
+		Event:     e.Type,
+		Status:    u.Order.Status,
+		Filled:    order.Decimal{Value: u.Order.Filled, Places: precision.Quantity},
+		Sequence:  u.Sequence,
+		Timestamp: e.Timestamp,
+	}
+	if u.Trade != nil {
+		entry.FillPrice = &order.Decimal{Value: u.Trade.Price, Places: precision.Price}
+		entry.FillQuantity = &order.Decimal{Value: u.Trade.Quantity, Places: precision.Quantity}
+	}
+	return entry
+}
+
+// Store keeps the history of orders. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Append adds an entry to the end of an order's history
+	Append(orderID string, entry Entry)
+
+	// History returns an order's entries, oldest first, and false if the
+	// store holds none for it
+	History(orderID string) ([]Entry, bool)
+}
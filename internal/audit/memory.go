@@ -0,0 +1,60 @@
+package audit
+
+import "sync"
+
+// DefaultMaxOrders bounds a MemoryStore when no other limit is configured
+const DefaultMaxOrders = 100_000
+
+// MemoryStore keeps the history of the most recent orders in memory. Once
+// it holds maxOrders orders, the order it first heard of longest ago is
+// forgotten to make room for a new one.
+type MemoryStore struct {
+	maxOrders int
+	histories map[string][]Entry
+	// queue holds order IDs in the order they were first recorded
+	queue []string
+	mutex sync.Mutex
+}
+
+func NewMemoryStore(maxOrders int) *MemoryStore {
+	if maxOrders <= 0 {
+		maxOrders = DefaultMaxOrders
+	}
+	return &MemoryStore{
+		maxOrders: maxOrders,
+		histories: make(map[string][]Entry),
+	}
+}
+
+func (s *MemoryStore) Append(orderID string, entry Entry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.histories[orderID]; !exists {
+		for len(s.queue) >= s.maxOrders {
+			delete(s.histories, s.queue[0])
+			s.queue = s.queue[1:]
+		}
+		s.queue = append(s.queue, orderID)
+	}
+	s.histories[orderID] = append(s.histories[orderID], entry)
+}
+
+func (s *MemoryStore) History(orderID string) ([]Entry, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	history, exists := s.histories[orderID]
+	if !exists {
+		return nil, false
+	}
+	return append([]Entry(nil), history...), true
+}
+
+// Len returns the number of orders with a recorded history
+func (s *MemoryStore) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.histories)
+}
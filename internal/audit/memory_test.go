@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"company.com/matchengine/internal/event"
+)
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore(2)
+
+	store.Append("a", Entry{Event: event.OrderCreated, Sequence: 1})
+	store.Append("b", Entry{Event: event.OrderCreated, Sequence: 2})
+	store.Append("a", Entry{Event: event.OrderCancelled, Sequence: 3})
+
+	history, exists := store.History("a")
+	require.True(t, exists)
+	require.Len(t, history, 2)
+	assert.Equal(t, event.OrderCreated, history[0].Event)
+	assert.Equal(t, event.OrderCancelled, history[1].Event)
+
+	// Callers get a copy
+	history[0].Sequence = 99
+	history, _ = store.History("a")
+	assert.Equal(t, uint64(1), history[0].Sequence)
+
+	// A third order evicts the first one recorded
+	store.Append("c", Entry{Event: event.OrderCreated, Sequence: 4})
+	assert.Equal(t, 2, store.Len())
+	_, exists = store.History("a")
+	assert.False(t, exists)
+	_, exists = store.History("b")
+	assert.True(t, exists)
+}
@@ -0,0 +1,145 @@
+// Package candle aggregates trades into OHLCV candles over a rolling window
+package candle
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+const (
+	// Resolution is the width of the buckets trades are kept in. Every
+	// interval is a whole number of them.
+	Resolution = time.Minute
+	// Window is how far back trades are kept
+	Window = 24 * time.Hour
+	// DefaultInterval is the candle width used when none is asked for
+	DefaultInterval = time.Minute
+)
+
+// ErrInvalidInterval is returned for intervals that aren't a whole number
+// of minutes or don't fit in the window
+var ErrInvalidInterval = errors.New("invalid candle interval")
+
+// Candle summarizes the trades of one interval, starting at Start
+type Candle struct {
+	Start  time.Time `json:"start"`
+	Open   float64   `json:"open"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Close  float64   `json:"close"`
+	Volume float64   `json:"volume"`
+	Trades int       `json:"trades"`
+}
+
+// Aggregator keeps a minute candle per symbol for every minute of the window
+// that had trades
+type Aggregator struct {
+	// symbols maps each symbol to its minute candles, oldest first
+	symbols map[string][]Candle
+	mutex   sync.RWMutex
+}
+
+func NewAggregator() *Aggregator {
+	return &Aggregator{symbols: make(map[string][]Candle)}
+}
+
+// Apply folds a trade into the minute candle it falls in and drops the
+// symbol's candles that have left the window
+func (a *Aggregator) Apply(trade orderbook.Trade) {
+	start := trade.Timestamp.Truncate(Resolution)
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	buckets := a.symbols[trade.Symbol]
+
+	// Trades arrive in time order, so the bucket is almost always the last
+	i := len(buckets)
+	for i > 0 && buckets[i-1].Start.After(start) {
+		i--
+	}
+	if i == 0 || !buckets[i-1].Start.Equal(start) {
+		buckets = append(buckets, Candle{})
+		copy(buckets[i+1:], buckets[i:])
+		buckets[i] = Candle{
+			Start: start,
+			Open:  trade.Price,
+			High:  trade.Price,
+			Low:   trade.Price,
+			Close: trade.Price,
+		}
+		i++
+	}
+	fold(&buckets[i-1], trade)
+
+	cutoff := buckets[len(buckets)-1].Start.Add(-Window)
+	expired := sort.Search(len(buckets), func(j int) bool {
+		return !buckets[j].Start.Before(cutoff)
+	})
+	a.symbols[trade.Symbol] = buckets[expired:]
+}
+
+// fold adds a trade to a candle that already has its open
+func fold(c *Candle, trade orderbook.Trade) {
+	c.High = max(c.High, trade.Price)
+	c.Low = min(c.Low, trade.Price)
+	c.Close = trade.Price
+	c.Volume += trade.Quantity
+	c.Trades++
+}
+
+// Candles returns a symbol's candles of the given width covering the window
+// that ends with the candle in progress at now, oldest first. Intervals
+// without trades repeat the previous close with no volume; those before the
+// symbol's first trade in the window are left out.
+func (a *Aggregator) Candles(symbol string, interval time.Duration, now time.Time) ([]Candle, error) {
+	if interval <= 0 || interval%Resolution != 0 || interval > Window {
+		return nil, fmt.Errorf("%w: %s (want whole minutes up to %s)", ErrInvalidInterval, interval, Window)
+	}
+
+	end := now.Truncate(interval).Add(interval)
+	start := end.Add(-Window).Truncate(interval)
+
+	a.mutex.RLock()
+	buckets := slices.Clone(a.symbols[symbol])
+	a.mutex.RUnlock()
+
+	candles := []Candle{}
+	var last *Candle
+	i := 0
+	// Buckets before the window only carry their close forward
+	for ; i < len(buckets) && buckets[i].Start.Before(start); i++ {
+		last = &buckets[i]
+	}
+
+	for t := start; t.Before(end); t = t.Add(interval) {
+		next := t.Add(interval)
+		var c *Candle
+		for ; i < len(buckets) && buckets[i].Start.Before(next); i++ {
+			b := buckets[i]
+			if c == nil {
+				c = &Candle{Start: t, Open: b.Open, High: b.High, Low: b.Low}
+			}
+			c.High = max(c.High, b.High)
+			c.Low = min(c.Low, b.Low)
+			c.Close = b.Close
+			c.Volume += b.Volume
+			c.Trades += b.Trades
+		}
+
+		switch {
+		case c != nil:
+			last = c
+			candles = append(candles, *c)
+		case last != nil:
+			candles = append(candles, Candle{Start: t, Open: last.Close, High: last.Close, Low: last.Close, Close: last.Close})
+		}
+	}
+	return candles, nil
+}
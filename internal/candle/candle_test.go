@@ -0,0 +1,119 @@
+package candle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+var base = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func newAggregator(t *testing.T, trades ...orderbook.Trade) *Aggregator {
+	t.Helper()
+
+	a := NewAggregator()
+	for _, trade := range trades {
+		a.Apply(trade)
+	}
+	return a
+}
+
+func trade(at time.Duration, price, quantity float64) orderbook.Trade {
+	return orderbook.Trade{Symbol: "BTC-USD", Price: price, Quantity: quantity, Timestamp: base.Add(at)}
+}
+
+// lastCandles returns the final n candles of a window ending at now
+func lastCandles(t *testing.T, a *Aggregator, interval time.Duration, now time.Time, n int) []Candle {
+	t.Helper()
+
+	candles, err := a.Candles("BTC-USD", interval, now)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(candles), n)
+	return candles[len(candles)-n:]
+}
+
+func TestCandles_MinuteBuckets(t *testing.T) {
+	a := newAggregator(t,
+		trade(5*time.Second, 100, 1),
+		trade(20*time.Second, 104, 2),
+		trade(50*time.Second, 99, 1),
+		trade(time.Minute+10*time.Second, 101, 3),
+	)
+
+	candles := lastCandles(t, a, time.Minute, base.Add(90*time.Second), 2)
+	assert.Equal(t, Candle{Start: base, Open: 100, High: 104, Low: 99, Close: 99, Volume: 4, Trades: 3}, candles[0])
+	assert.Equal(t, Candle{Start: base.Add(time.Minute), Open: 101, High: 101, Low: 101, Close: 101, Volume: 3, Trades: 1}, candles[1])
+}
+
+func TestCandles_ForwardFillsQuietIntervals(t *testing.T) {
+	a := newAggregator(t,
+		trade(0, 100, 1),
+		trade(3*time.Minute, 102, 1),
+	)
+
+	candles, err := a.Candles("BTC-USD", time.Minute, base.Add(4*time.Minute))
+	require.NoError(t, err)
+
+	// Nothing before the first trade, then one candle per minute through
+	// the one in progress
+	require.Len(t, candles, 5)
+	assert.Equal(t, base, candles[0].Start)
+	for _, quiet := range candles[1:3] {
+		assert.Equal(t, 100.0, quiet.Open)
+		assert.Equal(t, 100.0, quiet.Close)
+		assert.Zero(t, quiet.Volume)
+		assert.Zero(t, quiet.Trades)
+	}
+	assert.Equal(t, 102.0, candles[3].Close)
+	assert.Equal(t, base.Add(4*time.Minute), candles[4].Start)
+	assert.Equal(t, 102.0, candles[4].Open)
+	assert.Zero(t, candles[4].Volume)
+}
+
+func TestCandles_WiderIntervals(t *testing.T) {
+	a := newAggregator(t,
+		trade(time.Minute, 100, 1),
+		trade(4*time.Minute, 110, 1),
+		trade(6*time.Minute, 90, 2),
+		trade(14*time.Minute, 95, 1),
+	)
+
+	candles := lastCandles(t, a, 5*time.Minute, base.Add(14*time.Minute), 3)
+	assert.Equal(t, Candle{Start: base, Open: 100, High: 110, Low: 100, Close: 110, Volume: 2, Trades: 2}, candles[0])
+	assert.Equal(t, Candle{Start: base.Add(5 * time.Minute), Open: 90, High: 90, Low: 90, Close: 90, Volume: 2, Trades: 1}, candles[1])
+	assert.Equal(t, Candle{Start: base.Add(10 * time.Minute), Open: 95, High: 95, Low: 95, Close: 95, Volume: 1, Trades: 1}, candles[2])
+}
+
+func TestCandles_RollingWindow(t *testing.T) {
+	a := newAggregator(t, trade(0, 100, 1))
+
+	// Once the trade leaves the window its close still seeds the candles
+	now := base.Add(Window + time.Hour)
+	candles, err := a.Candles("BTC-USD", time.Hour, now)
+	require.NoError(t, err)
+	require.Len(t, candles, 24)
+	assert.Equal(t, base.Add(2*time.Hour), candles[0].Start)
+	assert.Equal(t, 100.0, candles[0].Open)
+	assert.Zero(t, candles[0].Volume)
+
+	// A later trade drops the minute candles that fell out of the window
+	a.Apply(trade(Window+time.Hour, 120, 1))
+	assert.Len(t, a.symbols["BTC-USD"], 1)
+}
+
+func TestCandles_InvalidInterval(t *testing.T) {
+	a := NewAggregator()
+
+	for _, interval := range []time.Duration{0, -time.Minute, 90 * time.Second, 48 * time.Hour} {
+		_, err := a.Candles("BTC-USD", interval, base)
+		assert.ErrorIs(t, err, ErrInvalidInterval, "interval %s", interval)
+	}
+
+	candles, err := a.Candles("ETH-USD", time.Minute, base)
+	require.NoError(t, err)
+	assert.Empty(t, candles)
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,10 +17,45 @@ type Config struct {
 }
 
 type ServerConfig struct {
-	Port         string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
-	IdleTimeout  time.Duration
+	Port              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	// IdleTimeout is how long a kept-alive connection may wait for its next
+	// request, over HTTP/1.1 and HTTP/2 alike
+	IdleTimeout    time.Duration
+	MaxHeaderBytes int
+	// KeepAlives lets HTTP/1.1 clients reuse connections
+	KeepAlives bool
+	// MaxConnections caps the connections open at once; further ones wait
+	// to be accepted. Zero means no cap.
+	MaxConnections int
+
+	// H2C serves HTTP/2 over cleartext to clients that speak it from the
+	// first byte or upgrade to it. HTTP/2 over TLS needs no setting.
+	H2C bool
+	// HTTP2MaxConcurrentStreams caps the requests in flight on one HTTP/2
+	// connection
+	HTTP2MaxConcurrentStreams uint32
+
+	TLS TLSConfig
+}
+
+// TLSConfig locates the server's certificate and bounds the TLS it speaks.
+// Without a certificate the server speaks plaintext.
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// MinVersion is the oldest TLS version accepted, "1.2" or "1.3"
+	MinVersion string
+	// CipherSuites names the suites offered over TLS 1.2, as Go names them;
+	// empty leaves Go's defaults. TLS 1.3 suites can't be chosen.
+	CipherSuites []string
+}
+
+// Enabled reports whether a certificate is configured
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
 }
 
 type LoggerConfig struct {
@@ -38,10 +74,22 @@ func Load() (*Config, error) {
 
 	return &Config{
 		Server: ServerConfig{
-			Port:         getEnv("SERVER_PORT", "8080"),
-			ReadTimeout:  getDurationEnv("SERVER_READ_TIMEOUT", 15*time.Second),
-			WriteTimeout: getDurationEnv("SERVER_WRITE_TIMEOUT", 15*time.Second),
-			IdleTimeout:  getDurationEnv("SERVER_IDLE_TIMEOUT", 60*time.Second),
+			Port:                      getEnv("SERVER_PORT", "8080"),
+			ReadTimeout:               getDurationEnv("SERVER_READ_TIMEOUT", 15*time.Second),
+			ReadHeaderTimeout:         getDurationEnv("SERVER_READ_HEADER_TIMEOUT", 5*time.Second),
+			WriteTimeout:              getDurationEnv("SERVER_WRITE_TIMEOUT", 15*time.Second),
+			IdleTimeout:               getDurationEnv("SERVER_IDLE_TIMEOUT", 60*time.Second),
+			MaxHeaderBytes:            getIntEnv("SERVER_MAX_HEADER_BYTES", 1<<20),
+			KeepAlives:                getBoolEnv("SERVER_KEEP_ALIVES", true),
+			MaxConnections:            getIntEnv("SERVER_MAX_CONNECTIONS", 0),
+			H2C:                       getBoolEnv("SERVER_H2C", false),
+			HTTP2MaxConcurrentStreams: uint32(getIntEnv("SERVER_HTTP2_MAX_STREAMS", 250)),
+			TLS: TLSConfig{
+				CertFile:     getEnv("TLS_CERT_FILE", ""),
+				KeyFile:      getEnv("TLS_KEY_FILE", ""),
+				MinVersion:   getEnv("TLS_MIN_VERSION", "1.2"),
+				CipherSuites: getSliceEnv("TLS_CIPHER_SUITES", nil),
+			},
 		},
 		Logger: LoggerConfig{
 			Level: getEnv("LOG_LEVEL", "info"),
@@ -70,6 +118,24 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+func getIntEnv(key string, defaultValue int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+func getBoolEnv(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
 func getSliceEnv(key string, defaultValue []string) []string {
 	if value, exists := os.LookupEnv(key); exists {
 		return strings.Split(value, ",")
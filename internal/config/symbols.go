@@ -0,0 +1,140 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+// SymbolsFile is the symbol universe an operator defines in a file: every
+// symbol's tick and lot precision, fees, price band, circuit breaker and
+// whether it is halted
+type SymbolsFile struct {
+	Symbols []SymbolSettings `json:"symbols"`
+}
+
+// SymbolSettings is one symbol's entry in a SymbolsFile. It takes the
+// fields of orderbook.SymbolConfig under the same names, with durations
+// written as strings such as "30s".
+type SymbolSettings struct {
+	orderbook.SymbolConfig
+
+	CircuitBreakerWindow   Duration `json:"circuit_breaker_window,omitempty"`
+	CircuitBreakerCooldown Duration `json:"circuit_breaker_cooldown,omitempty"`
+	OrderTTL               Duration `json:"order_ttl,omitempty"`
+	// Halted halts or resumes the symbol when set, and leaves its state
+	// alone when absent
+	Halted *bool `json:"halted,omitempty"`
+}
+
+// NewSymbolSettings describes a book config as a SymbolsFile entry
+func NewSymbolSettings(config orderbook.SymbolConfig, halted *bool) SymbolSettings {
+	return SymbolSettings{
+		SymbolConfig:           config,
+		CircuitBreakerWindow:   Duration(config.CircuitBreakerWindow),
+		CircuitBreakerCooldown: Duration(config.CircuitBreakerCooldown),
+		OrderTTL:               Duration(config.OrderTTL),
+		Halted:                 halted,
+	}
+}
+
+// Config returns the book config the entry describes
+func (s SymbolSettings) Config() orderbook.SymbolConfig {
+	config := s.SymbolConfig
+	config.CircuitBreakerWindow = time.Duration(s.CircuitBreakerWindow)
+	config.CircuitBreakerCooldown = time.Duration(s.CircuitBreakerCooldown)
+	config.OrderTTL = time.Duration(s.OrderTTL)
+	return config
+}
+
+// Duration is a time.Duration read from a string such as "1m30s"
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// SymbolRegistry is what a SymbolsFile is applied to. ConfigureSymbol
+// creates or updates a symbol and, when halted isn't nil, halts or resumes
+// it in the same step.
+type SymbolRegistry interface {
+	ConfigureSymbol(config orderbook.SymbolConfig, halted *bool) error
+}
+
+// LoadSymbols reads a SymbolsFile, as YAML when the file is named .yaml or
+// .yml and as JSON otherwise
+func LoadSymbols(path string) (*SymbolsFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading symbols file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return ParseSymbolsYAML(data)
+	default:
+		return ParseSymbols(data)
+	}
+}
+
+// ParseSymbols parses a SymbolsFile written as JSON
+func ParseSymbols(data []byte) (*SymbolsFile, error) {
+	var file SymbolsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("error parsing symbols file: %w", err)
+	}
+	for i, settings := range file.Symbols {
+		if settings.Symbol == "" {
+			return nil, fmt.Errorf("error parsing symbols file: entry %d has no symbol", i)
+		}
+	}
+	return &file, nil
+}
+
+// ParseSymbolsYAML parses a SymbolsFile written as YAML. Keys are the same
+// as in JSON.
+func ParseSymbolsYAML(data []byte) (*SymbolsFile, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing symbols file: %w", err)
+	}
+	converted, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing symbols file: %w", err)
+	}
+	return ParseSymbols(converted)
+}
+
+// Apply registers every symbol of the file, updating the config of those
+// that already exist, and halts or resumes those that say so. Each symbol
+// changes at once, but symbols change one after another. Symbols missing
+// from the file are left as they are. It stops at the first symbol the
+// registry refuses.
+func (f *SymbolsFile) Apply(registry SymbolRegistry) error {
+	for _, settings := range f.Symbols {
+		if err := registry.ConfigureSymbol(settings.Config(), settings.Halted); err != nil {
+			return fmt.Errorf("error configuring %s: %w", settings.Symbol, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"company.com/matchengine/internal/service/matching"
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+var _ SymbolRegistry = (*matching.Service)(nil)
+
+const sampleYAML = `
+symbols:
+  - symbol: BTC-USD
+    precision: {price: 2, quantity: 4}
+    taker_fee_rate: 0.001
+    maker_fee_rate: -0.0002
+    price_band_percent: 10
+    reference_price: 50000
+    circuit_breaker_percent: 5
+    circuit_breaker_window: 1m
+    circuit_breaker_cooldown: 30s
+    order_ttl: 24h
+  - symbol: ETH-USD
+    halted: true
+  - symbol: CAL-SPREAD
+    allow_negative_price: true
+`
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadSymbols(t *testing.T) {
+	file, err := LoadSymbols(writeFile(t, "symbols.yaml", sampleYAML))
+	require.NoError(t, err)
+	require.Len(t, file.Symbols, 3)
+
+	btc := file.Symbols[0].Config()
+	assert.Equal(t, "BTC-USD", btc.Symbol)
+	assert.Equal(t, &order.Precision{Price: 2, Quantity: 4}, btc.Precision)
+	assert.Equal(t, 0.001, btc.TakerFeeRate)
+	assert.Equal(t, -0.0002, btc.MakerFeeRate)
+	assert.Equal(t, 10.0, btc.PriceBandPercent)
+	assert.Equal(t, time.Minute, btc.CircuitBreakerWindow)
+	assert.Equal(t, 30*time.Second, btc.CircuitBreakerCooldown)
+	assert.Equal(t, 24*time.Hour, btc.OrderTTL)
+	assert.Nil(t, file.Symbols[0].Halted)
+
+	require.NotNil(t, file.Symbols[1].Halted)
+	assert.True(t, *file.Symbols[1].Halted)
+	assert.False(t, btc.AllowNegativePrice)
+	assert.True(t, file.Symbols[2].Config().AllowNegativePrice)
+
+	// The same file as JSON
+	file, err = LoadSymbols(writeFile(t, "symbols.json", `{"symbols": [
+		{"symbol": "BTC-USD", "precision": {"price": 2, "quantity": 4}, "circuit_breaker_window": "1m"}
+	]}`))
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, file.Symbols[0].Config().CircuitBreakerWindow)
+
+	_, err = ParseSymbols([]byte(`{"symbols": [{"price_band_percent": 5}]}`))
+	assert.Error(t, err)
+	_, err = ParseSymbols([]byte(`{"symbols": [{"symbol": "BTC-USD", "circuit_breaker_window": "soon"}]}`))
+	assert.Error(t, err)
+	_, err = LoadSymbols(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestSymbolsFile_ApplyAndReload(t *testing.T) {
+	service := matching.NewService()
+	ctx := context.Background()
+
+	path := writeFile(t, "symbols.yaml", sampleYAML)
+	file, err := LoadSymbols(path)
+	require.NoError(t, err)
+	require.NoError(t, file.Apply(service))
+
+	_, err = service.GetOrderBook(ctx, "ETH-USD")
+	require.NoError(t, err)
+	eth, err := order.NewOrder(order.SideBuy, "ETH-USD", 3000, 1)
+	require.NoError(t, err)
+	assert.ErrorIs(t, service.AddOrder(ctx, eth), orderbook.ErrTradingHalted)
+
+	place := func(price float64) float64 {
+		o, err := order.NewOrder(order.SideBuy, "BTC-USD", price, 1)
+		require.NoError(t, err)
+		require.NoError(t, service.AddOrder(ctx, o))
+		return o.Price
+	}
+	assert.Equal(t, 50000.12, place(50000.123))
+
+	// Reloading with a coarser tick rounds new orders to it, and resumes ETH
+	require.NoError(t, os.WriteFile(path, []byte(`
+symbols:
+  - symbol: BTC-USD
+    precision: {price: 1, quantity: 4}
+  - symbol: ETH-USD
+    halted: false
+`), 0o600))
+	file, err = LoadSymbols(path)
+	require.NoError(t, err)
+	require.NoError(t, file.Apply(service))
+
+	assert.Equal(t, 50000.1, place(50000.123))
+	eth, err = order.NewOrder(order.SideBuy, "ETH-USD", 3000, 1)
+	require.NoError(t, err)
+	assert.NoError(t, service.AddOrder(ctx, eth))
+}
+
+func TestNewSymbolSettings(t *testing.T) {
+	file, err := ParseSymbolsYAML([]byte(sampleYAML))
+	require.NoError(t, err)
+	config := file.Symbols[0].Config()
+
+	// Written back out, an entry reads the same as in the file
+	halted := false
+	data, err := json.Marshal(NewSymbolSettings(config, &halted))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"order_ttl":"24h0m0s"`)
+
+	parsed, err := ParseSymbols([]byte(`{"symbols":[` + string(data) + `]}`))
+	require.NoError(t, err)
+	assert.Equal(t, config, parsed.Symbols[0].Config())
+	require.NotNil(t, parsed.Symbols[0].Halted)
+	assert.False(t, *parsed.Symbols[0].Halted)
+}
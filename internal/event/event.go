@@ -0,0 +1,165 @@
+package event
+
+import (
+	"time"
+
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+// Type identifies what happened in an event
+type Type string
+
+// Constants for event types
+const (
+	OrderCreated         Type = "order.created"
+	OrderPartiallyFilled Type = "order.partially_filled"
+	OrderFilled          Type = "order.filled"
+	OrderCancelled       Type = "order.cancelled"
+	OrderExpired         Type = "order.expired"
+	OrderAmended         Type = "order.amended"
+	OrderFill            Type = "order.fill"
+	TradeExecuted        Type = "trade.executed"
+	SymbolHalted         Type = "symbol.halted"
+)
+
+// Event is an order lifecycle or market event published by the matching service
+type Event struct {
+	Type   Type                 `json:"type"`
+	Symbol string               `json:"symbol"`
+	Order  *order.Order         `json:"order,omitempty"`
+	Trade  *orderbook.Trade     `json:"trade,omitempty"`
+	Halt   *orderbook.HaltEvent `json:"halt,omitempty"`
+	Fill   *FillEvent           `json:"fill,omitempty"`
+	// Sequence is the book's sequence number after the change, for
+	// clients to detect gaps against snapshots
+	Sequence  uint64    `json:"sequence,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Liquidity tells whether a fill's order rested on the book or took from it
+type Liquidity string
+
+const (
+	LiquidityMaker Liquidity = "maker"
+	LiquidityTaker Liquidity = "taker"
+)
+
+// FillEvent is one fill seen from one of the orders in it: what this fill
+// added and where the order stands after it. A trade gives one to its maker
+// and one to its taker.
+type FillEvent struct {
+	OrderID       string     `json:"order_id"`
+	ClientOrderID string     `json:"client_order_id,omitempty"`
+	AccountID     string     `json:"account_id,omitempty"`
+	Side          order.Side `json:"side"`
+	Liquidity     Liquidity  `json:"liquidity"`
+	// LastQuantity and LastPrice are this fill's, and Fee what it cost the
+	// order
+	LastQuantity float64 `json:"last_quantity"`
+	LastPrice    float64 `json:"last_price"`
+	Fee          float64 `json:"fee"`
+	// CumulativeQuantity and AvgPrice cover every fill of the order so far,
+	// this one included
+	CumulativeQuantity float64      `json:"cumulative_quantity"`
+	AvgPrice           float64      `json:"avg_price"`
+	RemainingQuantity  float64      `json:"remaining_quantity"`
+	Status             order.Status `json:"status"`
+	Timestamp          time.Time    `json:"timestamp"`
+}
+
+// EventPublisher receives events from the matching service. Publish is
+// called outside of any book lock but on the caller's goroutine, so
+// implementations should hand slow work off rather than block.
+type EventPublisher interface {
+	Publish(e Event)
+}
+
+// FromUpdate maps an order book state change to its lifecycle event
+func FromUpdate(u orderbook.Update) Event {
+	o := u.Order
+
+	e := Event{
+		Symbol:    o.Symbol,
+		Order:     &o,
+		Trade:     u.Trade,
+		Sequence:  u.Sequence,
+		Timestamp: o.UpdatedAt,
+	}
+
+	switch o.Status {
+	case order.StatusFilled:
+		e.Type = OrderFilled
+	case order.StatusPartial:
+		e.Type = OrderPartiallyFilled
+	case order.StatusCancelled:
+		e.Type = OrderCancelled
+		if u.Expired {
+			e.Type = OrderExpired
+		}
+	default:
+		e.Type = OrderCreated
+	}
+	if u.Amended {
+		e.Type = OrderAmended
+	}
+	return e
+}
+
+// FromFill maps an order book update that fills its order to that order's
+// fill event; ok is false for updates without a trade
+func FromFill(u orderbook.Update) (e Event, ok bool) {
+	t := u.Trade
+	if t == nil {
+		return Event{}, false
+	}
+	o := u.Order
+
+	fill := &FillEvent{
+		OrderID:            o.ID,
+		ClientOrderID:      o.ClientOrderID,
+		AccountID:          o.AccountID,
+		Side:               o.Side,
+		Liquidity:          LiquidityMaker,
+		LastQuantity:       t.Quantity,
+		LastPrice:          t.Price,
+		Fee:                t.MakerFee,
+		CumulativeQuantity: o.Filled,
+		AvgPrice:           o.AvgFillPrice,
+		RemainingQuantity:  o.RemainingQuantity(),
+		Status:             o.Status,
+		Timestamp:          t.Timestamp,
+	}
+	if o.ID == t.TakerOrderID {
+		fill.Liquidity = LiquidityTaker
+		fill.Fee = t.TakerFee
+	}
+
+	return Event{
+		Type:      OrderFill,
+		Symbol:    o.Symbol,
+		Fill:      fill,
+		Sequence:  u.Sequence,
+		Timestamp: t.Timestamp,
+	}, true
+}
+
+// FromTrade wraps an executed trade as an event
+func FromTrade(t orderbook.Trade) Event {
+	return Event{
+		Type:      TradeExecuted,
+		Symbol:    t.Symbol,
+		Trade:     &t,
+		Timestamp: t.Timestamp,
+	}
+}
+
+// FromHalt wraps a circuit breaker halt as an event
+func FromHalt(h orderbook.HaltEvent) Event {
+	return Event{
+		Type:      SymbolHalted,
+		Symbol:    h.Symbol,
+		Halt:      &h,
+		Timestamp: h.HaltedAt,
+	}
+}
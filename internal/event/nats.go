@@ -0,0 +1,131 @@
+package event
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Conn is the part of a NATS connection the publisher needs. *nats.Conn
+// from github.com/nats-io/nats.go satisfies it.
+type Conn interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSConfig tunes the NATS publisher
+type NATSConfig struct {
+	// SubjectPrefix is prepended to every subject (default "matchengine")
+	SubjectPrefix string
+	// BufferSize bounds the events waiting to be sent (default 1024)
+	BufferSize int
+	// MaxRetries is how many times a failed publish is retried (default 3,
+	// negative disables retries)
+	MaxRetries int
+	// RetryBackoff is the pause between retries (default 100ms)
+	RetryBackoff time.Duration
+}
+
+// NATSPublisher sends events to NATS as JSON on subjects of the form
+// <prefix>.<symbol>.<event type>, e.g. matchengine.BTC-USD.trade.executed.
+// Publishing only enqueues; a background goroutine does the network work,
+// so a slow or broken connection never blocks matching. Events that can't
+// be queued or still fail after retries are dropped and counted.
+type NATSPublisher struct {
+	conn   Conn
+	config NATSConfig
+	logger *slog.Logger
+
+	queue   chan Event
+	done    chan struct{}
+	close   sync.Once
+	dropped atomic.Uint64
+}
+
+func NewNATSPublisher(conn Conn, config NATSConfig, logger *slog.Logger) *NATSPublisher {
+	if config.SubjectPrefix == "" {
+		config.SubjectPrefix = "matchengine"
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = 1024
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	} else if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 100 * time.Millisecond
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	p := &NATSPublisher{
+		conn:   conn,
+		config: config,
+		logger: logger,
+		queue:  make(chan Event, config.BufferSize),
+		done:   make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+func (p *NATSPublisher) Publish(e Event) {
+	select {
+	case p.queue <- e:
+	default:
+		p.dropped.Add(1)
+	}
+}
+
+// Close stops accepting events and waits for the queued ones to be sent
+func (p *NATSPublisher) Close() {
+	p.close.Do(func() {
+		close(p.queue)
+		<-p.done
+	})
+}
+
+// Dropped returns how many events were discarded
+func (p *NATSPublisher) Dropped() uint64 {
+	return p.dropped.Load()
+}
+
+// Subject returns the subject an event is published on
+func (p *NATSPublisher) Subject(e Event) string {
+	return p.config.SubjectPrefix + "." + e.Symbol + "." + string(e.Type)
+}
+
+func (p *NATSPublisher) run() {
+	defer close(p.done)
+
+	for e := range p.queue {
+		data, err := json.Marshal(e)
+		if err != nil {
+			p.logger.Error("failed to encode event", "type", e.Type, "error", err)
+			p.dropped.Add(1)
+			continue
+		}
+
+		if err := p.send(p.Subject(e), data); err != nil {
+			p.logger.Warn("dropping event after failed publish",
+				"type", e.Type,
+				"symbol", e.Symbol,
+				"error", err,
+			)
+			p.dropped.Add(1)
+		}
+	}
+}
+
+func (p *NATSPublisher) send(subject string, data []byte) error {
+	err := p.conn.Publish(subject, data)
+	for attempt := 0; err != nil && attempt < p.config.MaxRetries; attempt++ {
+		time.Sleep(p.config.RetryBackoff)
+		err = p.conn.Publish(subject, data)
+	}
+	return err
+}
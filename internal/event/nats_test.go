@@ -0,0 +1,127 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"company.com/matchengine/pkg/engine/orderbook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type natsMessage struct {
+	subject string
+	data    []byte
+}
+
+// mockConn records published messages and fails the first failures calls
+type mockConn struct {
+	mutex    sync.Mutex
+	messages []natsMessage
+	failures int
+	block    chan struct{}
+}
+
+func (c *mockConn) Publish(subject string, data []byte) error {
+	if c.block != nil {
+		<-c.block
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.failures > 0 {
+		c.failures--
+		return errors.New("nats: connection closed")
+	}
+	c.messages = append(c.messages, natsMessage{subject: subject, data: data})
+	return nil
+}
+
+func (c *mockConn) published() []natsMessage {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]natsMessage(nil), c.messages...)
+}
+
+func tradeEvent() Event {
+	return FromTrade(orderbook.Trade{
+		Symbol:       "BTC-USD",
+		Price:        100,
+		Quantity:     2,
+		TakerOrderID: "taker",
+		MakerOrderID: "maker",
+		Timestamp:    time.Now(),
+	})
+}
+
+func TestNATSPublisherPublishesTrade(t *testing.T) {
+	conn := &mockConn{}
+	publisher := NewNATSPublisher(conn, NATSConfig{}, nil)
+
+	publisher.Publish(tradeEvent())
+	publisher.Close()
+
+	messages := conn.published()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "matchengine.BTC-USD.trade.executed", messages[0].subject)
+
+	var e Event
+	require.NoError(t, json.Unmarshal(messages[0].data, &e))
+	assert.Equal(t, TradeExecuted, e.Type)
+	require.NotNil(t, e.Trade)
+	assert.Equal(t, 100.0, e.Trade.Price)
+	assert.Equal(t, "taker", e.Trade.TakerOrderID)
+}
+
+func TestNATSPublisherRetriesFailures(t *testing.T) {
+	conn := &mockConn{failures: 2}
+	publisher := NewNATSPublisher(conn, NATSConfig{RetryBackoff: time.Millisecond}, nil)
+
+	publisher.Publish(tradeEvent())
+	publisher.Close()
+
+	assert.Len(t, conn.published(), 1)
+	assert.Zero(t, publisher.Dropped())
+}
+
+func TestNATSPublisherDropsAfterRetries(t *testing.T) {
+	conn := &mockConn{failures: 10}
+	publisher := NewNATSPublisher(conn, NATSConfig{MaxRetries: 1, RetryBackoff: time.Millisecond}, nil)
+
+	publisher.Publish(tradeEvent())
+	publisher.Close()
+
+	assert.Empty(t, conn.published())
+	assert.Equal(t, uint64(1), publisher.Dropped())
+}
+
+func TestNATSPublisherDoesNotBlock(t *testing.T) {
+	conn := &mockConn{block: make(chan struct{})}
+	publisher := NewNATSPublisher(conn, NATSConfig{BufferSize: 1}, nil)
+
+	// The worker holds one event in a stuck Publish and one sits in the
+	// buffer; everything after that must be dropped rather than block
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			publisher.Publish(tradeEvent())
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a stuck connection")
+	}
+
+	close(conn.block)
+	publisher.Close()
+
+	assert.Equal(t, uint64(10), publisher.Dropped()+uint64(len(conn.published())))
+	assert.GreaterOrEqual(t, publisher.Dropped(), uint64(8))
+}
@@ -0,0 +1,46 @@
+package event
+
+import "sync/atomic"
+
+// NopPublisher discards every event
+type NopPublisher struct{}
+
+func (NopPublisher) Publish(Event) {}
+
+// ChannelPublisher delivers events on a buffered channel. When the buffer
+// is full events are dropped rather than stalling the matching service.
+type ChannelPublisher struct {
+	events  chan Event
+	dropped atomic.Uint64
+}
+
+func NewChannelPublisher(buffer int) *ChannelPublisher {
+	return &ChannelPublisher{events: make(chan Event, buffer)}
+}
+
+func (p *ChannelPublisher) Publish(e Event) {
+	select {
+	case p.events <- e:
+	default:
+		p.dropped.Add(1)
+	}
+}
+
+// Events returns the channel events are delivered on
+func (p *ChannelPublisher) Events() <-chan Event {
+	return p.events
+}
+
+// Dropped returns how many events were discarded because the buffer was full
+func (p *ChannelPublisher) Dropped() uint64 {
+	return p.dropped.Load()
+}
+
+// MultiPublisher hands every event to each of its publishers in turn
+type MultiPublisher []EventPublisher
+
+func (m MultiPublisher) Publish(e Event) {
+	for _, p := range m {
+		p.Publish(e)
+	}
+}
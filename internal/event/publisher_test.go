@@ -0,0 +1,75 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+func TestFromUpdate(t *testing.T) {
+	tests := []struct {
+		status order.Status
+		want   Type
+	}{
+		{order.StatusNew, OrderCreated},
+		{order.StatusPartial, OrderPartiallyFilled},
+		{order.StatusFilled, OrderFilled},
+		{order.StatusCancelled, OrderCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			e := FromUpdate(orderbook.Update{Order: order.Order{ID: "1", Symbol: "BTC-USD", Status: tt.status}})
+			assert.Equal(t, tt.want, e.Type)
+			assert.Equal(t, "BTC-USD", e.Symbol)
+		})
+	}
+}
+
+func TestFromUpdate_Expired(t *testing.T) {
+	e := FromUpdate(orderbook.Update{Order: order.Order{ID: "1", Status: order.StatusCancelled}, Expired: true})
+	assert.Equal(t, OrderExpired, e.Type)
+}
+
+func TestFromUpdate_Amended(t *testing.T) {
+	e := FromUpdate(orderbook.Update{Order: order.Order{ID: "1", Status: order.StatusPartial}, Amended: true})
+	assert.Equal(t, OrderAmended, e.Type)
+}
+
+func TestFromFill(t *testing.T) {
+	trade := &orderbook.Trade{Price: 101, Quantity: 2, TakerOrderID: "taker", MakerOrderID: "maker", MakerFee: 0.1, TakerFee: 0.3}
+	maker := order.Order{ID: "maker", Symbol: "BTC-USD", Quantity: 5, Filled: 3, AvgFillPrice: 100.5, Status: order.StatusPartial}
+	taker := order.Order{ID: "taker", Symbol: "BTC-USD", Quantity: 2, Filled: 2, AvgFillPrice: 101, Status: order.StatusFilled}
+
+	e, ok := FromFill(orderbook.Update{Order: maker, Trade: trade, Sequence: 7})
+	assert.True(t, ok)
+	assert.Equal(t, OrderFill, e.Type)
+	assert.Equal(t, uint64(7), e.Sequence)
+	assert.Equal(t, LiquidityMaker, e.Fill.Liquidity)
+	assert.Equal(t, 2.0, e.Fill.LastQuantity)
+	assert.Equal(t, 101.0, e.Fill.LastPrice)
+	assert.Equal(t, 0.1, e.Fill.Fee)
+	assert.Equal(t, 3.0, e.Fill.CumulativeQuantity)
+	assert.Equal(t, 100.5, e.Fill.AvgPrice)
+	assert.Equal(t, 2.0, e.Fill.RemainingQuantity)
+
+	e, _ = FromFill(orderbook.Update{Order: taker, Trade: trade})
+	assert.Equal(t, LiquidityTaker, e.Fill.Liquidity)
+	assert.Equal(t, 0.3, e.Fill.Fee)
+
+	_, ok = FromFill(orderbook.Update{Order: maker})
+	assert.False(t, ok)
+}
+
+func TestChannelPublisher_DropsWhenFull(t *testing.T) {
+	p := NewChannelPublisher(1)
+
+	p.Publish(Event{Type: OrderCreated})
+	p.Publish(Event{Type: OrderFilled})
+
+	assert.Equal(t, OrderCreated, (<-p.Events()).Type)
+	assert.Equal(t, uint64(1), p.Dropped())
+}
@@ -0,0 +1,267 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+// ErrInvalidCallbackURL is returned for callback URLs fill notifications
+// can't or mustn't be sent to
+var ErrInvalidCallbackURL = errors.New("invalid callback url")
+
+// FillNotification is the body POSTed to an order's callback URL when the
+// order fills or partially fills
+type FillNotification struct {
+	Type  Type             `json:"type"`
+	Order *order.Order     `json:"order"`
+	Trade *orderbook.Trade `json:"trade,omitempty"`
+	// Sequence is the book's sequence number after the fill
+	Sequence  uint64    `json:"sequence,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// WebhookConfig tunes the webhook publisher
+type WebhookConfig struct {
+	// BufferSize bounds the notifications waiting to be sent (default 1024)
+	BufferSize int
+	// Timeout bounds each delivery attempt (default 5s)
+	Timeout time.Duration
+	// MaxRetries is how many times a failed delivery is retried (default
+	// 3, negative disables retries)
+	MaxRetries int
+	// RetryBackoff is the pause between retries (default 500ms)
+	RetryBackoff time.Duration
+	// AllowPrivateAddresses lets notifications reach loopback, private and
+	// link-local addresses. Leave it off in production: callback URLs come
+	// from clients, and without the guard they could reach internal
+	// services.
+	AllowPrivateAddresses bool
+}
+
+// WebhookPublisher POSTs a FillNotification to an order's CallbackURL
+// whenever the order fills or partially fills; other events and orders
+// without a callback URL are ignored. Like the NATS publisher it only
+// enqueues on Publish and delivers from a background goroutine, so a slow
+// or unreachable endpoint never holds up matching. Notifications that can't
+// be queued or still fail after retries are dropped and counted.
+type WebhookPublisher struct {
+	client *http.Client
+	config WebhookConfig
+	logger *slog.Logger
+
+	queue   chan Event
+	done    chan struct{}
+	close   sync.Once
+	dropped atomic.Uint64
+}
+
+func NewWebhookPublisher(config WebhookConfig, logger *slog.Logger) *WebhookPublisher {
+	if config.BufferSize <= 0 {
+		config.BufferSize = 1024
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	} else if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 500 * time.Millisecond
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	dialer := &net.Dialer{Timeout: config.Timeout}
+	if !config.AllowPrivateAddresses {
+		// Checked on the address actually dialled, so a host name that
+		// resolves to an internal address is refused too
+		dialer.Control = refusePrivateAddresses
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	p := &WebhookPublisher{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   config.Timeout,
+			// A redirect could point anywhere; endpoints must answer
+			// themselves
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		config: config,
+		logger: logger,
+		queue:  make(chan Event, config.BufferSize),
+		done:   make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+func (p *WebhookPublisher) Publish(e Event) {
+	if e.Type != OrderFilled && e.Type != OrderPartiallyFilled {
+		return
+	}
+	if e.Order == nil || e.Order.CallbackURL == "" {
+		return
+	}
+
+	select {
+	case p.queue <- e:
+	default:
+		p.dropped.Add(1)
+	}
+}
+
+// Close stops accepting notifications and waits for the queued ones to be
+// sent
+func (p *WebhookPublisher) Close() {
+	p.close.Do(func() {
+		close(p.queue)
+		<-p.done
+	})
+}
+
+// Dropped returns how many notifications were discarded
+func (p *WebhookPublisher) Dropped() uint64 {
+	return p.dropped.Load()
+}
+
+func (p *WebhookPublisher) run() {
+	defer close(p.done)
+
+	for e := range p.queue {
+		data, err := json.Marshal(FillNotification{
+			Type:      e.Type,
+			Order:     e.Order,
+			Trade:     e.Trade,
+			Sequence:  e.Sequence,
+			Timestamp: e.Timestamp,
+		})
+		if err != nil {
+			p.logger.Error("failed to encode fill notification", "order_id", e.Order.ID, "error", err)
+			p.dropped.Add(1)
+			continue
+		}
+
+		if err := p.send(e.Order.CallbackURL, data); err != nil {
+			p.logger.Warn("dropping fill notification after failed delivery",
+				"order_id", e.Order.ID,
+				"symbol", e.Symbol,
+				"error", err,
+			)
+			p.dropped.Add(1)
+		}
+	}
+}
+
+func (p *WebhookPublisher) send(callbackURL string, data []byte) error {
+	if err := validateCallbackURL(callbackURL, p.config.AllowPrivateAddresses); err != nil {
+		return err
+	}
+
+	err := p.post(callbackURL, data)
+	for attempt := 0; err != nil && attempt < p.config.MaxRetries; attempt++ {
+		time.Sleep(p.config.RetryBackoff)
+		err = p.post(callbackURL, data)
+	}
+	return err
+}
+
+func (p *WebhookPublisher) post(callbackURL string, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("callback answered %s", resp.Status)
+	}
+	return nil
+}
+
+// ValidateCallbackURL checks that a callback URL is an absolute http or
+// https URL without credentials, and that it doesn't name a loopback,
+// private or link-local address outright. Host names are only resolved
+// when a notification is sent, where the publisher checks the address
+// again.
+func ValidateCallbackURL(raw string) error {
+	return validateCallbackURL(raw, false)
+}
+
+func validateCallbackURL(raw string, allowPrivate bool) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCallbackURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme must be http or https", ErrInvalidCallbackURL)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials are not allowed", ErrInvalidCallbackURL)
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("%w: host is required", ErrInvalidCallbackURL)
+	}
+	if allowPrivate {
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s is an internal address", ErrInvalidCallbackURL, host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !publicAddress(addr) {
+		return fmt.Errorf("%w: %s is an internal address", ErrInvalidCallbackURL, host)
+	}
+	return nil
+}
+
+// refusePrivateAddresses is a net.Dialer Control hook that refuses
+// connections to anything but public addresses
+func refusePrivateAddresses(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCallbackURL, err)
+	}
+	if !publicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s is an internal address", ErrInvalidCallbackURL, addrPort.Addr())
+	}
+	return nil
+}
+
+func publicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate()
+}
@@ -0,0 +1,210 @@
+package http
+
+import (
+	"net/http"
+
+	"company.com/matchengine/internal/config"
+	"company.com/matchengine/internal/service/matching"
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/errors"
+)
+
+// RegisterAdminRoutes mounts the admin actions that can take accounts or
+// symbols out of the market: flattening accounts and managing symbols,
+// halting and resuming them included. Every request must send adminToken as
+// a bearer token; an empty token refuses them all.
+func (h *Handler) RegisterAdminRoutes(mux *http.ServeMux, adminToken string) {
+	mux.HandleFunc("POST /api/v1/admin/flatten", requireAdmin(adminToken, h.Flatten))
+
+	mux.HandleFunc("GET /api/v1/admin/symbols", requireAdmin(adminToken, h.ListSymbols))
+	mux.HandleFunc("POST /api/v1/admin/symbols", requireAdmin(adminToken, h.AddSymbol))
+	mux.HandleFunc("DELETE /api/v1/admin/symbols/{symbol}", requireAdmin(adminToken, h.RetireSymbol))
+	mux.HandleFunc("POST /api/v1/admin/symbols/{symbol}/halt", requireAdmin(adminToken, h.HaltSymbol))
+	mux.HandleFunc("POST /api/v1/admin/symbols/{symbol}/resume", requireAdmin(adminToken, h.ResumeSymbol))
+}
+
+// AdminSymbol is a symbol as the admin symbol endpoints show it: its
+// settings in the symbols file format, halted always set, and the number of
+// orders resting on its book
+type AdminSymbol struct {
+	config.SymbolSettings
+	ActiveOrders int `json:"active_orders"`
+}
+
+func adminSymbol(info matching.SymbolInfo) AdminSymbol {
+	halted := info.Halted
+	return AdminSymbol{
+		SymbolSettings: config.NewSymbolSettings(info.Config, &halted),
+		ActiveOrders:   info.ActiveOrders,
+	}
+}
+
+// ListSymbols lists every symbol with a book
+func (h *Handler) ListSymbols(w http.ResponseWriter, r *http.Request) {
+	symbols := h.service.Symbols()
+	resp := make([]AdminSymbol, 0, len(symbols))
+	for _, info := range symbols {
+		resp = append(resp, adminSymbol(info))
+	}
+	errors.Write(w, r, resp)
+}
+
+// AddSymbol registers a new symbol from a body written like an entry of
+// the symbols file. A symbol that already exists gets 409; changing one is
+// left to the symbols file.
+func (h *Handler) AddSymbol(w http.ResponseWriter, r *http.Request) {
+	var settings config.SymbolSettings
+	if err := decodeJSON(r, &settings); err != nil {
+		h.writeError(w, r, err, errors.NewBadRequest(err.Error()))
+		return
+	}
+	if settings.Symbol == "" {
+		errors.Write(w, r, errors.NewBadRequest("symbol is required"))
+		return
+	}
+
+	if err := h.service.AddSymbol(settings.Config(), settings.Halted); err != nil {
+		h.writeError(w, r, err, errors.NewInternal(err))
+		return
+	}
+	info, err := h.service.Symbol(settings.Symbol)
+	if err != nil {
+		h.writeError(w, r, err, errors.NewInternal(err))
+		return
+	}
+
+	errors.WriteWithStatus(w, r, http.StatusCreated, adminSymbol(info))
+}
+
+// RetireSymbol removes a symbol whose book is empty; with orders resting on
+// it the symbol is kept and the request gets 409
+func (h *Handler) RetireSymbol(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.RetireSymbol(r.PathValue("symbol")); err != nil {
+		h.writeError(w, r, err, errors.NewInternal(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SymbolStatus is returned by the symbol halt/resume endpoints
+type SymbolStatus struct {
+	Symbol string `json:"symbol"`
+	Halted bool   `json:"halted"`
+}
+
+// HaltSymbol pauses trading on a symbol
+func (h *Handler) HaltSymbol(w http.ResponseWriter, r *http.Request) {
+	symbol := r.PathValue("symbol")
+	if err := h.service.HaltSymbol(symbol); err != nil {
+		h.writeError(w, r, err, errors.NewInternal(err))
+		return
+	}
+
+	errors.Write(w, r, SymbolStatus{Symbol: symbol, Halted: true})
+}
+
+// ResumeSymbol resumes trading on a halted symbol
+func (h *Handler) ResumeSymbol(w http.ResponseWriter, r *http.Request) {
+	symbol := r.PathValue("symbol")
+	if err := h.service.ResumeSymbol(symbol); err != nil {
+		h.writeError(w, r, err, errors.NewInternal(err))
+		return
+	}
+
+	errors.Write(w, r, SymbolStatus{Symbol: symbol, Halted: false})
+}
+
+// FlattenRequest is the body accepted by POST /api/v1/admin/flatten. It
+// names one account, or sets All to flatten every account; a body with
+// neither is refused rather than read as everyone.
+type FlattenRequest struct {
+	AccountID string `json:"account_id,omitempty"`
+	All       bool   `json:"all,omitempty"`
+}
+
+// FlattenResponse lists what was done to each account flattened
+type FlattenResponse struct {
+	Accounts []AccountFlattened `json:"accounts"`
+}
+
+// AccountFlattened is what flattening did to one account
+type AccountFlattened struct {
+	AccountID string        `json:"account_id"`
+	Cancelled []order.Order `json:"cancelled"`
+	Closing   []order.Order `json:"closing"`
+	// Failed lists closing orders that couldn't be placed; their positions
+	// are still open
+	Failed []ClosingFailure `json:"failed,omitempty"`
+}
+
+// ClosingFailure is a closing order a book refused
+type ClosingFailure struct {
+	Symbol   string              `json:"symbol"`
+	Side     order.Side          `json:"side"`
+	Quantity float64             `json:"quantity"`
+	Error    string              `json:"error"`
+	Reason   errors.RejectReason `json:"reason,omitempty"`
+}
+
+// Flatten cancels the resting orders of an account, or of every account,
+// and sends market orders to close their positions
+func (h *Handler) Flatten(w http.ResponseWriter, r *http.Request) {
+	var req FlattenRequest
+	if err := decodeJSON(r, &req); err != nil {
+		h.writeError(w, r, err, errors.NewBadRequest(err.Error()))
+		return
+	}
+	if (req.AccountID == "") == !req.All {
+		errors.Write(w, r, errors.NewBadRequest("exactly one of account_id and all is required"))
+		return
+	}
+
+	var results []matching.FlattenResult
+	if req.All {
+		all, err := h.service.FlattenAll(r.Context())
+		if err != nil {
+			h.writeError(w, r, err, errors.NewInternal(err))
+			return
+		}
+		results = all
+	} else {
+		result, err := h.service.FlattenAccount(r.Context(), req.AccountID)
+		if err != nil {
+			h.writeError(w, r, err, errors.NewInternal(err))
+			return
+		}
+		results = []matching.FlattenResult{*result}
+	}
+
+	resp := FlattenResponse{Accounts: make([]AccountFlattened, 0, len(results))}
+	for _, result := range results {
+		resp.Accounts = append(resp.Accounts, accountFlattened(result))
+	}
+	errors.Write(w, r, resp)
+}
+
+func accountFlattened(result matching.FlattenResult) AccountFlattened {
+	flattened := AccountFlattened{
+		AccountID: result.AccountID,
+		Cancelled: result.Cancelled,
+		Closing:   result.Closing,
+	}
+	if flattened.Cancelled == nil {
+		flattened.Cancelled = []order.Order{}
+	}
+	if flattened.Closing == nil {
+		flattened.Closing = []order.Order{}
+	}
+	for _, failure := range result.Failed {
+		reason, _ := rejectReason(failure.Err)
+		flattened.Failed = append(flattened.Failed, ClosingFailure{
+			Symbol:   failure.Order.Symbol,
+			Side:     failure.Order.Side,
+			Quantity: failure.Order.Quantity,
+			Error:    failure.Err.Error(),
+			Reason:   reason,
+		})
+	}
+	return flattened
+}
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"company.com/matchengine/pkg/errors"
+)
+
+// RegisterDebugRoutes mounts the support endpoints, which dump internal
+// state for incident debugging. They aren't part of the API and every
+// request must send adminToken as a bearer token; an empty token refuses
+// them all.
+func (h *Handler) RegisterDebugRoutes(mux *http.ServeMux, adminToken string) {
+	mux.HandleFunc("GET /debug/orderbook/{symbol}", requireAdmin(adminToken, h.DebugOrderBook))
+}
+
+// requireAdmin lets a request through only when it carries the admin token
+func requireAdmin(adminToken string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			errors.Write(w, r, errors.NewUnauthorized("admin token required"))
+			return
+		}
+		next(w, r)
+	}
+}
+
+// DebugOrderBook dumps a book's level list, raw orders and consistency
+// checks
+func (h *Handler) DebugOrderBook(w http.ResponseWriter, r *http.Request) {
+	dump, err := h.service.DebugOrderBook(r.Context(), r.PathValue("symbol"))
+	if err != nil {
+		h.writeError(w, r, err, errors.NewInternal(err))
+		return
+	}
+
+	errors.Write(w, r, dump)
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// decodeJSON reads a request body holding a single JSON object into dst,
+// rejecting fields dst doesn't have and anything after the object. Its
+// errors describe what is wrong with the body in terms a client can act on;
+// a body over the size limit is returned as the *http.MaxBytesError it is.
+func decodeJSON(r *http.Request, dst interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		return bodyError(err)
+	}
+
+	// A second object would otherwise be dropped without a word
+	end := decoder.InputOffset()
+	if _, err := decoder.Token(); !stderrors.Is(err, io.EOF) {
+		var tooLarge *http.MaxBytesError
+		if stderrors.As(err, &tooLarge) {
+			return err
+		}
+		return fmt.Errorf("request body must hold a single JSON object, found more after position %d", end)
+	}
+	return nil
+}
+
+// strictUnmarshal is json.Unmarshal rejecting unknown fields, for
+// UnmarshalJSON methods of request types, which the decoder's own setting
+// doesn't reach
+func strictUnmarshal(data []byte, dst interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(dst)
+}
+
+// bodyError rewrites a decoding error as the message a client gets
+func bodyError(err error) error {
+	var (
+		tooLarge  *http.MaxBytesError
+		syntax    *json.SyntaxError
+		typeError *json.UnmarshalTypeError
+	)
+	switch {
+	case err == nil:
+		return nil
+	case stderrors.As(err, &tooLarge):
+		return err
+	case stderrors.Is(err, io.EOF):
+		return fmt.Errorf("request body is required")
+	case stderrors.Is(err, io.ErrUnexpectedEOF):
+		return fmt.Errorf("malformed JSON: body ends before the object does")
+	case stderrors.As(err, &syntax):
+		return fmt.Errorf("malformed JSON at position %d", syntax.Offset)
+	case stderrors.As(err, &typeError):
+		if typeError.Field == "" {
+			return fmt.Errorf("request body must be %s", jsonKind(typeError.Type))
+		}
+		return fmt.Errorf("field %q must be %s", typeError.Field, jsonKind(typeError.Type))
+	}
+
+	// The decoder has no error type for unknown fields
+	if field, unknown := strings.CutPrefix(err.Error(), "json: unknown field "); unknown {
+		return fmt.Errorf("unknown field %s", field)
+	}
+	return err
+}
+
+// jsonKind names the JSON value a Go type is decoded from
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
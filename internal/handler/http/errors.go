@@ -0,0 +1,111 @@
+package http
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+
+	"company.com/matchengine/internal/risk"
+	"company.com/matchengine/internal/service/matching"
+	"company.com/matchengine/pkg/engine/orderbook"
+	"company.com/matchengine/pkg/errors"
+	"company.com/matchengine/pkg/requestid"
+)
+
+// apiError maps a service error to the API error it is reported as, or
+// returns fallback when the API doesn't distinguish it
+func apiError(err error, fallback *errors.APIError) *errors.APIError {
+	var tooLarge *http.MaxBytesError
+	var failure *orderbook.FailureError
+	switch {
+	case stderrors.As(err, &tooLarge):
+		return errors.NewPayloadTooLarge(tooLarge.Limit)
+	case stderrors.As(err, &failure):
+		// The panic stays in the logs
+		return errors.NewServiceUnavailable("trading on " + failure.Symbol + " was halted after an internal failure")
+	case stderrors.Is(err, orderbook.ErrOrderNotFound):
+		return errors.NewNotFound("order")
+	case stderrors.Is(err, matching.ErrSymbolNotFound):
+		return errors.NewNotFound("symbol")
+	case stderrors.Is(err, orderbook.ErrOrderNotCancellable):
+		return errors.NewOrderNotCancellable(err.Error())
+	case stderrors.Is(err, matching.ErrOrderNotResting):
+		return errors.NewOrderNotResting(err.Error())
+	case stderrors.Is(err, matching.ErrSymbolExists), stderrors.Is(err, orderbook.ErrBookNotEmpty):
+		return errors.NewConflict(err.Error())
+	case stderrors.Is(err, orderbook.ErrOrderChanged):
+		return errors.NewPreconditionFailed(err.Error())
+	case stderrors.Is(err, risk.ErrInsufficientBalance):
+		return errors.NewInsufficientBalance(err.Error())
+	case stderrors.Is(err, matching.ErrQuotaExceeded):
+		return withRetryAfter(err, errors.NewTooManyRequests(err.Error()))
+	case stderrors.Is(err, matching.ErrOverloaded):
+		return withRetryAfter(err, errors.NewServiceUnavailable(err.Error()))
+	case stderrors.Is(err, context.Canceled), stderrors.Is(err, context.DeadlineExceeded):
+		return errors.NewServiceUnavailable("request cancelled before it completed")
+	}
+	return fallback
+}
+
+// withRetryAfter tells the client when to retry, when the service says
+func withRetryAfter(err error, apiErr *errors.APIError) *errors.APIError {
+	if after, ok := matching.RetryAfter(err); ok {
+		return apiErr.WithRetryAfter(after)
+	}
+	return apiErr
+}
+
+// rejectReason names why an order was rejected, for the errors that say
+func rejectReason(err error) (errors.RejectReason, bool) {
+	reasons := []struct {
+		target error
+		reason errors.RejectReason
+	}{
+		{orderbook.ErrTradingHalted, errors.ReasonSymbolHalted},
+		{orderbook.ErrBookFailed, errors.ReasonSymbolHalted},
+		{orderbook.ErrPriceOutOfBand, errors.ReasonPriceOutOfBand},
+		{orderbook.ErrInvalidTick, errors.ReasonInvalidTick},
+		{orderbook.ErrDepthLimitReached, errors.ReasonDepthLimitReached},
+		{orderbook.ErrBelowMinNotional, errors.ReasonBelowMinNotional},
+		{orderbook.ErrWouldCross, errors.ReasonWouldCross},
+		{risk.ErrInsufficientBalance, errors.ReasonInsufficientBalance},
+		{matching.ErrReduceOnly, errors.ReasonReduceOnly},
+		{matching.ErrQuotaExceeded, errors.ReasonQuotaExceeded},
+	}
+	for _, r := range reasons {
+		if stderrors.Is(err, r.target) {
+			return r.reason, true
+		}
+	}
+	return "", false
+}
+
+// writeRejection reports why an order was not accepted, as writeError does,
+// adding the rejection reason when there is one
+func (h *Handler) writeRejection(w http.ResponseWriter, r *http.Request, err error) {
+	apiErr := apiError(err, errors.NewBadRequest(err.Error()))
+	if reason, ok := rejectReason(err); ok {
+		apiErr = apiErr.WithReason(reason)
+	}
+	h.writeAPIError(w, r, err, apiErr)
+}
+
+// writeError reports a service error to the client. Errors that end up as
+// 5xx are logged, since the client can't act on them.
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, err error, fallback *errors.APIError) {
+	h.writeAPIError(w, r, err, apiError(err, fallback))
+}
+
+// writeAPIError writes the API error a service error maps to, logging 5xx
+func (h *Handler) writeAPIError(w http.ResponseWriter, r *http.Request, err error, apiErr *errors.APIError) {
+	if apiErr.Status >= http.StatusInternalServerError {
+		h.logger.ErrorContext(r.Context(), "request failed",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"error", err,
+			"request_id", requestid.FromContext(r.Context()),
+		)
+	}
+
+	errors.Write(w, r, apiErr)
+}
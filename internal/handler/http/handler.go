@@ -0,0 +1,599 @@
+package http
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"company.com/matchengine/internal/candle"
+	"company.com/matchengine/internal/event"
+	"company.com/matchengine/internal/service/matching"
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/errors"
+)
+
+// Handler exposes the matching service over HTTP
+type Handler struct {
+	service *matching.Service
+	logger  *slog.Logger
+}
+
+func NewHandler(service *matching.Service, logger *slog.Logger) *Handler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Handler{service: service, logger: logger}
+}
+
+// route is one method and path pattern the API serves
+type route struct {
+	method  string
+	pattern string
+	handler http.HandlerFunc
+}
+
+func (h *Handler) routes() []route {
+	return []route{
+		{http.MethodGet, "/health/live", h.Live},
+		{http.MethodGet, "/health/ready", h.Ready},
+		{http.MethodGet, "/openapi.json", h.OpenAPI},
+		{http.MethodGet, "/docs", h.SwaggerUI},
+		{http.MethodGet, "/api/v1/orders", h.ListOrders},
+		{http.MethodPost, "/api/v1/orders", h.CreateOrder},
+		{http.MethodPost, "/api/v1/orders/validate", h.ValidateOrder},
+		{http.MethodGet, "/api/v1/orders/{id}", h.GetOrder},
+		{http.MethodDelete, "/api/v1/orders/{id}", h.CancelOrder},
+		{http.MethodPatch, "/api/v1/orders/{id}", h.PatchOrder},
+		{http.MethodGet, "/api/v1/orders/{id}/history", h.GetOrderHistory},
+		{http.MethodGet, "/api/v1/orders/{id}/queue", h.GetQueuePosition},
+		{http.MethodGet, "/api/v1/orderbook/{symbol}", h.GetOrderBook},
+		{http.MethodPost, "/api/v1/simulate", h.SimulateFill},
+		{http.MethodGet, "/api/v1/ticker/{symbol}", h.GetTicker},
+		{http.MethodGet, "/api/v1/tickers", h.GetTickers},
+		{http.MethodGet, "/api/v1/candles/{symbol}", h.GetCandles},
+		{http.MethodGet, "/api/v1/stats", h.GetStats},
+		{http.MethodGet, "/api/v1/accounts/{id}/positions", h.GetPositions},
+	}
+}
+
+// RegisterRoutes mounts the API routes on the given mux. Every path also
+// answers OPTIONS with the methods it allows; other methods get the mux's
+// 405 with the same Allow header.
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	var patterns []string
+	methods := make(map[string][]string)
+	for _, rt := range h.routes() {
+		mux.HandleFunc(rt.method+" "+rt.pattern, rt.handler)
+		if _, exists := methods[rt.pattern]; !exists {
+			patterns = append(patterns, rt.pattern)
+		}
+		methods[rt.pattern] = append(methods[rt.pattern], rt.method)
+	}
+
+	for _, pattern := range patterns {
+		allow := allowHeader(methods[pattern])
+		mux.HandleFunc(http.MethodOptions+" "+pattern, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Allow", allow)
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// allowHeader lists methods the way the mux does in its 405s: sorted, with
+// HEAD wherever GET is served and OPTIONS everywhere
+func allowHeader(methods []string) string {
+	allowed := append([]string{http.MethodOptions}, methods...)
+	if slices.Contains(methods, http.MethodGet) {
+		allowed = append(allowed, http.MethodHead)
+	}
+	slices.Sort(allowed)
+	return strings.Join(allowed, ", ")
+}
+
+// CreateOrderRequest is the body accepted by POST /api/v1/orders
+type CreateOrderRequest struct {
+	ClientOrderID string     `json:"client_order_id,omitempty"`
+	AccountID     string     `json:"account_id,omitempty"`
+	Type          order.Type `json:"type,omitempty"`
+	Symbol        string     `json:"symbol"`
+	Side          order.Side `json:"side"`
+	Price         float64    `json:"price"`
+	StopPrice     float64    `json:"stop_price,omitempty"`
+	Quantity      float64    `json:"quantity"`
+	QuoteQuantity float64    `json:"quote_quantity,omitempty"`
+	ReduceOnly    bool       `json:"reduce_only,omitempty"`
+	// ExpireAfterSeconds cancels the order if it is still resting this many
+	// seconds after it is accepted; zero or absent means good till cancelled
+	ExpireAfterSeconds int64 `json:"expire_after_seconds,omitempty"`
+	// CallbackURL receives a POST each time the order fills or partially
+	// fills; it must be a public http or https URL
+	CallbackURL string `json:"callback_url,omitempty"`
+}
+
+// UnmarshalJSON accepts prices and quantities either as JSON numbers or as
+// decimal strings such as "50000.00", which clients can write without going
+// through a float
+func (req *CreateOrderRequest) UnmarshalJSON(data []byte) error {
+	type plain CreateOrderRequest
+	wire := struct {
+		*plain
+		Price         order.Decimal `json:"price"`
+		StopPrice     order.Decimal `json:"stop_price,omitempty"`
+		Quantity      order.Decimal `json:"quantity"`
+		QuoteQuantity order.Decimal `json:"quote_quantity,omitempty"`
+	}{plain: (*plain)(req)}
+	if err := strictUnmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	req.Price = wire.Price.Value
+	req.StopPrice = wire.StopPrice.Value
+	req.Quantity = wire.Quantity.Value
+	req.QuoteQuantity = wire.QuoteQuantity.Value
+	return nil
+}
+
+// CreateOrder submits a new order to the matching engine and points the
+// Location header at it. Retries carrying the same client_order_id (or
+// Idempotency-Key header) get the original order back with 200 instead of
+// creating a new one.
+func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
+	o, ok := h.decodeOrder(w, r)
+	if !ok {
+		return
+	}
+
+	placed, err := h.service.SubmitOrder(r.Context(), o)
+	if err != nil {
+		h.writeRejection(w, r, err)
+		return
+	}
+
+	// The book may be filling the order already; encode a copy
+	snapshot := h.service.Snapshot(placed)
+	if placed != o {
+		errors.Write(w, r, snapshot)
+		return
+	}
+	w.Header().Set("Location", orderURL(o.ID))
+	errors.WriteWithStatus(w, r, http.StatusCreated, snapshot)
+}
+
+// orderURL is the path an order is served at
+func orderURL(id string) string {
+	return "/api/v1/orders/" + url.PathEscape(id)
+}
+
+// OrderValidation is returned by POST /api/v1/orders/validate for orders
+// that pass every check
+type OrderValidation struct {
+	Valid bool `json:"valid"`
+}
+
+// ValidateOrder runs an order through the checks CreateOrder would, without
+// placing it. Invalid orders get the same error CreateOrder would return.
+func (h *Handler) ValidateOrder(w http.ResponseWriter, r *http.Request) {
+	o, ok := h.decodeOrder(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ValidateOrder(r.Context(), o); err != nil {
+		h.writeRejection(w, r, err)
+		return
+	}
+
+	errors.Write(w, r, OrderValidation{Valid: true})
+}
+
+// decodeOrder builds the order a create request describes, attributed to
+// the caller's account. Invalid requests are answered and reported as not
+// ok.
+func (h *Handler) decodeOrder(w http.ResponseWriter, r *http.Request) (*order.Order, bool) {
+	var req CreateOrderRequest
+	if err := decodeJSON(r, &req); err != nil {
+		h.writeError(w, r, err, errors.NewBadRequest(err.Error()))
+		return nil, false
+	}
+
+	if req.Symbol == "" {
+		errors.Write(w, r, errors.NewBadRequest("symbol is required"))
+		return nil, false
+	}
+	if req.Side != order.SideBuy && req.Side != order.SideSell {
+		errors.Write(w, r, errors.NewBadRequest("side must be buy or sell"))
+		return nil, false
+	}
+	if req.Type == "" {
+		req.Type = order.TypeLimit
+	}
+
+	if req.CallbackURL != "" {
+		if err := event.ValidateCallbackURL(req.CallbackURL); err != nil {
+			errors.Write(w, r, errors.NewBadRequest(err.Error()))
+			return nil, false
+		}
+	}
+	if req.ExpireAfterSeconds < 0 {
+		errors.Write(w, r, errors.NewBadRequest("expire_after_seconds must not be negative"))
+		return nil, false
+	}
+
+	o, err := newOrder(req, h.service.NegativePricesAllowed(req.Symbol))
+	if err != nil {
+		errors.Write(w, r, errors.NewBadRequest(err.Error()))
+		return nil, false
+	}
+	if req.ExpireAfterSeconds > 0 {
+		o.ExpiresAt = h.service.Now().Add(time.Duration(req.ExpireAfterSeconds) * time.Second)
+	}
+
+	o.ReduceOnly = req.ReduceOnly
+	o.CallbackURL = req.CallbackURL
+	o.AccountID = req.AccountID
+	if account := accountID(r); account != "" {
+		o.AccountID = account
+	}
+	o.ClientOrderID = req.ClientOrderID
+	if o.ClientOrderID == "" {
+		o.ClientOrderID = r.Header.Get("Idempotency-Key")
+	}
+	return o, true
+}
+
+// AccountHeader carries the caller's account, as established by the
+// authenticating proxy in front of the API
+const AccountHeader = "X-Account-ID"
+
+// accountID returns the account the request is made on behalf of
+func accountID(r *http.Request) string {
+	return r.Header.Get(AccountHeader)
+}
+
+// mayRead reports whether the caller may read data of account. A request
+// made on behalf of an account only sees that account's; one without an
+// account header hasn't been through the proxy and sees everything.
+func mayRead(r *http.Request, account string) bool {
+	caller := accountID(r)
+	return caller == "" || caller == account
+}
+
+// newOrder builds the order a create request describes. A quote_quantity
+// asks for a market buy that spends that much quote currency.
+// allowNegative is the symbol's negative price policy.
+func newOrder(req CreateOrderRequest, allowNegative bool) (*order.Order, error) {
+	if req.QuoteQuantity == 0 {
+		return order.NewOrderOfType(req.Type, req.Side, req.Symbol, req.Price, req.StopPrice, req.Quantity, allowNegative)
+	}
+
+	if req.Type != order.TypeMarket || req.Side != order.SideBuy {
+		return nil, fmt.Errorf("quote_quantity is only supported for market buys")
+	}
+	if req.Quantity != 0 {
+		return nil, fmt.Errorf("quantity and quote_quantity are mutually exclusive")
+	}
+	return order.NewQuoteMarketBuy(req.Symbol, req.QuoteQuantity)
+}
+
+// ListOrders returns a page of orders across all books, optionally filtered
+// by symbol, side, status and a [from, to) creation time range. With a
+// clientOrderId it instead returns the caller's order with that client ID.
+// Requests made on behalf of an account only list that account's orders.
+func (h *Handler) ListOrders(w http.ResponseWriter, r *http.Request) {
+	if clientOrderID := r.URL.Query().Get("clientOrderId"); clientOrderID != "" {
+		h.getOrderByClientID(w, r, clientOrderID)
+		return
+	}
+
+	filter, err := parseOrderFilter(r.URL.Query())
+	if err != nil {
+		errors.Write(w, r, errors.NewBadRequest(err.Error()))
+		return
+	}
+
+	filter.AccountID = accountID(r)
+
+	page, err := h.service.ListOrders(r.Context(), filter)
+	if err != nil {
+		h.writeError(w, r, err, errors.NewInternal(err))
+		return
+	}
+
+	errors.Write(w, r, page)
+}
+
+func (h *Handler) getOrderByClientID(w http.ResponseWriter, r *http.Request, clientOrderID string) {
+	o, err := h.service.GetOrderByClientID(r.Context(), accountID(r), clientOrderID)
+	if err != nil {
+		h.writeError(w, r, err, errors.NewInternal(err))
+		return
+	}
+
+	errors.Write(w, r, o)
+}
+
+func parseOrderFilter(query url.Values) (matching.OrderFilter, error) {
+	filter := matching.OrderFilter{
+		Symbol: query.Get("symbol"),
+		Side:   order.Side(query.Get("side")),
+		Status: order.Status(query.Get("status")),
+	}
+
+	if filter.Side != "" && filter.Side != order.SideBuy && filter.Side != order.SideSell {
+		return filter, fmt.Errorf("side must be buy or sell")
+	}
+	switch filter.Status {
+	case "", order.StatusNew, order.StatusPartial, order.StatusFilled, order.StatusCancelled:
+	default:
+		return filter, fmt.Errorf("unknown status: %s", filter.Status)
+	}
+
+	for _, param := range []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"from", &filter.From},
+		{"to", &filter.To},
+	} {
+		if value := query.Get(param.name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, fmt.Errorf("%s must be an RFC 3339 timestamp", param.name)
+			}
+			*param.dst = t
+		}
+	}
+
+	for _, param := range []struct {
+		name string
+		dst  *int
+	}{
+		{"limit", &filter.Limit},
+		{"offset", &filter.Offset},
+	} {
+		if value := query.Get(param.name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return filter, fmt.Errorf("%s must be a non-negative integer", param.name)
+			}
+			*param.dst = n
+		}
+	}
+
+	return filter, nil
+}
+
+// GetOrder returns a resting order by ID, with an ETag for conditional
+// cancels. Another account's order is answered as not found.
+func (h *Handler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	o, err := h.service.GetOrder(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeError(w, r, err, errors.NewInternal(err))
+		return
+	}
+
+	// The ETag and the body come from one copy, so an If-Match cancel
+	// always refers to the state the client was shown
+	snapshot := *o
+	if !mayRead(r, snapshot.AccountID) {
+		errors.Write(w, r, errors.NewNotFound("order"))
+		return
+	}
+	w.Header().Set("ETag", snapshot.ETag())
+	errors.Write(w, r, snapshot)
+}
+
+// CancelOrder cancels a resting order and returns its final state, including
+// whatever was filled before the cancel. Orders that are already filled or
+// cancelled get 409. With an If-Match header the order is only cancelled if
+// its ETag still matches, and 412 otherwise.
+func (h *Handler) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	var o *order.Order
+	var err error
+	if etag := strings.TrimSpace(r.Header.Get("If-Match")); etag != "" && etag != "*" {
+		o, err = h.service.CancelOrderIfMatch(r.Context(), r.PathValue("id"), etag)
+	} else {
+		o, err = h.service.CancelOrderByID(r.Context(), r.PathValue("id"))
+	}
+	if err != nil {
+		h.writeError(w, r, err, errors.NewInternal(err))
+		return
+	}
+
+	errors.Write(w, r, o)
+}
+
+// PatchOpReduce is the PATCH operation that shrinks a resting order
+const PatchOpReduce = "reduce"
+
+// PatchOrderRequest is the body accepted by PATCH /api/v1/orders/{id}.
+// reduce_by may be a JSON number or a decimal string.
+type PatchOrderRequest struct {
+	Op       string        `json:"op"`
+	ReduceBy order.Decimal `json:"reduce_by"`
+}
+
+// PatchOrder changes a resting order in place. The reduce operation lowers
+// its quantity by reduce_by, keeping its price and queue position; reducing
+// it to what has already filled cancels it. Orders that are already filled
+// or cancelled get 409.
+func (h *Handler) PatchOrder(w http.ResponseWriter, r *http.Request) {
+	var req PatchOrderRequest
+	if err := decodeJSON(r, &req); err != nil {
+		h.writeError(w, r, err, errors.NewBadRequest(err.Error()))
+		return
+	}
+
+	if req.Op != PatchOpReduce {
+		errors.Write(w, r, errors.NewBadRequest(fmt.Sprintf("op must be %q", PatchOpReduce)))
+		return
+	}
+	if req.ReduceBy.Value <= 0 {
+		errors.Write(w, r, errors.NewBadRequest("reduce_by must be positive"))
+		return
+	}
+
+	o, err := h.service.ReduceOrderByID(r.Context(), r.PathValue("id"), req.ReduceBy.Value)
+	if err != nil {
+		h.writeError(w, r, err, errors.NewBadRequest(err.Error()))
+		return
+	}
+
+	w.Header().Set("ETag", o.ETag())
+	errors.Write(w, r, o)
+}
+
+// GetOrderHistory returns the state transitions recorded for an order
+func (h *Handler) GetOrderHistory(w http.ResponseWriter, r *http.Request) {
+	history, err := h.service.OrderHistory(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeError(w, r, err, errors.NewInternal(err))
+		return
+	}
+
+	errors.Write(w, r, history)
+}
+
+// GetQueuePosition returns a resting order's rank in its price level and
+// the quantity ahead of it. Another account's order is answered as not
+// found.
+func (h *Handler) GetQueuePosition(w http.ResponseWriter, r *http.Request) {
+	if accountID(r) != "" {
+		o, err := h.service.GetOrder(r.Context(), r.PathValue("id"))
+		if err != nil {
+			h.writeError(w, r, err, errors.NewInternal(err))
+			return
+		}
+		if !mayRead(r, o.AccountID) {
+			errors.Write(w, r, errors.NewNotFound("order"))
+			return
+		}
+	}
+
+	position, err := h.service.GetQueuePositionByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeError(w, r, err, errors.NewInternal(err))
+		return
+	}
+
+	errors.Write(w, r, position)
+}
+
+// GetOrderBook returns a snapshot of a symbol's book
+func (h *Handler) GetOrderBook(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := h.service.GetOrderBook(r.Context(), r.PathValue("symbol"))
+	if err != nil {
+		h.writeError(w, r, err, errors.NewInternal(err))
+		return
+	}
+
+	errors.Write(w, r, snapshot)
+}
+
+// SimulateFillRequest is the body accepted by POST /api/v1/simulate
+type SimulateFillRequest struct {
+	Symbol   string     `json:"symbol"`
+	Side     order.Side `json:"side"`
+	Quantity float64    `json:"quantity"`
+}
+
+// SimulateFill returns the VWAP a market order would get against the current book
+func (h *Handler) SimulateFill(w http.ResponseWriter, r *http.Request) {
+	var req SimulateFillRequest
+	if err := decodeJSON(r, &req); err != nil {
+		h.writeError(w, r, err, errors.NewBadRequest(err.Error()))
+		return
+	}
+
+	if req.Symbol == "" {
+		errors.Write(w, r, errors.NewBadRequest("symbol is required"))
+		return
+	}
+	if req.Side != order.SideBuy && req.Side != order.SideSell {
+		errors.Write(w, r, errors.NewBadRequest("side must be buy or sell"))
+		return
+	}
+	if req.Quantity <= 0 {
+		errors.Write(w, r, errors.NewBadRequest("quantity must be positive"))
+		return
+	}
+
+	sim, err := h.service.SimulateFill(req.Symbol, req.Side, req.Quantity)
+	if err != nil {
+		h.writeError(w, r, err, errors.NewBadRequest(err.Error()))
+		return
+	}
+
+	errors.Write(w, r, sim)
+}
+
+// GetTicker returns the top of book, imbalance and microprice for a symbol
+func (h *Handler) GetTicker(w http.ResponseWriter, r *http.Request) {
+	ticker, err := h.service.GetTicker(r.PathValue("symbol"))
+	if err != nil {
+		h.writeError(w, r, err, errors.NewInternal(err))
+		return
+	}
+
+	errors.Write(w, r, ticker)
+}
+
+// GetTickers returns the ticker of every symbol in one response
+func (h *Handler) GetTickers(w http.ResponseWriter, r *http.Request) {
+	tickers, err := h.service.Tickers(r.Context())
+	if err != nil {
+		h.writeError(w, r, err, errors.NewInternal(err))
+		return
+	}
+
+	errors.Write(w, r, tickers)
+}
+
+// GetCandles returns a symbol's OHLCV candles over the last 24 hours. The
+// interval query parameter sets their width (default 1m).
+func (h *Handler) GetCandles(w http.ResponseWriter, r *http.Request) {
+	interval := candle.DefaultInterval
+	if raw := r.URL.Query().Get("interval"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			errors.Write(w, r, errors.NewBadRequest("interval must be a duration such as 1m, 15m or 1h"))
+			return
+		}
+		interval = parsed
+	}
+
+	candles, err := h.service.Candles(r.Context(), r.PathValue("symbol"), interval)
+	if err != nil {
+		h.writeError(w, r, err, errors.NewBadRequest(err.Error()))
+		return
+	}
+
+	errors.Write(w, r, candles)
+}
+
+// GetStats returns aggregate engine counters and per-symbol depth
+func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
+	errors.Write(w, r, h.service.Stats())
+}
+
+// GetPositions returns an account's open positions with their average entry
+// prices. Requests made on behalf of another account get 404.
+func (h *Handler) GetPositions(w http.ResponseWriter, r *http.Request) {
+	if !mayRead(r, r.PathValue("id")) {
+		errors.Write(w, r, errors.NewNotFound("account"))
+		return
+	}
+
+	positions, err := h.service.Positions(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeError(w, r, err, errors.NewInternal(err))
+		return
+	}
+
+	errors.Write(w, r, positions)
+}
@@ -7,5 +7,23 @@ import (
 )
 
 func HealthCheck(w http.ResponseWriter, r *http.Request) {
-	errors.WriteJSON(w, map[string]string{"status": "ok"})
+	errors.Write(w, r, map[string]string{"status": "ok"})
+}
+
+// Live reports that the process is up and serving requests
+func (h *Handler) Live(w http.ResponseWriter, r *http.Request) {
+	HealthCheck(w, r)
+}
+
+// Ready reports whether the matching engine can take traffic. It fails with
+// 503 once the service starts draining for shutdown.
+func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
+	readiness := h.service.Readiness()
+	if !readiness.Ready {
+		errors.Write(w, r, errors.NewServiceUnavailable("matching engine is not ready").
+			WithRetryAfter(h.service.RetryBackoff()))
+		return
+	}
+
+	errors.Write(w, r, readiness)
 }
@@ -0,0 +1,480 @@
+package http
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"company.com/matchengine/internal/audit"
+	"company.com/matchengine/internal/service/matching"
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+	"company.com/matchengine/pkg/errors"
+)
+
+// schema is a JSON Schema object as used by OpenAPI 3
+type schema = map[string]interface{}
+
+// schemaBuilder derives component schemas from Go types through their json
+// tags. Types registered as components are referenced rather than inlined.
+type schemaBuilder struct {
+	components map[string]schema
+	names      map[reflect.Type]string
+	enums      map[reflect.Type][]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		components: make(map[string]schema),
+		names:      make(map[reflect.Type]string),
+		enums: map[reflect.Type][]string{
+			reflect.TypeOf(order.Side("")):          {string(order.SideBuy), string(order.SideSell)},
+			reflect.TypeOf(order.Type("")):          {string(order.TypeLimit), string(order.TypeMarket), string(order.TypeStop), string(order.TypeStopLimit)},
+			reflect.TypeOf(order.Status("")):        {string(order.StatusNew), string(order.StatusPartial), string(order.StatusFilled), string(order.StatusCancelled)},
+			reflect.TypeOf(errors.RejectReason("")): rejectReasons(),
+		},
+	}
+}
+
+func rejectReasons() []string {
+	reasons := make([]string, len(errors.RejectReasons))
+	for i, reason := range errors.RejectReasons {
+		reasons[i] = string(reason)
+	}
+	return reasons
+}
+
+// register adds a component for the type, derived from its fields unless a
+// schema is given for types whose JSON doesn't mirror their fields
+func (b *schemaBuilder) register(name string, v interface{}, override schema) {
+	t := reflect.TypeOf(v)
+	b.names[t] = name
+	if override == nil {
+		override = b.structSchema(t)
+	}
+	b.components[name] = override
+}
+
+func (b *schemaBuilder) ref(v interface{}) schema {
+	return b.schemaOf(reflect.TypeOf(v))
+}
+
+func (b *schemaBuilder) schemaOf(t reflect.Type) schema {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if name, registered := b.names[t]; registered {
+		return schema{"$ref": "#/components/schemas/" + name}
+	}
+	if values, isEnum := b.enums[t]; isEnum {
+		return schema{"type": "string", "enum": values}
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return schema{"type": "string", "format": "date-time"}
+	}
+	if t == reflect.TypeOf(order.Decimal{}) {
+		return decimal("")
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return schema{"type": "string"}
+	case reflect.Bool:
+		return schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return schema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return schema{"type": "array", "items": b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return schema{"type": "object", "additionalProperties": b.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return b.structSchema(t)
+	}
+	return schema{}
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) schema {
+	properties := schema{}
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = b.schemaOf(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	s := schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// decimal describes the fixed-decimal strings prices and quantities are
+// written as
+func decimal(description string) schema {
+	s := schema{"type": "string", "format": "decimal", "example": "50000.00000000"}
+	if description != "" {
+		s["description"] = description
+	}
+	return s
+}
+
+// orderSchema mirrors the order's wire format, which writes decimals as
+// strings and adds the remaining quantity
+func (b *schemaBuilder) orderSchema() schema {
+	return schema{
+		"type": "object",
+		"properties": schema{
+			"id":              schema{"type": "string"},
+			"client_order_id": schema{"type": "string"},
+			"account_id":      schema{"type": "string"},
+			"type":            b.ref(order.Type("")),
+			"side":            b.ref(order.Side("")),
+			"symbol":          schema{"type": "string"},
+			"price":           decimal("Limit price; zero for market orders"),
+			"stop_price":      decimal("Trigger price of stop orders"),
+			"quantity":        decimal("Base quantity ordered"),
+			"quote_quantity":  decimal("Quote amount a market buy spends"),
+			"reduce_only":     schema{"type": "boolean", "description": "Only reduces the account's position; capped at its size"},
+			"filled":          decimal("Base quantity executed"),
+			"remaining":       decimal("Base quantity still open"),
+			"filled_notional": decimal("Quote value of the fills, each fill's quantity times its price"),
+			"avg_fill_price":  decimal("Volume-weighted average price of the fills; absent until the first fill"),
+			"match_latency_micros": schema{
+				"type":        "number",
+				"description": "Microseconds the book took to match the order on arrival, not counting time queued or waiting for the book's lock",
+			},
+			"status":     b.ref(order.Status("")),
+			"created_at": b.ref(time.Time{}),
+			"updated_at": b.ref(time.Time{}),
+		},
+		"required": []string{"id", "type", "side", "symbol", "price", "quantity", "filled", "remaining", "status", "created_at", "updated_at"},
+	}
+}
+
+// createOrderRequestSchema is derived from the request's fields, except
+// that prices and quantities may also be sent as decimal strings
+func (b *schemaBuilder) createOrderRequestSchema() schema {
+	s := b.structSchema(reflect.TypeOf(CreateOrderRequest{}))
+	properties := s["properties"].(schema)
+	for _, name := range []string{"price", "stop_price", "quantity", "quote_quantity"} {
+		properties[name] = schema{"oneOf": []schema{{"type": "number"}, decimal("")}}
+	}
+	return s
+}
+
+// snapshotSchema mirrors the snapshot's wire format, where each level
+// carries its total quantity instead of list pointers
+func (b *schemaBuilder) snapshotSchema() schema {
+	level := schema{
+		"type": "object",
+		"properties": schema{
+			"price":    decimal("Level price"),
+			"quantity": decimal("Remaining quantity resting at the level"),
+			"orders":   b.ref([]order.Order{}),
+		},
+		"required": []string{"price", "quantity", "orders"},
+	}
+
+	return schema{
+		"type": "object",
+		"properties": schema{
+			"symbol":   schema{"type": "string"},
+			"sequence": schema{"type": "integer", "description": "Advances by one on every change to the book"},
+			"bids":     schema{"type": "array", "items": level},
+			"asks":     schema{"type": "array", "items": level},
+		},
+		"required": []string{"symbol", "sequence", "bids", "asks"},
+	}
+}
+
+// envelope wraps a payload schema in the standard response envelope
+func envelope(data schema) schema {
+	properties := schema{"success": schema{"type": "boolean"}}
+	if data != nil {
+		properties["data"] = data
+	}
+	return schema{"type": "object", "properties": properties, "required": []string{"success"}}
+}
+
+func jsonContent(s schema) schema {
+	return schema{
+		errors.ContentTypeJSON:    schema{"schema": s},
+		errors.ContentTypeMsgpack: schema{"schema": s},
+	}
+}
+
+func response(description string, data schema) schema {
+	return schema{"description": description, "content": jsonContent(envelope(data))}
+}
+
+func withHeaders(response, headers schema) schema {
+	response["headers"] = headers
+	return response
+}
+
+func errorResponse(description string) schema {
+	return schema{
+		"description": description,
+		"content":     jsonContent(schema{"$ref": "#/components/schemas/ErrorResponse"}),
+	}
+}
+
+func pathParam(name, description string) schema {
+	return schema{"name": name, "in": "path", "required": true, "description": description, "schema": schema{"type": "string"}}
+}
+
+func queryParam(name, description string, s schema) schema {
+	return schema{"name": name, "in": "query", "description": description, "schema": s}
+}
+
+// buildOpenAPI assembles the OpenAPI 3 description of the order endpoints
+func buildOpenAPI() schema {
+	b := newSchemaBuilder()
+	b.register("Order", order.Order{}, b.orderSchema())
+	b.register("OrderBookSnapshot", orderbook.OrderBookSnapshot{}, b.snapshotSchema())
+	b.register("CreateOrderRequest", CreateOrderRequest{}, b.createOrderRequestSchema())
+	b.register("OrderValidation", OrderValidation{}, nil)
+	b.register("PatchOrderRequest", PatchOrderRequest{}, nil)
+	b.register("OrderPage", matching.OrderPage{}, nil)
+	b.register("AuditEntry", audit.Entry{}, nil)
+	b.register("APIError", errors.APIError{}, nil)
+	b.components["ErrorResponse"] = schema{
+		"type": "object",
+		"properties": schema{
+			"success": schema{"type": "boolean"},
+			"error":   b.ref(errors.APIError{}),
+		},
+		"required": []string{"success", "error"},
+	}
+
+	orderRef := b.ref(order.Order{})
+	accountHeader := schema{
+		"name": AccountHeader, "in": "header",
+		"description": "Account the request is made on behalf of",
+		"schema":      schema{"type": "string"},
+	}
+
+	paths := schema{
+		"/api/v1/orders": schema{
+			"get": schema{
+				"summary":     "List orders, or look one up by client order ID",
+				"operationId": "listOrders",
+				"parameters": []schema{
+					queryParam("symbol", "Only orders for this symbol", schema{"type": "string"}),
+					queryParam("side", "Only orders on this side", b.ref(order.Side(""))),
+					queryParam("status", "Only orders in this status", b.ref(order.Status(""))),
+					queryParam("from", "Created at or after (RFC 3339)", b.ref(time.Time{})),
+					queryParam("to", "Created before (RFC 3339)", b.ref(time.Time{})),
+					queryParam("limit", "Page size", schema{"type": "integer", "default": matching.DefaultListLimit, "maximum": matching.MaxListLimit}),
+					queryParam("offset", "Orders to skip", schema{"type": "integer", "default": 0}),
+					queryParam("clientOrderId", "Return the caller's order with this client order ID instead of a page", schema{"type": "string"}),
+					accountHeader,
+				},
+				"responses": schema{
+					"200": response("A page of orders, or the matching order when clientOrderId is given", b.ref(matching.OrderPage{})),
+					"400": errorResponse("Invalid filter"),
+					"404": errorResponse("No order with that client order ID"),
+				},
+			},
+			"post": schema{
+				"summary":     "Submit an order",
+				"operationId": "createOrder",
+				"parameters": []schema{
+					accountHeader,
+					{
+						"name": "Idempotency-Key", "in": "header",
+						"description": "Used as the client order ID when the body has none",
+						"schema":      schema{"type": "string"},
+					},
+				},
+				"requestBody": schema{
+					"required": true,
+					"content": schema{
+						errors.ContentTypeJSON: schema{"schema": b.ref(CreateOrderRequest{})},
+					},
+				},
+				"responses": schema{
+					"201": withHeaders(response("Order accepted", orderRef), schema{
+						"Location": schema{
+							"description": "URL of the created order",
+							"schema":      schema{"type": "string"},
+						},
+					}),
+					"200": response("Retry of an order already placed with this client order ID", orderRef),
+					"400": errorResponse("Invalid order"),
+					"413": errorResponse("Request body too large"),
+					"422": errorResponse("Insufficient balance"),
+					"429": errorResponse("Account's message quota on the symbol used up"),
+				},
+			},
+		},
+		"/api/v1/orders/validate": schema{
+			"post": schema{
+				"summary":     "Check an order without placing it",
+				"operationId": "validateOrder",
+				"parameters":  []schema{accountHeader},
+				"requestBody": schema{
+					"required": true,
+					"content": schema{
+						errors.ContentTypeJSON: schema{"schema": b.ref(CreateOrderRequest{})},
+					},
+				},
+				"responses": schema{
+					"200": response("The order would be accepted", b.ref(OrderValidation{})),
+					"400": errorResponse("Invalid order"),
+					"413": errorResponse("Request body too large"),
+					"422": errorResponse("Insufficient balance"),
+				},
+			},
+		},
+		"/api/v1/orders/{id}": schema{
+			"parameters": []schema{pathParam("id", "Order ID")},
+			"get": schema{
+				"summary":     "Get a resting order",
+				"operationId": "getOrder",
+				"responses": schema{
+					"200": withHeaders(response("The order", orderRef), schema{
+						"ETag": schema{
+							"description": "Current state of the order, for If-Match on cancel",
+							"schema":      schema{"type": "string"},
+						},
+					}),
+					"404": errorResponse("Order not found"),
+				},
+			},
+			"delete": schema{
+				"summary":     "Cancel an order",
+				"operationId": "cancelOrder",
+				"parameters": []schema{
+					{
+						"name": "If-Match", "in": "header",
+						"description": "Only cancel if the order still has this ETag",
+						"schema":      schema{"type": "string"},
+					},
+				},
+				"responses": schema{
+					"200": response("The order's final state", orderRef),
+					"404": errorResponse("Order not found"),
+					"409": errorResponse("Order already filled or cancelled"),
+					"412": errorResponse("Order changed since the ETag was read"),
+					"429": errorResponse("Account's message quota on the symbol used up"),
+				},
+			},
+			"patch": schema{
+				"summary":     "Reduce a resting order, keeping its queue position",
+				"operationId": "patchOrder",
+				"requestBody": schema{
+					"required": true,
+					"content": schema{
+						errors.ContentTypeJSON: schema{"schema": b.ref(PatchOrderRequest{})},
+					},
+				},
+				"responses": schema{
+					"200": response("The order's new state, cancelled when nothing is left to fill", orderRef),
+					"400": errorResponse("Invalid operation"),
+					"404": errorResponse("Order not found"),
+					"409": errorResponse("Order already filled or cancelled"),
+				},
+			},
+		},
+		"/api/v1/orders/{id}/history": schema{
+			"parameters": []schema{pathParam("id", "Order ID")},
+			"get": schema{
+				"summary":     "Get the state transitions of an order",
+				"operationId": "getOrderHistory",
+				"responses": schema{
+					"200": response("The order's history, oldest first", b.ref([]audit.Entry{})),
+					"404": errorResponse("No history recorded for the order"),
+				},
+			},
+		},
+		"/api/v1/orders/{id}/queue": schema{
+			"parameters": []schema{pathParam("id", "Order ID")},
+			"get": schema{
+				"summary":     "Get a resting order's place in its price level's queue",
+				"operationId": "getQueuePosition",
+				"responses": schema{
+					"200": response("The order's rank and the quantity ahead of it", b.ref(orderbook.QueuePosition{})),
+					"404": errorResponse("Order not found"),
+					"409": errorResponse("Order already filled or cancelled"),
+				},
+			},
+		},
+		"/api/v1/orderbook/{symbol}": schema{
+			"parameters": []schema{pathParam("symbol", "Trading symbol, e.g. BTC-USD")},
+			"get": schema{
+				"summary":     "Get a snapshot of a symbol's book",
+				"operationId": "getOrderBook",
+				"responses": schema{
+					"200": response("The book snapshot", b.ref(orderbook.OrderBookSnapshot{})),
+					"404": errorResponse("Symbol not found"),
+				},
+			},
+		},
+	}
+
+	return schema{
+		"openapi": "3.0.3",
+		"info": schema{
+			"title":   "Matching Engine API",
+			"version": "v1",
+		},
+		"paths":      paths,
+		"components": schema{"schemas": b.components},
+	}
+}
+
+var (
+	openAPIOnce sync.Once
+	openAPISpec schema
+)
+
+// OpenAPI serves the OpenAPI 3 description of the API
+func (h *Handler) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	openAPIOnce.Do(func() { openAPISpec = buildOpenAPI() })
+
+	// The spec is the document itself, not wrapped in the response envelope
+	w.Header().Set("Content-Type", errors.ContentTypeJSON)
+	errors.JSON.Encode(w, openAPISpec)
+}
+
+const swaggerUI = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Matching Engine API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+// SwaggerUI serves a Swagger UI page for the OpenAPI description
+func (h *Handler) SwaggerUI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(swaggerUI))
+}
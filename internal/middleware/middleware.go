@@ -1,12 +1,14 @@
 package middleware
 
 import (
+	"encoding/json"
 	"net/http"
 	"time"
 
 	"log/slog"
 
 	"company.com/matchengine/pkg/errors"
+	"company.com/matchengine/pkg/requestid"
 )
 
 type responseWriter struct {
@@ -32,6 +34,81 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.wroteHeader = true
 }
 
+// RequestID middleware tags each request with the client's X-Request-ID, or
+// a fresh one, and echoes it in the response
+func RequestID() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(requestid.Header)
+			if id == "" {
+				id = requestid.New()
+			}
+
+			w.Header().Set(requestid.Header, id)
+			next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+		})
+	}
+}
+
+// DefaultMaxBodyBytes caps request bodies when no other limit is configured
+const DefaultMaxBodyBytes int64 = 1 << 20
+
+// MaxBodySize middleware rejects request bodies larger than limit bytes with
+// 413. Bodies that declare their length are refused up front; others are
+// cut off once they pass the limit, and the handler reading them reports it.
+func MaxBodySize(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				errors.WriteJSON(w, errors.NewPayloadTooLarge(limit))
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DefaultRequestTimeout bounds handlers when no other timeout is configured
+const DefaultRequestTimeout = 10 * time.Second
+
+// Timeout middleware answers 503 when a handler runs longer than timeout,
+// telling the client to retry after retryAfter. The request context carries
+// the deadline, so context-aware service calls abort as well; whatever the
+// handler writes afterwards is discarded.
+func Timeout(timeout, retryAfter time.Duration) func(http.Handler) http.Handler {
+	body, _ := json.Marshal(errors.Response{
+		Error: errors.NewServiceUnavailable("request timed out"),
+	})
+	retryAfterHeader := errors.RetryAfterSeconds(retryAfter)
+
+	return func(next http.Handler) http.Handler {
+		timeoutHandler := http.TimeoutHandler(next, timeout, string(body))
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// TimeoutHandler writes its body without a Content-Type;
+			// handlers that finish in time set their own
+			w.Header().Set("Content-Type", "application/json")
+			timeoutHandler.ServeHTTP(&timeoutWriter{ResponseWriter: w, retryAfter: retryAfterHeader}, r)
+		})
+	}
+}
+
+// timeoutWriter adds Retry-After to a 503 that doesn't carry one, as
+// TimeoutHandler's doesn't. Handlers that finish in time have their headers
+// copied over before the status is written, so theirs are kept.
+type timeoutWriter struct {
+	http.ResponseWriter
+	retryAfter string
+}
+
+func (w *timeoutWriter) WriteHeader(status int) {
+	if status == http.StatusServiceUnavailable && w.Header().Get("Retry-After") == "" {
+		w.Header().Set("Retry-After", w.retryAfter)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
 // Logging middleware
 func Logger(logger *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -42,6 +119,7 @@ func Logger(logger *slog.Logger) func(http.Handler) http.Handler {
 				"method", r.Method,
 				"path", r.URL.Path,
 				"remote_addr", r.RemoteAddr,
+				"request_id", requestid.FromContext(r.Context()),
 			)
 
 			rw := newResponseWriter(w)
@@ -54,6 +132,7 @@ func Logger(logger *slog.Logger) func(http.Handler) http.Handler {
 				"method", r.Method,
 				"path", r.URL.Path,
 				"duration", time.Since(start),
+				"request_id", requestid.FromContext(r.Context()),
 			)
 		})
 	}
@@ -0,0 +1,141 @@
+// Package position tracks the net position each account holds per symbol
+package position
+
+import (
+	"sort"
+	"sync"
+
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+// Position is an account's net holding in one symbol
+type Position struct {
+	Symbol string `json:"symbol"`
+	// Quantity is signed: positive when long, negative when short
+	Quantity float64 `json:"quantity"`
+	// AvgEntryPrice is the volume-weighted price the open quantity was
+	// entered at. Reducing a position leaves it unchanged; flipping it
+	// starts over at the price of the trade that flipped it.
+	AvgEntryPrice float64 `json:"avg_entry_price"`
+}
+
+// Tracker keeps signed net positions, updated trade by trade: buys add to
+// the position and sells take from it
+type Tracker struct {
+	// accounts maps each account to its open positions by symbol
+	accounts map[string]map[string]*Position
+	mutex    sync.RWMutex
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{accounts: make(map[string]map[string]*Position)}
+}
+
+// Apply updates the positions of both accounts on a trade. Sides without an
+// account don't hold positions.
+func (t *Tracker) Apply(trade orderbook.Trade) {
+	takerDelta := trade.Quantity
+	if trade.TakerSide == order.SideSell {
+		takerDelta = -takerDelta
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.apply(trade.TakerAccountID, trade.Symbol, takerDelta, trade.Price)
+	t.apply(trade.MakerAccountID, trade.Symbol, -takerDelta, trade.Price)
+}
+
+// apply moves an account's position by delta at price. Callers hold
+// t.mutex.
+func (t *Tracker) apply(account, symbol string, delta, price float64) {
+	if account == "" {
+		return
+	}
+
+	positions, exists := t.accounts[account]
+	if !exists {
+		positions = make(map[string]*Position)
+		t.accounts[account] = positions
+	}
+	p, exists := positions[symbol]
+	if !exists {
+		p = &Position{Symbol: symbol}
+		positions[symbol] = p
+	}
+
+	net := p.Quantity + delta
+	switch {
+	case net == 0:
+		delete(positions, symbol)
+		if len(positions) == 0 {
+			delete(t.accounts, account)
+		}
+		return
+	case p.Quantity == 0 || net*p.Quantity < 0:
+		// Opened or flipped: what's left was all entered at this price
+		p.AvgEntryPrice = price
+	case (net > 0) == (delta > 0):
+		// Increased: fold the trade into the average
+		p.AvgEntryPrice = (p.AvgEntryPrice*abs(p.Quantity) + price*abs(delta)) / abs(net)
+	}
+	p.Quantity = net
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Net returns the account's signed position in symbol: positive when long,
+// negative when short
+func (t *Tracker) Net(accountID, symbol string) float64 {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	if p, exists := t.accounts[accountID][symbol]; exists {
+		return p.Quantity
+	}
+	return 0
+}
+
+// Positions returns the account's open positions, ordered by symbol
+func (t *Tracker) Positions(accountID string) []Position {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	positions := []Position{}
+	for _, p := range t.accounts[accountID] {
+		positions = append(positions, *p)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].Symbol < positions[j].Symbol
+	})
+	return positions
+}
+
+// Accounts returns the accounts holding an open position, sorted
+func (t *Tracker) Accounts() []string {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	accounts := make([]string, 0, len(t.accounts))
+	for account := range t.accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+	return accounts
+}
+
+// Reducible returns how much an order on side can trade before it would
+// take the account's position past zero
+func (t *Tracker) Reducible(accountID, symbol string, side order.Side) float64 {
+	net := t.Net(accountID, symbol)
+	if side == order.SideSell {
+		return max(net, 0)
+	}
+	return max(-net, 0)
+}
@@ -0,0 +1,66 @@
+package position
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+func TestTracker(t *testing.T) {
+	tracker := NewTracker()
+	trade := func(taker, maker string, side order.Side, quantity float64) {
+		tracker.Apply(orderbook.Trade{
+			Symbol: "BTC-USD", Price: 100, Quantity: quantity,
+			TakerAccountID: taker, MakerAccountID: maker, TakerSide: side,
+		})
+	}
+
+	trade("alice", "bob", order.SideBuy, 3)
+	trade("alice", "", order.SideSell, 1)
+	trade("", "carol", order.SideSell, 5)
+
+	assert.Equal(t, 2.0, tracker.Net("alice", "BTC-USD"))
+	assert.Equal(t, -3.0, tracker.Net("bob", "BTC-USD"))
+	assert.Equal(t, 5.0, tracker.Net("carol", "BTC-USD"))
+	assert.Zero(t, tracker.Net("alice", "ETH-USD"))
+
+	assert.Equal(t, 2.0, tracker.Reducible("alice", "BTC-USD", order.SideSell))
+	assert.Zero(t, tracker.Reducible("alice", "BTC-USD", order.SideBuy))
+	assert.Equal(t, 3.0, tracker.Reducible("bob", "BTC-USD", order.SideBuy))
+	assert.Zero(t, tracker.Reducible("dave", "BTC-USD", order.SideSell))
+	assert.Equal(t, []string{"alice", "bob", "carol"}, tracker.Accounts())
+
+	// Closed positions drop their account
+	trade("bob", "alice", order.SideBuy, 2)
+	assert.Equal(t, []string{"bob", "carol"}, tracker.Accounts())
+}
+
+func TestAvgEntryPrice(t *testing.T) {
+	tracker := NewTracker()
+	trade := func(side order.Side, price, quantity float64) {
+		tracker.Apply(orderbook.Trade{
+			Symbol: "BTC-USD", Price: price, Quantity: quantity,
+			TakerAccountID: "alice", TakerSide: side,
+		})
+	}
+
+	// Buying 1 at 100 and 3 at 200 averages 175
+	trade(order.SideBuy, 100, 1)
+	trade(order.SideBuy, 200, 3)
+	assert.Equal(t, []Position{{Symbol: "BTC-USD", Quantity: 4, AvgEntryPrice: 175}}, tracker.Positions("alice"))
+
+	// Selling part of it keeps the entry price
+	trade(order.SideSell, 300, 2)
+	assert.Equal(t, []Position{{Symbol: "BTC-USD", Quantity: 2, AvgEntryPrice: 175}}, tracker.Positions("alice"))
+
+	// Selling through zero opens a short at the flipping trade's price
+	trade(order.SideSell, 150, 5)
+	assert.Equal(t, []Position{{Symbol: "BTC-USD", Quantity: -3, AvgEntryPrice: 150}}, tracker.Positions("alice"))
+
+	// Closing it removes the position
+	trade(order.SideBuy, 120, 3)
+	assert.Empty(t, tracker.Positions("alice"))
+}
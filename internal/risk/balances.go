@@ -0,0 +1,192 @@
+package risk
+
+import (
+	"fmt"
+	"sync"
+
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+// Balance is what an account holds of one asset
+type Balance struct {
+	Available float64 `json:"available"`
+	Reserved  float64 `json:"reserved"`
+}
+
+type hold struct {
+	account string
+	asset   string
+	amount  float64
+}
+
+// Balances is an in-memory Checker. Buys hold quote currency (price ×
+// quantity, or the whole quote quantity of a quote-sized market buy) and
+// sells hold the base asset.
+type Balances struct {
+	accounts map[string]map[string]*Balance
+	holds    map[string]*hold
+	mutex    sync.Mutex
+}
+
+func NewBalances() *Balances {
+	return &Balances{
+		accounts: make(map[string]map[string]*Balance),
+		holds:    make(map[string]*hold),
+	}
+}
+
+// Deposit credits an account's available balance
+func (b *Balances) Deposit(account, asset string, amount float64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.balance(account, asset).Available += amount
+}
+
+// Balance returns an account's balance of one asset
+func (b *Balances) Balance(account, asset string) Balance {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return *b.balance(account, asset)
+}
+
+func (b *Balances) balance(account, asset string) *Balance {
+	assets, exists := b.accounts[account]
+	if !exists {
+		assets = make(map[string]*Balance)
+		b.accounts[account] = assets
+	}
+
+	balance, exists := assets[asset]
+	if !exists {
+		balance = &Balance{}
+		assets[asset] = balance
+	}
+	return balance
+}
+
+func (b *Balances) Reserve(o *order.Order, price float64) error {
+	asset, amount, err := holdFor(o, price)
+	if err != nil {
+		return err
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	h, exists := b.holds[o.ID]
+	if !exists {
+		h = &hold{account: o.AccountID, asset: asset}
+	}
+
+	balance := b.balance(h.account, h.asset)
+	delta := amount - h.amount
+	if delta > balance.Available {
+		return insufficient(o, asset, delta, balance.Available)
+	}
+
+	balance.Available -= delta
+	balance.Reserved += delta
+	h.amount = amount
+	b.holds[o.ID] = h
+	return nil
+}
+
+func (b *Balances) Check(o *order.Order, price float64) error {
+	asset, amount, err := holdFor(o, price)
+	if err != nil {
+		return err
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	account := o.AccountID
+	if h, exists := b.holds[o.ID]; exists {
+		account, asset = h.account, h.asset
+		amount -= h.amount
+	}
+
+	// Look the balance up without creating it, so checking leaves no trace
+	available := 0.0
+	if balance, exists := b.accounts[account][asset]; exists {
+		available = balance.Available
+	}
+	if amount > available {
+		return insufficient(o, asset, amount, available)
+	}
+	return nil
+}
+
+// holdFor returns the asset and amount an order's remaining quantity holds,
+// valuing each unit at price
+func holdFor(o *order.Order, price float64) (asset string, amount float64, err error) {
+	base, quote, err := orderbook.Assets(o.Symbol)
+	if err != nil {
+		return "", 0, err
+	}
+
+	asset, amount = base, o.RemainingQuantity()
+	if o.Side == order.SideBuy {
+		asset, amount = quote, price*o.RemainingQuantity()
+	}
+	if o.QuoteQuantity > 0 {
+		amount = o.QuoteQuantity
+	}
+	return asset, amount, nil
+}
+
+func insufficient(o *order.Order, asset string, needed, available float64) error {
+	return fmt.Errorf("%w: order needs %v %s, account %q has %v available",
+		ErrInsufficientBalance, needed, asset, o.AccountID, available)
+}
+
+func (b *Balances) Fill(o order.Order, trade orderbook.Trade) {
+	base, quote, err := orderbook.Assets(o.Symbol)
+	if err != nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	cost := trade.Price * trade.Quantity
+	if o.Side == order.SideBuy {
+		b.spend(o, quote, cost)
+		b.balance(o.AccountID, base).Available += trade.Quantity
+	} else {
+		b.spend(o, base, trade.Quantity)
+		b.balance(o.AccountID, quote).Available += cost
+	}
+}
+
+// spend takes amount from the order's hold, and from the available balance
+// once the hold runs out. Callers hold b.mutex.
+func (b *Balances) spend(o order.Order, asset string, amount float64) {
+	balance := b.balance(o.AccountID, asset)
+
+	if h, exists := b.holds[o.ID]; exists {
+		held := min(h.amount, amount)
+		h.amount -= held
+		balance.Reserved -= held
+		amount -= held
+	}
+	balance.Available -= amount
+}
+
+func (b *Balances) Release(orderID string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	h, exists := b.holds[orderID]
+	if !exists {
+		return
+	}
+
+	balance := b.balance(h.account, h.asset)
+	balance.Reserved -= h.amount
+	balance.Available += h.amount
+	delete(b.holds, orderID)
+}
@@ -0,0 +1,112 @@
+package risk
+
+import (
+	"testing"
+
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccountOrder(t *testing.T, account string, side order.Side, price, quantity float64) *order.Order {
+	t.Helper()
+
+	o, err := order.NewOrder(side, "BTC-USD", price, quantity)
+	require.NoError(t, err)
+	o.AccountID = account
+	return o
+}
+
+func TestReserveSufficientBalance(t *testing.T) {
+	balances := NewBalances()
+	balances.Deposit("alice", "USD", 1000)
+	balances.Deposit("alice", "BTC", 5)
+
+	buy := newAccountOrder(t, "alice", order.SideBuy, 100, 10)
+	require.NoError(t, balances.Reserve(buy, buy.Price))
+	assert.Equal(t, Balance{Available: 0, Reserved: 1000}, balances.Balance("alice", "USD"))
+
+	sell := newAccountOrder(t, "alice", order.SideSell, 100, 5)
+	require.NoError(t, balances.Reserve(sell, sell.Price))
+	assert.Equal(t, Balance{Available: 0, Reserved: 5}, balances.Balance("alice", "BTC"))
+}
+
+func TestReserveInsufficientBalance(t *testing.T) {
+	balances := NewBalances()
+	balances.Deposit("alice", "USD", 999)
+
+	buy := newAccountOrder(t, "alice", order.SideBuy, 100, 10)
+	err := balances.Reserve(buy, buy.Price)
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+	assert.Equal(t, Balance{Available: 999}, balances.Balance("alice", "USD"))
+
+	sell := newAccountOrder(t, "bob", order.SideSell, 100, 1)
+	assert.ErrorIs(t, balances.Reserve(sell, sell.Price), ErrInsufficientBalance)
+}
+
+func TestReserveResizesExistingHold(t *testing.T) {
+	balances := NewBalances()
+	balances.Deposit("alice", "USD", 1000)
+
+	buy := newAccountOrder(t, "alice", order.SideBuy, 100, 5)
+	require.NoError(t, balances.Reserve(buy, buy.Price))
+
+	buy.Quantity = 8
+	require.NoError(t, balances.Reserve(buy, buy.Price))
+	assert.Equal(t, Balance{Available: 200, Reserved: 800}, balances.Balance("alice", "USD"))
+
+	buy.Quantity = 11
+	assert.ErrorIs(t, balances.Reserve(buy, buy.Price), ErrInsufficientBalance)
+	assert.Equal(t, Balance{Available: 200, Reserved: 800}, balances.Balance("alice", "USD"))
+}
+
+func TestCheckHoldsNothing(t *testing.T) {
+	balances := NewBalances()
+	balances.Deposit("alice", "USD", 1000)
+
+	buy := newAccountOrder(t, "alice", order.SideBuy, 100, 10)
+	require.NoError(t, balances.Check(buy, buy.Price))
+	assert.Equal(t, Balance{Available: 1000}, balances.Balance("alice", "USD"))
+
+	buy.Quantity = 11
+	assert.ErrorIs(t, balances.Check(buy, buy.Price), ErrInsufficientBalance)
+
+	sell := newAccountOrder(t, "bob", order.SideSell, 100, 1)
+	assert.ErrorIs(t, balances.Check(sell, sell.Price), ErrInsufficientBalance)
+}
+
+func TestReleaseReturnsHold(t *testing.T) {
+	balances := NewBalances()
+	balances.Deposit("alice", "USD", 1000)
+
+	buy := newAccountOrder(t, "alice", order.SideBuy, 100, 10)
+	require.NoError(t, balances.Reserve(buy, buy.Price))
+
+	balances.Release(buy.ID)
+	assert.Equal(t, Balance{Available: 1000}, balances.Balance("alice", "USD"))
+
+	// Releasing twice is harmless
+	balances.Release(buy.ID)
+	assert.Equal(t, Balance{Available: 1000}, balances.Balance("alice", "USD"))
+}
+
+func TestFillSettlesBothSides(t *testing.T) {
+	balances := NewBalances()
+	balances.Deposit("alice", "USD", 1000)
+	balances.Deposit("bob", "BTC", 10)
+
+	buy := newAccountOrder(t, "alice", order.SideBuy, 100, 10)
+	sell := newAccountOrder(t, "bob", order.SideSell, 90, 4)
+	require.NoError(t, balances.Reserve(buy, buy.Price))
+	require.NoError(t, balances.Reserve(sell, sell.Price))
+
+	trade := orderbook.Trade{Symbol: "BTC-USD", Price: 90, Quantity: 4}
+	balances.Fill(*buy, trade)
+	balances.Fill(*sell, trade)
+
+	assert.Equal(t, Balance{Available: 0, Reserved: 640}, balances.Balance("alice", "USD"))
+	assert.Equal(t, Balance{Available: 4}, balances.Balance("alice", "BTC"))
+	assert.Equal(t, Balance{Available: 6}, balances.Balance("bob", "BTC"))
+	assert.Equal(t, Balance{Available: 360}, balances.Balance("bob", "USD"))
+}
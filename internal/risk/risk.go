@@ -0,0 +1,29 @@
+package risk
+
+import (
+	"errors"
+
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+// ErrInsufficientBalance is returned when an account can't cover an order
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+// Checker holds funds for orders before they reach the book and settles
+// them as they trade.
+type Checker interface {
+	// Reserve holds what the order's remaining quantity could cost, valuing
+	// each unit at price, replacing any earlier hold for the same order. It
+	// fails with ErrInsufficientBalance if the account can't cover it.
+	Reserve(o *order.Order, price float64) error
+
+	// Check reports whether Reserve would succeed, without holding anything
+	Check(o *order.Order, price float64) error
+
+	// Fill settles one trade on the order's side, spending held funds first
+	Fill(o order.Order, trade orderbook.Trade)
+
+	// Release returns whatever is still held for the order
+	Release(orderID string)
+}
@@ -0,0 +1,68 @@
+// Package server builds the HTTP server the API is served from, out of the
+// server settings in config
+package server
+
+import (
+	"net"
+	"net/http"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"golang.org/x/net/netutil"
+
+	"company.com/matchengine/internal/config"
+)
+
+// New returns a server for handler with cfg's timeouts, header limit and
+// keep-alives. With certs the server is set up for TLS under cfg.TLS, to be
+// started with ServeTLS(listener, "", ""); without, it speaks plaintext.
+// HTTP/2 is negotiated over TLS, and served over cleartext too when cfg.H2C
+// is set, each connection carrying at most cfg.HTTP2MaxConcurrentStreams
+// requests at once.
+func New(cfg config.ServerConfig, handler http.Handler, certs *CertReloader) (*http.Server, error) {
+	h2 := &http2.Server{
+		MaxConcurrentStreams: cfg.HTTP2MaxConcurrentStreams,
+		IdleTimeout:          cfg.IdleTimeout,
+	}
+	if cfg.H2C {
+		handler = h2c.NewHandler(handler, h2)
+	}
+
+	server := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+	server.SetKeepAlivesEnabled(cfg.KeepAlives)
+
+	if certs != nil {
+		conf, err := tlsConfig(cfg.TLS, certs)
+		if err != nil {
+			return nil, err
+		}
+		server.TLSConfig = conf
+	}
+
+	if err := http2.ConfigureServer(server, h2); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// Listen opens the server's address. With cfg.MaxConnections set, at most
+// that many connections are open at once; the rest wait in the accept
+// queue until one closes.
+func Listen(cfg config.ServerConfig) (net.Listener, error) {
+	listener, err := net.Listen("tcp", ":"+cfg.Port)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxConnections > 0 {
+		listener = netutil.LimitListener(listener, cfg.MaxConnections)
+	}
+	return listener, nil
+}
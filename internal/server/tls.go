@@ -0,0 +1,77 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"company.com/matchengine/internal/config"
+)
+
+// CertReloader hands the TLS stack the server's certificate and can load it
+// again from its files, so a renewed certificate is picked up without a
+// restart. New handshakes get the new certificate; connections already
+// open keep the one they were set up with.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	current  atomic.Pointer[tls.Certificate]
+}
+
+// NewCertReloader loads the certificate and key from their PEM files
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the certificate files again. When they fail to load, the
+// certificate in use is kept and the error returned.
+func (r *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading certificate %s: %w", r.certFile, err)
+	}
+	r.current.Store(&cert)
+	return nil
+}
+
+// GetCertificate returns the current certificate, for tls.Config
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.current.Load(), nil
+}
+
+// tlsConfig builds the TLS settings cfg describes around certs
+func tlsConfig(cfg config.TLSConfig, certs *CertReloader) (*tls.Config, error) {
+	conf := &tls.Config{GetCertificate: certs.GetCertificate}
+
+	switch cfg.MinVersion {
+	case "", "1.2":
+		conf.MinVersion = tls.VersionTLS12
+	case "1.3":
+		conf.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported minimum TLS version %q: use 1.2 or 1.3", cfg.MinVersion)
+	}
+
+	// Only the suites Go considers secure can be chosen
+	suites := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		suites[suite.Name] = suite.ID
+	}
+	for _, name := range cfg.CipherSuites {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		id, ok := suites[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %q", name)
+		}
+		conf.CipherSuites = append(conf.CipherSuites, id)
+	}
+	return conf, nil
+}
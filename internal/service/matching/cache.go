@@ -0,0 +1,69 @@
+package matching
+
+import (
+	"time"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+const (
+	// defaultIdempotencyTTL is how long a client order ID is remembered
+	defaultIdempotencyTTL = 24 * time.Hour
+	// defaultFinishedOrderTTL is how long a filled or cancelled order is
+	// remembered after it leaves the book
+	defaultFinishedOrderTTL = 24 * time.Hour
+)
+
+type cacheEntry struct {
+	key       string
+	order     *order.Order
+	expiresAt time.Time
+}
+
+// orderCache remembers orders by key for a fixed TTL. Entries share a
+// single TTL, so insertion order is also expiry order and pruning only ever
+// has to look at the front of the queue.
+type orderCache struct {
+	ttl     time.Duration
+	entries map[string]*cacheEntry
+	queue   []*cacheEntry
+}
+
+func newOrderCache(ttl time.Duration) *orderCache {
+	return &orderCache{
+		ttl:     ttl,
+		entries: make(map[string]*cacheEntry),
+	}
+}
+
+func (c *orderCache) get(key string) (*order.Order, bool) {
+	entry, exists := c.entries[key]
+	if !exists {
+		return nil, false
+	}
+	return entry.order, true
+}
+
+func (c *orderCache) put(key string, o *order.Order, now time.Time) {
+	entry := &cacheEntry{key: key, order: o, expiresAt: now.Add(c.ttl)}
+	c.entries[key] = entry
+	c.queue = append(c.queue, entry)
+}
+
+func (c *orderCache) prune(now time.Time) {
+	for len(c.queue) > 0 && !now.Before(c.queue[0].expiresAt) {
+		delete(c.entries, c.queue[0].key)
+		c.queue[0] = nil
+		c.queue = c.queue[1:]
+	}
+}
+
+func (c *orderCache) each(fn func(*order.Order)) {
+	for _, entry := range c.entries {
+		fn(entry.order)
+	}
+}
+
+func (c *orderCache) len() int {
+	return len(c.entries)
+}
@@ -0,0 +1,53 @@
+package matching
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrSymbolNotFound is returned when no book exists for a symbol
+var ErrSymbolNotFound = errors.New("symbol not found")
+
+// ErrReduceOnly is returned when a reduce-only order has no position to
+// reduce
+var ErrReduceOnly = errors.New("reduce-only order would not reduce a position")
+
+// ErrOverloaded is returned when more orders are being matched at once than
+// the service is configured to take
+var ErrOverloaded = errors.New("matching engine overloaded")
+
+// ErrAccountRequired is returned by account-wide operations called without
+// an account
+var ErrAccountRequired = errors.New("account required")
+
+// ErrQuotaExceeded is returned when an account sends more orders and
+// cancels on a symbol than its message quota allows
+var ErrQuotaExceeded = errors.New("message quota exceeded")
+
+// ErrOrderNotResting is returned when asking for the queue position of an
+// order that has filled or been cancelled
+var ErrOrderNotResting = errors.New("order is not resting")
+
+// ErrSymbolExists is returned when adding a symbol that already has a book
+var ErrSymbolExists = errors.New("symbol already exists")
+
+// retryError is an error the caller can retry once after has passed
+type retryError struct {
+	err   error
+	after time.Duration
+}
+
+func (e *retryError) Error() string { return e.err.Error() }
+func (e *retryError) Unwrap() error { return e.err }
+
+// RetryAfter reports how long the caller should wait before retrying the
+// operation that failed with err, for the errors that say: ErrQuotaExceeded
+// until the account's quota window ends, ErrOverloaded for the backoff set
+// by WithRetryBackoff.
+func RetryAfter(err error) (time.Duration, bool) {
+	var retry *retryError
+	if errors.As(err, &retry) {
+		return retry.after, true
+	}
+	return 0, false
+}
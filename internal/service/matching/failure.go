@@ -0,0 +1,47 @@
+package matching
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"company.com/matchengine/pkg/engine/orderbook"
+	"company.com/matchengine/pkg/requestid"
+)
+
+// reportFailure logs err when it is the book failing mid-operation: the
+// book has recovered from the panic and halted itself, and the operator
+// gets the panic, its stack and what Validate finds wrong with the book
+// left behind. Other errors are left to the caller.
+func (s *Service) reportFailure(ctx context.Context, book *orderbook.OrderBook, err error) {
+	var failure *orderbook.FailureError
+	if !errors.As(err, &failure) {
+		return
+	}
+
+	validation := "ok"
+	if err := book.Validate(); err != nil {
+		validation = err.Error()
+	}
+	s.getLogger().ErrorContext(ctx, "order book failed and was halted",
+		"symbol", failure.Symbol,
+		"panic", fmt.Sprint(failure.Panic),
+		"validation", validation,
+		"stack", string(failure.Stack),
+		"request_id", requestid.FromContext(ctx),
+	)
+}
+
+// invariantViolated logs a book an order left out of price order or
+// crossed, panicking afterwards when the service fails fast
+func (s *Service) invariantViolated(v orderbook.InvariantViolation) {
+	s.getLogger().Error("order book invariant violated",
+		"symbol", v.Symbol,
+		"order_id", v.OrderID,
+		"problems", v.Problems,
+		"dump", v.Dump,
+	)
+	if s.failOnViolation {
+		panic(v)
+	}
+}
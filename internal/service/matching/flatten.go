@@ -0,0 +1,142 @@
+package matching
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/requestid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// FlattenResult reports what flattening did to one account
+type FlattenResult struct {
+	AccountID string
+	// Cancelled holds the final states of the account's resting orders
+	Cancelled []order.Order
+	// Closing holds the market orders sent to close the account's
+	// positions, as they ended up after matching
+	Closing []order.Order
+	// Failed holds the closing orders a book refused, with the reason
+	Failed []ClosingFailure
+}
+
+// ClosingFailure is a closing order that couldn't be placed, such as one on
+// a halted symbol. The position it was meant to close is still open.
+type ClosingFailure struct {
+	Order order.Order
+	Err   error
+}
+
+// FlattenAccount takes an account out of the market: it cancels every
+// resting order of the account, then sends a reduce-only market order
+// against each open position. Closing orders only fill against the
+// liquidity on the book, so positions are driven toward zero rather than
+// guaranteed to close; calling it again works off whatever is left and never
+// opens a position the other way.
+func (s *Service) FlattenAccount(ctx context.Context, accountID string) (_ *FlattenResult, err error) {
+	ctx, span := startSpan(ctx, "matching.FlattenAccount",
+		attribute.String("account_id", accountID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if accountID == "" {
+		return nil, ErrAccountRequired
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Once started, a flatten runs to the end: stopping between the cancels
+	// and the closing orders would leave the account half out
+	ctx = context.WithoutCancel(ctx)
+
+	cancelled, err := s.CancelAccountOrders(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	result := &FlattenResult{AccountID: accountID, Cancelled: cancelled}
+
+	for _, p := range s.positions.Positions(accountID) {
+		side, quantity := order.SideSell, p.Quantity
+		if quantity < 0 {
+			side, quantity = order.SideBuy, -quantity
+		}
+
+		o, err := order.NewOrderOfType(order.TypeMarket, side, p.Symbol, 0, 0, quantity, false)
+		if err != nil {
+			return nil, err
+		}
+		o.AccountID = accountID
+		o.ReduceOnly = true
+
+		if err := s.addOrder(ctx, o, false); err != nil {
+			result.Failed = append(result.Failed, ClosingFailure{Order: *o, Err: err})
+			continue
+		}
+		result.Closing = append(result.Closing, *o)
+	}
+
+	if logger := s.getLogger(); logger.Enabled(ctx, slog.LevelInfo) {
+		logger.InfoContext(ctx, "account flattened",
+			"account_id", accountID,
+			"cancelled", len(result.Cancelled),
+			"closing", len(result.Closing),
+			"failed", len(result.Failed),
+			"request_id", requestid.FromContext(ctx),
+		)
+	}
+	span.SetAttributes(
+		attribute.Int("cancelled", len(result.Cancelled)),
+		attribute.Int("closing", len(result.Closing)),
+	)
+	return result, nil
+}
+
+// FlattenAll flattens every account holding a position or a resting order,
+// in account order. Orders without an account hold no position and are left
+// on the book.
+func (s *Service) FlattenAll(ctx context.Context) ([]FlattenResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	accounts := make(map[string]bool)
+	for _, account := range s.positions.Accounts() {
+		accounts[account] = true
+	}
+	err := s.IterateActiveOrders(ctx, func(o *order.Order) bool {
+		if o.AccountID != "" {
+			accounts[o.AccountID] = true
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(accounts))
+	for account := range accounts {
+		ids = append(ids, account)
+	}
+	sort.Strings(ids)
+
+	results := make([]FlattenResult, 0, len(ids))
+	for _, account := range ids {
+		result, err := s.FlattenAccount(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *result)
+	}
+
+	if logger := s.getLogger(); logger.Enabled(ctx, slog.LevelInfo) {
+		logger.InfoContext(ctx, "all accounts flattened",
+			"accounts", len(results),
+			"request_id", requestid.FromContext(ctx),
+		)
+	}
+	return results, nil
+}
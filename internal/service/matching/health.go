@@ -0,0 +1,43 @@
+package matching
+
+import "time"
+
+// Readiness describes whether the service should receive traffic
+type Readiness struct {
+	Ready        bool `json:"ready"`
+	Draining     bool `json:"draining"`
+	Symbols      int  `json:"symbols"`
+	ActiveOrders int  `json:"active_orders"`
+}
+
+// Drain marks the service as shutting down so readiness probes fail and load
+// balancers stop routing new traffic to it. Requests already in flight are
+// still served.
+func (s *Service) Drain() {
+	s.draining.Store(true)
+}
+
+// Readiness reports the engine's readiness together with basic book stats
+func (s *Service) Readiness() Readiness {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	activeOrders := 0
+	for _, book := range s.sortedBooks() {
+		activeOrders += book.ActiveOrderCount()
+	}
+
+	draining := s.draining.Load()
+	return Readiness{
+		Ready:        s.books != nil && !draining,
+		Draining:     draining,
+		Symbols:      len(s.books),
+		ActiveOrders: activeOrders,
+	}
+}
+
+// RetryBackoff is how long clients turned away while the service is
+// overloaded or draining should wait before trying again
+func (s *Service) RetryBackoff() time.Duration {
+	return s.retryBackoff
+}
@@ -0,0 +1,47 @@
+package matching
+
+import (
+	"sync"
+
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+// orderIndex maps each live order to the book holding it, so lookups by ID
+// don't have to scan every book
+type orderIndex struct {
+	books map[string]*orderbook.OrderBook
+	mutex sync.RWMutex
+}
+
+func newOrderIndex() *orderIndex {
+	return &orderIndex{books: make(map[string]*orderbook.OrderBook)}
+}
+
+func (i *orderIndex) add(orderID string, book *orderbook.OrderBook) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.books[orderID] = book
+}
+
+func (i *orderIndex) remove(orderID string) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	delete(i.books, orderID)
+}
+
+func (i *orderIndex) get(orderID string) (*orderbook.OrderBook, bool) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	book, exists := i.books[orderID]
+	return book, exists
+}
+
+func (i *orderIndex) len() int {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	return len(i.books)
+}
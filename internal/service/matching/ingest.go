@@ -0,0 +1,156 @@
+package matching
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// ingestQueue holds orders between the callers of AddOrder and the workers
+// that match them, so a burst waits its turn instead of reaching the books
+// all at once. Its depth and the time orders wait in it show when matching
+// is falling behind.
+type ingestQueue struct {
+	jobs chan *ingestJob
+
+	enqueued  atomic.Uint64
+	peakDepth atomic.Int64
+
+	// waited sums, in nanoseconds, how long the dequeued orders waited
+	waited   atomic.Int64
+	dequeued atomic.Uint64
+	maxWait  atomic.Int64
+}
+
+// ingestJob is an order in the queue. The worker taking it and the caller
+// giving up on it race to claim it; only a job the worker claims is matched.
+type ingestJob struct {
+	ctx        context.Context
+	order      *order.Order
+	enqueuedAt time.Time
+	claimed    atomic.Bool
+	done       chan ingestResult
+}
+
+// ingestResult is what matching a job returned, or the panic it raised,
+// raised again in the caller as if it had matched the order itself
+type ingestResult struct {
+	err       error
+	panicking any
+}
+
+// QueueStats describes the ingestion queue
+type QueueStats struct {
+	Depth     int     `json:"depth"`
+	Capacity  int     `json:"capacity"`
+	PeakDepth int     `json:"peak_depth"`
+	Enqueued  uint64  `json:"enqueued"`
+	AvgWaitMs float64 `json:"avg_wait_ms"`
+	MaxWaitMs float64 `json:"max_wait_ms"`
+}
+
+// startIngestQueue puts a queue of capacity orders in front of matching,
+// drained by workers goroutines that live as long as the process
+func (s *Service) startIngestQueue(capacity, workers int) {
+	q := &ingestQueue{jobs: make(chan *ingestJob, capacity)}
+	for range max(workers, 1) {
+		go s.drainQueue(q)
+	}
+	s.queue = q
+}
+
+// enqueueOrder queues o and waits for a worker to match it, returning what
+// matching did. A full queue sheds o with ErrOverloaded. If ctx ends while
+// o is still waiting, it is dropped and ctx's error returned; once a worker
+// has taken it, it is matched to completion.
+func (s *Service) enqueueOrder(ctx context.Context, o *order.Order) error {
+	q := s.queue
+	job := &ingestJob{ctx: ctx, order: o, enqueuedAt: time.Now(), done: make(chan ingestResult, 1)}
+
+	select {
+	case q.jobs <- job:
+	default:
+		s.ordersShed.Add(1)
+		return &retryError{
+			err:   fmt.Errorf("%w: ingestion queue full with %d orders", ErrOverloaded, cap(q.jobs)),
+			after: s.retryBackoff,
+		}
+	}
+	q.enqueued.Add(1)
+	raiseMax(&q.peakDepth, int64(len(q.jobs)))
+
+	var result ingestResult
+	select {
+	case result = <-job.done:
+	case <-ctx.Done():
+		if job.claimed.CompareAndSwap(false, true) {
+			return ctx.Err()
+		}
+		result = <-job.done
+	}
+	if result.panicking != nil {
+		panic(result.panicking)
+	}
+	return result.err
+}
+
+// drainQueue matches orders from q, one at a time, until the process exits
+func (s *Service) drainQueue(q *ingestQueue) {
+	for job := range q.jobs {
+		if !job.claimed.CompareAndSwap(false, true) {
+			continue
+		}
+
+		wait := time.Since(job.enqueuedAt)
+		q.waited.Add(int64(wait))
+		q.dequeued.Add(1)
+		raiseMax(&q.maxWait, int64(wait))
+
+		job.done <- s.matchQueued(job)
+	}
+}
+
+// matchQueued matches a job's order, catching a panic so it reaches the
+// caller instead of bringing the worker down
+func (s *Service) matchQueued(job *ingestJob) (result ingestResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			result.panicking = r
+		}
+	}()
+	result.err = s.addOrder(job.ctx, job.order, true)
+	return result
+}
+
+// stats reports the queue's depth and wait times so far
+func (q *ingestQueue) stats() *QueueStats {
+	stats := &QueueStats{
+		Depth:     len(q.jobs),
+		Capacity:  cap(q.jobs),
+		PeakDepth: int(q.peakDepth.Load()),
+		Enqueued:  q.enqueued.Load(),
+		MaxWaitMs: millis(q.maxWait.Load()),
+	}
+	if dequeued := q.dequeued.Load(); dequeued > 0 {
+		stats.AvgWaitMs = millis(q.waited.Load() / int64(dequeued))
+	}
+	return stats
+}
+
+// raiseMax stores value in m if it is larger than what m holds
+func raiseMax(m *atomic.Int64, value int64) {
+	for {
+		current := m.Load()
+		if value <= current || m.CompareAndSwap(current, value) {
+			return
+		}
+	}
+}
+
+// millis converts nanoseconds to fractional milliseconds
+func millis(nanos int64) float64 {
+	return float64(nanos) / float64(time.Millisecond)
+}
@@ -0,0 +1,93 @@
+package matching
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// latencyBounds are the upper bounds, in microseconds, of the match latency
+// histogram's buckets; slower matches fall in a last, unbounded one
+var latencyBounds = []float64{10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 25000, 100000}
+
+// latencyHistogram counts orders by how long the book took to match them
+type latencyHistogram struct {
+	counts   []atomic.Uint64
+	count    atomic.Uint64
+	sumNanos atomic.Int64
+	maxNanos atomic.Int64
+}
+
+// LatencyStats describes how long the books took to match the orders added
+// so far, as measured in each order's MatchLatencyMicros. P50Micros and
+// P99Micros are the upper bounds of the buckets those quantiles fall in,
+// capped at MaxMicros.
+type LatencyStats struct {
+	Count     uint64          `json:"count"`
+	AvgMicros float64         `json:"avg_micros"`
+	P50Micros float64         `json:"p50_micros"`
+	P99Micros float64         `json:"p99_micros"`
+	MaxMicros float64         `json:"max_micros"`
+	Buckets   []LatencyBucket `json:"buckets"`
+}
+
+// LatencyBucket counts the matches that took at most LeMicros and longer
+// than the previous bucket's bound. The last bucket has no bound.
+type LatencyBucket struct {
+	LeMicros float64 `json:"le_micros,omitempty"`
+	Count    uint64  `json:"count"`
+}
+
+func newLatencyHistogram() *latencyHistogram {
+	return &latencyHistogram{counts: make([]atomic.Uint64, len(latencyBounds)+1)}
+}
+
+// observe records one match that took micros
+func (h *latencyHistogram) observe(micros float64) {
+	bucket := len(latencyBounds)
+	for i, bound := range latencyBounds {
+		if micros <= bound {
+			bucket = i
+			break
+		}
+	}
+	h.counts[bucket].Add(1)
+	h.count.Add(1)
+
+	nanos := int64(micros * 1000)
+	h.sumNanos.Add(nanos)
+	raiseMax(&h.maxNanos, nanos)
+}
+
+func (h *latencyHistogram) stats() LatencyStats {
+	stats := LatencyStats{
+		Count:     h.count.Load(),
+		MaxMicros: float64(h.maxNanos.Load()) / 1000,
+		Buckets:   make([]LatencyBucket, len(h.counts)),
+	}
+	for i := range h.counts {
+		stats.Buckets[i].Count = h.counts[i].Load()
+		if i < len(latencyBounds) {
+			stats.Buckets[i].LeMicros = latencyBounds[i]
+		}
+	}
+	if stats.Count > 0 {
+		stats.AvgMicros = float64(h.sumNanos.Load()) / 1000 / float64(stats.Count)
+		stats.P50Micros = stats.quantile(0.5)
+		stats.P99Micros = stats.quantile(0.99)
+	}
+	return stats
+}
+
+// quantile returns the bound of the bucket quantile q of the matches falls
+// in, or the slowest match when that is sooner
+func (s LatencyStats) quantile(q float64) float64 {
+	rank := uint64(math.Ceil(q * float64(s.Count)))
+	var seen uint64
+	for _, bucket := range s.Buckets {
+		seen += bucket.Count
+		if seen >= rank && bucket.LeMicros > 0 {
+			return min(bucket.LeMicros, s.MaxMicros)
+		}
+	}
+	return s.MaxMicros
+}
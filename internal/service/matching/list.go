@@ -0,0 +1,151 @@
+package matching
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+const (
+	// DefaultListLimit is the page size used when a listing doesn't set one
+	DefaultListLimit = 100
+	// MaxListLimit caps the page size of a listing
+	MaxListLimit = 1000
+)
+
+// OrderFilter selects orders for ListOrders. Zero fields match everything;
+// From and To bound CreatedAt as [From, To).
+type OrderFilter struct {
+	AccountID string
+	Symbol    string
+	Side      order.Side
+	Status    order.Status
+	From      time.Time
+	To        time.Time
+	Limit     int
+	Offset    int
+}
+
+func (f OrderFilter) matches(o *order.Order) bool {
+	switch {
+	case f.AccountID != "" && o.AccountID != f.AccountID:
+		return false
+	case f.Symbol != "" && o.Symbol != f.Symbol:
+		return false
+	case f.Side != "" && o.Side != f.Side:
+		return false
+	case f.Status != "" && o.Status != f.Status:
+		return false
+	case !f.From.IsZero() && o.CreatedAt.Before(f.From):
+		return false
+	case !f.To.IsZero() && !o.CreatedAt.Before(f.To):
+		return false
+	}
+	return true
+}
+
+// OrderPage is one page of a listing along with the total number of
+// matching orders
+type OrderPage struct {
+	Orders []order.Order `json:"orders"`
+	Total  int           `json:"total"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+}
+
+// Orders returns copies of every order the service knows about: those
+// resting on a book, in symbol order, and those filled or cancelled recently
+func (s *Service) Orders(ctx context.Context) ([]order.Order, error) {
+	s.mutex.RLock()
+	var orders []order.Order
+	for _, book := range s.sortedBooks() {
+		if err := ctx.Err(); err != nil {
+			s.mutex.RUnlock()
+			return nil, err
+		}
+		orders = append(orders, book.Orders()...)
+	}
+	s.mutex.RUnlock()
+
+	s.finishedMutex.Lock()
+	s.finished.prune(s.now())
+	s.finished.each(func(o *order.Order) {
+		orders = append(orders, *o)
+	})
+	s.finishedMutex.Unlock()
+
+	return orders, nil
+}
+
+// IterateActiveOrders calls fn with a copy of each order resting on a book,
+// in symbol order and then in the book's order, until fn returns false or
+// ctx is done. Books are walked one at a time under their read lock, so fn
+// must not change them: collect what to act on and act after the walk.
+func (s *Service) IterateActiveOrders(ctx context.Context, fn func(*order.Order) bool) error {
+	s.mutex.RLock()
+	books := s.sortedBooks()
+	s.mutex.RUnlock()
+
+	more := true
+	for _, book := range books {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		book.IterateActiveOrders(func(o *order.Order) bool {
+			more = fn(o)
+			return more
+		})
+		if !more {
+			break
+		}
+	}
+	return nil
+}
+
+// ListOrders returns the page of orders matching filter, oldest first. Orders
+// created at the same instant are ordered by ID so pages are stable.
+func (s *Service) ListOrders(ctx context.Context, filter OrderFilter) (*OrderPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultListLimit
+	}
+	filter.Limit = min(filter.Limit, MaxListLimit)
+	filter.Offset = max(filter.Offset, 0)
+	if filter.Symbol != "" {
+		s.mutex.RLock()
+		filter.Symbol = s.canonicalSymbol(filter.Symbol)
+		s.mutex.RUnlock()
+	}
+
+	all, err := s.Orders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := all[:0]
+	for i := range all {
+		if filter.matches(&all[i]) {
+			matched = append(matched, all[i])
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.Before(matched[j].CreatedAt)
+		}
+		return matched[i].ID < matched[j].ID
+	})
+
+	page := &OrderPage{
+		Orders: []order.Order{},
+		Total:  len(matched),
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}
+	if filter.Offset < len(matched) {
+		end := min(filter.Offset+filter.Limit, len(matched))
+		page.Orders = matched[filter.Offset:end]
+	}
+	return page, nil
+}
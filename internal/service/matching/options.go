@@ -0,0 +1,173 @@
+package matching
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"company.com/matchengine/internal/audit"
+	"company.com/matchengine/internal/event"
+	"company.com/matchengine/internal/risk"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+// Option configures a Service at construction
+type Option func(*Service)
+
+// WithLogger sets the logger the service writes to, as SetLogger does
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Service) {
+		if logger != nil {
+			s.logger = logger
+		}
+	}
+}
+
+// WithClock sets the time source used to expire remembered orders and
+// client order IDs
+func WithClock(now func() time.Time) Option {
+	return func(s *Service) {
+		if now != nil {
+			s.now = now
+		}
+	}
+}
+
+// WithSymbols registers symbols up front, as RegisterSymbol does. Every
+// config needs a Symbol; NewService panics otherwise.
+func WithSymbols(configs ...orderbook.SymbolConfig) Option {
+	return func(s *Service) {
+		s.symbols = append(s.symbols, configs...)
+	}
+}
+
+// WithEventPublisher sets where order lifecycle events go, as
+// SetEventPublisher does
+func WithEventPublisher(publisher event.EventPublisher) Option {
+	return func(s *Service) {
+		if publisher != nil {
+			s.publisher = publisher
+		}
+	}
+}
+
+// WithRiskChecker makes orders reserve funds through checker, as
+// SetRiskChecker does
+func WithRiskChecker(checker risk.Checker) Option {
+	return func(s *Service) {
+		s.risk = checker
+	}
+}
+
+// WithAuditStore sets where order histories are kept. By default the most
+// recent audit.DefaultMaxOrders orders are kept in memory.
+func WithAuditStore(store audit.Store) Option {
+	return func(s *Service) {
+		if store != nil {
+			s.audit = store
+		}
+	}
+}
+
+// WithIdempotencyTTL sets how long a client order ID is remembered for
+// deduplication
+func WithIdempotencyTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.idempotency = newOrderCache(ttl)
+	}
+}
+
+// WithFinishedOrderTTL sets how long filled and cancelled orders can still
+// be looked up after they leave the book
+func WithFinishedOrderTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.finished = newOrderCache(ttl)
+	}
+}
+
+// WithMaxInFlight sheds load: once max orders are being matched at once,
+// further orders fail with ErrOverloaded until some complete. Zero, the
+// default, admits every order.
+func WithMaxInFlight(max int) Option {
+	return func(s *Service) {
+		s.maxInFlight = int64(max)
+	}
+}
+
+// defaultRetryBackoff is how long clients turned away for overload are told
+// to wait unless WithRetryBackoff says otherwise
+const defaultRetryBackoff = time.Second
+
+// WithRetryBackoff sets how long clients whose orders are shed for overload,
+// or who find the service draining, are told to wait before retrying; see
+// RetryAfter and RetryBackoff. The default is one second.
+func WithRetryBackoff(d time.Duration) Option {
+	return func(s *Service) {
+		if d > 0 {
+			s.retryBackoff = d
+		}
+	}
+}
+
+// WithIngestQueue puts a queue of up to capacity orders in front of
+// matching, drained by workers goroutines. AddOrder waits for its order to
+// come out of the queue and be matched; once capacity orders are waiting,
+// further ones fail with ErrOverloaded. Stats reports the queue's depth and
+// how long orders wait in it. Zero capacity, the default, matches each
+// order in its caller's goroutine.
+func WithIngestQueue(capacity, workers int) Option {
+	return func(s *Service) {
+		s.queue = nil
+		if capacity > 0 {
+			s.startIngestQueue(capacity, workers)
+		}
+	}
+}
+
+// WithMessageQuota caps the orders and cancels each account may send per
+// symbol at perSecond in every one-second window; past it they fail with
+// ErrQuotaExceeded until the next window. The cap applies to every
+// transport, since it is enforced here. Orders and cancels without an
+// account aren't counted. Zero, the default, sets no cap.
+func WithMessageQuota(perSecond int) Option {
+	return func(s *Service) {
+		s.quota = nil
+		if perSecond > 0 {
+			s.quota = newMessageQuota(perSecond)
+		}
+	}
+}
+
+// WithInvariantChecks has every book check, after each order, that its
+// levels are in price order and that it isn't left locked or crossed
+// outside an auction or halt. Violations are logged at error level with a
+// dump of the book; with failFast they also panic, so the order that
+// caused one fails loudly instead of trading on. The check walks whole
+// books and is meant for debugging.
+func WithInvariantChecks(failFast bool) Option {
+	return func(s *Service) {
+		s.invariantChecks = true
+		s.failOnViolation = failFast
+	}
+}
+
+// WithPaperAccounts puts accounts on paper trading from the start, as
+// SetPaperAccount does
+func WithPaperAccounts(accountIDs ...string) Option {
+	return func(s *Service) {
+		for _, accountID := range accountIDs {
+			s.paper.setPaper(accountID, true)
+		}
+	}
+}
+
+// registerSymbols creates the books collected by WithSymbols, once every
+// other option has been applied
+func (s *Service) registerSymbols() {
+	for _, config := range s.symbols {
+		if err := s.RegisterSymbol(config); err != nil {
+			panic(fmt.Sprintf("matching: %v", err))
+		}
+	}
+	s.symbols = nil
+}
@@ -0,0 +1,169 @@
+package matching
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"company.com/matchengine/internal/position"
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+	"company.com/matchengine/pkg/requestid"
+)
+
+// paperOrdersPerAccount bounds the paper orders kept per account; the
+// oldest are forgotten first
+const paperOrdersPerAccount = 1000
+
+// paperLedger holds what paper accounts did: which accounts trade on paper,
+// their orders' final states and their positions, apart from the real ones
+type paperLedger struct {
+	mutex     sync.RWMutex
+	accounts  map[string]bool
+	orders    map[string]*order.Order
+	byAccount map[string][]string
+
+	positions *position.Tracker
+}
+
+func newPaperLedger() *paperLedger {
+	return &paperLedger{
+		accounts:  make(map[string]bool),
+		orders:    make(map[string]*order.Order),
+		byAccount: make(map[string][]string),
+		positions: position.NewTracker(),
+	}
+}
+
+func (l *paperLedger) isPaper(accountID string) bool {
+	if accountID == "" {
+		return false
+	}
+
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return l.accounts[accountID]
+}
+
+func (l *paperLedger) setPaper(accountID string, paper bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if paper {
+		l.accounts[accountID] = true
+	} else {
+		delete(l.accounts, accountID)
+	}
+}
+
+// record keeps an order's final state and applies its trades to the paper
+// positions
+func (l *paperLedger) record(o *order.Order, trades []orderbook.Trade) {
+	for _, trade := range trades {
+		l.positions.Apply(trade)
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	final := *o
+	l.orders[o.ID] = &final
+	ids := append(l.byAccount[o.AccountID], o.ID)
+	if len(ids) > paperOrdersPerAccount {
+		delete(l.orders, ids[0])
+		ids = ids[1:]
+	}
+	l.byAccount[o.AccountID] = ids
+}
+
+func (l *paperLedger) order(orderID string) (*order.Order, bool) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	o, exists := l.orders[orderID]
+	if !exists {
+		return nil, false
+	}
+	final := *o
+	return &final, true
+}
+
+func (l *paperLedger) accountOrders(accountID string) []order.Order {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	ids := l.byAccount[accountID]
+	orders := make([]order.Order, 0, len(ids))
+	for _, id := range ids {
+		orders = append(orders, *l.orders[id])
+	}
+	return orders
+}
+
+// SetPaperAccount switches an account between paper and live trading. A
+// paper account's orders are matched against the live book's liquidity
+// without changing it: no resting order is filled, nothing of the paper
+// order rests, and no events are published. What doesn't fill on arrival
+// is cancelled, since no live order ever trades against a paper one. The
+// fills update positions kept apart from the live ones, which Positions
+// reports for the account while it is on paper.
+func (s *Service) SetPaperAccount(accountID string, paper bool) {
+	s.paper.setPaper(accountID, paper)
+}
+
+// IsPaperAccount reports whether an account trades on paper
+func (s *Service) IsPaperAccount(accountID string) bool {
+	return s.paper.isPaper(accountID)
+}
+
+// PaperOrders returns the final states of an account's paper orders, oldest
+// first
+func (s *Service) PaperOrders(ctx context.Context, accountID string) ([]order.Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.paper.accountOrders(accountID), nil
+}
+
+// addPaperOrder runs an order of a paper account through the same checks
+// as a live one, then fills it against a read-only walk of the live book
+func (s *Service) addPaperOrder(ctx context.Context, o *order.Order) error {
+	book, symbol, exists := s.lookupBook(o.Symbol)
+	o.Symbol = symbol
+	if !exists {
+		book = orderbook.NewOrderBook(symbol)
+	}
+
+	config, release := book.HoldConfig()
+	defer release()
+
+	if err := config.Normalize(o); err != nil {
+		return err
+	}
+	if err := capReduceOnly(s.paper.positions, o); err != nil {
+		return err
+	}
+	if err := book.ValidateOrder(o); err != nil {
+		return err
+	}
+
+	trades := book.SimulateOrder(o)
+	if o.IsActive() {
+		o.Cancel()
+	}
+	s.paper.record(o, trades)
+
+	if logger := s.getLogger(); logger.Enabled(ctx, slog.LevelDebug) {
+		logger.DebugContext(ctx, "paper order filled",
+			"order_id", o.ID,
+			"account_id", o.AccountID,
+			"symbol", o.Symbol,
+			"filled", o.Filled,
+			"status", o.Status,
+			"request_id", requestid.FromContext(ctx),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package matching
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+// quotaKey is what a message quota is counted per
+type quotaKey struct {
+	account string
+	symbol  string
+}
+
+// messageQuota caps the orders and cancels each account sends per symbol
+// in each one-second window. Counts only cover the current window and are
+// dropped when it ends, so memory is bounded by the accounts active within
+// a second.
+type messageQuota struct {
+	limit int
+
+	mutex  sync.Mutex
+	window time.Time
+	counts map[quotaKey]int
+}
+
+func newMessageQuota(perSecond int) *messageQuota {
+	return &messageQuota{limit: perSecond, counts: make(map[quotaKey]int)}
+}
+
+// take counts one message of the account on symbol, reporting false when
+// the account has used up its quota for the current window. Messages past
+// the quota aren't counted.
+func (q *messageQuota) take(account, symbol string, now time.Time) bool {
+	window := now.Truncate(time.Second)
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if !window.Equal(q.window) {
+		q.window = window
+		clear(q.counts)
+	}
+
+	key := quotaKey{account: account, symbol: symbol}
+	if q.counts[key] >= q.limit {
+		return false
+	}
+	q.counts[key]++
+	return true
+}
+
+// checkQuota counts an order or cancel against the account's quota on
+// symbol. Messages without an account aren't throttled, nor is anything
+// when no quota is configured.
+func (s *Service) checkQuota(account, symbol string) error {
+	if s.quota == nil || account == "" {
+		return nil
+	}
+	now := s.now()
+	if !s.quota.take(account, symbol, now) {
+		s.messagesThrottled.Add(1)
+		return &retryError{
+			err: fmt.Errorf("%w: account %s sent more than %d orders and cancels on %s in a second",
+				ErrQuotaExceeded, account, s.quota.limit, symbol),
+			after: now.Truncate(time.Second).Add(time.Second).Sub(now),
+		}
+	}
+	return nil
+}
+
+// checkCancelQuota counts a cancel against the quota of the account owning
+// the order. An order the book doesn't know is left for the cancel to
+// report.
+func (s *Service) checkCancelQuota(book *orderbook.OrderBook, symbol, orderID string) error {
+	if s.quota == nil {
+		return nil
+	}
+	resting, err := book.GetOrder(orderID)
+	if err != nil {
+		return nil
+	}
+	return s.checkQuota(resting.AccountID, symbol)
+}
@@ -0,0 +1,185 @@
+package matching
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"company.com/matchengine/internal/risk"
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+	"company.com/matchengine/pkg/requestid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// OperationResult is the outcome of one operation in a CancelReplace. Err is
+// nil when the operation was applied.
+type OperationResult struct {
+	OrderID string
+	Err     error
+}
+
+// CancelReplaceResult holds one result per requested cancel and order, in
+// request order
+type CancelReplaceResult struct {
+	Cancels []OperationResult
+	Orders  []OperationResult
+}
+
+// CancelReplace cancels orders and adds new ones on a symbol's book as a
+// single operation: no reader of the book sees the cancels without the new
+// orders. Operations fail independently and are reported per operation.
+//
+// Risk holds for the new orders are taken before the cancels free theirs, so
+// the account must cover both for the duration of the call. Client order IDs
+// on the new orders are not deduplicated.
+//
+// With keepIDs each order replaces the order cancelled at the same position
+// and takes its ID, so clients can go on tracking it by the ID they know;
+// there must be as many orders as cancels. A replacement is only added when
+// its cancel succeeds, and fails with orderbook.ErrOriginalNotCancelled
+// otherwise. It belongs to the same account as the order it replaces,
+// whose hold is resized to it rather than a second one taken, and its
+// history carries on the original's. Without keepIDs the new orders keep
+// their own IDs. Either way a replacement is a new order: it joins the back
+// of the queue at its price, even at the original's price and quantity.
+// AmendOrder keeps the queue position of an order reduced in place.
+func (s *Service) CancelReplace(ctx context.Context, symbol string, cancelIDs []string, orders []*order.Order, keepIDs bool) (_ *CancelReplaceResult, err error) {
+	ctx, span := startSpan(ctx, "matching.CancelReplace",
+		attribute.String("symbol", symbol),
+		attribute.Int("cancels", len(cancelIDs)),
+		attribute.Int("orders", len(orders)),
+		attribute.Bool("keep_ids", keepIDs),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if keepIDs && len(orders) != len(cancelIDs) {
+		return nil, fmt.Errorf("keeping IDs needs one order per cancel, got %d cancels and %d orders", len(cancelIDs), len(orders))
+	}
+
+	var book *orderbook.OrderBook
+	if len(orders) == 0 {
+		var exists bool
+		if book, symbol, exists = s.lookupBook(symbol); !exists {
+			return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+		}
+	} else {
+		book, symbol = s.bookOrCreate(symbol)
+	}
+
+	config, release := book.HoldConfig()
+	defer release()
+
+	result := &CancelReplaceResult{
+		Cancels: make([]OperationResult, len(cancelIDs)),
+		Orders:  make([]OperationResult, len(orders)),
+	}
+	var cancelling []string
+	var cancellingAt []int
+	for i, orderID := range cancelIDs {
+		result.Cancels[i].OrderID = orderID
+		if err := s.checkCancelQuota(book, symbol, orderID); err != nil {
+			result.Cancels[i].Err = err
+			continue
+		}
+		cancelling = append(cancelling, orderID)
+		cancellingAt = append(cancellingAt, i)
+	}
+
+	// Orders the risk checker turns down never reach the book
+	checker := s.riskChecker()
+	var accepted []*order.Order
+	var acceptedAt []int
+	for i, o := range orders {
+		if keepIDs {
+			o.ID = cancelIDs[i]
+			if result.Cancels[i].Err != nil {
+				result.Orders[i] = OperationResult{
+					OrderID: o.ID,
+					Err:     fmt.Errorf("%w: %s", orderbook.ErrOriginalNotCancelled, o.ID),
+				}
+				continue
+			}
+		}
+		result.Orders[i].OrderID = o.ID
+		o.Symbol = symbol
+		if err := s.checkQuota(o.AccountID, symbol); err != nil {
+			result.Orders[i].Err = err
+			continue
+		}
+		if err := s.prepareOrder(config, o); err != nil {
+			result.Orders[i].Err = err
+			continue
+		}
+		if checker != nil {
+			if err := checker.Reserve(o, reservePrice(book, o)); err != nil {
+				result.Orders[i].Err = err
+				continue
+			}
+		}
+		s.index.add(o.ID, book)
+		accepted = append(accepted, o)
+		acceptedAt = append(acceptedAt, i)
+	}
+
+	cancelErrs, addErrs := book.ReplaceOrders(cancelling, accepted)
+
+	for j, err := range cancelErrs {
+		if err != nil {
+			if final, exists := s.finishedOrder(cancelling[j]); exists {
+				err = fmt.Errorf("%w: order is %s", orderbook.ErrOrderNotCancellable, final.Status)
+			}
+			result.Cancels[cancellingAt[j]].Err = err
+			continue
+		}
+		s.ordersCancelled.Add(1)
+	}
+	for j, err := range addErrs {
+		o := accepted[j]
+		if err != nil {
+			s.reportFailure(ctx, book, err)
+			result.Orders[acceptedAt[j]].Err = err
+			if errors.Is(err, orderbook.ErrOriginalNotCancelled) {
+				s.keepOriginal(book, checker, o.ID)
+				continue
+			}
+			s.index.remove(o.ID)
+			if checker != nil {
+				checker.Release(o.ID)
+			}
+			continue
+		}
+		s.ordersAdded.Add(1)
+	}
+
+	if logger := s.getLogger(); logger.Enabled(ctx, slog.LevelDebug) {
+		logger.DebugContext(ctx, "orders replaced",
+			"symbol", symbol,
+			"cancels", len(cancelIDs),
+			"orders", len(orders),
+			"request_id", requestid.FromContext(ctx),
+		)
+	}
+	return result, nil
+}
+
+// keepOriginal undoes taking a replacement's hold and index entry under the
+// ID of an order that failed to cancel: an original still on the book gets
+// its hold back at its own size, and one that is gone loses both
+func (s *Service) keepOriginal(book *orderbook.OrderBook, checker risk.Checker, orderID string) {
+	original, err := book.GetOrder(orderID)
+	if err != nil {
+		s.index.remove(orderID)
+		if checker != nil {
+			checker.Release(orderID)
+		}
+		return
+	}
+	if checker != nil {
+		_ = checker.Reserve(original, reservePrice(book, original))
+	}
+}
@@ -1,56 +1,1224 @@
 package matching
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
 	"sync"
+	"sync/atomic"
+	"time"
 
-	"company.com/matchengine/internal/domain/order"
-	"company.com/matchengine/internal/domain/orderbook"
+	"company.com/matchengine/internal/audit"
+	"company.com/matchengine/internal/candle"
+	"company.com/matchengine/internal/event"
+	"company.com/matchengine/internal/position"
+	"company.com/matchengine/internal/risk"
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+	"company.com/matchengine/pkg/requestid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+var _ orderbook.Engine = (*Service)(nil)
+
 type Service struct {
 	books map[string]*orderbook.OrderBook
-	mutex sync.RWMutex
+	// compactSymbols maps each book's symbol without its separator to the
+	// symbol, so BTCUSD finds BTC-USD
+	compactSymbols map[string]string
+	// sortedSymbols lists the keys of books in order
+	sortedSymbols []string
+	mutex         sync.RWMutex
+
+	idempotency      *orderCache
+	idempotencyMutex sync.Mutex
+	// reserved holds the client keys whose orders are being placed; each
+	// channel is closed once its order is recorded or dropped
+	reserved map[string]chan struct{}
+
+	finished      *orderCache
+	finishedMutex sync.Mutex
+
+	index *orderIndex
+
+	haltListener func(orderbook.HaltEvent)
+	publisher    event.EventPublisher
+	risk         risk.Checker
+	audit        audit.Store
+	positions    *position.Tracker
+	paper        *paperLedger
+	candles      *candle.Aggregator
+	logger       *slog.Logger
+
+	draining        atomic.Bool
+	ordersAdded     atomic.Uint64
+	ordersCancelled atomic.Uint64
+	ordersShed      atomic.Uint64
+
+	// matchLatency collects how long the books took to match each order
+	matchLatency *latencyHistogram
+
+	// quota, when set, caps the orders and cancels each account sends per
+	// symbol; messagesThrottled counts those it turned down
+	quota             *messageQuota
+	messagesThrottled atomic.Uint64
+
+	// inFlight counts the orders AddOrder is working on; past maxInFlight
+	// (when positive) new ones are shed
+	inFlight    atomic.Int64
+	maxInFlight int64
+
+	// queue, when set, holds orders until a worker matches them
+	queue *ingestQueue
+
+	// retryBackoff is how long clients turned away for overload or while
+	// draining are told to wait
+	retryBackoff time.Duration
+
+	// invariantChecks has books check their price ordering after each
+	// order; failOnViolation makes a violation panic
+	invariantChecks bool
+	failOnViolation bool
+
+	now func() time.Time
+
+	// symbols holds WithSymbols configs until NewService registers them
+	symbols []orderbook.SymbolConfig
 }
 
-func NewService() *Service {
-	return &Service{
-		books: make(map[string]*orderbook.OrderBook),
+// NewService creates a matching service. Without options it logs to the
+// default logger, publishes no events and takes orders without balance
+// checks; symbols are created on their first order.
+func NewService(opts ...Option) *Service {
+	s := &Service{
+		books:          make(map[string]*orderbook.OrderBook),
+		compactSymbols: make(map[string]string),
+		idempotency:    newOrderCache(defaultIdempotencyTTL),
+		reserved:       make(map[string]chan struct{}),
+		finished:       newOrderCache(defaultFinishedOrderTTL),
+		index:          newOrderIndex(),
+		publisher:      event.NopPublisher{},
+		audit:          audit.NewMemoryStore(audit.DefaultMaxOrders),
+		positions:      position.NewTracker(),
+		paper:          newPaperLedger(),
+		candles:        candle.NewAggregator(),
+		logger:         slog.Default(),
+		matchLatency:   newLatencyHistogram(),
+		retryBackoff:   defaultRetryBackoff,
+		now:            time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.registerSymbols()
+	return s
+}
+
+// newBook creates a book wired to the service. Callers hold s.mutex.
+func (s *Service) newBook(config orderbook.SymbolConfig) *orderbook.OrderBook {
+	book := orderbook.NewOrderBookWithConfig(config)
+	book.SetHaltListener(s.notifyHalt)
+	book.SetUpdateListener(s.handleUpdates)
+	if s.invariantChecks {
+		book.SetInvariantListener(s.invariantViolated)
+	}
+	return book
+}
+
+// SetLogger replaces the logger the service writes to. Per-order tracing is
+// logged at debug level.
+func (s *Service) SetLogger(logger *slog.Logger) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if logger == nil {
+		logger = slog.Default()
 	}
+	s.logger = logger
+}
+
+// Now returns the time on the service's clock, as set by WithClock. Order
+// expiry is measured against it, so absolute expiry times handed to the
+// service should be computed from it.
+func (s *Service) Now() time.Time {
+	return s.now()
 }
 
-func (s *Service) AddOrder(o *order.Order) error {
+func (s *Service) getLogger() *slog.Logger {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.logger
+}
+
+// SetRiskChecker makes orders reserve funds through checker before they
+// reach the book. Pass nil to accept orders without balance checks.
+func (s *Service) SetRiskChecker(checker risk.Checker) {
 	s.mutex.Lock()
-	book, exists := s.books[o.Symbol]
+	defer s.mutex.Unlock()
+
+	s.risk = checker
+}
+
+func (s *Service) riskChecker() risk.Checker {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.risk
+}
+
+func (s *Service) handleUpdates(updates []orderbook.Update) {
+	departs := departures(updates)
+	s.recordHistory(updates)
+	s.rememberFinished(updates, departs)
+	s.trackPositions(updates)
+	s.recordCandles(updates)
+	s.settleUpdates(updates, departs)
+	s.publishUpdates(updates)
+}
+
+// departures reports, for each update, whether its order leaves the book
+// with it: the update is final and no later one in the batch brings the ID
+// back, as replacing an order under its own ID does
+func departures(updates []orderbook.Update) []bool {
+	departs := make([]bool, len(updates))
+	var returning map[string]bool
+	for i := len(updates) - 1; i >= 0; i-- {
+		o := &updates[i].Order
+		if o.IsActive() {
+			if returning == nil {
+				returning = make(map[string]bool)
+			}
+			returning[o.ID] = true
+			continue
+		}
+		departs[i] = !returning[o.ID]
+	}
+	return departs
+}
+
+// recordHistory appends each update to its order's audit trail
+func (s *Service) recordHistory(updates []orderbook.Update) {
+	for _, u := range updates {
+		s.audit.Append(u.Order.ID, audit.FromUpdate(u))
+	}
+}
+
+// OrderHistory returns the recorded state transitions of an order, oldest
+// first
+func (s *Service) OrderHistory(ctx context.Context, orderID string) ([]audit.Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	history, exists := s.audit.History(orderID)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", orderbook.ErrOrderNotFound, orderID)
+	}
+	return history, nil
+}
+
+// rememberFinished keeps the final state of orders that left the book, so
+// they can still be told apart from orders that never existed
+func (s *Service) rememberFinished(updates []orderbook.Update, departs []bool) {
+	s.finishedMutex.Lock()
+	defer s.finishedMutex.Unlock()
+
+	now := s.now()
+	s.finished.prune(now)
+
+	for i, u := range updates {
+		if departs[i] {
+			final := u.Order
+			s.finished.put(final.ID, &final, now)
+			s.index.remove(final.ID)
+		}
+	}
+}
+
+func (s *Service) finishedOrder(orderID string) (*order.Order, bool) {
+	s.finishedMutex.Lock()
+	defer s.finishedMutex.Unlock()
+
+	return s.finished.get(orderID)
+}
+
+// trackPositions applies every trade to the positions of both accounts.
+// Each trade updates the maker then the taker; it is applied once, on the
+// taker's update.
+func (s *Service) trackPositions(updates []orderbook.Update) {
+	for _, u := range updates {
+		if u.Trade != nil && u.Order.ID == u.Trade.TakerOrderID {
+			s.positions.Apply(*u.Trade)
+		}
+	}
+}
+
+// Positions returns the open positions of an account, ordered by symbol.
+// For a paper account these are its paper positions.
+func (s *Service) Positions(ctx context.Context, accountID string) ([]position.Position, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if s.paper.isPaper(accountID) {
+		return s.paper.positions.Positions(accountID), nil
+	}
+	return s.positions.Positions(accountID), nil
+}
+
+// recordCandles folds each trade into its symbol's candles, once per trade
+func (s *Service) recordCandles(updates []orderbook.Update) {
+	for _, u := range updates {
+		if u.Trade != nil && u.Order.ID == u.Trade.TakerOrderID {
+			s.candles.Apply(*u.Trade)
+		}
+	}
+}
+
+// Candles returns a symbol's OHLCV candles of the given width over the last
+// 24 hours, oldest first, ending with the one in progress
+func (s *Service) Candles(ctx context.Context, symbol string, interval time.Duration) ([]candle.Candle, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, symbol, exists := s.lookupBook(symbol)
 	if !exists {
-		book = orderbook.NewOrderBook(o.Symbol)
-		s.books[o.Symbol] = book
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+	return s.candles.Candles(symbol, interval, s.now())
+}
+
+// capReduceOnly shrinks a reduce-only order to the position it can reduce
+// in positions. The cap is taken when the order arrives; fills from other
+// orders of the account don't shrink it afterwards.
+func capReduceOnly(positions *position.Tracker, o *order.Order) error {
+	if !o.ReduceOnly {
+		return nil
+	}
+	if o.QuoteQuantity > 0 {
+		return fmt.Errorf("%w: reduce-only orders need a base quantity", ErrReduceOnly)
+	}
+
+	reducible := positions.Reducible(o.AccountID, o.Symbol, o.Side)
+	if reducible <= 0 {
+		return fmt.Errorf("%w: no %s position in %s to reduce", ErrReduceOnly, reducedPosition(o.Side), o.Symbol)
+	}
+	o.Quantity = min(o.Quantity, reducible)
+	return nil
+}
+
+// reducedPosition names the position an order on side reduces
+func reducedPosition(side order.Side) string {
+	if side == order.SideBuy {
+		return "short"
+	}
+	return "long"
+}
+
+// settleUpdates passes trades to the risk checker and releases whatever is
+// still held once an order is done
+func (s *Service) settleUpdates(updates []orderbook.Update, departs []bool) {
+	checker := s.riskChecker()
+	if checker == nil {
+		return
+	}
+
+	for i, u := range updates {
+		if u.Trade != nil {
+			checker.Fill(u.Order, *u.Trade)
+		}
+		if departs[i] {
+			checker.Release(u.Order.ID)
+		}
+	}
+}
+
+// reservePrice is the unit price an order's hold is valued at. Market
+// orders have no price, so they're valued at what they'd fill at now.
+func reservePrice(book *orderbook.OrderBook, o *order.Order) float64 {
+	if o.Type != order.TypeMarket {
+		return o.Price
+	}
+	return book.SimulateFill(o.Side, o.RemainingQuantity()).AvgPrice
+}
+
+// SetEventPublisher replaces the publisher order lifecycle events go to
+func (s *Service) SetEventPublisher(publisher event.EventPublisher) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if publisher == nil {
+		publisher = event.NopPublisher{}
+	}
+	s.publisher = publisher
+}
+
+func (s *Service) eventPublisher() event.EventPublisher {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.publisher
+}
+
+func (s *Service) publishUpdates(updates []orderbook.Update) {
+	publisher := s.eventPublisher()
+	for _, u := range updates {
+		publisher.Publish(event.FromUpdate(u))
+		if fill, ok := event.FromFill(u); ok {
+			publisher.Publish(fill)
+		}
+
+		// Each trade updates the maker then the taker; publish the trade
+		// itself once, after both sides
+		if u.Trade != nil && u.Order.ID == u.Trade.TakerOrderID {
+			publisher.Publish(event.FromTrade(*u.Trade))
+		}
+	}
+}
+
+// SetHaltListener registers a callback invoked whenever a circuit breaker
+// halts a symbol
+func (s *Service) SetHaltListener(listener func(orderbook.HaltEvent)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.haltListener = listener
+}
+
+func (s *Service) notifyHalt(halt orderbook.HaltEvent) {
+	s.mutex.RLock()
+	listener, publisher, logger := s.haltListener, s.publisher, s.logger
+	s.mutex.RUnlock()
+
+	logger.Warn("symbol halted",
+		"symbol", halt.Symbol,
+		"reason", halt.Reason,
+		"resume_at", halt.ResumeAt,
+	)
+
+	publisher.Publish(event.FromHalt(halt))
+	if listener != nil {
+		listener(halt)
+	}
+}
+
+// RegisterSymbol creates the book for a symbol with the given config, or
+// updates the config of an existing book. The symbol is normalized first,
+// so btc/usd registers BTC-USD.
+func (s *Service) RegisterSymbol(config orderbook.SymbolConfig) error {
+	return s.ConfigureSymbol(config, nil)
+}
+
+// ConfigureSymbol is RegisterSymbol that also halts or resumes the symbol
+// when halted isn't nil. The config and halt state change together: orders
+// are checked against the old settings or the new ones, never a mix.
+func (s *Service) ConfigureSymbol(config orderbook.SymbolConfig, halted *bool) error {
+	if config.Symbol == "" {
+		return fmt.Errorf("symbol is required")
+	}
+
+	s.mutex.Lock()
+	config.Symbol = s.canonicalSymbol(config.Symbol)
+	if config.Precision != nil {
+		order.SetPrecision(config.Symbol, *config.Precision)
+	}
+
+	book, exists := s.books[config.Symbol]
+	if !exists {
+		// Nothing holds a new book's config yet, so it can be settled
+		// before anyone sees the book
+		book = s.addBook(config)
+		book.Reconfigure(config, halted)
+		s.mutex.Unlock()
+		return nil
 	}
 	s.mutex.Unlock()
 
-	return book.AddOrder(o)
+	// Orders being checked hold the book's config until they are placed, and
+	// may need s.mutex meanwhile
+	book.Reconfigure(config, halted)
+	return nil
+}
+
+// HaltSymbol pauses trading on a symbol. New orders are rejected and
+// nothing matches until ResumeSymbol, but cancellations still go through.
+func (s *Service) HaltSymbol(symbol string) error {
+	book, symbol, exists := s.lookupBook(symbol)
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+
+	book.Halt()
+	s.getLogger().Info("symbol halted", "symbol", symbol)
+	return nil
+}
+
+// ResumeSymbol resumes trading on a halted symbol
+func (s *Service) ResumeSymbol(symbol string) error {
+	book, symbol, exists := s.lookupBook(symbol)
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+
+	book.Resume()
+	s.getLogger().Info("symbol resumed", "symbol", symbol)
+	return nil
+}
+
+// SubmitOrder adds an order unless its account already used its
+// ClientOrderID, in which case the order originally created for that ID is
+// returned and nothing is matched. Callers can tell the cases apart by
+// comparing the returned order with the one they passed in. A duplicate
+// that arrives while the first order is still being placed waits for it.
+func (s *Service) SubmitOrder(ctx context.Context, o *order.Order) (*order.Order, error) {
+	if o.ClientOrderID == "" {
+		return o, s.AddOrder(ctx, o)
+	}
+
+	key := clientKey(o.AccountID, o.ClientOrderID)
+	for {
+		original, pending := s.reserveClientID(key)
+		if original != nil {
+			return original, nil
+		}
+		if pending == nil {
+			break
+		}
+
+		// Another order holds the ID: wait for it to be recorded, or for
+		// its reservation to be dropped and the ID to be free again
+		select {
+		case <-pending:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	// The ID is reserved: match without holding the idempotency lock, so
+	// lookups and other client IDs don't wait behind the book
+	err := s.AddOrder(ctx, o)
+
+	s.idempotencyMutex.Lock()
+	if err == nil {
+		s.idempotency.put(key, o, s.now())
+	}
+	done := s.reserved[key]
+	delete(s.reserved, key)
+	s.idempotencyMutex.Unlock()
+	close(done)
+
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// reserveClientID returns the order already recorded for key, or the
+// channel of a reservation in progress, or reserves key for the caller
+// when it's free and returns neither
+func (s *Service) reserveClientID(key string) (*order.Order, <-chan struct{}) {
+	s.idempotencyMutex.Lock()
+	defer s.idempotencyMutex.Unlock()
+
+	s.idempotency.prune(s.now())
+	if original, exists := s.idempotency.get(key); exists {
+		return original, nil
+	}
+	if pending, exists := s.reserved[key]; exists {
+		return nil, pending
+	}
+
+	s.reserved[key] = make(chan struct{})
+	return nil, nil
+}
+
+// GetOrderByClientID finds an order by the ID the client gave it. Client
+// order IDs are only unique per account, and are remembered as long as
+// they're kept for deduplication.
+func (s *Service) GetOrderByClientID(ctx context.Context, accountID, clientOrderID string) (*order.Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.idempotencyMutex.Lock()
+	defer s.idempotencyMutex.Unlock()
+
+	s.idempotency.prune(s.now())
+	if o, exists := s.idempotency.get(clientKey(accountID, clientOrderID)); exists {
+		return o, nil
+	}
+
+	return nil, fmt.Errorf("%w: client order ID %s", orderbook.ErrOrderNotFound, clientOrderID)
+}
+
+// clientKey scopes a client order ID to its account
+func clientKey(accountID, clientOrderID string) string {
+	return accountID + "/" + clientOrderID
+}
+
+// admit counts an order in flight, or reports false and counts it as shed
+// when the limit is reached. Admitted orders must be released with
+// s.inFlight.Add(-1).
+func (s *Service) admit() bool {
+	if n := s.inFlight.Add(1); s.maxInFlight > 0 && n > s.maxInFlight {
+		s.inFlight.Add(-1)
+		s.ordersShed.Add(1)
+		return false
+	}
+	return true
+}
+
+// prepareOrder rounds an order to its symbol's precision, so the hold
+// covers what the book will see, and caps reduce-only orders. config comes
+// from the book's HoldConfig, held until the order is placed.
+func (s *Service) prepareOrder(config orderbook.SymbolConfig, o *order.Order) error {
+	if err := config.Normalize(o); err != nil {
+		return err
+	}
+	return capReduceOnly(s.positions, o)
+}
+
+// ValidateOrder runs the checks AddOrder would, including the balance
+// check, without placing the order or holding funds. Symbols are created on
+// their first order, so an unknown symbol is checked against an empty book
+// with the default config.
+func (s *Service) ValidateOrder(ctx context.Context, o *order.Order) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	book, symbol, exists := s.lookupBook(o.Symbol)
+	o.Symbol = symbol
+	if !exists {
+		book = orderbook.NewOrderBook(symbol)
+	}
+
+	config, release := book.HoldConfig()
+	defer release()
+
+	if err := s.prepareOrder(config, o); err != nil {
+		return err
+	}
+	if checker := s.riskChecker(); checker != nil {
+		if err := checker.Check(o, reservePrice(book, o)); err != nil {
+			return err
+		}
+	}
+	return book.ValidateOrder(o)
+}
+
+// AddOrder matches an order against its book and rests whatever is left.
+// A cancelled context stops the order before it reaches the book; once
+// matching has started it runs to completion. With an ingestion queue the
+// order waits in it for a worker first.
+func (s *Service) AddOrder(ctx context.Context, o *order.Order) error {
+	if s.queue != nil {
+		return s.enqueueOrder(ctx, o)
+	}
+	return s.addOrder(ctx, o, true)
+}
+
+// addOrder is AddOrder, counting the order against its account's message
+// quota when throttle is set. Orders the service sends on an account's
+// behalf, such as to flatten it, aren't throttled.
+func (s *Service) addOrder(ctx context.Context, o *order.Order, throttle bool) (err error) {
+	ctx, span := startSpan(ctx, "matching.AddOrder",
+		attribute.String("symbol", o.Symbol),
+		attribute.String("order_id", o.ID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if throttle {
+		_, symbol, _ := s.lookupBook(o.Symbol)
+		if err := s.checkQuota(o.AccountID, symbol); err != nil {
+			return err
+		}
+	}
+	if s.paper.isPaper(o.AccountID) {
+		return s.addPaperOrder(ctx, o)
+	}
+
+	if !s.admit() {
+		return &retryError{
+			err:   fmt.Errorf("%w: %d orders in flight", ErrOverloaded, s.maxInFlight),
+			after: s.retryBackoff,
+		}
+	}
+	defer s.inFlight.Add(-1)
+
+	var book *orderbook.OrderBook
+	book, o.Symbol = s.bookOrCreate(o.Symbol)
+
+	// A reload waits until the order is placed against the config it was
+	// checked with
+	config, release := book.HoldConfig()
+	defer release()
+
+	if err := s.prepareOrder(config, o); err != nil {
+		return err
+	}
+
+	checker := s.riskChecker()
+	if checker != nil {
+		if err := checker.Reserve(o, reservePrice(book, o)); err != nil {
+			return err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		if checker != nil {
+			checker.Release(o.ID)
+		}
+		return err
+	}
+
+	// Index before the book sees the order: its final update may arrive
+	// before AddOrder returns, and that is what takes it out again
+	s.index.add(o.ID, book)
+
+	logger := s.getLogger()
+	if err := book.AddOrderContext(ctx, o); err != nil {
+		s.reportFailure(ctx, book, err)
+		s.index.remove(o.ID)
+		if checker != nil {
+			checker.Release(o.ID)
+		}
+		if logger.Enabled(ctx, slog.LevelDebug) {
+			logger.DebugContext(ctx, "order rejected",
+				"order_id", o.ID,
+				"symbol", o.Symbol,
+				"error", err,
+				"request_id", requestid.FromContext(ctx),
+			)
+		}
+		return err
+	}
+
+	s.ordersAdded.Add(1)
+	s.matchLatency.observe(o.MatchLatencyMicros)
+	if logger.Enabled(ctx, slog.LevelDebug) {
+		logger.DebugContext(ctx, "order added",
+			"order_id", o.ID,
+			"symbol", o.Symbol,
+			"side", o.Side,
+			"type", o.Type,
+			"price", o.Price,
+			"quantity", o.Quantity,
+			"filled", o.Filled,
+			"status", o.Status,
+			"match_latency_us", o.MatchLatencyMicros,
+			"request_id", requestid.FromContext(ctx),
+		)
+	}
+	return nil
+}
+
+// BulkLoad seeds a symbol's book with resting limit orders in one pass,
+// such as at startup or after maintenance, instead of matching them one by
+// one. Unless match is set, the orders must not cross each other or the
+// book; with it, the book matches whatever crosses once they are loaded.
+// The load is all or nothing. Bulk-loaded orders skip the message quota,
+// the balance check, price bands and the other per-order entry checks, so
+// only load orders from a trusted source.
+func (s *Service) BulkLoad(ctx context.Context, symbol string, orders []*order.Order, match bool) (err error) {
+	ctx, span := startSpan(ctx, "matching.BulkLoad",
+		attribute.String("symbol", symbol),
+		attribute.Int("orders", len(orders)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	book, symbol := s.bookOrCreate(symbol)
+	for _, o := range orders {
+		if _, orderSymbol, _ := s.lookupBook(o.Symbol); orderSymbol != symbol {
+			return fmt.Errorf("%w: %s", orderbook.ErrInvalidSymbol, o.Symbol)
+		}
+		o.Symbol = symbol
+	}
+
+	// As in AddOrder, index first: matching may finish orders before
+	// BulkLoad returns. Only IDs new to the index are taken out on failure.
+	var indexed []string
+	for _, o := range orders {
+		if _, exists := s.index.get(o.ID); !exists {
+			s.index.add(o.ID, book)
+			indexed = append(indexed, o.ID)
+		}
+	}
+	if err := book.BulkLoad(orders, match); err != nil {
+		s.reportFailure(ctx, book, err)
+		for _, orderID := range indexed {
+			s.index.remove(orderID)
+		}
+		return err
+	}
+
+	s.ordersAdded.Add(uint64(len(orders)))
+	if logger := s.getLogger(); logger.Enabled(ctx, slog.LevelInfo) {
+		logger.InfoContext(ctx, "orders bulk loaded",
+			"symbol", symbol,
+			"orders", len(orders),
+			"match", match,
+			"request_id", requestid.FromContext(ctx),
+		)
+	}
+	return nil
+}
+
+// GetOrder looks up a resting order across all books and returns a copy of
+// it, taken under its book's lock
+func (s *Service) GetOrder(ctx context.Context, orderID string) (*order.Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	book, exists := s.index.get(orderID)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", orderbook.ErrOrderNotFound, orderID)
+	}
+
+	return book.GetOrder(orderID)
+}
+
+// Snapshot returns a copy of an order handed to AddOrder or SubmitOrder,
+// taken under its book's lock. Once on the book the order keeps changing as
+// it fills, so callers read it through here rather than directly.
+func (s *Service) Snapshot(o *order.Order) order.Order {
+	book, _, exists := s.lookupBook(o.Symbol)
+	if !exists {
+		return *o
+	}
+	return book.Snapshot(o)
+}
+
+// LookupOrder is like GetOrder but also finds orders that have been filled
+// or cancelled recently, and the orders of paper accounts
+func (s *Service) LookupOrder(ctx context.Context, orderID string) (*order.Order, error) {
+	o, err := s.GetOrder(ctx, orderID)
+	if err == nil {
+		return o, nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+
+	if o, exists := s.finishedOrder(orderID); exists {
+		return o, nil
+	}
+	if o, exists := s.paper.order(orderID); exists {
+		return o, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", orderbook.ErrOrderNotFound, orderID)
+}
+
+func (s *Service) CancelOrder(ctx context.Context, symbol, orderID string) (err error) {
+	ctx, span := startSpan(ctx, "matching.CancelOrder",
+		attribute.String("symbol", symbol),
+		attribute.String("order_id", orderID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	book, symbol, exists := s.lookupBook(symbol)
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+
+	return s.cancelOn(ctx, book, symbol, orderID, "")
+}
+
+// CancelOrderByID cancels an order found through the order index, for
+// callers that don't know its symbol, and returns its final state
+func (s *Service) CancelOrderByID(ctx context.Context, orderID string) (*order.Order, error) {
+	return s.cancelByID(ctx, orderID, "")
+}
+
+// CancelOrderIfMatch is CancelOrderByID for an order that must still have
+// the given ETag. An order that changed since, including one that filled,
+// is left alone and reported with ErrOrderChanged.
+func (s *Service) CancelOrderIfMatch(ctx context.Context, orderID, etag string) (*order.Order, error) {
+	return s.cancelByID(ctx, orderID, etag)
 }
 
-func (s *Service) CancelOrder(symbol, orderID string) error {
+// cancelByID cancels an order found through the order index, only if it
+// has etag when one is given
+func (s *Service) cancelByID(ctx context.Context, orderID, etag string) (_ *order.Order, err error) {
+	ctx, span := startSpan(ctx, "matching.CancelOrder",
+		attribute.String("order_id", orderID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	book, exists := s.index.get(orderID)
+	if !exists {
+		if final, exists := s.finishedOrder(orderID); exists {
+			return nil, notCancellable(final, etag)
+		}
+		return nil, fmt.Errorf("%w: %s", orderbook.ErrOrderNotFound, orderID)
+	}
+
+	symbol := book.Config().Symbol
+	span.SetAttributes(attribute.String("symbol", symbol))
+	if err := s.cancelOn(ctx, book, symbol, orderID, etag); err != nil {
+		return nil, err
+	}
+
+	// The book's update has already moved the order to the finished cache
+	final, exists := s.finishedOrder(orderID)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", orderbook.ErrOrderNotFound, orderID)
+	}
+	return final, nil
+}
+
+// cancelOn cancels an order on the given book, only if it has etag when one
+// is given. An order that has just left the book is reported as not
+// cancellable rather than unknown.
+func (s *Service) cancelOn(ctx context.Context, book *orderbook.OrderBook, symbol, orderID, etag string) error {
+	if err := s.checkCancelQuota(book, symbol, orderID); err != nil {
+		return err
+	}
+
+	cancel := book.CancelOrderContext
+	if etag != "" {
+		cancel = func(ctx context.Context, orderID string) error {
+			return book.CancelOrderIfMatch(ctx, orderID, etag)
+		}
+	}
+	if err := cancel(ctx, orderID); err != nil {
+		if final, exists := s.finishedOrder(orderID); exists {
+			return notCancellable(final, etag)
+		}
+		return err
+	}
+
+	s.ordersCancelled.Add(1)
+	if logger := s.getLogger(); logger.Enabled(ctx, slog.LevelDebug) {
+		logger.DebugContext(ctx, "order cancelled",
+			"order_id", orderID,
+			"symbol", symbol,
+			"request_id", requestid.FromContext(ctx),
+		)
+	}
+	return nil
+}
+
+// notCancellable reports a finished order a cancel found: as changed when
+// the caller expected it in another state, as not cancellable otherwise
+func notCancellable(final *order.Order, etag string) error {
+	if etag != "" && final.ETag() != etag {
+		return fmt.Errorf("%w: order is %s", orderbook.ErrOrderChanged, final.Status)
+	}
+	return fmt.Errorf("%w: order is %s", orderbook.ErrOrderNotCancellable, final.Status)
+}
+
+// ReduceOrder shrinks a resting order's quantity by reduceBy, keeping its
+// price and queue position, and returns its new state. The quantity never
+// drops below what has filled; a reduction that leaves nothing to fill
+// cancels the order.
+func (s *Service) ReduceOrder(ctx context.Context, symbol, orderID string, reduceBy float64) (*order.Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	book, symbol, exists := s.lookupBook(symbol)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+	return s.reduceOn(ctx, book, orderID, reduceBy)
+}
+
+// ReduceOrderByID is ReduceOrder for an order found through the order
+// index, for callers that don't know its symbol
+func (s *Service) ReduceOrderByID(ctx context.Context, orderID string, reduceBy float64) (*order.Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	book, exists := s.index.get(orderID)
+	if !exists {
+		if final, exists := s.finishedOrder(orderID); exists {
+			return nil, notCancellable(final, "")
+		}
+		return nil, fmt.Errorf("%w: %s", orderbook.ErrOrderNotFound, orderID)
+	}
+	return s.reduceOn(ctx, book, orderID, reduceBy)
+}
+
+// reduceOn reduces an order on the given book and shrinks its hold to
+// match. An order that has just left the book is reported as not
+// cancellable rather than unknown.
+func (s *Service) reduceOn(ctx context.Context, book *orderbook.OrderBook, orderID string, reduceBy float64) (*order.Order, error) {
+	reduced, err := book.ReduceOrder(orderID, reduceBy)
+	if err != nil {
+		if final, exists := s.finishedOrder(orderID); exists {
+			return nil, notCancellable(final, "")
+		}
+		return nil, err
+	}
+
+	if reduced.Status == order.StatusCancelled {
+		s.ordersCancelled.Add(1)
+	} else if checker := s.riskChecker(); checker != nil {
+		// A smaller hold always fits, so this can't fail for lack of funds
+		_ = checker.Reserve(&reduced, reduced.Price)
+	}
+
+	if logger := s.getLogger(); logger.Enabled(ctx, slog.LevelDebug) {
+		logger.DebugContext(ctx, "order reduced",
+			"order_id", orderID,
+			"symbol", reduced.Symbol,
+			"quantity", reduced.Quantity,
+			"status", reduced.Status,
+			"request_id", requestid.FromContext(ctx),
+		)
+	}
+	return &reduced, nil
+}
+
+// GetQueuePosition returns where a resting order stands in its price
+// level: its rank and the quantity ahead of it. An order that has filled or
+// been cancelled is reported with ErrOrderNotResting.
+func (s *Service) GetQueuePosition(ctx context.Context, symbol, orderID string) (*orderbook.QueuePosition, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	book, symbol, exists := s.lookupBook(symbol)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+	return s.queuePositionOn(book, orderID)
+}
+
+// GetQueuePositionByID is GetQueuePosition for an order found through the
+// order index, for callers that don't know its symbol
+func (s *Service) GetQueuePositionByID(ctx context.Context, orderID string) (*orderbook.QueuePosition, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	book, exists := s.index.get(orderID)
+	if !exists {
+		if final, exists := s.finishedOrder(orderID); exists {
+			return nil, fmt.Errorf("%w: order is %s", ErrOrderNotResting, final.Status)
+		}
+		return nil, fmt.Errorf("%w: %s", orderbook.ErrOrderNotFound, orderID)
+	}
+	return s.queuePositionOn(book, orderID)
+}
+
+// queuePositionOn reads an order's queue position on the given book. An
+// order that has just left the book is reported as not resting rather than
+// unknown.
+func (s *Service) queuePositionOn(book *orderbook.OrderBook, orderID string) (*orderbook.QueuePosition, error) {
+	position, err := book.QueuePosition(orderID)
+	if err != nil {
+		if final, exists := s.finishedOrder(orderID); exists {
+			return nil, fmt.Errorf("%w: order is %s", ErrOrderNotResting, final.Status)
+		}
+		return nil, err
+	}
+	return &position, nil
+}
+
+// AmendOrder changes the price and/or quantity of a resting order. Only a
+// same-price reduction keeps the order's queue position.
+func (s *Service) AmendOrder(symbol, orderID string, price, quantity float64) error {
+	book, symbol, exists := s.lookupBook(symbol)
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+
+	checker := s.riskChecker()
+	if checker == nil {
+		err := book.AmendOrder(orderID, price, quantity)
+		s.reportFailure(context.Background(), book, err)
+		return err
+	}
+
+	resting, err := book.GetOrder(orderID)
+	if err != nil {
+		return err
+	}
+
+	// Resize the hold to the amended order first, and put it back if the
+	// book refuses the amendment
+	original := *resting
+	amended := original
+	amended.Price, amended.Quantity = price, quantity
+	if err := book.Config().Normalize(&amended); err != nil {
+		return err
+	}
+	if err := checker.Reserve(&amended, amended.Price); err != nil {
+		return err
+	}
+
+	if err := book.AmendOrder(orderID, price, quantity); err != nil {
+		s.reportFailure(context.Background(), book, err)
+		_ = checker.Reserve(&original, original.Price)
+		return err
+	}
+	return nil
+}
+
+func (s *Service) GetOrderBook(ctx context.Context, symbol string) (*orderbook.OrderBookSnapshot, error) {
+	book, err := s.bookFor(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return book.GetOrderBook(), nil
+}
+
+// DebugOrderBook returns the internal dump of a symbol's book, with its
+// level list checked for corruption. It's meant for support, not clients.
+func (s *Service) DebugOrderBook(ctx context.Context, symbol string) (*orderbook.DebugDump, error) {
+	book, err := s.bookFor(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	dump := book.DebugDump()
+	return &dump, nil
+}
+
+// ExpireOrders cancels, on every book, the orders that have rested longer
+// than their symbol's OrderTTL or past their own ExpiresAt, and returns
+// their final states. It is the sweep to run periodically.
+func (s *Service) ExpireOrders(ctx context.Context) ([]order.Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	s.mutex.RLock()
-	book, exists := s.books[symbol]
+	books := s.sortedBooks()
 	s.mutex.RUnlock()
 
+	now := s.now()
+	var expired []order.Order
+	for _, book := range books {
+		expired = append(expired, book.ExpireOrders(now)...)
+	}
+	s.ordersCancelled.Add(uint64(len(expired)))
+
+	if len(expired) > 0 {
+		if logger := s.getLogger(); logger.Enabled(ctx, slog.LevelDebug) {
+			logger.DebugContext(ctx, "orders expired", "expired", len(expired))
+		}
+	}
+	return expired, nil
+}
+
+// ValidateBooks checks the structure of every book, returning the
+// corruption found in each, joined, or nil when all are sound
+func (s *Service) ValidateBooks(ctx context.Context) error {
+	s.mutex.RLock()
+	books := s.sortedBooks()
+	s.mutex.RUnlock()
+
+	var errs []error
+	for _, book := range books {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := book.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetBestBid returns the best bid price of a symbol and the quantity
+// resting at it
+func (s *Service) GetBestBid(ctx context.Context, symbol string) (price, quantity float64, err error) {
+	book, err := s.bookFor(ctx, symbol)
+	if err != nil {
+		return 0, 0, err
+	}
+	return book.GetBestBid()
+}
+
+// GetBestAsk returns the best ask price of a symbol and the quantity
+// resting at it
+func (s *Service) GetBestAsk(ctx context.Context, symbol string) (price, quantity float64, err error) {
+	book, err := s.bookFor(ctx, symbol)
+	if err != nil {
+		return 0, 0, err
+	}
+	return book.GetBestAsk()
+}
+
+// bookFor returns the book of an existing symbol
+func (s *Service) bookFor(ctx context.Context, symbol string) (*orderbook.OrderBook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	book, symbol, exists := s.lookupBook(symbol)
 	if !exists {
-		return fmt.Errorf("symbol not found: %s", symbol)
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
 	}
+	return book, nil
+}
 
-	return book.CancelOrder(orderID)
+// GetTicker returns top-of-book data, imbalance and microprice for a symbol
+func (s *Service) GetTicker(symbol string) (*orderbook.Ticker, error) {
+	book, symbol, exists := s.lookupBook(symbol)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+
+	return book.GetTicker(), nil
 }
 
-func (s *Service) GetOrderBook(symbol string) (*orderbook.OrderBookSnapshot, error) {
+// Tickers returns the ticker of every book in symbol order. Each book is
+// read on its own, so the tickers are not one atomic snapshot.
+func (s *Service) Tickers(ctx context.Context) ([]*orderbook.Ticker, error) {
 	s.mutex.RLock()
-	book, exists := s.books[symbol]
+	books := s.sortedBooks()
 	s.mutex.RUnlock()
 
+	tickers := make([]*orderbook.Ticker, 0, len(books))
+	for _, book := range books {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		tickers = append(tickers, book.GetTicker())
+	}
+	return tickers, nil
+}
+
+// SimulateFill reports the average price, filled and unfilled quantity a
+// market order of the given size would get, without touching the book.
+func (s *Service) SimulateFill(symbol string, side order.Side, quantity float64) (*orderbook.FillSimulation, error) {
+	if side != order.SideBuy && side != order.SideSell {
+		return nil, fmt.Errorf("invalid side: %s", side)
+	}
+	// NaN compares false against everything and would pass the sign check
+	if math.IsNaN(quantity) || math.IsInf(quantity, 0) || quantity <= 0 {
+		return nil, fmt.Errorf("quantity must be positive")
+	}
+
+	book, symbol, exists := s.lookupBook(symbol)
 	if !exists {
-		return nil, fmt.Errorf("symbol not found: %s", symbol)
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
 	}
 
-	return book.GetOrderBook(), nil
+	return book.SimulateFill(side, quantity), nil
 }
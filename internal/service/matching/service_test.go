@@ -1,9 +1,21 @@
 package matching
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 
-	"company.com/matchengine/internal/domain/order"
+	"company.com/matchengine/internal/event"
+	"company.com/matchengine/internal/risk"
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -61,14 +73,14 @@ func TestMatchingService(t *testing.T) {
 			require.NoError(t, err)
 
 			// Execute
-			err = service.AddOrder(buyOrder)
+			err = service.AddOrder(context.Background(), buyOrder)
 			require.NoError(t, err, "failed to add buy order")
 
-			err = service.AddOrder(sellOrder)
+			err = service.AddOrder(context.Background(), sellOrder)
 			require.NoError(t, err, "failed to add sell order")
 
 			// Verify orderbook state
-			book, err := service.GetOrderBook("BTC-USD")
+			book, err := service.GetOrderBook(context.Background(), "BTC-USD")
 			require.NoError(t, err)
 
 			// Verify order states
@@ -98,18 +110,18 @@ func TestOrderCancellation(t *testing.T) {
 	createdOrder, err := createTestOrder(orderData)
 	require.NoError(t, err)
 
-	err = service.AddOrder(createdOrder)
+	err = service.AddOrder(context.Background(), createdOrder)
 	require.NoError(t, err)
 
 	// Cancel order
-	err = service.CancelOrder(createdOrder.Symbol, createdOrder.ID)
+	err = service.CancelOrder(context.Background(), createdOrder.Symbol, createdOrder.ID)
 	require.NoError(t, err)
 
 	// Verify cancellation
 	assert.Equal(t, createdOrder.Status, order.StatusCancelled)
 
 	// Verify empty orderbook
-	book, err := service.GetOrderBook("BTC-USD")
+	book, err := service.GetOrderBook(context.Background(), "BTC-USD")
 	require.NoError(t, err)
 	assert.Empty(t, book.Bids)
 }
@@ -123,7 +135,7 @@ func TestErrorCases(t *testing.T) {
 		{
 			name: "invalid symbol orderbook",
 			testFunc: func(s *Service) error {
-				_, err := s.GetOrderBook("INVALID-PAIR")
+				_, err := s.GetOrderBook(context.Background(), "INVALID-PAIR")
 				return err
 			},
 			expectedErr: true,
@@ -131,14 +143,14 @@ func TestErrorCases(t *testing.T) {
 		{
 			name: "cancel order with invalid symbol",
 			testFunc: func(s *Service) error {
-				return s.CancelOrder("INVALID-PAIR", "some-id")
+				return s.CancelOrder(context.Background(), "INVALID-PAIR", "some-id")
 			},
 			expectedErr: true,
 		},
 		{
 			name: "cancel non-existent order",
 			testFunc: func(s *Service) error {
-				return s.CancelOrder("BTC-USD", "non-existent-id")
+				return s.CancelOrder(context.Background(), "BTC-USD", "non-existent-id")
 			},
 			expectedErr: true,
 		},
@@ -158,13 +170,13 @@ func TestMatchingService_ErrorCases(t *testing.T) {
 		service := NewService()
 
 		// Try to get non-existent order book
-		_, err := service.GetOrderBook("INVALID-PAIR")
+		_, err := service.GetOrderBook(context.Background(), "INVALID-PAIR")
 		if err == nil {
 			t.Error("expected error for invalid symbol, got nil")
 		}
 
 		// Try to cancel order for non-existent symbol
-		err = service.CancelOrder("INVALID-PAIR", "some-id")
+		err = service.CancelOrder(context.Background(), "INVALID-PAIR", "some-id")
 		if err == nil {
 			t.Error("expected error for invalid symbol, got nil")
 		}
@@ -174,7 +186,7 @@ func TestMatchingService_ErrorCases(t *testing.T) {
 		service := NewService()
 
 		// Try to cancel non-existent order
-		err := service.CancelOrder("BTC-USD", "non-existent-id")
+		err := service.CancelOrder(context.Background(), "BTC-USD", "non-existent-id")
 		if err == nil {
 			t.Error("expected error when cancelling non-existent order, got nil")
 		}
@@ -185,13 +197,13 @@ func TestMatchingServiceErrors(t *testing.T) {
 	service := NewService()
 
 	// Test canceling non-existent order
-	err := service.CancelOrder("BTC-USD", "invalid-id")
+	err := service.CancelOrder(context.Background(), "BTC-USD", "invalid-id")
 	if err == nil {
 		t.Error("Expected error when canceling non-existent order")
 	}
 
 	// Test getting non-existent order book
-	_, err = service.GetOrderBook("invalid-symbol")
+	_, err = service.GetOrderBook(context.Background(), "invalid-symbol")
 	if err == nil {
 		t.Error("Expected error when getting non-existent order book")
 	}
@@ -212,7 +224,7 @@ func TestOrderMatching(t *testing.T) {
 	}
 
 	// Adicionar ordem de compra
-	err = service.AddOrder(buyOrder)
+	err = service.AddOrder(context.Background(), buyOrder)
 	if err != nil {
 		t.Fatalf("Failed to add buy order: %v", err)
 	}
@@ -229,14 +241,14 @@ func TestOrderMatching(t *testing.T) {
 	}
 
 	// Adicionar ordem de venda
-	err = service.AddOrder(sellOrder)
+	err = service.AddOrder(context.Background(), sellOrder)
 	if err != nil {
 		t.Fatalf("Failed to add sell order: %v", err)
 	}
 
 	// Verificar status das ordens após matching
-	if buyOrder.Status != order.StatusPartial {
-		t.Errorf("Expected buy order status to be %v, got %v", order.StatusPartial, buyOrder.Status)
+	if buyOrder.Status != order.StatusFilled {
+		t.Errorf("Expected buy order status to be %v, got %v", order.StatusFilled, buyOrder.Status)
 	}
 
 	if sellOrder.Status != order.StatusFilled {
@@ -252,3 +264,2289 @@ func TestOrderMatching(t *testing.T) {
 		t.Errorf("Expected sell order filled quantity to be 1.0, got %v", sellOrder.Filled)
 	}
 }
+
+func TestSimulateFill(t *testing.T) {
+	service := NewService()
+
+	asks := []TestOrder{
+		{side: order.SideSell, symbol: "BTC-USD", price: 50000.0, quantity: 0.5},
+		{side: order.SideSell, symbol: "BTC-USD", price: 50100.0, quantity: 1.0},
+		{side: order.SideSell, symbol: "BTC-USD", price: 50200.0, quantity: 2.0},
+	}
+	resting := make([]*order.Order, 0, len(asks))
+	for _, data := range asks {
+		o, err := createTestOrder(data)
+		require.NoError(t, err)
+		require.NoError(t, service.AddOrder(context.Background(), o))
+		resting = append(resting, o)
+	}
+
+	t.Run("insufficient liquidity", func(t *testing.T) {
+		sim, err := service.SimulateFill("BTC-USD", order.SideBuy, 5.0)
+		require.NoError(t, err)
+
+		assert.InDelta(t, 3.5, sim.Filled, 1e-9)
+		assert.InDelta(t, 1.5, sim.Unfilled, 1e-9)
+		assert.InDelta(t, (0.5*50000.0+1.0*50100.0+2.0*50200.0)/3.5, sim.AvgPrice, 1e-9)
+	})
+
+	t.Run("unknown symbol", func(t *testing.T) {
+		_, err := service.SimulateFill("ETH-USD", order.SideBuy, 1.0)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid quantity", func(t *testing.T) {
+		for _, quantity := range []float64{0, -1, math.NaN(), math.Inf(1), math.Inf(-1)} {
+			_, err := service.SimulateFill("BTC-USD", order.SideBuy, quantity)
+			assert.EqualError(t, err, "quantity must be positive", "quantity %v", quantity)
+		}
+	})
+
+	t.Run("matches an actual fill", func(t *testing.T) {
+		sim, err := service.SimulateFill("BTC-USD", order.SideBuy, 1.2)
+		require.NoError(t, err)
+
+		// The simulation must not have touched the book
+		for _, o := range resting {
+			assert.Equal(t, 0.0, o.Filled)
+			assert.Equal(t, order.StatusNew, o.Status)
+		}
+
+		buyOrder, err := createTestOrder(TestOrder{
+			side:     order.SideBuy,
+			symbol:   "BTC-USD",
+			price:    50200.0,
+			quantity: 1.2,
+		})
+		require.NoError(t, err)
+		require.NoError(t, service.AddOrder(context.Background(), buyOrder))
+
+		filled, notional := 0.0, 0.0
+		for _, o := range resting {
+			filled += o.Filled
+			notional += o.Filled * o.Price
+		}
+
+		assert.InDelta(t, buyOrder.Filled, sim.Filled, 1e-9)
+		assert.InDelta(t, buyOrder.RemainingQuantity(), sim.Unfilled, 1e-9)
+		assert.InDelta(t, notional/filled, sim.AvgPrice, 1e-9)
+	})
+}
+
+func TestCancelPartiallyFilledOrder(t *testing.T) {
+	service := NewService()
+
+	buyOrder, err := createTestOrder(TestOrder{
+		side:     order.SideBuy,
+		symbol:   "BTC-USD",
+		price:    50000.0,
+		quantity: 1.0,
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), buyOrder))
+
+	sellOrder, err := createTestOrder(TestOrder{
+		side:     order.SideSell,
+		symbol:   "BTC-USD",
+		price:    50000.0,
+		quantity: 0.4,
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), sellOrder))
+
+	require.NoError(t, service.CancelOrder(context.Background(), "BTC-USD", buyOrder.ID))
+
+	// The fill survives the cancel and the remainder leaves the book
+	assert.Equal(t, order.StatusCancelled, buyOrder.Status)
+	assert.InDelta(t, 0.4, buyOrder.Filled, 1e-9)
+	assert.InDelta(t, 0.6, buyOrder.RemainingQuantity(), 1e-9)
+
+	book, err := service.GetOrderBook(context.Background(), "BTC-USD")
+	require.NoError(t, err)
+	assert.Empty(t, book.Bids)
+
+	_, err = service.GetOrder(context.Background(), buyOrder.ID)
+	assert.Error(t, err)
+}
+
+func TestSubmitOrder_Idempotency(t *testing.T) {
+	service := NewService()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	sellOrder, err := createTestOrder(TestOrder{
+		side:     order.SideSell,
+		symbol:   "BTC-USD",
+		price:    50000.0,
+		quantity: 2.0,
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), sellOrder))
+
+	newBuy := func() *order.Order {
+		o, err := createTestOrder(TestOrder{
+			side:     order.SideBuy,
+			symbol:   "BTC-USD",
+			price:    50000.0,
+			quantity: 0.5,
+		})
+		require.NoError(t, err)
+		o.ClientOrderID = "client-1"
+		return o
+	}
+
+	// A fresh key creates and matches the order
+	first := newBuy()
+	placed, err := service.SubmitOrder(context.Background(), first)
+	require.NoError(t, err)
+	assert.Same(t, first, placed)
+	assert.Equal(t, 0.5, sellOrder.Filled)
+
+	// A retry with the same key returns the original without matching again
+	retry := newBuy()
+	placed, err = service.SubmitOrder(context.Background(), retry)
+	require.NoError(t, err)
+	assert.Same(t, first, placed)
+	assert.Equal(t, 0.0, retry.Filled)
+	assert.Equal(t, 0.5, sellOrder.Filled)
+
+	// Once the key expires it can be used again
+	now = now.Add(defaultIdempotencyTTL)
+	later := newBuy()
+	placed, err = service.SubmitOrder(context.Background(), later)
+	require.NoError(t, err)
+	assert.Same(t, later, placed)
+	assert.Equal(t, 1.0, sellOrder.Filled)
+	assert.Equal(t, 1, service.idempotency.len())
+}
+
+// slowChecker holds the order with ClientOrderID "slow" in Reserve until
+// release is closed
+type slowChecker struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (c *slowChecker) Reserve(o *order.Order, price float64) error {
+	if o.ClientOrderID == "slow" {
+		close(c.entered)
+		<-c.release
+	}
+	return nil
+}
+
+func (c *slowChecker) Check(o *order.Order, price float64) error { return nil }
+func (c *slowChecker) Fill(o order.Order, trade orderbook.Trade) {}
+func (c *slowChecker) Release(orderID string)                    {}
+
+func TestSubmitOrder_IdempotencyWhileMatching(t *testing.T) {
+	checker := &slowChecker{entered: make(chan struct{}), release: make(chan struct{})}
+	service := NewService(WithRiskChecker(checker))
+	ctx := context.Background()
+
+	newBuy := func(clientOrderID string) *order.Order {
+		o, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+		require.NoError(t, err)
+		o.ClientOrderID = clientOrderID
+		return o
+	}
+	submit := func(o *order.Order) <-chan *order.Order {
+		placed := make(chan *order.Order, 1)
+		go func() {
+			p, err := service.SubmitOrder(ctx, o)
+			assert.NoError(t, err)
+			placed <- p
+		}()
+		return placed
+	}
+
+	fast := newBuy("fast")
+	_, err := service.SubmitOrder(ctx, fast)
+	require.NoError(t, err)
+
+	slow := newBuy("slow")
+	slowPlaced := submit(slow)
+	<-checker.entered
+	duplicatePlaced := submit(newBuy("slow"))
+
+	// While the slow order is being placed, lookups and other client IDs
+	// don't wait for it
+	found, err := service.GetOrderByClientID(ctx, "", "fast")
+	require.NoError(t, err)
+	assert.Same(t, fast, found)
+	_, err = service.GetOrderByClientID(ctx, "", "slow")
+	assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+	other := newBuy("other")
+	placed, err := service.SubmitOrder(ctx, other)
+	require.NoError(t, err)
+	assert.Same(t, other, placed)
+
+	// The duplicate waits for the reservation and gets the original
+	close(checker.release)
+	assert.Same(t, slow, <-slowPlaced)
+	assert.Same(t, slow, <-duplicatePlaced)
+	assert.Equal(t, 3, service.idempotency.len())
+	assert.Empty(t, service.reserved)
+}
+
+func TestSubmitOrder_FailureFreesClientID(t *testing.T) {
+	service := NewService(WithSymbols(orderbook.SymbolConfig{Symbol: "BTC-USD"}))
+	ctx := context.Background()
+
+	newBuy := func() *order.Order {
+		o, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+		require.NoError(t, err)
+		o.ClientOrderID = "client-1"
+		return o
+	}
+
+	require.NoError(t, service.HaltSymbol("BTC-USD"))
+	_, err := service.SubmitOrder(ctx, newBuy())
+	assert.ErrorIs(t, err, orderbook.ErrTradingHalted)
+	assert.Empty(t, service.reserved)
+
+	// A rejected order doesn't use up its client ID
+	require.NoError(t, service.ResumeSymbol("BTC-USD"))
+	retry := newBuy()
+	placed, err := service.SubmitOrder(ctx, retry)
+	require.NoError(t, err)
+	assert.Same(t, retry, placed)
+}
+
+func TestStats(t *testing.T) {
+	service := NewService()
+
+	orders := []TestOrder{
+		{side: order.SideSell, symbol: "BTC-USD", price: 50100.0, quantity: 1.0},
+		{side: order.SideSell, symbol: "BTC-USD", price: 50200.0, quantity: 1.0},
+		{side: order.SideBuy, symbol: "BTC-USD", price: 49900.0, quantity: 1.0},
+		{side: order.SideBuy, symbol: "ETH-USD", price: 3000.0, quantity: 2.0},
+		// Sweeps both BTC asks: two trades
+		{side: order.SideBuy, symbol: "BTC-USD", price: 50200.0, quantity: 1.5},
+	}
+	var placed []*order.Order
+	for _, data := range orders {
+		o, err := createTestOrder(data)
+		require.NoError(t, err)
+		require.NoError(t, service.AddOrder(context.Background(), o))
+		placed = append(placed, o)
+	}
+
+	require.NoError(t, service.CancelOrder(context.Background(), "ETH-USD", placed[3].ID))
+
+	stats := service.Stats()
+	assert.Equal(t, 2, stats.Symbols)
+	assert.Equal(t, uint64(5), stats.OrdersAdded)
+	assert.Equal(t, uint64(1), stats.OrdersCancelled)
+	assert.Equal(t, uint64(2), stats.TradesExecuted)
+	// The partially filled 50200 ask and the 49900 bid remain
+	assert.Equal(t, 2, stats.ActiveOrders)
+
+	require.Len(t, stats.Depth, 2)
+	assert.Equal(t, "BTC-USD", stats.Depth[0].Symbol)
+	assert.Equal(t, 1, stats.Depth[0].BidLevels)
+	assert.InDelta(t, 0.5, stats.Depth[0].AskQuantity, 1e-9)
+	assert.Equal(t, "ETH-USD", stats.Depth[1].Symbol)
+	assert.Equal(t, 0, stats.Depth[1].ActiveOrders)
+}
+
+func TestRegisterSymbol(t *testing.T) {
+	service := NewService()
+
+	require.Error(t, service.RegisterSymbol(orderbook.SymbolConfig{}))
+	require.NoError(t, service.RegisterSymbol(orderbook.SymbolConfig{
+		Symbol:           "BTC-USD",
+		MaxOrdersPerSide: 1,
+		DepthPolicy:      orderbook.DepthPolicyReject,
+	}))
+
+	// The registered book exists before any order arrives
+	_, err := service.GetOrderBook(context.Background(), "BTC-USD")
+	require.NoError(t, err)
+
+	first, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100.0, quantity: 1.0})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), first))
+
+	second, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 99.0, quantity: 1.0})
+	require.NoError(t, err)
+	assert.Error(t, service.AddOrder(context.Background(), second))
+
+	// Re-registering updates the existing book's limits
+	require.NoError(t, service.RegisterSymbol(orderbook.SymbolConfig{Symbol: "BTC-USD"}))
+	assert.NoError(t, service.AddOrder(context.Background(), second))
+}
+
+func TestConfigureSymbol_OrdersSeeOneConfig(t *testing.T) {
+	service := NewService()
+	ctx := context.Background()
+
+	coarse := orderbook.SymbolConfig{Symbol: "BTC-USD", Precision: &order.Precision{Price: 1, Quantity: 4}}
+	fine := orderbook.SymbolConfig{Symbol: "BTC-USD", Precision: &order.Precision{Price: 2, Quantity: 4}}
+	require.NoError(t, service.RegisterSymbol(fine))
+
+	stop := make(chan struct{})
+	var reloads sync.WaitGroup
+	reloads.Add(1)
+	go func() {
+		defer reloads.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			config := fine
+			if i%2 == 0 {
+				config = coarse
+			}
+			assert.NoError(t, service.RegisterSymbol(config))
+		}
+	}()
+
+	// 100.149 rounds to 100.15 under the fine tick and 100.1 under the
+	// coarse one; rounding it under one and then the other gives 100.2
+	var orders sync.WaitGroup
+	prices := make(chan float64, 800)
+	for w := 0; w < 8; w++ {
+		orders.Add(1)
+		go func() {
+			defer orders.Done()
+			for i := 0; i < 100; i++ {
+				o, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100.149, quantity: 1.0})
+				if !assert.NoError(t, err) {
+					return
+				}
+				if assert.NoError(t, service.AddOrder(ctx, o)) {
+					prices <- o.Price
+				}
+			}
+		}()
+	}
+	orders.Wait()
+	close(stop)
+	reloads.Wait()
+	close(prices)
+
+	for price := range prices {
+		assert.Contains(t, []float64{100.1, 100.15}, price)
+	}
+}
+
+func TestSymbolNormalization(t *testing.T) {
+	service := NewService()
+	ctx := context.Background()
+
+	for i, symbol := range []string{"btc-usd", "BTC-USD", "BTC/USD", "BTCUSD"} {
+		o, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: symbol, price: 100.0 - float64(i), quantity: 1.0})
+		require.NoError(t, err)
+		require.NoError(t, service.AddOrder(ctx, o))
+		assert.Equal(t, "BTC-USD", o.Symbol)
+	}
+
+	assert.Equal(t, 1, service.Stats().Symbols)
+
+	book, err := service.GetOrderBook(ctx, "btc_usd")
+	require.NoError(t, err)
+	assert.Len(t, book.Bids, 4)
+
+	page, err := service.ListOrders(ctx, OrderFilter{Symbol: "btcusd"})
+	require.NoError(t, err)
+	assert.Equal(t, 4, page.Total)
+
+	// Symbols that don't resolve to a book are still unknown
+	_, err = service.GetOrderBook(ctx, "ETH-USD")
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+}
+
+func TestOrdersIterationIsStable(t *testing.T) {
+	service := NewService()
+	ctx := context.Background()
+
+	for _, symbol := range []string{"SOL-USD", "BTC-USD", "ETH-USD", "ADA-USD"} {
+		for i := 0; i < 3; i++ {
+			o, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: symbol, price: 100.0 - float64(i), quantity: 1.0})
+			require.NoError(t, err)
+			require.NoError(t, service.AddOrder(ctx, o))
+		}
+	}
+
+	ids := func() []string {
+		orders, err := service.Orders(ctx)
+		require.NoError(t, err)
+		ids := make([]string, len(orders))
+		for i, o := range orders {
+			ids[i] = o.ID
+		}
+		return ids
+	}
+
+	first := ids()
+	require.Len(t, first, 12)
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, first, ids())
+	}
+
+	orders, err := service.Orders(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "ADA-USD", orders[0].Symbol)
+	assert.Equal(t, "SOL-USD", orders[len(orders)-1].Symbol)
+}
+
+func TestHaltAndResumeSymbol(t *testing.T) {
+	service := NewService()
+
+	assert.Error(t, service.HaltSymbol("BTC-USD"))
+
+	resting, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100.0, quantity: 1.0})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), resting))
+
+	toCancel, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 99.0, quantity: 1.0})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), toCancel))
+
+	require.NoError(t, service.HaltSymbol("BTC-USD"))
+
+	// New orders are rejected and nothing matches
+	sell, err := createTestOrder(TestOrder{side: order.SideSell, symbol: "BTC-USD", price: 100.0, quantity: 1.0})
+	require.NoError(t, err)
+	assert.Error(t, service.AddOrder(context.Background(), sell))
+	assert.Equal(t, 0.0, resting.Filled)
+
+	// Cancellations still work
+	require.NoError(t, service.CancelOrder(context.Background(), "BTC-USD", toCancel.ID))
+	assert.Equal(t, order.StatusCancelled, toCancel.Status)
+
+	require.NoError(t, service.ResumeSymbol("BTC-USD"))
+	require.NoError(t, service.AddOrder(context.Background(), sell))
+	assert.Equal(t, order.StatusFilled, resting.Status)
+	assert.Equal(t, order.StatusFilled, sell.Status)
+}
+
+func TestCircuitBreakerHaltListener(t *testing.T) {
+	service := NewService()
+	require.NoError(t, service.RegisterSymbol(orderbook.SymbolConfig{
+		Symbol:                "BTC-USD",
+		CircuitBreakerPercent: 5,
+		CircuitBreakerWindow:  time.Minute,
+	}))
+
+	var halted []orderbook.HaltEvent
+	service.SetHaltListener(func(e orderbook.HaltEvent) { halted = append(halted, e) })
+
+	for _, data := range []TestOrder{
+		{side: order.SideSell, symbol: "BTC-USD", price: 100.0, quantity: 1.0},
+		{side: order.SideSell, symbol: "BTC-USD", price: 110.0, quantity: 1.0},
+		{side: order.SideBuy, symbol: "BTC-USD", price: 110.0, quantity: 2.0},
+	} {
+		o, err := createTestOrder(data)
+		require.NoError(t, err)
+		require.NoError(t, service.AddOrder(context.Background(), o))
+	}
+
+	require.Len(t, halted, 1)
+	assert.Equal(t, "BTC-USD", halted[0].Symbol)
+
+	o, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100.0, quantity: 1.0})
+	require.NoError(t, err)
+	assert.Error(t, service.AddOrder(context.Background(), o))
+}
+
+// panickingMatcher fails whenever an order reaches the other side
+type panickingMatcher struct{}
+
+func (panickingMatcher) Match(incoming *order.Order, opposing *orderbook.PriceLevel, limit float64) []orderbook.Fill {
+	if opposing != nil {
+		panic("matcher bug")
+	}
+	return nil
+}
+
+func TestMatchingPanicIsolatesSymbol(t *testing.T) {
+	var buf bytes.Buffer
+	service := NewService(WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+	require.NoError(t, service.RegisterSymbol(orderbook.SymbolConfig{Symbol: "BTC-USD", Matcher: panickingMatcher{}}))
+
+	var halted []orderbook.HaltEvent
+	service.SetHaltListener(func(e orderbook.HaltEvent) { halted = append(halted, e) })
+
+	add := func(data TestOrder) (*order.Order, error) {
+		o, err := createTestOrder(data)
+		require.NoError(t, err)
+		return o, service.AddOrder(context.Background(), o)
+	}
+
+	resting, err := add(TestOrder{side: order.SideSell, symbol: "BTC-USD", price: 100.0, quantity: 1.0})
+	require.NoError(t, err)
+	_, err = add(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100.0, quantity: 1.0})
+	assert.ErrorIs(t, err, orderbook.ErrBookFailed)
+
+	require.Len(t, halted, 1)
+	assert.Equal(t, "BTC-USD", halted[0].Symbol)
+	assert.Contains(t, buf.String(), "order book failed and was halted")
+	assert.Contains(t, buf.String(), "matcher bug")
+
+	// The failed symbol takes no new orders but still lets its orders go
+	_, err = add(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 90.0, quantity: 1.0})
+	assert.ErrorIs(t, err, orderbook.ErrTradingHalted)
+	assert.NoError(t, service.CancelOrder(context.Background(), "BTC-USD", resting.ID))
+
+	// Other symbols keep trading
+	sell, err := add(TestOrder{side: order.SideSell, symbol: "ETH-USD", price: 10.0, quantity: 1.0})
+	require.NoError(t, err)
+	_, err = add(TestOrder{side: order.SideBuy, symbol: "ETH-USD", price: 10.0, quantity: 1.0})
+	require.NoError(t, err)
+	assert.Equal(t, order.StatusFilled, sell.Status)
+}
+
+func TestMatchLatency(t *testing.T) {
+	service := NewService()
+
+	orders := []TestOrder{
+		{side: order.SideSell, symbol: "BTC-USD", price: 100.0, quantity: 1.0},
+		{side: order.SideSell, symbol: "BTC-USD", price: 101.0, quantity: 1.0},
+		{side: order.SideBuy, symbol: "BTC-USD", price: 101.0, quantity: 2.0},
+	}
+	for _, data := range orders {
+		o, err := createTestOrder(data)
+		require.NoError(t, err)
+		require.NoError(t, service.AddOrder(context.Background(), o))
+		assert.Greater(t, o.MatchLatencyMicros, 0.0)
+	}
+
+	// Orders the book turns away aren't measured
+	require.NoError(t, service.HaltSymbol("BTC-USD"))
+	rejected, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100.0, quantity: 1.0})
+	require.NoError(t, err)
+	require.ErrorIs(t, service.AddOrder(context.Background(), rejected), orderbook.ErrTradingHalted)
+	assert.Zero(t, rejected.MatchLatencyMicros)
+
+	latency := service.Stats().MatchLatency
+	assert.Equal(t, uint64(3), latency.Count)
+	assert.Greater(t, latency.AvgMicros, 0.0)
+	assert.LessOrEqual(t, latency.P50Micros, latency.P99Micros)
+	assert.LessOrEqual(t, latency.P99Micros, latency.MaxMicros)
+	var bucketed uint64
+	for _, bucket := range latency.Buckets {
+		bucketed += bucket.Count
+	}
+	assert.Equal(t, latency.Count, bucketed)
+}
+
+func TestLatencyHistogram(t *testing.T) {
+	h := newLatencyHistogram()
+	for range 98 {
+		h.observe(5)
+	}
+	h.observe(3000)
+	h.observe(3000)
+
+	stats := h.stats()
+	assert.Equal(t, uint64(100), stats.Count)
+	assert.InDelta(t, 64.9, stats.AvgMicros, 1e-9)
+	assert.Equal(t, 10.0, stats.P50Micros)
+	// 3000µs falls in the 5000µs bucket, but nothing took that long
+	assert.Equal(t, 3000.0, stats.P99Micros)
+	assert.Equal(t, 3000.0, stats.MaxMicros)
+	assert.Equal(t, uint64(98), stats.Buckets[0].Count)
+	assert.Equal(t, LatencyBucket{LeMicros: 5000, Count: 2}, stats.Buckets[8])
+
+	h.observe(500000)
+	stats = h.stats()
+	assert.Equal(t, LatencyBucket{Count: 1}, stats.Buckets[len(stats.Buckets)-1])
+}
+
+// BenchmarkAddOrderLatency places orders that rest and orders that trade
+// against them, reporting the median and 99th percentile match latency the
+// orders carry back
+func BenchmarkAddOrderLatency(b *testing.B) {
+	service := NewService()
+	latencies := make([]float64, 0, b.N)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		side := order.SideSell
+		if i%2 == 1 {
+			side = order.SideBuy
+		}
+		o, _ := createTestOrder(TestOrder{side: side, symbol: "BTC-USD", price: 100.0, quantity: 1.0})
+		if err := service.AddOrder(context.Background(), o); err != nil {
+			b.Fatal(err)
+		}
+		latencies = append(latencies, o.MatchLatencyMicros)
+	}
+	b.StopTimer()
+
+	slices.Sort(latencies)
+	percentile := func(p float64) float64 {
+		return latencies[int(p*float64(len(latencies)-1))]
+	}
+	b.ReportMetric(percentile(0.5), "p50-µs")
+	b.ReportMetric(percentile(0.99), "p99-µs")
+}
+
+func TestEventPublishing(t *testing.T) {
+	service := NewService()
+	publisher := event.NewChannelPublisher(16)
+	service.SetEventPublisher(publisher)
+
+	buyOrder, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100.0, quantity: 2.0})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), buyOrder))
+
+	sellOrder, err := createTestOrder(TestOrder{side: order.SideSell, symbol: "BTC-USD", price: 100.0, quantity: 0.5})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), sellOrder))
+
+	require.NoError(t, service.CancelOrder(context.Background(), "BTC-USD", buyOrder.ID))
+
+	expected := []struct {
+		eventType event.Type
+		orderID   string
+		hasTrade  bool
+	}{
+		{event.OrderCreated, buyOrder.ID, false},
+		{event.OrderCreated, sellOrder.ID, false},
+		{event.OrderPartiallyFilled, buyOrder.ID, true},
+		{event.OrderFill, buyOrder.ID, false},
+		{event.OrderFilled, sellOrder.ID, true},
+		{event.OrderFill, sellOrder.ID, false},
+		{event.TradeExecuted, "", true},
+		{event.OrderCancelled, buyOrder.ID, false},
+	}
+
+	require.Len(t, publisher.Events(), len(expected))
+	for _, want := range expected {
+		e := <-publisher.Events()
+		assert.Equal(t, want.eventType, e.Type)
+		assert.Equal(t, "BTC-USD", e.Symbol)
+		assert.Equal(t, want.hasTrade, e.Trade != nil)
+		if e.Type == event.OrderFill {
+			require.NotNil(t, e.Fill)
+			assert.Equal(t, want.orderID, e.Fill.OrderID)
+			continue
+		}
+		if want.orderID == "" {
+			assert.Nil(t, e.Order)
+			continue
+		}
+		require.NotNil(t, e.Order)
+		assert.Equal(t, want.orderID, e.Order.ID)
+	}
+	assert.Equal(t, uint64(0), publisher.Dropped())
+}
+
+func TestFillEvents_Sweep(t *testing.T) {
+	service := NewService()
+	publisher := event.NewChannelPublisher(64)
+	service.SetEventPublisher(publisher)
+
+	add := func(side order.Side, price, quantity float64) *order.Order {
+		o, err := createTestOrder(TestOrder{side: side, symbol: "BTC-USD", price: price, quantity: quantity})
+		require.NoError(t, err)
+		require.NoError(t, service.AddOrder(context.Background(), o))
+		return o
+	}
+	first := add(order.SideSell, 100, 1)
+	second := add(order.SideSell, 101, 3)
+	sweep := add(order.SideBuy, 101, 3)
+	last := add(order.SideBuy, 102, 1)
+
+	var fills []event.FillEvent
+	for len(publisher.Events()) > 0 {
+		if e := <-publisher.Events(); e.Type == event.OrderFill {
+			fills = append(fills, *e.Fill)
+		}
+	}
+
+	expected := []struct {
+		orderID    string
+		liquidity  event.Liquidity
+		lastQty    float64
+		lastPrice  float64
+		cumulative float64
+		avgPrice   float64
+		remaining  float64
+		status     order.Status
+	}{
+		{first.ID, event.LiquidityMaker, 1, 100, 1, 100, 0, order.StatusFilled},
+		{sweep.ID, event.LiquidityTaker, 1, 100, 1, 100, 2, order.StatusPartial},
+		{second.ID, event.LiquidityMaker, 2, 101, 2, 101, 1, order.StatusPartial},
+		{sweep.ID, event.LiquidityTaker, 2, 101, 3, 302.0 / 3, 0, order.StatusFilled},
+		{second.ID, event.LiquidityMaker, 1, 101, 3, 101, 0, order.StatusFilled},
+		{last.ID, event.LiquidityTaker, 1, 101, 1, 101, 0, order.StatusFilled},
+	}
+	require.Len(t, fills, len(expected))
+	for i, want := range expected {
+		got := fills[i]
+		assert.Equal(t, want.orderID, got.OrderID, "fill %d", i)
+		assert.Equal(t, want.liquidity, got.Liquidity, "fill %d", i)
+		assert.Equal(t, want.lastQty, got.LastQuantity, "fill %d", i)
+		assert.Equal(t, want.lastPrice, got.LastPrice, "fill %d", i)
+		assert.Equal(t, want.cumulative, got.CumulativeQuantity, "fill %d", i)
+		assert.InDelta(t, want.avgPrice, got.AvgPrice, 1e-9, "fill %d", i)
+		assert.Equal(t, want.remaining, got.RemainingQuantity, "fill %d", i)
+		assert.Equal(t, want.status, got.Status, "fill %d", i)
+	}
+}
+
+func TestRiskChecks(t *testing.T) {
+	newAccountOrder := func(account string, side order.Side, price, quantity float64) *order.Order {
+		o, err := createTestOrder(TestOrder{side: side, symbol: "BTC-USD", price: price, quantity: quantity})
+		require.NoError(t, err)
+		o.AccountID = account
+		return o
+	}
+
+	setup := func() (*Service, *risk.Balances) {
+		balances := risk.NewBalances()
+		balances.Deposit("alice", "USD", 1000)
+		balances.Deposit("bob", "BTC", 10)
+
+		service := NewService()
+		service.SetRiskChecker(balances)
+		return service, balances
+	}
+
+	t.Run("sufficient balance rests and reserves", func(t *testing.T) {
+		service, balances := setup()
+
+		require.NoError(t, service.AddOrder(context.Background(), newAccountOrder("alice", order.SideBuy, 100, 10)))
+		assert.Equal(t, risk.Balance{Reserved: 1000}, balances.Balance("alice", "USD"))
+	})
+
+	t.Run("insufficient balance is rejected", func(t *testing.T) {
+		service, balances := setup()
+
+		err := service.AddOrder(context.Background(), newAccountOrder("alice", order.SideBuy, 100, 11))
+		assert.ErrorIs(t, err, risk.ErrInsufficientBalance)
+		assert.Equal(t, risk.Balance{Available: 1000}, balances.Balance("alice", "USD"))
+
+		err = service.AddOrder(context.Background(), newAccountOrder("bob", order.SideSell, 100, 11))
+		assert.ErrorIs(t, err, risk.ErrInsufficientBalance)
+
+		book, err := service.GetOrderBook(context.Background(), "BTC-USD")
+		require.NoError(t, err)
+		assert.Empty(t, book.Bids)
+		assert.Empty(t, book.Asks)
+	})
+
+	t.Run("cancel releases the hold", func(t *testing.T) {
+		service, balances := setup()
+
+		buy := newAccountOrder("alice", order.SideBuy, 100, 10)
+		require.NoError(t, service.AddOrder(context.Background(), buy))
+		require.NoError(t, service.CancelOrder(context.Background(), "BTC-USD", buy.ID))
+
+		assert.Equal(t, risk.Balance{Available: 1000}, balances.Balance("alice", "USD"))
+	})
+
+	t.Run("fills settle and release what is left", func(t *testing.T) {
+		service, balances := setup()
+
+		require.NoError(t, service.AddOrder(context.Background(), newAccountOrder("bob", order.SideSell, 90, 4)))
+		require.NoError(t, service.AddOrder(context.Background(), newAccountOrder("alice", order.SideBuy, 100, 4)))
+
+		// Alice held 400 but paid 360; the rest comes back once she's filled
+		assert.Equal(t, risk.Balance{Available: 640}, balances.Balance("alice", "USD"))
+		assert.Equal(t, risk.Balance{Available: 4}, balances.Balance("alice", "BTC"))
+		assert.Equal(t, risk.Balance{Available: 6}, balances.Balance("bob", "BTC"))
+		assert.Equal(t, risk.Balance{Available: 360}, balances.Balance("bob", "USD"))
+	})
+
+	t.Run("amend resizes the hold", func(t *testing.T) {
+		service, balances := setup()
+
+		buy := newAccountOrder("alice", order.SideBuy, 100, 5)
+		require.NoError(t, service.AddOrder(context.Background(), buy))
+
+		err := service.AmendOrder("BTC-USD", buy.ID, 100, 20)
+		assert.ErrorIs(t, err, risk.ErrInsufficientBalance)
+		assert.Equal(t, risk.Balance{Available: 500, Reserved: 500}, balances.Balance("alice", "USD"))
+
+		require.NoError(t, service.AmendOrder("BTC-USD", buy.ID, 50, 10))
+		assert.Equal(t, risk.Balance{Available: 500, Reserved: 500}, balances.Balance("alice", "USD"))
+	})
+}
+
+func TestCancelFinishedOrder(t *testing.T) {
+	service := NewService()
+
+	buy, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+	require.NoError(t, err)
+	sell, err := createTestOrder(TestOrder{side: order.SideSell, symbol: "BTC-USD", price: 100, quantity: 1})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), buy))
+	require.NoError(t, service.AddOrder(context.Background(), sell))
+
+	err = service.CancelOrder(context.Background(), "BTC-USD", buy.ID)
+	assert.ErrorIs(t, err, order.ErrOrderNotCancellable)
+
+	found, err := service.LookupOrder(context.Background(), buy.ID)
+	require.NoError(t, err)
+	assert.Equal(t, order.StatusFilled, found.Status)
+
+	resting, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 90, quantity: 1})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), resting))
+	require.NoError(t, service.CancelOrder(context.Background(), "BTC-USD", resting.ID))
+	assert.ErrorIs(t, service.CancelOrder(context.Background(), "BTC-USD", resting.ID), order.ErrOrderNotCancellable)
+
+	err = service.CancelOrder(context.Background(), "BTC-USD", "unknown")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, order.ErrOrderNotCancellable)
+
+	_, err = service.LookupOrder(context.Background(), "unknown")
+	assert.Error(t, err)
+}
+
+func TestTypedErrors(t *testing.T) {
+	service := NewService()
+
+	resting, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), resting))
+	require.NoError(t, service.CancelOrder(context.Background(), "BTC-USD", resting.ID))
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{"cancel on unknown symbol", func() error {
+			return service.CancelOrder(context.Background(), "ETH-USD", "some-id")
+		}, ErrSymbolNotFound},
+		{"halt unknown symbol", func() error {
+			return service.HaltSymbol("ETH-USD")
+		}, ErrSymbolNotFound},
+		{"ticker for unknown symbol", func() error {
+			_, err := service.GetTicker("ETH-USD")
+			return err
+		}, ErrSymbolNotFound},
+		{"cancel unknown order", func() error {
+			return service.CancelOrder(context.Background(), "BTC-USD", "missing")
+		}, orderbook.ErrOrderNotFound},
+		{"get unknown order", func() error {
+			_, err := service.GetOrder(context.Background(), "missing")
+			return err
+		}, orderbook.ErrOrderNotFound},
+		{"amend unknown order", func() error {
+			return service.AmendOrder("BTC-USD", "missing", 100, 1)
+		}, orderbook.ErrOrderNotFound},
+		{"cancel cancelled order", func() error {
+			return service.CancelOrder(context.Background(), "BTC-USD", resting.ID)
+		}, orderbook.ErrOrderNotCancellable},
+		{"add while halted", func() error {
+			require.NoError(t, service.HaltSymbol("BTC-USD"))
+			defer service.ResumeSymbol("BTC-USD")
+
+			o, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+			require.NoError(t, err)
+			return service.AddOrder(context.Background(), o)
+		}, orderbook.ErrTradingHalted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.call(), tt.want)
+		})
+	}
+}
+
+func TestCancelledContext(t *testing.T) {
+	service := NewService()
+
+	resting, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), resting))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	incoming, err := createTestOrder(TestOrder{side: order.SideSell, symbol: "BTC-USD", price: 100, quantity: 1})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, service.AddOrder(ctx, incoming), context.Canceled)
+	_, err = service.SubmitOrder(ctx, incoming)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, service.CancelOrder(ctx, "BTC-USD", resting.ID), context.Canceled)
+	_, err = service.GetOrder(ctx, resting.ID)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = service.GetOrderBook(ctx, "BTC-USD")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Nothing reached the book
+	assert.Equal(t, order.StatusNew, resting.Status)
+	assert.Equal(t, order.StatusNew, incoming.Status)
+	assert.Equal(t, uint64(1), service.Stats().OrdersAdded)
+}
+
+func TestContextDeadline(t *testing.T) {
+	service := NewService()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+
+	o, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+	require.NoError(t, err)
+	assert.ErrorIs(t, service.AddOrder(ctx, o), context.DeadlineExceeded)
+}
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	service := NewService()
+	service.SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	o, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), o))
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "DEBUG", record["level"])
+	assert.Equal(t, "order added", record["msg"])
+	assert.Equal(t, o.ID, record["order_id"])
+	assert.Equal(t, "BTC-USD", record["symbol"])
+}
+
+func TestHotPathQuietAtInfo(t *testing.T) {
+	var buf bytes.Buffer
+	service := NewService()
+	service.SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	ctx := context.Background()
+	buy, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 2})
+	require.NoError(t, err)
+	sell, err := createTestOrder(TestOrder{side: order.SideSell, symbol: "BTC-USD", price: 100, quantity: 1})
+	require.NoError(t, err)
+
+	require.NoError(t, service.AddOrder(ctx, buy))
+	require.NoError(t, service.AddOrder(ctx, sell))
+	_, err = service.GetOrder(ctx, buy.ID)
+	require.NoError(t, err)
+	_, err = service.GetOrder(ctx, "missing")
+	require.Error(t, err)
+	require.NoError(t, service.CancelOrder(ctx, "BTC-USD", buy.ID))
+
+	assert.Empty(t, buf.String())
+}
+
+func TestOrderIndex(t *testing.T) {
+	service := NewService()
+	ctx := context.Background()
+
+	buy, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 2})
+	require.NoError(t, err)
+	other, err := createTestOrder(TestOrder{side: order.SideSell, symbol: "ETH-USD", price: 10, quantity: 1})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(ctx, buy))
+	require.NoError(t, service.AddOrder(ctx, other))
+	assert.Equal(t, 2, service.index.len())
+
+	found, err := service.GetOrder(ctx, other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, *other, *found)
+	assert.NotSame(t, other, found)
+
+	// Filled and cancelled orders leave the index
+	sell, err := createTestOrder(TestOrder{side: order.SideSell, symbol: "BTC-USD", price: 100, quantity: 2})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(ctx, sell))
+	require.NoError(t, service.CancelOrder(ctx, "ETH-USD", other.ID))
+	assert.Zero(t, service.index.len())
+
+	// So do rejected ones
+	require.NoError(t, service.HaltSymbol("BTC-USD"))
+	rejected, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+	require.NoError(t, err)
+	require.Error(t, service.AddOrder(ctx, rejected))
+	assert.Zero(t, service.index.len())
+}
+
+func TestListOrders(t *testing.T) {
+	service := NewService()
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	add := func(side order.Side, symbol string, price, quantity float64, minute int) *order.Order {
+		o, err := createTestOrder(TestOrder{side: side, symbol: symbol, price: price, quantity: quantity})
+		require.NoError(t, err)
+		o.CreatedAt = base.Add(time.Duration(minute) * time.Minute)
+		require.NoError(t, service.AddOrder(ctx, o))
+		return o
+	}
+
+	btcBuy := add(order.SideBuy, "BTC-USD", 100, 2, 0)
+	ethSell := add(order.SideSell, "ETH-USD", 10, 1, 1)
+	btcSell := add(order.SideSell, "BTC-USD", 100, 1, 2) // partially fills btcBuy
+	ethBuy := add(order.SideBuy, "ETH-USD", 9, 1, 3)
+	require.NoError(t, service.CancelOrder(ctx, "ETH-USD", ethBuy.ID))
+
+	ids := func(page *OrderPage) []string {
+		var out []string
+		for _, o := range page.Orders {
+			out = append(out, o.ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		filter OrderFilter
+		want   []string
+	}{
+		{"everything, oldest first", OrderFilter{}, []string{btcBuy.ID, ethSell.ID, btcSell.ID, ethBuy.ID}},
+		{"by symbol", OrderFilter{Symbol: "ETH-USD"}, []string{ethSell.ID, ethBuy.ID}},
+		{"by side", OrderFilter{Side: order.SideSell}, []string{ethSell.ID, btcSell.ID}},
+		{"by status", OrderFilter{Status: order.StatusPartial}, []string{btcBuy.ID}},
+		{"finished orders", OrderFilter{Status: order.StatusFilled}, []string{btcSell.ID}},
+		{"cancelled orders", OrderFilter{Status: order.StatusCancelled}, []string{ethBuy.ID}},
+		{"time range", OrderFilter{From: base.Add(time.Minute), To: base.Add(3 * time.Minute)}, []string{ethSell.ID, btcSell.ID}},
+		{"combined", OrderFilter{Symbol: "BTC-USD", Side: order.SideBuy}, []string{btcBuy.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := service.ListOrders(ctx, tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ids(page))
+			assert.Equal(t, len(tt.want), page.Total)
+		})
+	}
+
+	t.Run("pagination", func(t *testing.T) {
+		page, err := service.ListOrders(ctx, OrderFilter{Limit: 3})
+		require.NoError(t, err)
+		assert.Equal(t, []string{btcBuy.ID, ethSell.ID, btcSell.ID}, ids(page))
+		assert.Equal(t, 4, page.Total)
+
+		page, err = service.ListOrders(ctx, OrderFilter{Limit: 3, Offset: 3})
+		require.NoError(t, err)
+		assert.Equal(t, []string{ethBuy.ID}, ids(page))
+
+		page, err = service.ListOrders(ctx, OrderFilter{Offset: 4})
+		require.NoError(t, err)
+		assert.Empty(t, page.Orders)
+		assert.NotNil(t, page.Orders)
+		assert.Equal(t, 4, page.Total)
+
+		page, err = service.ListOrders(ctx, OrderFilter{Limit: MaxListLimit + 1})
+		require.NoError(t, err)
+		assert.Equal(t, MaxListLimit, page.Limit)
+
+		page, err = service.ListOrders(ctx, OrderFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, DefaultListLimit, page.Limit)
+	})
+}
+
+func TestGetOrderByClientID(t *testing.T) {
+	service := NewService()
+	ctx := context.Background()
+
+	submit := func(account, clientOrderID string) *order.Order {
+		o, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+		require.NoError(t, err)
+		o.AccountID = account
+		o.ClientOrderID = clientOrderID
+
+		placed, err := service.SubmitOrder(ctx, o)
+		require.NoError(t, err)
+		return placed
+	}
+
+	alice := submit("alice", "order-1")
+	bob := submit("bob", "order-1")
+	assert.NotEqual(t, alice.ID, bob.ID, "client IDs are scoped per account")
+
+	found, err := service.GetOrderByClientID(ctx, "alice", "order-1")
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, found.ID)
+
+	found, err = service.GetOrderByClientID(ctx, "bob", "order-1")
+	require.NoError(t, err)
+	assert.Equal(t, bob.ID, found.ID)
+
+	_, err = service.GetOrderByClientID(ctx, "alice", "order-2")
+	assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+
+	_, err = service.GetOrderByClientID(ctx, "carol", "order-1")
+	assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+}
+
+func TestCancelReplace(t *testing.T) {
+	ctx := context.Background()
+	service := NewService()
+
+	var quotes []*order.Order
+	for _, price := range []float64{49900.0, 49800.0} {
+		o, err := order.NewOrder(order.SideBuy, "BTC-USD", price, 1.0)
+		require.NoError(t, err)
+		require.NoError(t, service.AddOrder(ctx, o))
+		quotes = append(quotes, o)
+	}
+
+	before, err := service.GetOrderBook(ctx, "BTC-USD")
+	require.NoError(t, err)
+
+	// Watch the book while the quotes are replaced: it must never be seen
+	// without bids
+	done := make(chan struct{})
+	emptySeen := make(chan bool, 1)
+	go func() {
+		seen := false
+		for {
+			select {
+			case <-done:
+				emptySeen <- seen
+				return
+			default:
+			}
+			snapshot, err := service.GetOrderBook(ctx, "BTC-USD")
+			if err == nil && len(snapshot.Bids) == 0 {
+				seen = true
+			}
+		}
+	}()
+
+	replacement1, err := order.NewOrder(order.SideBuy, "BTC-USD", 49950.0, 2.0)
+	require.NoError(t, err)
+	replacement2, err := order.NewOrder(order.SideBuy, "BTC-USD", 49850.0, 2.0)
+	require.NoError(t, err)
+
+	result, err := service.CancelReplace(ctx, "BTC-USD",
+		[]string{quotes[0].ID, quotes[1].ID, "unknown"},
+		[]*order.Order{replacement1, replacement2},
+		false,
+	)
+	close(done)
+	require.NoError(t, err)
+	assert.False(t, <-emptySeen, "book was seen empty mid-replace")
+
+	require.Len(t, result.Cancels, 3)
+	assert.NoError(t, result.Cancels[0].Err)
+	assert.NoError(t, result.Cancels[1].Err)
+	assert.ErrorIs(t, result.Cancels[2].Err, orderbook.ErrOrderNotFound)
+	require.Len(t, result.Orders, 2)
+	assert.Equal(t, replacement1.ID, result.Orders[0].OrderID)
+	assert.NoError(t, result.Orders[0].Err)
+	assert.NoError(t, result.Orders[1].Err)
+
+	// The whole replace is one book operation
+	after, err := service.GetOrderBook(ctx, "BTC-USD")
+	require.NoError(t, err)
+	assert.Equal(t, before.Sequence+1, after.Sequence)
+	require.Len(t, after.Bids, 2)
+	assert.Equal(t, 49950.0, after.Bids[0].Price)
+	assert.Equal(t, 49850.0, after.Bids[1].Price)
+
+	_, err = service.GetOrder(ctx, quotes[0].ID)
+	assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+	got, err := service.GetOrder(ctx, replacement2.ID)
+	require.NoError(t, err)
+	assert.Equal(t, replacement2.ID, got.ID)
+
+	// A cancel of an order that already left the book is not cancellable
+	result, err = service.CancelReplace(ctx, "BTC-USD", []string{quotes[0].ID}, nil, false)
+	require.NoError(t, err)
+	assert.ErrorIs(t, result.Cancels[0].Err, orderbook.ErrOrderNotCancellable)
+
+	_, err = service.CancelReplace(ctx, "ETH-USD", []string{"x"}, nil, false)
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+}
+
+func TestCancelReplace_IDs(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*Service, *risk.Balances, *order.Order) {
+		balances := risk.NewBalances()
+		balances.Deposit("alice", "USD", 1000)
+		balances.Deposit("bob", "BTC", 10)
+		service := NewService(WithRiskChecker(balances))
+
+		original, err := order.NewOrder(order.SideBuy, "BTC-USD", 100.0, 2.0)
+		require.NoError(t, err)
+		original.AccountID = "alice"
+		require.NoError(t, service.AddOrder(ctx, original))
+		return service, balances, original
+	}
+	replacement := func(t *testing.T) *order.Order {
+		o, err := order.NewOrder(order.SideBuy, "BTC-USD", 100.0, 3.0)
+		require.NoError(t, err)
+		o.AccountID = "alice"
+		return o
+	}
+
+	t.Run("new ID", func(t *testing.T) {
+		service, balances, original := setup(t)
+		fresh := replacement(t)
+
+		result, err := service.CancelReplace(ctx, "BTC-USD", []string{original.ID}, []*order.Order{fresh}, false)
+		require.NoError(t, err)
+		assert.NoError(t, result.Cancels[0].Err)
+		assert.NoError(t, result.Orders[0].Err)
+		assert.Equal(t, fresh.ID, result.Orders[0].OrderID)
+		assert.NotEqual(t, original.ID, fresh.ID)
+
+		// The original resolves to its final state, the replacement to the book
+		_, err = service.GetOrder(ctx, original.ID)
+		assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+		final, err := service.LookupOrder(ctx, original.ID)
+		require.NoError(t, err)
+		assert.Equal(t, order.StatusCancelled, final.Status)
+		got, err := service.GetOrder(ctx, fresh.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3.0, got.Quantity)
+
+		assert.Equal(t, risk.Balance{Available: 700, Reserved: 300}, balances.Balance("alice", "USD"))
+	})
+
+	t.Run("kept ID", func(t *testing.T) {
+		service, _, original := setup(t)
+
+		// Another order resting at the same price goes ahead of the
+		// replacement, which loses its queue position
+		other, err := order.NewOrder(order.SideBuy, "BTC-USD", 100.0, 1.0)
+		require.NoError(t, err)
+		other.AccountID = "alice"
+		require.NoError(t, service.AddOrder(ctx, other))
+
+		kept := replacement(t)
+		result, err := service.CancelReplace(ctx, "BTC-USD", []string{original.ID}, []*order.Order{kept}, true)
+		require.NoError(t, err)
+		assert.NoError(t, result.Cancels[0].Err)
+		assert.NoError(t, result.Orders[0].Err)
+		assert.Equal(t, original.ID, result.Orders[0].OrderID)
+		assert.Equal(t, original.ID, kept.ID)
+
+		// The ID resolves to the replacement, still on the book
+		got, err := service.GetOrder(ctx, original.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3.0, got.Quantity)
+		assert.Equal(t, order.StatusNew, got.Status)
+
+		snapshot, err := service.GetOrderBook(ctx, "BTC-USD")
+		require.NoError(t, err)
+		require.Len(t, snapshot.Bids, 1)
+		require.Len(t, snapshot.Bids[0].Orders, 2)
+		assert.Equal(t, other.ID, snapshot.Bids[0].Orders[0].ID)
+		assert.Equal(t, original.ID, snapshot.Bids[0].Orders[1].ID)
+
+		// Its history carries on the original's: created, cancelled, created
+		history, err := service.OrderHistory(ctx, original.ID)
+		require.NoError(t, err)
+		assert.Len(t, history, 3)
+
+		require.NoError(t, service.CancelOrder(ctx, "BTC-USD", original.ID))
+		_, err = service.GetOrder(ctx, original.ID)
+		assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+	})
+
+	t.Run("kept ID resizes the hold", func(t *testing.T) {
+		service, balances, original := setup(t)
+
+		_, err := service.CancelReplace(ctx, "BTC-USD", []string{original.ID}, []*order.Order{replacement(t)}, true)
+		require.NoError(t, err)
+		assert.Equal(t, risk.Balance{Available: 700, Reserved: 300}, balances.Balance("alice", "USD"))
+
+		// and fills settle against it
+		sell, err := order.NewOrder(order.SideSell, "BTC-USD", 100.0, 3.0)
+		require.NoError(t, err)
+		sell.AccountID = "bob"
+		require.NoError(t, service.AddOrder(ctx, sell))
+		assert.Equal(t, risk.Balance{Available: 700}, balances.Balance("alice", "USD"))
+		final, err := service.LookupOrder(ctx, original.ID)
+		require.NoError(t, err)
+		assert.Equal(t, order.StatusFilled, final.Status)
+	})
+
+	t.Run("kept ID of an order already gone", func(t *testing.T) {
+		service, balances, original := setup(t)
+		require.NoError(t, service.CancelOrder(ctx, "BTC-USD", original.ID))
+
+		result, err := service.CancelReplace(ctx, "BTC-USD", []string{original.ID}, []*order.Order{replacement(t)}, true)
+		require.NoError(t, err)
+		assert.ErrorIs(t, result.Cancels[0].Err, orderbook.ErrOrderNotCancellable)
+		assert.ErrorIs(t, result.Orders[0].Err, orderbook.ErrOriginalNotCancelled)
+
+		// Nothing is left behind under the ID
+		_, err = service.GetOrder(ctx, original.ID)
+		assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+		assert.Equal(t, risk.Balance{Available: 1000}, balances.Balance("alice", "USD"))
+	})
+
+	t.Run("kept ID needs a cancel per order", func(t *testing.T) {
+		service, _, original := setup(t)
+		_, err := service.CancelReplace(ctx, "BTC-USD", []string{original.ID}, nil, true)
+		assert.Error(t, err)
+
+		got, err := service.GetOrder(ctx, original.ID)
+		require.NoError(t, err)
+		assert.Equal(t, order.StatusNew, got.Status)
+	})
+}
+
+func TestNewServiceOptions(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		service := NewService()
+		assert.Same(t, slog.Default(), service.getLogger())
+		assert.Equal(t, event.NopPublisher{}, service.eventPublisher())
+		assert.Nil(t, service.riskChecker())
+		assert.Equal(t, 0, service.Stats().Symbols)
+	})
+
+	t.Run("logger and publisher", func(t *testing.T) {
+		var buf bytes.Buffer
+		publisher := event.NewChannelPublisher(16)
+		service := NewService(
+			WithLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+			WithEventPublisher(publisher),
+		)
+
+		o, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+		require.NoError(t, err)
+		require.NoError(t, service.AddOrder(context.Background(), o))
+
+		assert.Contains(t, buf.String(), `"msg":"order added"`)
+		require.Len(t, publisher.Events(), 1)
+		assert.Equal(t, event.OrderCreated, (<-publisher.Events()).Type)
+	})
+
+	t.Run("symbols", func(t *testing.T) {
+		service := NewService(WithSymbols(
+			orderbook.SymbolConfig{Symbol: "BTC-USD", MaxOrdersPerSide: 1, DepthPolicy: orderbook.DepthPolicyReject},
+			orderbook.SymbolConfig{Symbol: "ETH-USD"},
+		))
+		assert.Equal(t, 2, service.Stats().Symbols)
+
+		first, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+		require.NoError(t, err)
+		require.NoError(t, service.AddOrder(context.Background(), first))
+
+		second, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 99, quantity: 1})
+		require.NoError(t, err)
+		assert.Error(t, service.AddOrder(context.Background(), second))
+
+		assert.Panics(t, func() { NewService(WithSymbols(orderbook.SymbolConfig{})) })
+	})
+
+	t.Run("risk checker", func(t *testing.T) {
+		balances := risk.NewBalances()
+		service := NewService(WithRiskChecker(balances))
+
+		o, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+		require.NoError(t, err)
+		o.AccountID = "alice"
+		assert.ErrorIs(t, service.AddOrder(context.Background(), o), risk.ErrInsufficientBalance)
+	})
+
+	t.Run("clock and TTLs", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		service := NewService(
+			WithClock(func() time.Time { return now }),
+			WithIdempotencyTTL(time.Minute),
+			WithFinishedOrderTTL(time.Minute),
+		)
+
+		o, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+		require.NoError(t, err)
+		o.ClientOrderID = "client-1"
+		_, err = service.SubmitOrder(context.Background(), o)
+		require.NoError(t, err)
+		require.NoError(t, service.CancelOrder(context.Background(), "BTC-USD", o.ID))
+
+		_, err = service.LookupOrder(context.Background(), o.ID)
+		require.NoError(t, err)
+		_, err = service.GetOrderByClientID(context.Background(), "", "client-1")
+		require.NoError(t, err)
+
+		// Both are forgotten a minute later on the injected clock
+		now = now.Add(time.Minute)
+		service.rememberFinished(nil, nil)
+		_, err = service.LookupOrder(context.Background(), o.ID)
+		assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+		_, err = service.GetOrderByClientID(context.Background(), "", "client-1")
+		assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+	})
+}
+
+func TestCancelOrderByID(t *testing.T) {
+	service := NewService()
+
+	sell, err := createTestOrder(TestOrder{side: order.SideSell, symbol: "ETH-USD", price: 3000, quantity: 2})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), sell))
+
+	buy, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "ETH-USD", price: 3000, quantity: 0.5})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), buy))
+
+	t.Run("existing order", func(t *testing.T) {
+		final, err := service.CancelOrderByID(context.Background(), sell.ID)
+		require.NoError(t, err)
+		assert.Equal(t, sell.ID, final.ID)
+		assert.Equal(t, order.StatusCancelled, final.Status)
+		assert.Equal(t, 0.5, final.Filled)
+
+		_, err = service.GetOrder(context.Background(), sell.ID)
+		assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+		assert.Equal(t, uint64(1), service.Stats().OrdersCancelled)
+	})
+
+	t.Run("already cancelled", func(t *testing.T) {
+		_, err := service.CancelOrderByID(context.Background(), sell.ID)
+		assert.ErrorIs(t, err, orderbook.ErrOrderNotCancellable)
+	})
+
+	t.Run("filled order", func(t *testing.T) {
+		_, err := service.CancelOrderByID(context.Background(), buy.ID)
+		assert.ErrorIs(t, err, orderbook.ErrOrderNotCancellable)
+	})
+
+	t.Run("unknown order", func(t *testing.T) {
+		_, err := service.CancelOrderByID(context.Background(), "missing")
+		assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+	})
+}
+
+func TestOrderHistory(t *testing.T) {
+	service := NewService()
+
+	buy, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 2})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), buy))
+	require.NoError(t, service.AmendOrder("BTC-USD", buy.ID, 100, 3))
+
+	sell, err := createTestOrder(TestOrder{side: order.SideSell, symbol: "BTC-USD", price: 99, quantity: 0.5})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), sell))
+
+	require.NoError(t, service.CancelOrder(context.Background(), "BTC-USD", buy.ID))
+
+	history, err := service.OrderHistory(context.Background(), buy.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 4)
+
+	assert.Equal(t, event.OrderCreated, history[0].Event)
+	assert.Equal(t, order.StatusNew, history[0].Status)
+	assert.Nil(t, history[0].FillPrice)
+
+	assert.Equal(t, event.OrderAmended, history[1].Event)
+	assert.Equal(t, order.StatusNew, history[1].Status)
+	assert.Nil(t, history[1].FillPrice)
+
+	assert.Equal(t, event.OrderPartiallyFilled, history[2].Event)
+	assert.Equal(t, order.StatusPartial, history[2].Status)
+	require.NotNil(t, history[2].FillPrice)
+	assert.Equal(t, 100.0, history[2].FillPrice.Value)
+	assert.Equal(t, 0.5, history[2].FillQuantity.Value)
+	assert.Equal(t, 0.5, history[2].Filled.Value)
+
+	assert.Equal(t, event.OrderCancelled, history[3].Event)
+	assert.Equal(t, order.StatusCancelled, history[3].Status)
+	assert.Equal(t, 0.5, history[3].Filled.Value)
+
+	for i := 1; i < len(history); i++ {
+		assert.Greater(t, history[i].Sequence, history[i-1].Sequence)
+	}
+
+	_, err = service.OrderHistory(context.Background(), "missing")
+	assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+}
+
+func TestReduceOnly(t *testing.T) {
+	newOrder := func(account string, side order.Side, price, quantity float64, reduceOnly bool) *order.Order {
+		o, err := createTestOrder(TestOrder{side: side, symbol: "BTC-USD", price: price, quantity: quantity})
+		require.NoError(t, err)
+		o.AccountID, o.ReduceOnly = account, reduceOnly
+		return o
+	}
+
+	service := NewService()
+
+	t.Run("rejected without a position", func(t *testing.T) {
+		err := service.AddOrder(context.Background(), newOrder("alice", order.SideSell, 100, 1, true))
+		assert.ErrorIs(t, err, ErrReduceOnly)
+	})
+
+	// Alice buys 2 from bob, going long 2
+	require.NoError(t, service.AddOrder(context.Background(), newOrder("bob", order.SideSell, 100, 2, false)))
+	require.NoError(t, service.AddOrder(context.Background(), newOrder("alice", order.SideBuy, 100, 2, false)))
+
+	t.Run("rejected when it would grow the position", func(t *testing.T) {
+		err := service.AddOrder(context.Background(), newOrder("alice", order.SideBuy, 100, 1, true))
+		assert.ErrorIs(t, err, ErrReduceOnly)
+	})
+
+	t.Run("capped at the position size", func(t *testing.T) {
+		require.NoError(t, service.AddOrder(context.Background(), newOrder("carol", order.SideBuy, 100, 5, false)))
+
+		reduce := newOrder("alice", order.SideSell, 100, 5, true)
+		require.NoError(t, service.AddOrder(context.Background(), reduce))
+		assert.Equal(t, 2.0, reduce.Quantity)
+		assert.Equal(t, order.StatusFilled, reduce.Status)
+
+		// Flat now, so nothing more to reduce
+		err := service.AddOrder(context.Background(), newOrder("alice", order.SideSell, 100, 1, true))
+		assert.ErrorIs(t, err, ErrReduceOnly)
+	})
+}
+
+func TestValidateOrder(t *testing.T) {
+	service := NewService()
+
+	o, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+	require.NoError(t, err)
+	require.NoError(t, service.ValidateOrder(context.Background(), o))
+
+	// Validating neither creates the book nor indexes the order
+	_, err = service.GetOrderBook(context.Background(), "BTC-USD")
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+	_, err = service.GetOrder(context.Background(), o.ID)
+	assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+
+	require.NoError(t, service.RegisterSymbol(orderbook.SymbolConfig{Symbol: "BTC-USD"}))
+	require.NoError(t, service.HaltSymbol("BTC-USD"))
+	assert.ErrorIs(t, service.ValidateOrder(context.Background(), o), orderbook.ErrTradingHalted)
+}
+
+func TestNegativePricesPerSymbol(t *testing.T) {
+	service := NewService(WithSymbols(
+		orderbook.SymbolConfig{Symbol: "CAL-SPREAD", AllowNegativePrice: true},
+		orderbook.SymbolConfig{Symbol: "BTC-USD"},
+	))
+	ctx := context.Background()
+
+	assert.True(t, service.NegativePricesAllowed("CAL-SPREAD"))
+	assert.False(t, service.NegativePricesAllowed("BTC-USD"))
+	assert.False(t, service.NegativePricesAllowed("ETH-USD"))
+
+	spread, err := order.NewOrderOfType(order.TypeLimit, order.SideBuy, "CAL-SPREAD", -1.5, 0, 1, true)
+	require.NoError(t, err)
+	require.NoError(t, service.ValidateOrder(ctx, spread))
+
+	// The policy belongs to each book: validating against a throwaway book
+	// for another symbol leaves the spread accepting negative prices
+	other, err := order.NewOrderOfType(order.TypeLimit, order.SideBuy, "ETH-USD", -1.5, 0, 1, true)
+	require.NoError(t, err)
+	assert.Error(t, service.ValidateOrder(ctx, other))
+	require.NoError(t, service.AddOrder(ctx, spread))
+
+	btc, err := order.NewOrderOfType(order.TypeLimit, order.SideBuy, "BTC-USD", -1.5, 0, 1, true)
+	require.NoError(t, err)
+	assert.Error(t, service.AddOrder(ctx, btc))
+}
+
+func TestServiceAsEngine(t *testing.T) {
+	var engine orderbook.Engine = NewService()
+	ctx := context.Background()
+
+	_, _, err := engine.GetBestBid(ctx, "BTC-USD")
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+
+	for _, data := range []TestOrder{
+		{side: order.SideBuy, symbol: "BTC-USD", price: 99, quantity: 1},
+		{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 2},
+		{side: order.SideSell, symbol: "BTC-USD", price: 101, quantity: 3},
+	} {
+		o, err := createTestOrder(data)
+		require.NoError(t, err)
+		require.NoError(t, engine.AddOrder(ctx, o))
+	}
+
+	price, quantity, err := engine.GetBestBid(ctx, "BTC-USD")
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, price)
+	assert.Equal(t, 2.0, quantity)
+
+	price, quantity, err = engine.GetBestAsk(ctx, "BTC-USD")
+	require.NoError(t, err)
+	assert.Equal(t, 101.0, price)
+	assert.Equal(t, 3.0, quantity)
+
+	snapshot, err := engine.GetOrderBook(ctx, "BTC-USD")
+	require.NoError(t, err)
+	require.Len(t, snapshot.Asks, 1)
+	ask := snapshot.Asks[0].Orders[0]
+
+	require.NoError(t, engine.CancelOrder(ctx, "BTC-USD", ask.ID))
+	_, err = engine.GetOrder(ctx, ask.ID)
+	assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+	_, _, err = engine.GetBestAsk(ctx, "BTC-USD")
+	assert.Error(t, err)
+}
+
+// gatedPublisher holds up the first event it gets until released, keeping
+// the order that caused it in flight
+type gatedPublisher struct {
+	once     sync.Once
+	reached  chan struct{}
+	released chan struct{}
+}
+
+func (p *gatedPublisher) Publish(event.Event) {
+	p.once.Do(func() {
+		close(p.reached)
+		<-p.released
+	})
+}
+
+func TestLoadShedding(t *testing.T) {
+	publisher := &gatedPublisher{reached: make(chan struct{}), released: make(chan struct{})}
+	service := NewService(WithMaxInFlight(1), WithEventPublisher(publisher))
+
+	newOrder := func() *order.Order {
+		o, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+		require.NoError(t, err)
+		return o
+	}
+
+	first := newOrder()
+	done := make(chan error)
+	go func() { done <- service.AddOrder(context.Background(), first) }()
+	<-publisher.reached
+
+	// The limit is taken, so the next order is shed untouched
+	shed := newOrder()
+	err := service.AddOrder(context.Background(), shed)
+	assert.ErrorIs(t, err, ErrOverloaded)
+	retryAfter, ok := RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, retryAfter)
+	_, err = service.GetOrder(context.Background(), shed.ID)
+	assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+
+	// The order already in flight still completes
+	close(publisher.released)
+	require.NoError(t, <-done)
+	assert.Equal(t, order.StatusNew, first.Status)
+
+	// and frees its slot
+	require.NoError(t, service.AddOrder(context.Background(), newOrder()))
+
+	stats := service.Stats()
+	assert.Equal(t, uint64(2), stats.OrdersAdded)
+	assert.Equal(t, uint64(1), stats.OrdersShed)
+}
+
+func TestIngestQueue(t *testing.T) {
+	publisher := &gatedPublisher{reached: make(chan struct{}), released: make(chan struct{})}
+	service := NewService(WithIngestQueue(2, 1), WithEventPublisher(publisher))
+
+	newOrder := func() *order.Order {
+		o, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+		require.NoError(t, err)
+		return o
+	}
+
+	// The only worker is stuck matching the first order, so the next two
+	// wait in the queue
+	done := make(chan error, 3)
+	go func() { done <- service.AddOrder(context.Background(), newOrder()) }()
+	<-publisher.reached
+	for range 2 {
+		go func() { done <- service.AddOrder(context.Background(), newOrder()) }()
+	}
+	require.Eventually(t, func() bool { return service.Stats().Queue.Depth == 2 }, time.Second, time.Millisecond)
+
+	// and the queue, full, sheds the rest untouched
+	shed := newOrder()
+	assert.ErrorIs(t, service.AddOrder(context.Background(), shed), ErrOverloaded)
+	_, err := service.GetOrder(context.Background(), shed.ID)
+	assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+
+	stats := service.Stats()
+	require.NotNil(t, stats.Queue)
+	assert.Equal(t, 2, stats.Queue.Capacity)
+	assert.Equal(t, 2, stats.Queue.PeakDepth)
+	assert.Equal(t, uint64(3), stats.Queue.Enqueued)
+	assert.Equal(t, uint64(1), stats.OrdersShed)
+
+	close(publisher.released)
+	for range 3 {
+		require.NoError(t, <-done)
+	}
+
+	stats = service.Stats()
+	assert.Equal(t, 0, stats.Queue.Depth)
+	assert.Equal(t, uint64(3), stats.OrdersAdded)
+	assert.Positive(t, stats.Queue.MaxWaitMs)
+	assert.Positive(t, stats.Queue.AvgWaitMs)
+	assert.LessOrEqual(t, stats.Queue.AvgWaitMs, stats.Queue.MaxWaitMs)
+}
+
+func TestIngestQueue_CallerGivesUp(t *testing.T) {
+	publisher := &gatedPublisher{reached: make(chan struct{}), released: make(chan struct{})}
+	service := NewService(WithIngestQueue(1, 1), WithEventPublisher(publisher))
+
+	first, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- service.AddOrder(context.Background(), first) }()
+	<-publisher.reached
+
+	// Still waiting when its context ends, the order never reaches the book
+	waiting, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, service.AddOrder(ctx, waiting), context.DeadlineExceeded)
+
+	close(publisher.released)
+	require.NoError(t, <-done)
+
+	// The worker skips it and goes on to the next
+	next, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), next))
+
+	_, err = service.GetOrder(context.Background(), waiting.ID)
+	assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+	assert.Equal(t, uint64(2), service.Stats().OrdersAdded)
+}
+
+func TestValidateBooks(t *testing.T) {
+	service := NewService()
+	ctx := context.Background()
+
+	// Crossing and resting orders on both sides, with every third one
+	// cancelled, leave the level lists sound
+	var placed []*order.Order
+	for i := 0; i < 60; i++ {
+		side := order.SideBuy
+		if i%2 == 1 {
+			side = order.SideSell
+		}
+		o, err := createTestOrder(TestOrder{side: side, symbol: "BTC-USD", price: 95 + float64(i*7%11), quantity: 1 + float64(i%3)})
+		require.NoError(t, err)
+		require.NoError(t, service.AddOrder(ctx, o))
+		placed = append(placed, o)
+	}
+	for i, o := range placed {
+		if i%3 == 0 && o.IsActive() {
+			require.NoError(t, service.CancelOrder(ctx, o.Symbol, o.ID))
+		}
+	}
+
+	assert.NoError(t, service.ValidateBooks(ctx))
+}
+
+func TestSession_CancelOnDisconnect(t *testing.T) {
+	service := NewService()
+	ctx := context.Background()
+
+	place := func(account, symbol string, side order.Side, price float64) *order.Order {
+		o, err := createTestOrder(TestOrder{side: side, symbol: symbol, price: price, quantity: 1.0})
+		require.NoError(t, err)
+		o.AccountID = account
+		require.NoError(t, service.AddOrder(ctx, o))
+		return o
+	}
+	mmBid := place("mm", "BTC-USD", order.SideBuy, 99.0)
+	mmAsk := place("mm", "BTC-USD", order.SideSell, 101.0)
+	mmEth := place("mm", "ETH-USD", order.SideBuy, 10.0)
+	other := place("other", "BTC-USD", order.SideBuy, 98.0)
+
+	_, err := service.OpenSession("", true)
+	assert.ErrorIs(t, err, ErrAccountRequired)
+
+	// A session that didn't opt in leaves the orders alone
+	plain, err := service.OpenSession("mm", false)
+	require.NoError(t, err)
+	cancelled, err := plain.Close(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, cancelled)
+
+	// The subscriber's connection drops: its context ends, and the
+	// transport closes the session on the way out
+	session, err := service.OpenSession("mm", true)
+	require.NoError(t, err)
+	connCtx, disconnect := context.WithCancel(ctx)
+	done := make(chan []order.Order)
+	go func() {
+		<-connCtx.Done()
+		cancelled, err := session.Close(connCtx)
+		assert.NoError(t, err)
+		done <- cancelled
+	}()
+	disconnect()
+	cancelled = <-done
+
+	var ids []string
+	for _, o := range cancelled {
+		assert.Equal(t, order.StatusCancelled, o.Status)
+		ids = append(ids, o.ID)
+	}
+	assert.Equal(t, []string{mmBid.ID, mmAsk.ID, mmEth.ID}, ids)
+
+	for _, o := range []*order.Order{mmBid, mmAsk, mmEth} {
+		_, err := service.GetOrder(ctx, o.ID)
+		assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+	}
+	_, err = service.GetOrder(ctx, other.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), service.Stats().OrdersCancelled)
+
+	// Closing again does nothing
+	cancelled, err = session.Close(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, cancelled)
+}
+
+func TestExpireOrders(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	publisher := event.NewChannelPublisher(16)
+	service := NewService(
+		WithClock(func() time.Time { return now }),
+		WithEventPublisher(publisher),
+	)
+	require.NoError(t, service.RegisterSymbol(orderbook.SymbolConfig{Symbol: "BTC-USD", OrderTTL: time.Hour}))
+	ctx := context.Background()
+
+	resting, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100.0, quantity: 1.0})
+	require.NoError(t, err)
+	resting.CreatedAt = now
+	require.NoError(t, service.AddOrder(ctx, resting))
+	untimed, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "ETH-USD", price: 10.0, quantity: 1.0})
+	require.NoError(t, err)
+	untimed.CreatedAt = now
+	require.NoError(t, service.AddOrder(ctx, untimed))
+	<-publisher.Events()
+	<-publisher.Events()
+
+	now = now.Add(59 * time.Minute)
+	expired, err := service.ExpireOrders(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, expired)
+
+	now = now.Add(time.Minute)
+	expired, err = service.ExpireOrders(ctx)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, resting.ID, expired[0].ID)
+	assert.Equal(t, order.StatusCancelled, expired[0].Status)
+
+	e := <-publisher.Events()
+	assert.Equal(t, event.OrderExpired, e.Type)
+	assert.Equal(t, resting.ID, e.Order.ID)
+
+	_, err = service.GetOrder(ctx, resting.ID)
+	assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+	_, err = service.GetOrder(ctx, untimed.ID)
+	assert.NoError(t, err, "a symbol without a TTL keeps its orders")
+	assert.Equal(t, uint64(1), service.Stats().OrdersCancelled)
+}
+
+func TestExpireOrders_ExpiresAt(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	service := NewService(WithClock(func() time.Time { return now }))
+	ctx := context.Background()
+
+	o, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100.0, quantity: 1.0})
+	require.NoError(t, err)
+	o.ExpiresAt = service.Now().Add(30 * time.Second)
+	require.NoError(t, service.AddOrder(ctx, o))
+
+	now = now.Add(29 * time.Second)
+	expired, err := service.ExpireOrders(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, expired)
+
+	now = now.Add(time.Second)
+	expired, err = service.ExpireOrders(ctx)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, o.ID, expired[0].ID)
+	assert.Equal(t, order.StatusCancelled, expired[0].Status)
+}
+
+func TestFlatten(t *testing.T) {
+	service := NewService()
+	ctx := context.Background()
+
+	place := func(account, symbol string, side order.Side, price, quantity float64) *order.Order {
+		o, err := createTestOrder(TestOrder{side: side, symbol: symbol, price: price, quantity: quantity})
+		require.NoError(t, err)
+		o.AccountID = account
+		require.NoError(t, service.AddOrder(ctx, o))
+		return o
+	}
+
+	// alice ends long 2 BTC and short 1 ETH against mm, with an order
+	// still resting
+	place("mm", "BTC-USD", order.SideSell, 100.0, 2.0)
+	place("alice", "BTC-USD", order.SideBuy, 100.0, 2.0)
+	place("mm", "ETH-USD", order.SideBuy, 10.0, 1.0)
+	place("alice", "ETH-USD", order.SideSell, 10.0, 1.0)
+	resting := place("alice", "BTC-USD", order.SideBuy, 90.0, 1.0)
+
+	// Liquidity for one and a half of alice's two BTC
+	place("mm", "BTC-USD", order.SideBuy, 95.0, 1.5)
+	place("mm", "ETH-USD", order.SideSell, 11.0, 5.0)
+
+	_, err := service.FlattenAccount(ctx, "")
+	assert.ErrorIs(t, err, ErrAccountRequired)
+
+	result, err := service.FlattenAccount(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, result.Cancelled, 1)
+	assert.Equal(t, resting.ID, result.Cancelled[0].ID)
+	require.Len(t, result.Closing, 2)
+	assert.Empty(t, result.Failed)
+
+	assert.Equal(t, order.SideSell, result.Closing[0].Side)
+	assert.Equal(t, 0.5, service.positions.Net("alice", "BTC-USD"))
+	assert.Zero(t, service.positions.Net("alice", "ETH-USD"))
+
+	// Again, against fresh liquidity: what's left is closed and the
+	// position doesn't flip
+	place("mm", "BTC-USD", order.SideBuy, 94.0, 10.0)
+	result, err = service.FlattenAccount(ctx, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, result.Cancelled)
+	require.Len(t, result.Closing, 1)
+	assert.Equal(t, 0.5, result.Closing[0].Filled)
+	positions, err := service.Positions(ctx, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, positions)
+
+	// A halted symbol refuses the closing order and is reported
+	place("bob", "BTC-USD", order.SideSell, 94.0, 1.0)
+	place("bob", "BTC-USD", order.SideSell, 120.0, 1.0)
+	require.NoError(t, service.HaltSymbol("BTC-USD"))
+	results, err := service.FlattenAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "bob", results[0].AccountID)
+	assert.Len(t, results[0].Cancelled, 1)
+	require.Len(t, results[0].Failed, 1)
+	assert.ErrorIs(t, results[0].Failed[0].Err, orderbook.ErrTradingHalted)
+	assert.Equal(t, order.SideBuy, results[0].Failed[0].Order.Side)
+	assert.Equal(t, "mm", results[1].AccountID)
+	assert.Equal(t, -1.0, service.positions.Net("bob", "BTC-USD"))
+}
+
+func TestPaperAccount(t *testing.T) {
+	service := NewService(WithPaperAccounts("paper"))
+	ctx := context.Background()
+
+	place := func(account string, side order.Side, price, quantity float64) *order.Order {
+		o, err := createTestOrder(TestOrder{side: side, symbol: "BTC-USD", price: price, quantity: quantity})
+		require.NoError(t, err)
+		o.AccountID = account
+		require.NoError(t, service.AddOrder(ctx, o))
+		return o
+	}
+
+	maker := place("mm", order.SideSell, 100.0, 1.0)
+	place("mm", order.SideSell, 101.0, 1.0)
+	before, err := service.GetOrderBook(ctx, "BTC-USD")
+	require.NoError(t, err)
+
+	assert.True(t, service.IsPaperAccount("paper"))
+	paper := place("paper", order.SideBuy, 100.5, 1.5)
+	assert.Equal(t, 1.0, paper.Filled)
+	assert.Equal(t, order.StatusCancelled, paper.Status, "what doesn't fill on arrival is cancelled")
+
+	// The live book and the maker are untouched, and the paper order
+	// doesn't rest in it
+	after, err := service.GetOrderBook(ctx, "BTC-USD")
+	require.NoError(t, err)
+	assert.Equal(t, before.Bids, after.Bids)
+	assert.Equal(t, before.Asks, after.Asks)
+	assert.Empty(t, after.Bids)
+	resting, err := service.GetOrder(ctx, maker.ID)
+	require.NoError(t, err)
+	assert.Zero(t, resting.Filled)
+	_, err = service.GetOrder(ctx, paper.ID)
+	assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+
+	// Paper fills move paper positions only
+	positions, err := service.Positions(ctx, "paper")
+	require.NoError(t, err)
+	require.Len(t, positions, 1)
+	assert.Equal(t, 1.0, positions[0].Quantity)
+	assert.Equal(t, 100.0, positions[0].AvgEntryPrice)
+	positions, err = service.Positions(ctx, "mm")
+	require.NoError(t, err)
+	assert.Empty(t, positions)
+	assert.Zero(t, service.positions.Net("paper", "BTC-USD"))
+
+	// Reduce-only is checked against the paper position
+	closing, err := order.NewOrderOfType(order.TypeMarket, order.SideSell, "BTC-USD", 0, 0, 5.0, false)
+	require.NoError(t, err)
+	closing.AccountID = "paper"
+	closing.ReduceOnly = true
+	require.NoError(t, service.AddOrder(ctx, closing))
+	assert.Equal(t, 1.0, closing.Quantity)
+	assert.Equal(t, order.StatusCancelled, closing.Status, "no bids to sell into")
+
+	orders, err := service.PaperOrders(ctx, "paper")
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+	assert.Equal(t, paper.ID, orders[0].ID)
+	found, err := service.LookupOrder(ctx, paper.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, found.Filled)
+
+	// Back on live, the account's orders reach the book
+	service.SetPaperAccount("paper", false)
+	live := place("paper", order.SideBuy, 100.0, 1.0)
+	assert.Equal(t, order.StatusFilled, live.Status)
+	assert.Equal(t, 1.0, service.positions.Net("paper", "BTC-USD"))
+	_, err = service.GetOrder(ctx, maker.ID)
+	assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+}
+
+func TestBulkLoad(t *testing.T) {
+	publisher := event.NewChannelPublisher(16)
+	service := NewService(WithEventPublisher(publisher))
+	ctx := context.Background()
+
+	var orders []*order.Order
+	for _, data := range []TestOrder{
+		{side: order.SideBuy, symbol: "btc-usd", price: 99.0, quantity: 1.0},
+		{side: order.SideBuy, symbol: "BTC-USD", price: 100.0, quantity: 2.0},
+		{side: order.SideSell, symbol: "BTC/USD", price: 101.0, quantity: 1.5},
+	} {
+		o, err := createTestOrder(data)
+		require.NoError(t, err)
+		orders = append(orders, o)
+	}
+	require.NoError(t, service.BulkLoad(ctx, "BTC-USD", orders, false))
+
+	price, quantity, err := service.GetBestBid(ctx, "BTC-USD")
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, price)
+	assert.Equal(t, 2.0, quantity)
+	price, _, err = service.GetBestAsk(ctx, "BTC-USD")
+	require.NoError(t, err)
+	assert.Equal(t, 101.0, price)
+
+	for _, o := range orders {
+		found, err := service.GetOrder(ctx, o.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "BTC-USD", found.Symbol)
+	}
+	assert.Len(t, publisher.Events(), 3)
+	assert.Equal(t, uint64(3), service.Stats().OrdersAdded)
+
+	// A crossing order is refused and leaves nothing behind
+	crossing, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 101.0, quantity: 1.0})
+	require.NoError(t, err)
+	err = service.BulkLoad(ctx, "BTC-USD", []*order.Order{crossing}, false)
+	assert.ErrorIs(t, err, orderbook.ErrBookCrossed)
+	_, err = service.GetOrder(ctx, crossing.ID)
+	assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+
+	other, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "ETH-USD", price: 10.0, quantity: 1.0})
+	require.NoError(t, err)
+	err = service.BulkLoad(ctx, "BTC-USD", []*order.Order{other}, false)
+	assert.ErrorIs(t, err, orderbook.ErrInvalidSymbol)
+
+	// With matching, it trades against the ask once loaded
+	require.NoError(t, service.BulkLoad(ctx, "BTC-USD", []*order.Order{crossing}, true))
+	filled, err := service.LookupOrder(ctx, crossing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, order.StatusFilled, filled.Status)
+	assert.Equal(t, 101.0, filled.AvgFillPrice)
+}
+
+func TestIterateActiveOrders(t *testing.T) {
+	service := NewService()
+	ctx := context.Background()
+
+	for _, data := range []TestOrder{
+		{side: order.SideBuy, symbol: "ETH-USD", price: 10.0, quantity: 1.0},
+		{side: order.SideBuy, symbol: "BTC-USD", price: 100.0, quantity: 1.0},
+		{side: order.SideSell, symbol: "BTC-USD", price: 101.0, quantity: 1.0},
+	} {
+		o, err := createTestOrder(data)
+		require.NoError(t, err)
+		require.NoError(t, service.AddOrder(ctx, o))
+	}
+
+	var symbols []string
+	require.NoError(t, service.IterateActiveOrders(ctx, func(o *order.Order) bool {
+		symbols = append(symbols, o.Symbol)
+		return true
+	}))
+	assert.Equal(t, []string{"BTC-USD", "BTC-USD", "ETH-USD"}, symbols)
+
+	// Stopping in the first book skips the rest
+	count := 0
+	require.NoError(t, service.IterateActiveOrders(ctx, func(*order.Order) bool {
+		count++
+		return false
+	}))
+	assert.Equal(t, 1, count)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, service.IterateActiveOrders(cancelled, func(*order.Order) bool { return true }), context.Canceled)
+}
+
+func TestSymbolRegistry(t *testing.T) {
+	service := NewService()
+	ctx := context.Background()
+
+	halted := true
+	require.NoError(t, service.AddSymbol(orderbook.SymbolConfig{Symbol: "eth/usd", MinNotional: 10}, &halted))
+	assert.ErrorIs(t, service.AddSymbol(orderbook.SymbolConfig{Symbol: "ETH-USD"}, nil), ErrSymbolExists)
+
+	o, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100.0, quantity: 1.0})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(ctx, o))
+
+	symbols := service.Symbols()
+	require.Len(t, symbols, 2)
+	assert.Equal(t, "BTC-USD", symbols[0].Config.Symbol)
+	assert.Equal(t, 1, symbols[0].ActiveOrders)
+	assert.False(t, symbols[0].Halted)
+	assert.Equal(t, "ETH-USD", symbols[1].Config.Symbol)
+	assert.Equal(t, 10.0, symbols[1].Config.MinNotional)
+	assert.True(t, symbols[1].Halted)
+
+	// A book with a resting order can't be retired
+	assert.ErrorIs(t, service.RetireSymbol("BTC-USD"), orderbook.ErrBookNotEmpty)
+	require.NoError(t, service.CancelOrder(ctx, "BTC-USD", o.ID))
+	require.NoError(t, service.RetireSymbol("btcusd"))
+
+	_, err = service.Symbol("BTC-USD")
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+	assert.ErrorIs(t, service.RetireSymbol("BTC-USD"), ErrSymbolNotFound)
+	require.Len(t, service.Symbols(), 1)
+
+	// Once retired, the symbol starts afresh like any unknown one
+	again, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100.0, quantity: 1.0})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(ctx, again))
+	info, err := service.Symbol("BTC-USD")
+	require.NoError(t, err)
+	assert.Equal(t, 1, info.ActiveOrders)
+}
+
+func TestMessageQuota(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 300*int(time.Millisecond), time.UTC)
+	service := NewService(
+		WithMessageQuota(3),
+		WithClock(func() time.Time { return now }),
+	)
+	ctx := context.Background()
+
+	place := func(account, symbol string) (*order.Order, error) {
+		o, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: symbol, price: 100, quantity: 1})
+		require.NoError(t, err)
+		o.AccountID = account
+		return o, service.AddOrder(ctx, o)
+	}
+
+	// Two orders and a cancel use up alice's quota on BTC-USD
+	first, err := place("alice", "BTC-USD")
+	require.NoError(t, err)
+	_, err = place("alice", "btc/usd")
+	require.NoError(t, err)
+	require.NoError(t, service.CancelOrder(ctx, "BTC-USD", first.ID))
+
+	throttled, err := place("alice", "BTC-USD")
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	retryAfter, ok := RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, 700*time.Millisecond, retryAfter, "the quota frees up when the second ends")
+	_, err = service.GetOrder(ctx, throttled.ID)
+	assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+
+	// Other symbols, other accounts and orders without an account are
+	// unaffected
+	_, err = place("alice", "ETH-USD")
+	assert.NoError(t, err)
+	_, err = place("bob", "BTC-USD")
+	assert.NoError(t, err)
+	for range 5 {
+		_, err = place("", "BTC-USD")
+		assert.NoError(t, err)
+	}
+
+	// Cancels count too, and so do the operations of a cancel-replace
+	resting, err := place("carol", "BTC-USD")
+	require.NoError(t, err)
+	_, err = place("carol", "BTC-USD")
+	require.NoError(t, err)
+	fresh, err := createTestOrder(TestOrder{side: order.SideSell, symbol: "BTC-USD", price: 200, quantity: 1})
+	require.NoError(t, err)
+	fresh.AccountID = "carol"
+	result, err := service.CancelReplace(ctx, "BTC-USD", []string{resting.ID}, []*order.Order{fresh}, false)
+	require.NoError(t, err)
+	assert.NoError(t, result.Cancels[0].Err)
+	assert.ErrorIs(t, result.Orders[0].Err, ErrQuotaExceeded)
+	_, err = place("carol", "BTC-USD")
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+
+	// The next second starts afresh
+	now = now.Add(time.Second)
+	_, err = place("alice", "BTC-USD")
+	assert.NoError(t, err)
+
+	assert.Equal(t, uint64(3), service.Stats().MessagesThrottled)
+}
+
+func TestMessageQuota_BoundedMemory(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	quota := newMessageQuota(1)
+	for i := range 1000 {
+		assert.True(t, quota.take(fmt.Sprintf("account-%d", i), "BTC-USD", now))
+	}
+	assert.Len(t, quota.counts, 1000)
+
+	// A new window drops the counts of the last one
+	assert.True(t, quota.take("account-0", "BTC-USD", now.Add(time.Second)))
+	assert.Len(t, quota.counts, 1)
+}
+
+func TestGetQueuePosition(t *testing.T) {
+	service := NewService()
+	ctx := context.Background()
+
+	var queued []*order.Order
+	for _, quantity := range []float64{1.0, 2.0, 3.0} {
+		o, err := createTestOrder(TestOrder{side: order.SideSell, symbol: "BTC-USD", price: 100, quantity: quantity})
+		require.NoError(t, err)
+		require.NoError(t, service.AddOrder(ctx, o))
+		queued = append(queued, o)
+	}
+
+	for i, want := range []struct {
+		rank  int
+		ahead float64
+	}{{1, 0}, {2, 1}, {3, 3}} {
+		position, err := service.GetQueuePosition(ctx, "btc-usd", queued[i].ID)
+		require.NoError(t, err)
+		assert.Equal(t, want.rank, position.Rank)
+		assert.Equal(t, want.ahead, position.QuantityAhead)
+		assert.Equal(t, "BTC-USD", position.Symbol)
+
+		byID, err := service.GetQueuePositionByID(ctx, queued[i].ID)
+		require.NoError(t, err)
+		assert.Equal(t, position, byID)
+	}
+
+	// The first fills: it isn't resting any more, and the others move up
+	buy, err := createTestOrder(TestOrder{side: order.SideBuy, symbol: "BTC-USD", price: 100, quantity: 1})
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(ctx, buy))
+
+	_, err = service.GetQueuePositionByID(ctx, queued[0].ID)
+	assert.ErrorIs(t, err, ErrOrderNotResting)
+	_, err = service.GetQueuePosition(ctx, "BTC-USD", queued[0].ID)
+	assert.ErrorIs(t, err, ErrOrderNotResting)
+	position, err := service.GetQueuePositionByID(ctx, queued[2].ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, position.Rank)
+
+	_, err = service.GetQueuePositionByID(ctx, "missing")
+	assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+	_, err = service.GetQueuePosition(ctx, "ETH-USD", queued[1].ID)
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+}
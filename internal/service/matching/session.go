@@ -0,0 +1,107 @@
+package matching
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/requestid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Session is a streaming client's connection as the service sees it. A
+// transport opens one when a client connects and closes it when the
+// connection ends, however it ends. A session opened with
+// cancel-on-disconnect is a dead man's switch: closing it cancels every
+// resting order of its account.
+type Session struct {
+	service            *Service
+	accountID          string
+	cancelOnDisconnect bool
+	closeOnce          sync.Once
+}
+
+// OpenSession starts a session for an account's streaming connection.
+// Cancel-on-disconnect needs an account to cancel the orders of.
+func (s *Service) OpenSession(accountID string, cancelOnDisconnect bool) (*Session, error) {
+	if cancelOnDisconnect && accountID == "" {
+		return nil, ErrAccountRequired
+	}
+	return &Session{service: s, accountID: accountID, cancelOnDisconnect: cancelOnDisconnect}, nil
+}
+
+// AccountID returns the account the session belongs to
+func (sess *Session) AccountID() string {
+	return sess.accountID
+}
+
+// CancelOnDisconnect reports whether closing the session cancels the
+// account's orders
+func (sess *Session) CancelOnDisconnect() bool {
+	return sess.cancelOnDisconnect
+}
+
+// Close ends the session. With cancel-on-disconnect it cancels the account's
+// resting orders and returns their final states. Only the first call does
+// anything.
+func (sess *Session) Close(ctx context.Context) ([]order.Order, error) {
+	var (
+		cancelled []order.Order
+		err       error
+	)
+	sess.closeOnce.Do(func() {
+		if !sess.cancelOnDisconnect {
+			return
+		}
+		// The connection's context is usually done by now; the cancels
+		// must go through regardless
+		ctx = context.WithoutCancel(ctx)
+		cancelled, err = sess.service.CancelAccountOrders(ctx, sess.accountID)
+		if logger := sess.service.getLogger(); logger.Enabled(ctx, slog.LevelInfo) {
+			logger.InfoContext(ctx, "session closed, orders cancelled",
+				"account_id", sess.accountID,
+				"cancelled", len(cancelled),
+			)
+		}
+	})
+	return cancelled, err
+}
+
+// CancelAccountOrders cancels every resting order of an account and returns
+// their final states, book by book in symbol order. Each book's orders are
+// cancelled in one step, with no fill landing in between, but books are
+// visited one after another.
+func (s *Service) CancelAccountOrders(ctx context.Context, accountID string) (_ []order.Order, err error) {
+	ctx, span := startSpan(ctx, "matching.CancelAccountOrders",
+		attribute.String("account_id", accountID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if accountID == "" {
+		return nil, ErrAccountRequired
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	books := s.sortedBooks()
+	s.mutex.RUnlock()
+
+	var cancelled []order.Order
+	for _, book := range books {
+		cancelled = append(cancelled, book.CancelAccountOrders(accountID)...)
+	}
+	s.ordersCancelled.Add(uint64(len(cancelled)))
+
+	if logger := s.getLogger(); logger.Enabled(ctx, slog.LevelDebug) {
+		logger.DebugContext(ctx, "account orders cancelled",
+			"account_id", accountID,
+			"cancelled", len(cancelled),
+			"request_id", requestid.FromContext(ctx),
+		)
+	}
+	span.SetAttributes(attribute.Int("cancelled", len(cancelled)))
+	return cancelled, nil
+}
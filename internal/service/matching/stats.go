@@ -0,0 +1,47 @@
+package matching
+
+import (
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+// Stats is a point-in-time view of the engine's aggregate counters
+type Stats struct {
+	Symbols           int               `json:"symbols"`
+	ActiveOrders      int               `json:"active_orders"`
+	TradesExecuted    uint64            `json:"trades_executed"`
+	OrdersAdded       uint64            `json:"orders_added"`
+	OrdersCancelled   uint64            `json:"orders_cancelled"`
+	OrdersShed        uint64            `json:"orders_shed"`
+	MessagesThrottled uint64            `json:"messages_throttled"`
+	Queue             *QueueStats       `json:"queue,omitempty"`
+	MatchLatency      LatencyStats      `json:"match_latency"`
+	Depth             []orderbook.Depth `json:"depth"`
+}
+
+// Stats collects engine-wide counters and per-symbol depth
+func (s *Service) Stats() Stats {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	stats := Stats{
+		Symbols:           len(s.books),
+		OrdersAdded:       s.ordersAdded.Load(),
+		OrdersCancelled:   s.ordersCancelled.Load(),
+		OrdersShed:        s.ordersShed.Load(),
+		MessagesThrottled: s.messagesThrottled.Load(),
+		MatchLatency:      s.matchLatency.stats(),
+		Depth:             make([]orderbook.Depth, 0, len(s.books)),
+	}
+	if s.queue != nil {
+		stats.Queue = s.queue.stats()
+	}
+
+	for _, book := range s.sortedBooks() {
+		depth := book.GetDepth()
+		stats.ActiveOrders += depth.ActiveOrders
+		stats.TradesExecuted += book.TradeCount()
+		stats.Depth = append(stats.Depth, depth)
+	}
+
+	return stats
+}
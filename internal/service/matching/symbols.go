@@ -0,0 +1,181 @@
+package matching
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+// SymbolInfo describes a registered symbol: its config, whether it is
+// halted and how many orders rest on its book
+type SymbolInfo struct {
+	Config       orderbook.SymbolConfig
+	Halted       bool
+	ActiveOrders int
+}
+
+// Symbols describes every symbol with a book, in symbol order
+func (s *Service) Symbols() []SymbolInfo {
+	s.mutex.RLock()
+	books := s.sortedBooks()
+	s.mutex.RUnlock()
+
+	symbols := make([]SymbolInfo, 0, len(books))
+	for _, book := range books {
+		symbols = append(symbols, symbolInfo(book))
+	}
+	return symbols
+}
+
+// Symbol describes one symbol, as Symbols does
+func (s *Service) Symbol(symbol string) (SymbolInfo, error) {
+	book, symbol, exists := s.lookupBook(symbol)
+	if !exists {
+		return SymbolInfo{}, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+	return symbolInfo(book), nil
+}
+
+// NegativePricesAllowed reports whether the symbol's book accepts zero and
+// negative prices. Unknown symbols don't.
+func (s *Service) NegativePricesAllowed(symbol string) bool {
+	book, _, exists := s.lookupBook(symbol)
+	return exists && book.Config().AllowNegativePrice
+}
+
+func symbolInfo(book *orderbook.OrderBook) SymbolInfo {
+	return SymbolInfo{
+		Config:       book.Config(),
+		Halted:       book.IsHalted(),
+		ActiveOrders: book.ActiveOrderCount(),
+	}
+}
+
+// AddSymbol is ConfigureSymbol for a symbol that has no book yet; it fails
+// with ErrSymbolExists rather than change a symbol already trading
+func (s *Service) AddSymbol(config orderbook.SymbolConfig, halted *bool) error {
+	if config.Symbol == "" {
+		return fmt.Errorf("symbol is required")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	config.Symbol = s.canonicalSymbol(config.Symbol)
+	if _, exists := s.books[config.Symbol]; exists {
+		return fmt.Errorf("%w: %s", ErrSymbolExists, config.Symbol)
+	}
+	if config.Precision != nil {
+		order.SetPrecision(config.Symbol, *config.Precision)
+	}
+	s.addBook(config).Reconfigure(config, halted)
+	return nil
+}
+
+// RetireSymbol removes a symbol whose book is empty, failing with
+// orderbook.ErrBookNotEmpty while orders rest on it. Orders already on their
+// way to the book are rejected. Like any unknown symbol, a retired one gets
+// a new book with the default config on its next order, unless it is
+// registered again first.
+func (s *Service) RetireSymbol(symbol string) error {
+	book, symbol, exists := s.lookupBook(symbol)
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+
+	// Retire waits for orders being checked, which may need s.mutex, so it
+	// runs before taking it
+	if err := book.Retire(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	if s.books[symbol] == book {
+		s.removeBook(symbol)
+	}
+	s.mutex.Unlock()
+
+	s.getLogger().Info("symbol retired", "symbol", symbol)
+	return nil
+}
+
+// compactSymbol drops the separator from a normalized symbol, so BTC-USD
+// and BTCUSD compare equal
+func compactSymbol(symbol string) string {
+	return strings.ReplaceAll(symbol, "-", "")
+}
+
+// canonicalSymbol resolves a symbol as clients write it to the one its book
+// is kept under. Casing and separators don't matter, and a symbol written
+// without a separator finds the book of the same pair written with one.
+// Callers hold s.mutex.
+func (s *Service) canonicalSymbol(symbol string) string {
+	normalized := orderbook.NormalizeSymbol(symbol)
+	if _, exists := s.books[normalized]; exists {
+		return normalized
+	}
+	if canonical, exists := s.compactSymbols[compactSymbol(normalized)]; exists {
+		return canonical
+	}
+	return normalized
+}
+
+// lookupBook returns the book of an existing symbol and its canonical form
+func (s *Service) lookupBook(symbol string) (*orderbook.OrderBook, string, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	symbol = s.canonicalSymbol(symbol)
+	book, exists := s.books[symbol]
+	return book, symbol, exists
+}
+
+// bookOrCreate returns the book of a symbol and its canonical form,
+// creating the book with the default config on first use
+func (s *Service) bookOrCreate(symbol string) (*orderbook.OrderBook, string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	symbol = s.canonicalSymbol(symbol)
+	book, exists := s.books[symbol]
+	if !exists {
+		book = s.addBook(orderbook.SymbolConfig{Symbol: symbol})
+	}
+	return book, symbol
+}
+
+// addBook creates and registers the book of a symbol whose config already
+// carries its canonical form. Callers hold s.mutex.
+func (s *Service) addBook(config orderbook.SymbolConfig) *orderbook.OrderBook {
+	book := s.newBook(config)
+	s.books[config.Symbol] = book
+	if i, exists := slices.BinarySearch(s.sortedSymbols, config.Symbol); !exists {
+		s.sortedSymbols = slices.Insert(s.sortedSymbols, i, config.Symbol)
+	}
+	s.compactSymbols[compactSymbol(config.Symbol)] = config.Symbol
+	return book
+}
+
+// removeBook unregisters the book of a symbol. Callers hold s.mutex.
+func (s *Service) removeBook(symbol string) {
+	delete(s.books, symbol)
+	if i, exists := slices.BinarySearch(s.sortedSymbols, symbol); exists {
+		s.sortedSymbols = slices.Delete(s.sortedSymbols, i, i+1)
+	}
+	if s.compactSymbols[compactSymbol(symbol)] == symbol {
+		delete(s.compactSymbols, compactSymbol(symbol))
+	}
+}
+
+// sortedBooks returns the books in symbol order, so everything that walks
+// every book does it the same way each time. Callers hold s.mutex.
+func (s *Service) sortedBooks() []*orderbook.OrderBook {
+	books := make([]*orderbook.OrderBook, len(s.sortedSymbols))
+	for i, symbol := range s.sortedSymbols {
+		books[i] = s.books[symbol]
+	}
+	return books
+}
@@ -0,0 +1,292 @@
+// Package booktest scripts operations against a single order book and checks
+// what they did, for testing strategies and matching rules without going
+// through the service or HTTP. Orders get sequential IDs ("1", "2", ...) and
+// every timestamp comes from a clock the script advances, so a scenario
+// produces the same trades on every run.
+//
+//	h := booktest.New(t, orderbook.SymbolConfig{Symbol: "BTC-USD"})
+//	ask := h.Add(booktest.Sell(100, 2))
+//	h.Add(booktest.Buy(101, 1))
+//	h.ExpectTrades(booktest.Trade{Price: 100, Quantity: 1})
+//	h.ExpectOrder(ask, order.StatusPartial, 1)
+package booktest
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+// DefaultSymbol is the symbol of books created without one
+const DefaultSymbol = "BTC-USD"
+
+// Start is the harness clock's initial time
+var Start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Spec describes an order to add. Build one with Buy, Sell, MarketBuy or
+// MarketSell.
+type Spec struct {
+	Type     order.Type
+	Side     order.Side
+	Price    float64
+	Quantity float64
+	// AccountID is carried by the order and its trades when set
+	AccountID string
+}
+
+// Buy is a limit buy
+func Buy(price, quantity float64) Spec {
+	return Spec{Type: order.TypeLimit, Side: order.SideBuy, Price: price, Quantity: quantity}
+}
+
+// Sell is a limit sell
+func Sell(price, quantity float64) Spec {
+	return Spec{Type: order.TypeLimit, Side: order.SideSell, Price: price, Quantity: quantity}
+}
+
+// MarketBuy is a market buy of quantity
+func MarketBuy(quantity float64) Spec {
+	return Spec{Type: order.TypeMarket, Side: order.SideBuy, Quantity: quantity}
+}
+
+// MarketSell is a market sell of quantity
+func MarketSell(quantity float64) Spec {
+	return Spec{Type: order.TypeMarket, Side: order.SideSell, Quantity: quantity}
+}
+
+// For returns the spec with its orders attributed to an account
+func (s Spec) For(accountID string) Spec {
+	s.AccountID = accountID
+	return s
+}
+
+// Level is a price level as ExpectBook compares it: the price and the
+// remaining quantity resting there
+type Level struct {
+	Price    float64
+	Quantity float64
+}
+
+// Trade is a trade as ExpectTrades compares it. Order IDs are compared only
+// when set.
+type Trade struct {
+	Price    float64
+	Quantity float64
+	Taker    string
+	Maker    string
+}
+
+// Harness drives one order book. Its methods fail the test on unexpected
+// errors, so a script reads as a list of steps.
+type Harness struct {
+	t    testing.TB
+	book *orderbook.OrderBook
+	now  time.Time
+
+	nextID int
+	// orders holds the latest state of every order the script added
+	orders map[string]order.Order
+	// trades holds every trade so far, and seen how many ExpectTrades
+	// already checked
+	trades []orderbook.Trade
+	seen   int
+}
+
+// New creates a harness around a fresh book with the given config, on the
+// DefaultSymbol when the config has none
+func New(t testing.TB, config orderbook.SymbolConfig) *Harness {
+	t.Helper()
+
+	if config.Symbol == "" {
+		config.Symbol = DefaultSymbol
+	}
+	h := &Harness{
+		t:      t,
+		book:   orderbook.NewOrderBookWithConfig(config),
+		now:    Start,
+		orders: make(map[string]order.Order),
+	}
+	h.book.SetClock(h.clock)
+	h.book.SetUpdateListener(h.record)
+	return h
+}
+
+func (h *Harness) clock() time.Time {
+	return h.now
+}
+
+// record keeps the state each update reports and every trade, once
+func (h *Harness) record(updates []orderbook.Update) {
+	for _, u := range updates {
+		h.orders[u.Order.ID] = u.Order
+		if u.Trade != nil && u.Order.ID == u.Trade.TakerOrderID {
+			h.trades = append(h.trades, *u.Trade)
+		}
+	}
+}
+
+// Book returns the book under test, for checks the harness doesn't offer
+func (h *Harness) Book() *orderbook.OrderBook {
+	return h.book
+}
+
+// Now returns the harness clock
+func (h *Harness) Now() time.Time {
+	return h.now
+}
+
+// Advance moves the harness clock forward
+func (h *Harness) Advance(d time.Duration) {
+	h.now = h.now.Add(d)
+}
+
+// Add places an order and returns its ID, failing the test if the book
+// rejects it
+func (h *Harness) Add(spec Spec) string {
+	h.t.Helper()
+
+	id, err := h.TryAdd(spec)
+	if err != nil {
+		h.t.Fatalf("booktest: add %s %s %v @ %v: %v", spec.Type, spec.Side, spec.Quantity, spec.Price, err)
+	}
+	return id
+}
+
+// TryAdd places an order and returns its ID along with the book's error, for
+// scripts that expect a rejection
+func (h *Harness) TryAdd(spec Spec) (string, error) {
+	h.t.Helper()
+
+	config := h.book.Config()
+	o, err := order.NewOrderOfType(spec.Type, spec.Side, config.Symbol, spec.Price, 0, spec.Quantity, config.AllowNegativePrice)
+	if err != nil {
+		return "", err
+	}
+
+	h.nextID++
+	o.ID = strconv.Itoa(h.nextID)
+	o.AccountID = spec.AccountID
+	o.CreatedAt, o.UpdatedAt = h.now, h.now
+	h.orders[o.ID] = *o
+
+	return o.ID, h.book.AddOrder(o)
+}
+
+// Cancel cancels an order, failing the test if the book refuses
+func (h *Harness) Cancel(id string) {
+	h.t.Helper()
+
+	if err := h.book.CancelOrder(id); err != nil {
+		h.t.Fatalf("booktest: cancel %s: %v", id, err)
+	}
+}
+
+// Amend changes an order's price and quantity, failing the test if the book
+// refuses
+func (h *Harness) Amend(id string, price, quantity float64) {
+	h.t.Helper()
+
+	if err := h.book.AmendOrder(id, price, quantity); err != nil {
+		h.t.Fatalf("booktest: amend %s: %v", id, err)
+	}
+}
+
+// Order returns the latest state of an order the script added
+func (h *Harness) Order(id string) order.Order {
+	h.t.Helper()
+
+	o, exists := h.orders[id]
+	if !exists {
+		h.t.Fatalf("booktest: no order %s", id)
+	}
+	return o
+}
+
+// Trades returns every trade so far, oldest first
+func (h *Harness) Trades() []orderbook.Trade {
+	return append([]orderbook.Trade(nil), h.trades...)
+}
+
+// ExpectOrder checks an order's status and filled quantity
+func (h *Harness) ExpectOrder(id string, status order.Status, filled float64) {
+	h.t.Helper()
+
+	o := h.Order(id)
+	if o.Status != status || o.Filled != filled {
+		h.t.Errorf("booktest: order %s is %s with %v filled, want %s with %v", id, o.Status, o.Filled, status, filled)
+	}
+}
+
+// ExpectTrades checks the trades made since the previous ExpectTrades, in
+// order
+func (h *Harness) ExpectTrades(want ...Trade) {
+	h.t.Helper()
+
+	got := h.trades[h.seen:]
+	h.seen = len(h.trades)
+
+	if len(got) != len(want) {
+		h.t.Errorf("booktest: got %d trades %s, want %d", len(got), formatTrades(got), len(want))
+		return
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Price != w.Price || g.Quantity != w.Quantity ||
+			(w.Taker != "" && g.TakerOrderID != w.Taker) ||
+			(w.Maker != "" && g.MakerOrderID != w.Maker) {
+			h.t.Errorf("booktest: trade %d is %s, want %v @ %v (taker %q, maker %q)",
+				i, formatTrades(got[i:i+1]), w.Quantity, w.Price, w.Taker, w.Maker)
+		}
+	}
+}
+
+// ExpectBook checks the levels resting on each side, best price first, and
+// that the book's level list is sound
+func (h *Harness) ExpectBook(bids, asks []Level) {
+	h.t.Helper()
+
+	if err := h.book.Validate(); err != nil {
+		h.t.Errorf("booktest: %v", err)
+	}
+
+	snapshot := h.book.GetOrderBook()
+	if got := levels(snapshot.Bids); !slices.Equal(got, bids) {
+		h.t.Errorf("booktest: bids are %v, want %v", got, bids)
+	}
+	if got := levels(snapshot.Asks); !slices.Equal(got, asks) {
+		h.t.Errorf("booktest: asks are %v, want %v", got, asks)
+	}
+}
+
+// levels sums the remaining quantity of the active orders of each level
+func levels(side []orderbook.PriceLevel) []Level {
+	var out []Level
+	for _, level := range side {
+		var quantity float64
+		for _, o := range level.Orders {
+			if o.IsActive() {
+				quantity += o.RemainingQuantity()
+			}
+		}
+		if quantity > 0 {
+			out = append(out, Level{Price: level.Price, Quantity: quantity})
+		}
+	}
+	return out
+}
+
+func formatTrades(trades []orderbook.Trade) string {
+	s := "["
+	for i, t := range trades {
+		if i > 0 {
+			s += ", "
+		}
+		s += fmt.Sprintf("%v @ %v (taker %s, maker %s)", t.Quantity, t.Price, t.TakerOrderID, t.MakerOrderID)
+	}
+	return s + "]"
+}
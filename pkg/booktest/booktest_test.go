@@ -0,0 +1,86 @@
+package booktest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"company.com/matchengine/pkg/booktest"
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+func TestScenario_SweepAndRest(t *testing.T) {
+	h := booktest.New(t, orderbook.SymbolConfig{})
+
+	ask1 := h.Add(booktest.Sell(101, 1))
+	ask2 := h.Add(booktest.Sell(102, 2))
+	bid := h.Add(booktest.Buy(99, 1))
+	h.ExpectTrades()
+	h.ExpectBook(
+		[]booktest.Level{{Price: 99, Quantity: 1}},
+		[]booktest.Level{{Price: 101, Quantity: 1}, {Price: 102, Quantity: 2}},
+	)
+
+	// A buy through both asks fills the first and half the second, then
+	// rests its remainder at its limit
+	h.Advance(time.Second)
+	taker := h.Add(booktest.Buy(102, 2.5))
+	h.ExpectTrades(
+		booktest.Trade{Price: 101, Quantity: 1, Taker: taker, Maker: ask1},
+		booktest.Trade{Price: 102, Quantity: 1.5, Taker: taker, Maker: ask2},
+	)
+	h.ExpectOrder(ask1, order.StatusFilled, 1)
+	h.ExpectOrder(ask2, order.StatusPartial, 1.5)
+	h.ExpectOrder(taker, order.StatusFilled, 2.5)
+	h.ExpectBook(
+		[]booktest.Level{{Price: 99, Quantity: 1}},
+		[]booktest.Level{{Price: 102, Quantity: 0.5}},
+	)
+
+	// Trades carry the harness clock
+	trades := h.Trades()
+	require.Len(t, trades, 2)
+	assert.Equal(t, booktest.Start.Add(time.Second), trades[0].Timestamp)
+
+	h.Cancel(ask2)
+	h.ExpectOrder(ask2, order.StatusCancelled, 1.5)
+	h.ExpectBook([]booktest.Level{{Price: 99, Quantity: 1}}, nil)
+
+	// A market sell takes the bid at its amended price
+	h.Amend(bid, 100, 2)
+	h.Add(booktest.MarketSell(0.5))
+	h.ExpectTrades(booktest.Trade{Price: 100, Quantity: 0.5, Maker: bid})
+	h.ExpectOrder(bid, order.StatusPartial, 0.5)
+}
+
+func TestScenario_DeterministicIDs(t *testing.T) {
+	run := func() []orderbook.Trade {
+		h := booktest.New(t, orderbook.SymbolConfig{Symbol: "ETH-USD"})
+		h.Add(booktest.Sell(10, 1).For("maker"))
+		h.Add(booktest.Sell(10, 1))
+		h.Advance(time.Minute)
+		h.Add(booktest.MarketBuy(1.5).For("taker"))
+		return h.Trades()
+	}
+
+	first := run()
+	require.Len(t, first, 2)
+	assert.Equal(t, "3", first[0].TakerOrderID)
+	assert.Equal(t, "1", first[0].MakerOrderID)
+	assert.Equal(t, "maker", first[0].MakerAccountID)
+	assert.Equal(t, "taker", first[0].TakerAccountID)
+	assert.Equal(t, "ETH-USD", first[0].Symbol)
+	assert.Equal(t, first, run())
+}
+
+func TestScenario_Rejection(t *testing.T) {
+	h := booktest.New(t, orderbook.SymbolConfig{MaxOrdersPerSide: 1, DepthPolicy: orderbook.DepthPolicyReject})
+
+	h.Add(booktest.Buy(100, 1))
+	_, err := h.TryAdd(booktest.Buy(99, 1))
+	assert.Error(t, err)
+	h.ExpectBook([]booktest.Level{{Price: 100, Quantity: 1}}, nil)
+}
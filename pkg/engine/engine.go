@@ -0,0 +1,241 @@
+// Package engine is the matching engine as a library: a set of order books,
+// one per symbol, matching limit and market orders by price-time priority.
+// It has no transport, persistence or risk checks; those belong to the
+// program embedding it. Orders are built with the order package and books
+// are configured with orderbook.SymbolConfig.
+//
+//	e := engine.New()
+//	e.AddSymbol(orderbook.SymbolConfig{Symbol: "BTC-USD"})
+//	ask, _ := order.NewOrder(order.SideSell, "BTC-USD", 100, 2)
+//	e.Submit(ask)
+//	bid, _ := order.NewOrder(order.SideBuy, "BTC-USD", 101, 1)
+//	trades, _ := e.Submit(bid) // one trade of 1 at 100
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+// ErrSymbolNotFound is returned for a symbol the engine has no book for
+var ErrSymbolNotFound = errors.New("symbol not found")
+
+// ErrSymbolExists is returned when adding a symbol the engine already has
+var ErrSymbolExists = errors.New("symbol already exists")
+
+// Engine matches orders on the books of the symbols added to it. It is safe
+// for concurrent use; operations on one symbol run one at a time, in the
+// order they lock the book, and operations on different symbols run in
+// parallel.
+type Engine struct {
+	mutex    sync.RWMutex
+	markets  map[string]*market
+	now      func() time.Time
+	listener func([]orderbook.Update)
+}
+
+// market is a symbol's book and the updates of the operation running on it
+type market struct {
+	book    *orderbook.OrderBook
+	mutex   sync.Mutex
+	updates []orderbook.Update
+}
+
+// Option configures an Engine
+type Option func(*Engine)
+
+// WithClock sets the time source of the books and of ExpireOrders
+func WithClock(now func() time.Time) Option {
+	return func(e *Engine) {
+		if now != nil {
+			e.now = now
+		}
+	}
+}
+
+// WithUpdateListener receives every order state change, each operation's
+// changes in one call. Calls for one symbol come one at a time, in order;
+// the listener must not call back into the engine for that symbol.
+func WithUpdateListener(listener func([]orderbook.Update)) Option {
+	return func(e *Engine) {
+		e.listener = listener
+	}
+}
+
+// New creates an engine with no symbols
+func New(opts ...Option) *Engine {
+	e := &Engine{
+		markets: make(map[string]*market),
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// AddSymbol creates the book of a symbol with its configuration
+func (e *Engine) AddSymbol(config orderbook.SymbolConfig) error {
+	if config.Symbol == "" {
+		return fmt.Errorf("%w: symbol is required", orderbook.ErrInvalidSymbol)
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if _, exists := e.markets[config.Symbol]; exists {
+		return fmt.Errorf("%w: %s", ErrSymbolExists, config.Symbol)
+	}
+	m := &market{book: orderbook.NewOrderBookWithConfig(config)}
+	m.book.SetClock(e.now)
+	m.book.SetUpdateListener(func(updates []orderbook.Update) {
+		m.updates = append(m.updates, updates...)
+	})
+	e.markets[config.Symbol] = m
+	return nil
+}
+
+// Symbols returns the engine's symbols in order
+func (e *Engine) Symbols() []string {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	symbols := make([]string, 0, len(e.markets))
+	for symbol := range e.markets {
+		symbols = append(symbols, symbol)
+	}
+	slices.Sort(symbols)
+	return symbols
+}
+
+// Submit matches an order against its symbol's book and rests what is left
+// of a limit order. It returns the trades the order made, in execution
+// order; the order itself is updated in place.
+func (e *Engine) Submit(o *order.Order) ([]orderbook.Trade, error) {
+	m, err := e.market(o.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	var trades []orderbook.Trade
+	err = e.run(m, func() error {
+		return m.book.AddOrder(o)
+	}, func(updates []orderbook.Update) {
+		trades = tradesOf(updates)
+	})
+	return trades, err
+}
+
+// Cancel cancels a resting order
+func (e *Engine) Cancel(symbol, orderID string) error {
+	m, err := e.market(symbol)
+	if err != nil {
+		return err
+	}
+	return e.run(m, func() error {
+		return m.book.CancelOrder(orderID)
+	}, nil)
+}
+
+// Order returns a copy of a resting order. Filled and cancelled orders have
+// left the book and are not found.
+func (e *Engine) Order(symbol, orderID string) (order.Order, error) {
+	m, err := e.market(symbol)
+	if err != nil {
+		return order.Order{}, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	o, err := m.book.GetOrder(orderID)
+	if err != nil {
+		return order.Order{}, err
+	}
+	return *o, nil
+}
+
+// Snapshot returns the aggregated levels of a symbol's book
+func (e *Engine) Snapshot(symbol string) (*orderbook.OrderBookSnapshot, error) {
+	m, err := e.market(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return m.book.GetOrderBook(), nil
+}
+
+// ExpireOrders cancels, on every book, the orders resting longer than their
+// symbol's OrderTTL and returns their final states. Call it periodically
+// when a symbol has a TTL.
+func (e *Engine) ExpireOrders() []order.Order {
+	e.mutex.RLock()
+	markets := make([]*market, 0, len(e.markets))
+	for _, m := range e.markets {
+		markets = append(markets, m)
+	}
+	e.mutex.RUnlock()
+
+	now := e.now()
+	var expired []order.Order
+	for _, m := range markets {
+		e.run(m, func() error {
+			expired = append(expired, m.book.ExpireOrders(now)...)
+			return nil
+		}, nil)
+	}
+	return expired
+}
+
+func (e *Engine) market(symbol string) (*market, error) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	m, exists := e.markets[symbol]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+	return m, nil
+}
+
+// run executes an operation on a market and hands the updates it caused to
+// collect, when given, and to the listener
+func (e *Engine) run(m *market, operation func() error, collect func([]orderbook.Update)) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	err := operation()
+	updates := m.updates
+	m.updates = nil
+
+	if len(updates) > 0 {
+		if collect != nil {
+			collect(updates)
+		}
+		if e.listener != nil {
+			e.listener(updates)
+		}
+	}
+	return err
+}
+
+// tradesOf lists the trades of an operation's updates; both sides of a
+// trade carry it, so each appears twice
+func tradesOf(updates []orderbook.Update) []orderbook.Trade {
+	var (
+		trades []orderbook.Trade
+		seen   = make(map[*orderbook.Trade]bool)
+	)
+	for _, u := range updates {
+		if u.Trade != nil && !seen[u.Trade] {
+			seen[u.Trade] = true
+			trades = append(trades, *u.Trade)
+		}
+	}
+	return trades
+}
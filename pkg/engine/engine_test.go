@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+func newOrder(t *testing.T, side order.Side, symbol string, price, quantity float64) *order.Order {
+	t.Helper()
+
+	o, err := order.NewOrder(side, symbol, price, quantity)
+	require.NoError(t, err)
+	return o
+}
+
+func TestEngine_Symbols(t *testing.T) {
+	e := New()
+	require.NoError(t, e.AddSymbol(orderbook.SymbolConfig{Symbol: "ETH-USD"}))
+	require.NoError(t, e.AddSymbol(orderbook.SymbolConfig{Symbol: "BTC-USD"}))
+	assert.ErrorIs(t, e.AddSymbol(orderbook.SymbolConfig{Symbol: "BTC-USD"}), ErrSymbolExists)
+	assert.ErrorIs(t, e.AddSymbol(orderbook.SymbolConfig{}), orderbook.ErrInvalidSymbol)
+	assert.Equal(t, []string{"BTC-USD", "ETH-USD"}, e.Symbols())
+
+	_, err := e.Submit(newOrder(t, order.SideBuy, "SOL-USD", 10, 1))
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+	assert.ErrorIs(t, e.Cancel("SOL-USD", "1"), ErrSymbolNotFound)
+	_, err = e.Snapshot("SOL-USD")
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+}
+
+func TestEngine_SubmitReturnsItsOwnTrades(t *testing.T) {
+	var (
+		mutex   sync.Mutex
+		updates []orderbook.Update
+	)
+	e := New(WithUpdateListener(func(u []orderbook.Update) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		updates = append(updates, u...)
+	}))
+	require.NoError(t, e.AddSymbol(orderbook.SymbolConfig{Symbol: "BTC-USD"}))
+
+	// Concurrent takers each get back only the trades they made
+	const takers = 20
+	for i := 0; i < takers; i++ {
+		_, err := e.Submit(newOrder(t, order.SideSell, "BTC-USD", 100, 1))
+		require.NoError(t, err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < takers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bid, err := order.NewOrder(order.SideBuy, "BTC-USD", 100, 1)
+			if !assert.NoError(t, err) {
+				return
+			}
+			trades, err := e.Submit(bid)
+			assert.NoError(t, err)
+			if assert.Len(t, trades, 1) {
+				assert.Equal(t, bid.ID, trades[0].TakerOrderID)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Each ask resting, then each taker arriving and both sides filling
+	assert.Equal(t, takers+3*takers, len(updates))
+	snapshot, err := e.Snapshot("BTC-USD")
+	require.NoError(t, err)
+	assert.Empty(t, snapshot.Asks)
+	assert.Empty(t, snapshot.Bids)
+}
+
+func TestEngine_ExpireOrders(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := New(WithClock(func() time.Time { return now }))
+	require.NoError(t, e.AddSymbol(orderbook.SymbolConfig{Symbol: "BTC-USD", OrderTTL: time.Minute}))
+
+	bid := newOrder(t, order.SideBuy, "BTC-USD", 100, 1)
+	bid.CreatedAt = now
+	_, err := e.Submit(bid)
+	require.NoError(t, err)
+
+	resting, err := e.Order("BTC-USD", bid.ID)
+	require.NoError(t, err)
+	assert.Equal(t, order.StatusNew, resting.Status)
+
+	now = now.Add(time.Minute)
+	expired := e.ExpireOrders()
+	require.Len(t, expired, 1)
+	assert.Equal(t, bid.ID, expired[0].ID)
+
+	_, err = e.Order("BTC-USD", bid.ID)
+	assert.ErrorIs(t, err, orderbook.ErrOrderNotFound)
+}
@@ -0,0 +1,61 @@
+package engine_test
+
+import (
+	"fmt"
+
+	"company.com/matchengine/pkg/engine"
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+func Example() {
+	e := engine.New()
+	if err := e.AddSymbol(orderbook.SymbolConfig{Symbol: "BTC-USD"}); err != nil {
+		panic(err)
+	}
+
+	// Two asks rest on the book
+	for _, price := range []float64{101, 100} {
+		ask, err := order.NewOrder(order.SideSell, "BTC-USD", price, 1)
+		if err != nil {
+			panic(err)
+		}
+		if _, err := e.Submit(ask); err != nil {
+			panic(err)
+		}
+	}
+
+	// A bid for more than the best ask sweeps it and the next one, and the
+	// rest of it stays on the book
+	bid, err := order.NewOrder(order.SideBuy, "BTC-USD", 101, 3)
+	if err != nil {
+		panic(err)
+	}
+	trades, err := e.Submit(bid)
+	if err != nil {
+		panic(err)
+	}
+	for _, trade := range trades {
+		fmt.Printf("traded %v at %v\n", trade.Quantity, trade.Price)
+	}
+	fmt.Println("bid:", bid.Status, bid.Filled)
+
+	snapshot, err := e.Snapshot("BTC-USD")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("book: %d bid level(s), %d ask level(s)\n", len(snapshot.Bids), len(snapshot.Asks))
+
+	if err := e.Cancel("BTC-USD", bid.ID); err != nil {
+		panic(err)
+	}
+	_, err = e.Order("BTC-USD", bid.ID)
+	fmt.Println(err != nil)
+
+	// Output:
+	// traded 1 at 100
+	// traded 1 at 101
+	// bid: partial 2
+	// book: 1 bid level(s), 0 ask level(s)
+	// true
+}
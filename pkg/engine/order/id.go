@@ -0,0 +1,86 @@
+package order
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IDGenerator produces order IDs. Implementations must be safe for
+// concurrent use and never return the same ID twice.
+type IDGenerator interface {
+	NewID() string
+}
+
+// UUIDGenerator generates random UUIDs. It is the default.
+type UUIDGenerator struct{}
+
+func (UUIDGenerator) NewID() string {
+	return uuid.New().String()
+}
+
+// SequentialGenerator generates IDs that sort in the order they were
+// generated, as strings and as creation order: a millisecond timestamp and a
+// counter within it, both fixed-width hex, such as "018f4c2a9b10-00000003".
+// A clock that steps back doesn't break the order; the IDs keep counting
+// from the last timestamp until the clock catches up.
+type SequentialGenerator struct {
+	now   func() time.Time
+	mutex sync.Mutex
+	ms    int64
+	seq   uint32
+}
+
+// NewSequentialGenerator creates a SequentialGenerator reading the given
+// clock, or time.Now when nil
+func NewSequentialGenerator(now func() time.Time) *SequentialGenerator {
+	if now == nil {
+		now = time.Now
+	}
+	return &SequentialGenerator{now: now, ms: -1}
+}
+
+func (g *SequentialGenerator) NewID() string {
+	ms := g.now().UnixMilli()
+
+	g.mutex.Lock()
+	switch {
+	case ms > g.ms:
+		g.ms, g.seq = ms, 0
+	case g.seq == ^uint32(0):
+		g.ms, g.seq = g.ms+1, 0
+	default:
+		g.seq++
+	}
+	ms, seq := g.ms, g.seq
+	g.mutex.Unlock()
+
+	return fmt.Sprintf("%012x-%08x", ms, seq)
+}
+
+var (
+	idGenerator      IDGenerator = UUIDGenerator{}
+	idGeneratorMutex sync.RWMutex
+)
+
+// SetIDGenerator replaces the generator new orders get their IDs from; nil
+// restores the UUIDGenerator
+func SetIDGenerator(g IDGenerator) {
+	idGeneratorMutex.Lock()
+	defer idGeneratorMutex.Unlock()
+
+	if g == nil {
+		g = UUIDGenerator{}
+	}
+	idGenerator = g
+}
+
+func generateOrderID() string {
+	idGeneratorMutex.RLock()
+	g := idGenerator
+	idGeneratorMutex.RUnlock()
+
+	return g.NewID()
+}
@@ -0,0 +1,79 @@
+package order
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSequentialGenerator_Monotonic(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := NewSequentialGenerator(func() time.Time { return now })
+
+	first := g.NewID()
+	assert.Equal(t, "018cc251f400-00000000", first)
+
+	// Within a millisecond, across milliseconds and with the clock stepping
+	// back, every ID sorts after the one before
+	var ids []string
+	prev := first
+	for _, step := range []time.Duration{0, 0, time.Millisecond, 0, -time.Second, 0, 2 * time.Second} {
+		now = now.Add(step)
+		id := g.NewID()
+		assert.Greater(t, id, prev, "after a %s step", step)
+		ids = append(ids, id)
+		prev = id
+	}
+	assert.Equal(t, "018cc251f401-00000000", ids[2])
+	assert.Equal(t, "018cc251f401-00000003", ids[5], "a clock step back keeps counting")
+}
+
+func TestSequentialGenerator_Concurrent(t *testing.T) {
+	g := NewSequentialGenerator(nil)
+
+	const workers, perWorker = 8, 2000
+	results := make([][]string, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				results[w] = append(results[w], g.NewID())
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool, workers*perWorker)
+	for _, ids := range results {
+		for i, id := range ids {
+			require.False(t, seen[id], "duplicate ID %s", id)
+			seen[id] = true
+			if i > 0 {
+				require.Greater(t, id, ids[i-1])
+			}
+		}
+	}
+}
+
+func TestSetIDGenerator(t *testing.T) {
+	t.Cleanup(func() { SetIDGenerator(nil) })
+
+	SetIDGenerator(NewSequentialGenerator(nil))
+	a, err := NewOrder(SideBuy, "BTC-USD", 100, 1)
+	require.NoError(t, err)
+	b, err := NewQuoteMarketBuy("BTC-USD", 100)
+	require.NoError(t, err)
+	assert.Greater(t, b.ID, a.ID)
+
+	SetIDGenerator(nil)
+	c, err := NewOrder(SideBuy, "BTC-USD", 100, 1)
+	require.NoError(t, err)
+	_, err = uuid.Parse(c.ID)
+	assert.NoError(t, err)
+}
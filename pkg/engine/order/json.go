@@ -0,0 +1,200 @@
+package order
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Precision is the number of decimals a symbol's prices and quantities are
+// written with in JSON
+type Precision struct {
+	Price    int `json:"price"`
+	Quantity int `json:"quantity"`
+}
+
+// DefaultPrecision applies to symbols without a registered precision
+var DefaultPrecision = Precision{Price: 8, Quantity: 8}
+
+var (
+	precisions     = make(map[string]Precision)
+	precisionMutex sync.RWMutex
+)
+
+// SetPrecision registers the JSON precision for a symbol
+func SetPrecision(symbol string, precision Precision) {
+	precisionMutex.Lock()
+	defer precisionMutex.Unlock()
+
+	precisions[symbol] = precision
+}
+
+// PrecisionFor returns the JSON precision registered for a symbol
+func PrecisionFor(symbol string) Precision {
+	precisionMutex.RLock()
+	defer precisionMutex.RUnlock()
+
+	if precision, exists := precisions[symbol]; exists {
+		return precision
+	}
+	return DefaultPrecision
+}
+
+// Decimal is a float64 written to JSON as a fixed-decimal string, so tiny
+// or huge values never show up in scientific notation. It reads back both
+// strings and plain JSON numbers, and leaves the value alone on null.
+type Decimal struct {
+	Value  float64
+	Places int
+}
+
+func (d Decimal) String() string {
+	return strconv.FormatFloat(d.Value, 'f', d.Places, 64)
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return strconv.AppendQuote(nil, d.String()), nil
+}
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	data = bytes.Trim(data, `"`)
+	value, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fmt.Errorf("invalid decimal %s", data)
+	}
+	d.Value = value
+	return nil
+}
+
+// orderJSON is the wire format of an Order, in JSON and MessagePack alike
+type orderJSON struct {
+	ID             string     `json:"id" msgpack:"id"`
+	ClientOrderID  string     `json:"client_order_id,omitempty" msgpack:"client_order_id,omitempty"`
+	AccountID      string     `json:"account_id,omitempty" msgpack:"account_id,omitempty"`
+	Type           Type       `json:"type" msgpack:"type"`
+	Side           Side       `json:"side" msgpack:"side"`
+	Symbol         string     `json:"symbol" msgpack:"symbol"`
+	Price          Decimal    `json:"price" msgpack:"price"`
+	StopPrice      *Decimal   `json:"stop_price,omitempty" msgpack:"stop_price,omitempty"`
+	Quantity       Decimal    `json:"quantity" msgpack:"quantity"`
+	QuoteQuantity  *Decimal   `json:"quote_quantity,omitempty" msgpack:"quote_quantity,omitempty"`
+	ReduceOnly     bool       `json:"reduce_only,omitempty" msgpack:"reduce_only,omitempty"`
+	Filled         Decimal    `json:"filled" msgpack:"filled"`
+	Remaining      Decimal    `json:"remaining" msgpack:"remaining"`
+	FilledNotional *Decimal   `json:"filled_notional,omitempty" msgpack:"filled_notional,omitempty"`
+	AvgFillPrice   *Decimal   `json:"avg_fill_price,omitempty" msgpack:"avg_fill_price,omitempty"`
+	Status         Status     `json:"status" msgpack:"status"`
+	CreatedAt      time.Time  `json:"created_at" msgpack:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" msgpack:"updated_at"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty" msgpack:"expires_at,omitempty"`
+	CallbackURL    string     `json:"callback_url,omitempty" msgpack:"callback_url,omitempty"`
+	// MatchLatencyMicros is written as a plain number: it is a measurement,
+	// not an amount
+	MatchLatencyMicros float64 `json:"match_latency_micros,omitempty" msgpack:"match_latency_micros,omitempty"`
+}
+
+// MarshalJSON writes prices and quantities as fixed-decimal strings using
+// the symbol's precision, along with the remaining quantity
+func (o Order) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.wire())
+}
+
+// wire converts the order to its wire format, shared by every encoding
+func (o Order) wire() orderJSON {
+	precision := PrecisionFor(o.Symbol)
+	price := func(v float64) Decimal { return Decimal{Value: v, Places: precision.Price} }
+	quantity := func(v float64) Decimal { return Decimal{Value: v, Places: precision.Quantity} }
+
+	wire := orderJSON{
+		ID:            o.ID,
+		ClientOrderID: o.ClientOrderID,
+		AccountID:     o.AccountID,
+		Type:          o.Type,
+		Side:          o.Side,
+		Symbol:        o.Symbol,
+		Price:         price(o.Price),
+		Quantity:      quantity(o.Quantity),
+		Filled:        quantity(o.Filled),
+		Remaining:     quantity(o.RemainingQuantity()),
+		ReduceOnly:    o.ReduceOnly,
+		CallbackURL:   o.CallbackURL,
+		Status:        o.Status,
+		CreatedAt:     o.CreatedAt,
+		UpdatedAt:     o.UpdatedAt,
+
+		MatchLatencyMicros: o.MatchLatencyMicros,
+	}
+	if o.StopPrice != 0 {
+		stopPrice := price(o.StopPrice)
+		wire.StopPrice = &stopPrice
+	}
+	if o.QuoteQuantity != 0 {
+		quoteQuantity := price(o.QuoteQuantity)
+		wire.QuoteQuantity = &quoteQuantity
+	}
+	if !o.ExpiresAt.IsZero() {
+		expiresAt := o.ExpiresAt
+		wire.ExpiresAt = &expiresAt
+	}
+	if o.Filled != 0 {
+		filledNotional := price(o.FilledNotional)
+		avgFillPrice := price(o.AvgFillPrice)
+		wire.FilledNotional, wire.AvgFillPrice = &filledNotional, &avgFillPrice
+	}
+	return wire
+}
+
+func (o *Order) UnmarshalJSON(data []byte) error {
+	var wire orderJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	*o = wire.order()
+	return nil
+}
+
+// order converts the wire format back to an order
+func (wire orderJSON) order() Order {
+	o := Order{
+		ID:            wire.ID,
+		ClientOrderID: wire.ClientOrderID,
+		AccountID:     wire.AccountID,
+		Type:          wire.Type,
+		Side:          wire.Side,
+		Symbol:        wire.Symbol,
+		Price:         wire.Price.Value,
+		Quantity:      wire.Quantity.Value,
+		Filled:        wire.Filled.Value,
+		ReduceOnly:    wire.ReduceOnly,
+		CallbackURL:   wire.CallbackURL,
+		Status:        wire.Status,
+		CreatedAt:     wire.CreatedAt,
+		UpdatedAt:     wire.UpdatedAt,
+
+		MatchLatencyMicros: wire.MatchLatencyMicros,
+	}
+	if wire.StopPrice != nil {
+		o.StopPrice = wire.StopPrice.Value
+	}
+	if wire.QuoteQuantity != nil {
+		o.QuoteQuantity = wire.QuoteQuantity.Value
+	}
+	if wire.FilledNotional != nil {
+		o.FilledNotional = wire.FilledNotional.Value
+	}
+	if wire.AvgFillPrice != nil {
+		o.AvgFillPrice = wire.AvgFillPrice.Value
+	}
+	if wire.ExpiresAt != nil {
+		o.ExpiresAt = *wire.ExpiresAt
+	}
+	return o
+}
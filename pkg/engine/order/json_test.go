@@ -0,0 +1,111 @@
+package order
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func marshalFields(t *testing.T, o *Order) map[string]any {
+	t.Helper()
+
+	data, err := json.Marshal(o)
+	require.NoError(t, err)
+
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(data, &fields))
+	return fields
+}
+
+func TestOrderJSON_FixedDecimals(t *testing.T) {
+	tests := []struct {
+		name     string
+		price    float64
+		quantity float64
+		want     map[string]any
+	}{
+		{
+			name:     "tiny values",
+			price:    0.00000001,
+			quantity: 0.00000003,
+			want:     map[string]any{"price": "0.00000001", "quantity": "0.00000003", "remaining": "0.00000003"},
+		},
+		{
+			name:     "large values",
+			price:    123456789012.5,
+			quantity: 1e9,
+			want:     map[string]any{"price": "123456789012.50000000", "quantity": "1000000000.00000000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := NewOrder(SideBuy, "JSON-DEFAULT", tt.price, tt.quantity)
+			require.NoError(t, err)
+
+			fields := marshalFields(t, o)
+			for key, want := range tt.want {
+				assert.Equal(t, want, fields[key], key)
+			}
+			assert.Equal(t, "0.00000000", fields["filled"])
+			assert.NotContains(t, fields, "stop_price")
+			assert.NotContains(t, fields, "quote_quantity")
+		})
+	}
+}
+
+func TestOrderJSON_SymbolPrecision(t *testing.T) {
+	SetPrecision("JSON-USD", Precision{Price: 2, Quantity: 4})
+
+	o, err := NewOrderOfType(TypeStopLimit, SideSell, "JSON-USD", 50000.126, 49000, 0.5, false)
+	require.NoError(t, err)
+
+	fields := marshalFields(t, o)
+	assert.Equal(t, "50000.13", fields["price"])
+	assert.Equal(t, "49000.00", fields["stop_price"])
+	assert.Equal(t, "0.5000", fields["quantity"])
+	assert.Equal(t, Precision{Price: 2, Quantity: 4}, PrecisionFor("JSON-USD"))
+	assert.Equal(t, DefaultPrecision, PrecisionFor("JSON-UNKNOWN"))
+}
+
+func TestOrderJSON_RoundTrip(t *testing.T) {
+	o, err := NewOrder(SideBuy, "JSON-DEFAULT", 0.00012345, 42)
+	require.NoError(t, err)
+	o.ClientOrderID = "client-1"
+	o.AccountID = "alice"
+	require.NoError(t, o.Fill(2.5, 0.00012, false))
+
+	data, err := json.Marshal(o)
+	require.NoError(t, err)
+
+	var decoded Order
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, o.ID, decoded.ID)
+	assert.Equal(t, o.ClientOrderID, decoded.ClientOrderID)
+	assert.Equal(t, o.AccountID, decoded.AccountID)
+	assert.Equal(t, o.Price, decoded.Price)
+	assert.Equal(t, o.Quantity, decoded.Quantity)
+	assert.Equal(t, o.Filled, decoded.Filled)
+	assert.InDelta(t, o.FilledNotional, decoded.FilledNotional, 1e-12)
+	assert.InDelta(t, o.AvgFillPrice, decoded.AvgFillPrice, 1e-12)
+	assert.Equal(t, o.Status, decoded.Status)
+	assert.True(t, o.CreatedAt.Equal(decoded.CreatedAt))
+
+	quote, err := NewQuoteMarketBuy("JSON-DEFAULT", 10000)
+	require.NoError(t, err)
+	data, err = json.Marshal(quote)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, 10000.0, decoded.QuoteQuantity)
+}
+
+func TestOrderJSON_UnmarshalNumbers(t *testing.T) {
+	var o Order
+	require.NoError(t, json.Unmarshal([]byte(`{"symbol":"BTC-USD","price":100.5,"quantity":"2","filled":0}`), &o))
+	assert.Equal(t, 100.5, o.Price)
+	assert.Equal(t, 2.0, o.Quantity)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"price":"abc"}`), &o))
+}
@@ -0,0 +1,54 @@
+package order
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// EncodeMsgpack writes the decimal as the same fixed-decimal string used in JSON
+func (d Decimal) EncodeMsgpack(enc *msgpack.Encoder) error {
+	return enc.EncodeString(d.String())
+}
+
+// DecodeMsgpack reads a decimal written as a string or a number
+func (d *Decimal) DecodeMsgpack(dec *msgpack.Decoder) error {
+	v, err := dec.DecodeInterfaceLoose()
+	if err != nil {
+		return err
+	}
+
+	switch v := v.(type) {
+	case string:
+		value, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid decimal %s", v)
+		}
+		d.Value = value
+	case float64:
+		d.Value = v
+	case int64:
+		d.Value = float64(v)
+	case uint64:
+		d.Value = float64(v)
+	default:
+		return fmt.Errorf("invalid decimal %v", v)
+	}
+	return nil
+}
+
+// EncodeMsgpack writes the order in the same shape as its JSON
+func (o Order) EncodeMsgpack(enc *msgpack.Encoder) error {
+	return enc.Encode(o.wire())
+}
+
+func (o *Order) DecodeMsgpack(dec *msgpack.Decoder) error {
+	var wire orderJSON
+	if err := dec.Decode(&wire); err != nil {
+		return err
+	}
+
+	*o = wire.order()
+	return nil
+}
@@ -0,0 +1,305 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"time"
+)
+
+// Side represents the order side (buy/sell)
+type Side string
+
+// Status represents the order status
+type Status string
+
+// Type represents the order type
+type Type string
+
+// Constants for order sides
+const (
+	SideBuy  Side = "buy"
+	SideSell Side = "sell"
+)
+
+// Constants for order statuses
+const (
+	StatusNew       Status = "new"
+	StatusFilled    Status = "filled"
+	StatusCancelled Status = "cancelled"
+	StatusPartial   Status = "partial"
+)
+
+// Constants for order types
+const (
+	TypeLimit     Type = "limit"
+	TypeMarket    Type = "market"
+	TypeStop      Type = "stop"
+	TypeStopLimit Type = "stop-limit"
+)
+
+// ErrOrderNotCancellable is returned when cancelling an order that is
+// already filled or cancelled
+var ErrOrderNotCancellable = errors.New("order not cancellable")
+
+// ErrOrderChanged is returned when a conditional operation names an ETag
+// the order no longer has
+var ErrOrderChanged = errors.New("order changed")
+
+// Order represents a trading order
+type Order struct {
+	ID            string  `json:"id"`
+	ClientOrderID string  `json:"client_order_id,omitempty"`
+	AccountID     string  `json:"account_id,omitempty"`
+	Type          Type    `json:"type"`
+	Side          Side    `json:"side"`
+	Symbol        string  `json:"symbol"`
+	Price         float64 `json:"price"`
+	StopPrice     float64 `json:"stop_price,omitempty"`
+	Quantity      float64 `json:"quantity"`
+	QuoteQuantity float64 `json:"quote_quantity,omitempty"`
+	// ReduceOnly orders may only shrink the account's position, never open
+	// or flip it
+	ReduceOnly bool    `json:"reduce_only,omitempty"`
+	Filled     float64 `json:"filled"`
+	// FilledNotional is the quote value of the fills so far, each fill's
+	// quantity times its price
+	FilledNotional float64 `json:"filled_notional,omitempty"`
+	// AvgFillPrice is the volume-weighted average price of the fills so far
+	AvgFillPrice float64   `json:"avg_fill_price,omitempty"`
+	Status       Status    `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	// ExpiresAt, when set, is when the order is cancelled if it is still
+	// resting; the zero time means good till cancelled
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	// CallbackURL, when set, is where a notification is POSTed each time
+	// the order fills or partially fills
+	CallbackURL string `json:"callback_url,omitempty"`
+	// MatchLatencyMicros is how long the book took to take the order in
+	// and match it on arrival, in microseconds on the monotonic clock. It
+	// starts once the book's lock is held, so neither waiting for the lock
+	// nor any queue in front of the book is counted.
+	MatchLatencyMicros float64 `json:"match_latency_micros,omitempty"`
+}
+
+// NewOrder creates a new limit order instance with a positive price
+func NewOrder(side Side, symbol string, price, quantity float64) (*Order, error) {
+	return NewOrderOfType(TypeLimit, side, symbol, price, 0, quantity, false)
+}
+
+// NewOrderOfType creates a new order of the given type, rejecting price and
+// stop price combinations the type doesn't allow. Pass zero for prices the
+// type doesn't use. allowNegative accepts zero and negative prices, as the
+// symbol's configuration allows for spreads and some energy products.
+func NewOrderOfType(orderType Type, side Side, symbol string, price, stopPrice, quantity float64, allowNegative bool) (*Order, error) {
+	if side != SideBuy && side != SideSell {
+		return nil, fmt.Errorf("invalid side: %s", side)
+	}
+	if err := validateFinite(price, stopPrice, quantity); err != nil {
+		return nil, err
+	}
+	if err := validateType(orderType, price, stopPrice, allowNegative); err != nil {
+		return nil, err
+	}
+	if quantity <= 0 {
+		return nil, fmt.Errorf("quantity must be positive")
+	}
+
+	now := time.Now()
+	return &Order{
+		ID:        generateOrderID(),
+		Type:      orderType,
+		Side:      side,
+		Symbol:    symbol,
+		Price:     price,
+		StopPrice: stopPrice,
+		Quantity:  quantity,
+		Filled:    0,
+		Status:    StatusNew,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// NewQuoteMarketBuy creates a market buy that spends up to quoteQuantity of
+// the quote currency instead of buying a fixed base quantity. Its Quantity
+// starts at zero and grows with each fill, so once matched it reports the
+// base quantity bought.
+func NewQuoteMarketBuy(symbol string, quoteQuantity float64) (*Order, error) {
+	if !isFinite(quoteQuantity) {
+		return nil, fmt.Errorf("quote quantity must be a finite number")
+	}
+	if quoteQuantity <= 0 {
+		return nil, fmt.Errorf("quote quantity must be positive")
+	}
+
+	now := time.Now()
+	return &Order{
+		ID:            generateOrderID(),
+		Type:          TypeMarket,
+		Side:          SideBuy,
+		Symbol:        symbol,
+		QuoteQuantity: quoteQuantity,
+		Status:        StatusNew,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+// validateFinite rejects NaN and infinite values, which compare false
+// against everything and would slip past the range checks
+func validateFinite(price, stopPrice, quantity float64) error {
+	if !isFinite(price) {
+		return fmt.Errorf("price must be a finite number")
+	}
+	if !isFinite(stopPrice) {
+		return fmt.Errorf("stop price must be a finite number")
+	}
+	if !isFinite(quantity) {
+		return fmt.Errorf("quantity must be a finite number")
+	}
+	return nil
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// ValidatePrices checks the order's price and stop price against its type,
+// as NewOrderOfType does
+func (o *Order) ValidatePrices(allowNegative bool) error {
+	return validateType(o.Type, o.Price, o.StopPrice, allowNegative)
+}
+
+// validPrice reports whether price is acceptable as a limit, stop or fill
+// price. NaN and infinite prices are rejected separately.
+func validPrice(price float64, allowNegative bool) bool {
+	return price > 0 || allowNegative
+}
+
+func validateType(orderType Type, price, stopPrice float64, allowNegative bool) error {
+	switch orderType {
+	case TypeLimit:
+		if !validPrice(price, allowNegative) {
+			return fmt.Errorf("price must be positive")
+		}
+		if stopPrice != 0 {
+			return fmt.Errorf("limit orders must not have a stop price")
+		}
+	case TypeMarket:
+		if price != 0 {
+			return fmt.Errorf("market orders must not have a price")
+		}
+		if stopPrice != 0 {
+			return fmt.Errorf("market orders must not have a stop price")
+		}
+	case TypeStop:
+		if price != 0 {
+			return fmt.Errorf("stop orders must not have a price")
+		}
+		if stopPrice == 0 || !validPrice(stopPrice, allowNegative) {
+			return fmt.Errorf("stop orders must have a stop price")
+		}
+	case TypeStopLimit:
+		if !validPrice(price, allowNegative) {
+			return fmt.Errorf("stop-limit orders must have a price")
+		}
+		if stopPrice == 0 || !validPrice(stopPrice, allowNegative) {
+			return fmt.Errorf("stop-limit orders must have a stop price")
+		}
+	default:
+		return fmt.Errorf("unsupported order type: %s", orderType)
+	}
+	return nil
+}
+
+// ValidateFill reports whether Fill would accept quantity at price, without
+// changing the order
+func (o *Order) ValidateFill(quantity, price float64, allowNegative bool) error {
+	if !isFinite(quantity) || quantity <= 0 {
+		return fmt.Errorf("fill quantity must be positive")
+	}
+	if !isFinite(price) || !validPrice(price, allowNegative) {
+		return fmt.Errorf("fill price must be positive")
+	}
+	if o.Status == StatusCancelled {
+		return fmt.Errorf("cannot fill cancelled order")
+	}
+	if o.Filled+quantity > o.Quantity {
+		return fmt.Errorf("fill amount exceeds order quantity")
+	}
+	return nil
+}
+
+// Fill records an execution of quantity at price, updating the filled
+// quantity, average fill price and status. A rejected fill leaves the order
+// untouched. allowNegative accepts zero and negative fill prices.
+func (o *Order) Fill(quantity, price float64, allowNegative bool) error {
+	if err := o.ValidateFill(quantity, price, allowNegative); err != nil {
+		return err
+	}
+
+	o.Filled += quantity
+	o.FilledNotional += price * quantity
+	o.AvgFillPrice = o.FilledNotional / o.Filled
+	o.UpdatedAt = time.Now()
+
+	if o.Filled == o.Quantity {
+		o.Status = StatusFilled
+	} else {
+		o.Status = StatusPartial
+	}
+	return nil
+}
+
+// Cancel marks the order as cancelled
+func (o *Order) Cancel() error {
+	if !o.IsActive() {
+		return fmt.Errorf("%w: order is %s", ErrOrderNotCancellable, o.Status)
+	}
+	o.Status = StatusCancelled
+	o.UpdatedAt = time.Now()
+	return nil
+}
+
+// Amend changes the order's price and total quantity. allowNegative accepts
+// a zero or negative price.
+func (o *Order) Amend(price, quantity float64, allowNegative bool) error {
+	if !o.IsActive() {
+		return fmt.Errorf("cannot amend inactive order")
+	}
+	if err := validateFinite(price, 0, quantity); err != nil {
+		return err
+	}
+	if !validPrice(price, allowNegative) {
+		return fmt.Errorf("price must be positive")
+	}
+	if quantity <= o.Filled {
+		return fmt.Errorf("quantity must exceed filled quantity")
+	}
+
+	o.Price = price
+	o.Quantity = quantity
+	o.UpdatedAt = time.Now()
+	return nil
+}
+
+// ETag identifies the order's current state: it changes whenever the order
+// fills, is amended or cancelled
+func (o *Order) ETag() string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s|%s|%v|%v|%v|%d", o.ID, o.Status, o.Price, o.Quantity, o.Filled, o.UpdatedAt.UnixNano())
+	return fmt.Sprintf(`"%016x"`, h.Sum64())
+}
+
+// RemainingQuantity returns the unfilled quantity
+func (o *Order) RemainingQuantity() float64 {
+	return o.Quantity - o.Filled
+}
+
+// IsActive returns whether the order is still active
+func (o *Order) IsActive() bool {
+	return o.Status != StatusFilled && o.Status != StatusCancelled
+}
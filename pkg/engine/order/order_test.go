@@ -0,0 +1,206 @@
+package order
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOrderOfType(t *testing.T) {
+	tests := []struct {
+		name      string
+		orderType Type
+		side      Side
+		price     float64
+		stopPrice float64
+		quantity  float64
+		wantErr   string
+	}{
+		{name: "limit", orderType: TypeLimit, side: SideBuy, price: 100, quantity: 1},
+		{name: "limit without price", orderType: TypeLimit, side: SideBuy, quantity: 1, wantErr: "price must be positive"},
+		{name: "limit with stop price", orderType: TypeLimit, side: SideBuy, price: 100, stopPrice: 90, quantity: 1, wantErr: "limit orders must not have a stop price"},
+
+		{name: "market", orderType: TypeMarket, side: SideSell, quantity: 1},
+		{name: "market with price", orderType: TypeMarket, side: SideSell, price: 100, quantity: 1, wantErr: "market orders must not have a price"},
+		{name: "market with stop price", orderType: TypeMarket, side: SideSell, stopPrice: 90, quantity: 1, wantErr: "market orders must not have a stop price"},
+
+		{name: "stop", orderType: TypeStop, side: SideSell, stopPrice: 90, quantity: 1},
+		{name: "stop without stop price", orderType: TypeStop, side: SideSell, quantity: 1, wantErr: "stop orders must have a stop price"},
+		{name: "stop with price", orderType: TypeStop, side: SideSell, price: 100, stopPrice: 90, quantity: 1, wantErr: "stop orders must not have a price"},
+
+		{name: "stop-limit", orderType: TypeStopLimit, side: SideBuy, price: 101, stopPrice: 100, quantity: 1},
+		{name: "stop-limit without price", orderType: TypeStopLimit, side: SideBuy, stopPrice: 100, quantity: 1, wantErr: "stop-limit orders must have a price"},
+		{name: "stop-limit without stop price", orderType: TypeStopLimit, side: SideBuy, price: 101, quantity: 1, wantErr: "stop-limit orders must have a stop price"},
+
+		{name: "unknown type", orderType: "iceberg", side: SideBuy, price: 100, quantity: 1, wantErr: "unsupported order type: iceberg"},
+		{name: "invalid side", orderType: TypeLimit, side: "hold", price: 100, quantity: 1, wantErr: "invalid side: hold"},
+		{name: "non-positive quantity", orderType: TypeMarket, side: SideBuy, quantity: 0, wantErr: "quantity must be positive"},
+
+		{name: "NaN price", orderType: TypeLimit, side: SideBuy, price: math.NaN(), quantity: 1, wantErr: "price must be a finite number"},
+		{name: "infinite price", orderType: TypeLimit, side: SideBuy, price: math.Inf(1), quantity: 1, wantErr: "price must be a finite number"},
+		{name: "negative infinite price", orderType: TypeLimit, side: SideSell, price: math.Inf(-1), quantity: 1, wantErr: "price must be a finite number"},
+		{name: "NaN stop price", orderType: TypeStop, side: SideSell, stopPrice: math.NaN(), quantity: 1, wantErr: "stop price must be a finite number"},
+		{name: "NaN quantity", orderType: TypeLimit, side: SideBuy, price: 100, quantity: math.NaN(), wantErr: "quantity must be a finite number"},
+		{name: "infinite quantity", orderType: TypeMarket, side: SideBuy, quantity: math.Inf(1), wantErr: "quantity must be a finite number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := NewOrderOfType(tt.orderType, tt.side, "BTC-USD", tt.price, tt.stopPrice, tt.quantity, false)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, o)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.orderType, o.Type)
+			assert.Equal(t, tt.price, o.Price)
+			assert.Equal(t, tt.stopPrice, o.StopPrice)
+		})
+	}
+}
+
+func TestNewOrder_IsLimit(t *testing.T) {
+	o, err := NewOrder(SideBuy, "BTC-USD", 100, 1)
+	require.NoError(t, err)
+	assert.Equal(t, TypeLimit, o.Type)
+}
+
+func TestNewQuoteMarketBuy(t *testing.T) {
+	o, err := NewQuoteMarketBuy("BTC-USD", 10000)
+	require.NoError(t, err)
+	assert.Equal(t, TypeMarket, o.Type)
+	assert.Equal(t, SideBuy, o.Side)
+	assert.Equal(t, 10000.0, o.QuoteQuantity)
+	assert.Zero(t, o.Quantity)
+
+	_, err = NewQuoteMarketBuy("BTC-USD", 0)
+	assert.EqualError(t, err, "quote quantity must be positive")
+}
+
+func TestCancel_TerminalOrders(t *testing.T) {
+	filled, err := NewOrder(SideBuy, "BTC-USD", 100, 1)
+	require.NoError(t, err)
+	require.NoError(t, filled.Fill(1, 100, false))
+	assert.ErrorIs(t, filled.Cancel(), ErrOrderNotCancellable)
+
+	cancelled, err := NewOrder(SideBuy, "BTC-USD", 100, 1)
+	require.NoError(t, err)
+	require.NoError(t, cancelled.Cancel())
+	assert.ErrorIs(t, cancelled.Cancel(), ErrOrderNotCancellable)
+}
+
+func TestNonFiniteValues(t *testing.T) {
+	_, err := NewQuoteMarketBuy("BTC-USD", math.NaN())
+	assert.EqualError(t, err, "quote quantity must be a finite number")
+
+	o, err := NewOrder(SideBuy, "BTC-USD", 100, 1)
+	require.NoError(t, err)
+	assert.EqualError(t, o.Amend(math.Inf(1), 1, false), "price must be a finite number")
+	assert.EqualError(t, o.Amend(100, math.NaN(), false), "quantity must be a finite number")
+	assert.Error(t, o.Fill(math.NaN(), 100, false))
+	assert.Equal(t, 100.0, o.Price)
+	assert.Zero(t, o.Filled)
+}
+
+func TestNegativePrices(t *testing.T) {
+	_, err := NewOrder(SideBuy, "CAL-SPREAD", -1.5, 1)
+	assert.EqualError(t, err, "price must be positive")
+
+	o, err := NewOrderOfType(TypeLimit, SideBuy, "CAL-SPREAD", -1.5, 0, 1, true)
+	require.NoError(t, err)
+	assert.Equal(t, -1.5, o.Price)
+	_, err = NewOrderOfType(TypeLimit, SideSell, "CAL-SPREAD", 0, 0, 1, true)
+	assert.NoError(t, err)
+	_, err = NewOrderOfType(TypeStop, SideSell, "CAL-SPREAD", 0, -2, 1, true)
+	assert.NoError(t, err)
+	_, err = NewOrderOfType(TypeStop, SideSell, "CAL-SPREAD", 0, 0, 1, true)
+	assert.EqualError(t, err, "stop orders must have a stop price")
+
+	_, err = NewOrderOfType(TypeLimit, SideBuy, "CAL-SPREAD", math.NaN(), 0, 1, true)
+	assert.EqualError(t, err, "price must be a finite number")
+	_, err = NewOrderOfType(TypeLimit, SideBuy, "CAL-SPREAD", math.Inf(-1), 0, 1, true)
+	assert.EqualError(t, err, "price must be a finite number")
+
+	assert.EqualError(t, o.Amend(-2, 1, false), "price must be positive")
+	assert.Error(t, o.ValidateFill(0.5, -2.5, false))
+	assert.EqualError(t, o.ValidatePrices(false), "price must be positive")
+	assert.NoError(t, o.ValidatePrices(true))
+
+	require.NoError(t, o.Amend(-2, 1, true))
+	require.NoError(t, o.Fill(0.5, -2.5, true))
+	assert.Equal(t, -2.5, o.AvgFillPrice)
+	assert.Error(t, o.Fill(0.5, math.NaN(), true))
+}
+
+func TestFill_RejectedFillLeavesOrderUntouched(t *testing.T) {
+	o, err := NewOrder(SideBuy, "BTC-USD", 100, 1)
+	require.NoError(t, err)
+	require.NoError(t, o.Fill(0.4, 100, false))
+	updatedAt := o.UpdatedAt
+
+	assert.EqualError(t, o.Fill(0.7, 100, false), "fill amount exceeds order quantity")
+	assert.Equal(t, 0.4, o.Filled)
+	assert.Equal(t, StatusPartial, o.Status)
+	assert.Equal(t, updatedAt, o.UpdatedAt)
+
+	assert.Error(t, o.ValidateFill(0.7, 100, false))
+	assert.NoError(t, o.ValidateFill(0.6, 100, false))
+	require.NoError(t, o.Fill(0.6, 100, false))
+	assert.Equal(t, StatusFilled, o.Status)
+
+	assert.Error(t, o.Fill(0.1, 100, false))
+	assert.Equal(t, 1.0, o.Filled)
+}
+
+func TestFill_AvgFillPrice(t *testing.T) {
+	o, err := NewOrder(SideBuy, "BTC-USD", 101, 3)
+	require.NoError(t, err)
+	assert.Zero(t, o.AvgFillPrice)
+
+	// Fills across two price levels average by volume
+	require.NoError(t, o.Fill(1, 100, false))
+	assert.Equal(t, 100.0, o.AvgFillPrice)
+	require.NoError(t, o.Fill(2, 101, false))
+	assert.InDelta(t, (100*1+101*2)/3.0, o.AvgFillPrice, 1e-9)
+
+	// A rejected fill doesn't move the average
+	assert.Error(t, o.Fill(1, 50, false))
+	assert.Error(t, o.ValidateFill(0.5, math.Inf(1), false))
+	assert.InDelta(t, (100*1+101*2)/3.0, o.AvgFillPrice, 1e-9)
+}
+
+func TestFill_AccumulatesNotional(t *testing.T) {
+	o, err := NewOrder(SideSell, "BTC-USD", 99, 4)
+	require.NoError(t, err)
+
+	require.NoError(t, o.Fill(1.5, 100, false))
+	require.NoError(t, o.Fill(0.5, 102, false))
+	require.NoError(t, o.Fill(2, 99.5, false))
+
+	assert.InDelta(t, 1.5*100+0.5*102+2*99.5, o.FilledNotional, 1e-9)
+	assert.InDelta(t, o.FilledNotional/4, o.AvgFillPrice, 1e-9)
+	assert.Equal(t, StatusFilled, o.Status)
+
+	// Rejected fills add nothing
+	assert.Error(t, o.Fill(1, 100, false))
+	assert.InDelta(t, 1.5*100+0.5*102+2*99.5, o.FilledNotional, 1e-9)
+}
+
+func TestETag(t *testing.T) {
+	o, err := NewOrder(SideBuy, "BTC-USD", 100.0, 2.0)
+	require.NoError(t, err)
+
+	etag := o.ETag()
+	assert.Equal(t, etag, o.ETag())
+
+	require.NoError(t, o.Fill(0.5, 100.0, false))
+	filled := o.ETag()
+	assert.NotEqual(t, etag, filled)
+
+	require.NoError(t, o.Cancel())
+	assert.NotEqual(t, filled, o.ETag())
+}
@@ -0,0 +1,50 @@
+package order
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Rounding is how values with more decimals than a symbol's precision are
+// brought to its scale
+type Rounding string
+
+const (
+	// RoundHalfUp rounds to the nearest value, halves away from zero
+	RoundHalfUp Rounding = "half-up"
+	// RoundDown truncates the extra decimals
+	RoundDown Rounding = "down"
+)
+
+// Round brings value to the given number of decimals. It rounds the
+// shortest decimal that reads back as value, which is what the client
+// sent, rather than its binary approximation: 1.005 rounds half-up to 1.01
+// even though the float64 is slightly below it. The result is the float64
+// closest to the rounded decimal. An empty mode means RoundHalfUp.
+func Round(value float64, places int, mode Rounding) (float64, error) {
+	if mode != RoundHalfUp && mode != RoundDown && mode != "" {
+		return 0, fmt.Errorf("unknown rounding mode: %s", mode)
+	}
+	if places < 0 || math.IsNaN(value) || math.IsInf(value, 0) {
+		return value, nil
+	}
+
+	digits := strconv.FormatFloat(math.Abs(value), 'f', -1, 64)
+	whole, fraction, _ := strings.Cut(digits, ".")
+	if len(fraction) <= places {
+		return value, nil
+	}
+
+	// The value at scale as an integer, e.g. 1.005 at 2 places is 100
+	scaled, err := strconv.ParseFloat(whole+fraction[:places], 64)
+	if err != nil {
+		return 0, err
+	}
+	if mode != RoundDown && fraction[places] >= '5' {
+		scaled++
+	}
+
+	return math.Copysign(scaled/math.Pow10(places), value), nil
+}
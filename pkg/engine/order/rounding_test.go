@@ -0,0 +1,42 @@
+package order
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  float64
+		places int
+		mode   Rounding
+		want   float64
+	}{
+		{"half-up rounds up at the half", 1.005, 2, RoundHalfUp, 1.01},
+		{"half-up rounds down below the half", 1.0049, 2, RoundHalfUp, 1.0},
+		{"default is half-up", 0.125, 2, "", 0.13},
+		{"down truncates", 1.0099, 2, RoundDown, 1.0},
+		{"down keeps exact decimals", 0.29, 2, RoundDown, 0.29},
+		{"already at scale", 50000.5, 1, RoundDown, 50000.5},
+		{"zero places", 50000.7, 0, RoundHalfUp, 50001},
+		{"fine precision", 0.123456789, 8, RoundHalfUp, 0.12345679},
+		{"fine precision down", 0.123456789, 8, RoundDown, 0.12345678},
+		{"negative half-up away from zero", -1.005, 2, RoundHalfUp, -1.01},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Round(tt.value, tt.places, tt.mode)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRound_UnknownMode(t *testing.T) {
+	_, err := Round(1.5, 0, Rounding("banker"))
+	assert.ErrorContains(t, err, "unknown rounding mode")
+}
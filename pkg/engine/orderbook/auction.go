@@ -0,0 +1,190 @@
+package orderbook
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// AuctionResult descreve o resultado de um leilão
+type AuctionResult struct {
+	Symbol string `json:"symbol"`
+	// Price é o preço de equilíbrio; zero quando nenhuma ordem cruzou
+	Price    float64 `json:"price"`
+	Quantity float64 `json:"quantity"`
+	Trades   int     `json:"trades"`
+	// Imbalance é a quantidade que ficou sem contraparte no preço de
+	// equilíbrio, positiva do lado comprador e negativa do vendedor
+	Imbalance float64 `json:"imbalance"`
+}
+
+// PauseMatching inicia a fase de leilão: ordens limitadas passam a repousar
+// no livro sem casar, mesmo cruzando o lado oposto, até RunAuction. Ordens a
+// mercado são rejeitadas durante o leilão.
+func (ob *OrderBook) PauseMatching() {
+	ob.mutex.Lock()
+	defer ob.mutex.Unlock()
+
+	ob.auction = true
+}
+
+// InAuction indica se o livro está acumulando ordens para um leilão
+func (ob *OrderBook) InAuction() bool {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	return ob.auction
+}
+
+// RunAuction encerra a fase de leilão: calcula o preço de equilíbrio,
+// executa a esse preço todas as ordens que cruzam e retoma o matching
+// contínuo com o que sobrou no livro.
+func (ob *OrderBook) RunAuction() (result *AuctionResult, err error) {
+	ob.mutex.Lock()
+	defer ob.unlock()
+	defer ob.recoverFailure(&err)
+
+	if !ob.auction {
+		return nil, fmt.Errorf("%w for %s", ErrNoAuction, ob.symbol)
+	}
+	if ob.haltActive() {
+		return nil, fmt.Errorf("%w for %s", ErrTradingHalted, ob.symbol)
+	}
+
+	result = &AuctionResult{Symbol: ob.symbol}
+	if price, imbalance, ok := ob.clearingPrice(); ok {
+		result.Price, result.Imbalance = price, imbalance
+		result.Quantity, result.Trades = ob.executeAuction(price)
+	}
+
+	ob.auction = false
+	return result, nil
+}
+
+// clearingPrice escolhe, entre os preços dos níveis do livro, o que maximiza
+// a quantidade executável e, no empate, o que minimiza o desequilíbrio entre
+// os lados. Empates restantes ficam com o preço mediano entre os empatados.
+func (ob *OrderBook) clearingPrice() (price, imbalance float64, ok bool) {
+	var candidates []float64
+	for level := ob.buyLevels; level != nil; level = level.Next {
+		candidates = append(candidates, level.Price)
+	}
+	for level := ob.sellLevels; level != nil; level = level.Next {
+		candidates = append(candidates, level.Price)
+	}
+	sort.Float64s(candidates)
+
+	var (
+		tied   []float64
+		volume float64
+		gap    float64
+	)
+	for i, p := range candidates {
+		if i > 0 && p == candidates[i-1] {
+			continue
+		}
+
+		demand, supply := ob.auctionDemand(p), ob.auctionSupply(p)
+		executable := min(demand, supply)
+		if executable <= 0 {
+			continue
+		}
+		imbalance := demand - supply
+
+		switch {
+		case executable > volume,
+			executable == volume && math.Abs(imbalance) < math.Abs(gap):
+			volume, gap, tied = executable, imbalance, []float64{p}
+		case executable == volume && math.Abs(imbalance) == math.Abs(gap):
+			tied = append(tied, p)
+		}
+	}
+
+	if len(tied) == 0 {
+		return 0, 0, false
+	}
+	price = tied[(len(tied)-1)/2]
+	return price, ob.auctionDemand(price) - ob.auctionSupply(price), true
+}
+
+// auctionDemand soma a quantidade de compra disposta a pagar ao menos price
+func (ob *OrderBook) auctionDemand(price float64) float64 {
+	total := 0.0
+	for level := ob.buyLevels; level != nil && level.Price >= price; level = level.Next {
+		total += levelQuantity(level)
+	}
+	return total
+}
+
+// auctionSupply soma a quantidade de venda disposta a receber até price
+func (ob *OrderBook) auctionSupply(price float64) float64 {
+	total := 0.0
+	for level := ob.sellLevels; level != nil && level.Price <= price; level = level.Next {
+		total += levelQuantity(level)
+	}
+	return total
+}
+
+// executeAuction casa, em prioridade preço-tempo, as ordens que cruzam o
+// preço de equilíbrio, todas a esse preço. Em cada negócio a ordem que chegou
+// por último é o taker.
+func (ob *OrderBook) executeAuction(price float64) (quantity float64, trades int) {
+	for ob.buyLevels != nil && ob.sellLevels != nil &&
+		ob.buyLevels.Price >= price && ob.sellLevels.Price <= price {
+		buyLevel, sellLevel := ob.buyLevels, ob.sellLevels
+		buy, sell := buyLevel.Orders[0], sellLevel.Orders[0]
+
+		matchQty := min(buy.RemainingQuantity(), sell.RemainingQuantity())
+		tripped := false
+		if matchQty > 0 && buy.IsActive() && sell.IsActive() {
+			if buy.ValidateFill(matchQty, price, ob.config.AllowNegativePrice) != nil || sell.ValidateFill(matchQty, price, ob.config.AllowNegativePrice) != nil {
+				break
+			}
+			buy.Fill(matchQty, price, ob.config.AllowNegativePrice)
+			sell.Fill(matchQty, price, ob.config.AllowNegativePrice)
+
+			taker, maker := sell, buy
+			if buy.CreatedAt.After(sell.CreatedAt) {
+				taker, maker = buy, sell
+			}
+			trade := Trade{
+				Symbol:         ob.symbol,
+				Price:          price,
+				Quantity:       matchQty,
+				TakerOrderID:   taker.ID,
+				MakerOrderID:   maker.ID,
+				TakerAccountID: taker.AccountID,
+				MakerAccountID: maker.AccountID,
+				TakerSide:      taker.Side,
+				Timestamp:      ob.now(),
+			}
+			ob.chargeFees(&trade)
+			ob.emit(maker, &trade)
+			ob.emit(taker, &trade)
+
+			quantity += matchQty
+			trades++
+			tripped = ob.recordTrade(trade)
+		}
+
+		ob.dropInactive(buyLevel, sellLevel)
+		if tripped {
+			break
+		}
+	}
+	return quantity, trades
+}
+
+// dropInactive retira do início dos níveis as ordens que não estão mais
+// ativas e descarta os níveis que ficarem vazios
+func (ob *OrderBook) dropInactive(levels ...*PriceLevel) {
+	for _, level := range levels {
+		i := 0
+		for i < len(level.Orders) && (!level.Orders[i].IsActive() || level.Orders[i].RemainingQuantity() <= 0) {
+			delete(ob.orders, level.Orders[i].ID)
+			i++
+		}
+		level.Orders = level.Orders[i:]
+	}
+	ob.cleanupEmptyLevels()
+}
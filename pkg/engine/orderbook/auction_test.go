@@ -0,0 +1,145 @@
+package orderbook
+
+import (
+	"errors"
+	"testing"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestOrderBook_RunAuction(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	ob.PauseMatching()
+
+	for _, o := range []struct {
+		side     order.Side
+		price    float64
+		quantity float64
+	}{
+		{order.SideBuy, 101, 3},
+		{order.SideBuy, 100, 2},
+		{order.SideBuy, 99, 5},
+		{order.SideSell, 98, 2},
+		{order.SideSell, 99, 2},
+		{order.SideSell, 100, 4},
+		{order.SideSell, 102, 1},
+	} {
+		if err := ob.AddOrder(mustNewOrder(t, o.side, "BTC-USD", o.price, o.quantity)); err != nil {
+			t.Fatalf("unexpected error adding order: %v", err)
+		}
+	}
+
+	// Durante o leilão nada casa, mesmo com o livro cruzado
+	if ob.tradeCount != 0 {
+		t.Fatalf("expected no trades before the auction, got %d", ob.tradeCount)
+	}
+
+	var trades []Trade
+	ob.SetUpdateListener(func(updates []Update) {
+		for _, u := range updates {
+			if u.Trade != nil && u.Order.ID == u.Trade.TakerOrderID {
+				trades = append(trades, *u.Trade)
+			}
+		}
+	})
+
+	result, err := ob.RunAuction()
+	if err != nil {
+		t.Fatalf("unexpected error running auction: %v", err)
+	}
+
+	// A 100 compram 5 (101 e 100) e vendem 8 (98, 99 e 100): máximo de 5
+	if result.Price != 100 {
+		t.Errorf("expected clearing price 100, got %v", result.Price)
+	}
+	if result.Quantity != 5 {
+		t.Errorf("expected matched quantity 5, got %v", result.Quantity)
+	}
+	if result.Imbalance != -3 {
+		t.Errorf("expected imbalance -3, got %v", result.Imbalance)
+	}
+	if result.Trades != len(trades) {
+		t.Errorf("expected %d trades reported, got %d", len(trades), result.Trades)
+	}
+	for _, trade := range trades {
+		if trade.Price != 100 {
+			t.Errorf("expected every trade at the clearing price, got %v", trade.Price)
+		}
+	}
+
+	price, qty, _ := ob.GetBestBid()
+	if price != 99 || qty != 5 {
+		t.Errorf("expected best bid 99 x 5 after the auction, got %v x %v", price, qty)
+	}
+	price, qty, _ = ob.GetBestAsk()
+	if price != 100 || qty != 3 {
+		t.Errorf("expected best ask 100 x 3 after the auction, got %v x %v", price, qty)
+	}
+	if ob.InAuction() {
+		t.Error("expected the auction to end")
+	}
+
+	// O matching contínuo é retomado
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 100, 1))
+	if _, qty, _ = ob.GetBestAsk(); qty != 2 {
+		t.Errorf("expected continuous matching after the auction, best ask qty %v", qty)
+	}
+}
+
+func TestOrderBook_RunAuction_MinimizesImbalance(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	ob.PauseMatching()
+
+	// 99, 100 e 101 executam 3; a 101 sobra 1 contra 2 nos outros preços
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 101, 3))
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 100, 2))
+	ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 99, 3))
+	ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 101, 1))
+
+	result, err := ob.RunAuction()
+	if err != nil {
+		t.Fatalf("unexpected error running auction: %v", err)
+	}
+	if result.Quantity != 3 {
+		t.Errorf("expected matched quantity 3, got %v", result.Quantity)
+	}
+	if result.Price != 101 || result.Imbalance != -1 {
+		t.Errorf("expected clearing at 101 with imbalance -1, got %v (imbalance %v)", result.Price, result.Imbalance)
+	}
+}
+
+func TestOrderBook_RunAuction_NoCross(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	ob.PauseMatching()
+
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 99, 1))
+	ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 100, 1))
+
+	result, err := ob.RunAuction()
+	if err != nil {
+		t.Fatalf("unexpected error running auction: %v", err)
+	}
+	if result.Price != 0 || result.Quantity != 0 || result.Trades != 0 {
+		t.Errorf("expected an empty auction, got %+v", result)
+	}
+	if ob.ActiveOrderCount() != 2 {
+		t.Errorf("expected both orders to keep resting, got %d", ob.ActiveOrderCount())
+	}
+}
+
+func TestOrderBook_AuctionRejections(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+
+	if _, err := ob.RunAuction(); !errors.Is(err, ErrNoAuction) {
+		t.Errorf("expected ErrNoAuction outside an auction, got %v", err)
+	}
+
+	ob.PauseMatching()
+	market, err := order.NewOrderOfType(order.TypeMarket, order.SideBuy, "BTC-USD", 0, 0, 1, false)
+	if err != nil {
+		t.Fatalf("unexpected error creating order: %v", err)
+	}
+	if err := ob.AddOrder(market); !errors.Is(err, ErrAuctionInProgress) {
+		t.Errorf("expected ErrAuctionInProgress for a market order, got %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package orderbook
+
+import (
+	"fmt"
+	"math"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// checkPriceBand rejeita preços mais distantes da referência do que a banda
+// configurada permite. Sem referência disponível a ordem é aceita.
+func (ob *OrderBook) checkPriceBand(o *order.Order, price float64) error {
+	if ob.config.PriceBandPercent <= 0 || o.Type == order.TypeMarket {
+		return nil
+	}
+
+	// Com referência zero não há desvio percentual que se possa medir
+	ref, ok := ob.referencePrice()
+	if !ok || ref == 0 {
+		return nil
+	}
+
+	deviation := math.Abs(price-ref) / math.Abs(ref) * 100
+	if deviation > ob.config.PriceBandPercent {
+		return fmt.Errorf("%w: price %v is outside the %v%% band around reference price %v",
+			ErrPriceOutOfBand, price, ob.config.PriceBandPercent, ref)
+	}
+	return nil
+}
+
+// referencePrice usa o meio do spread quando há os dois lados, o melhor preço
+// do único lado disponível, ou o preço de referência configurado
+func (ob *OrderBook) referencePrice() (float64, bool) {
+	bid, _, hasBid := ob.bestBid()
+	ask, _, hasAsk := ob.bestAsk()
+
+	switch {
+	case hasBid && hasAsk:
+		return (bid + ask) / 2, true
+	case hasBid:
+		return bid, true
+	case hasAsk:
+		return ask, true
+	case ob.config.ReferencePrice != 0:
+		return ob.config.ReferencePrice, true
+	}
+	return 0, false
+}
@@ -0,0 +1,69 @@
+package orderbook
+
+import (
+	"errors"
+	"testing"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestOrderBook_PriceBand(t *testing.T) {
+	ob := NewOrderBookWithConfig(SymbolConfig{
+		Symbol:           "BTC-USD",
+		PriceBandPercent: 10,
+	})
+
+	// Sem referência, qualquer preço é aceito
+	if err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 96.0, 1.0)); err != nil {
+		t.Fatalf("unexpected error on an empty book: %v", err)
+	}
+	if err := ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 104.0, 1.0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Referência = meio do spread = 100
+	tests := []struct {
+		name    string
+		side    order.Side
+		price   float64
+		wantErr bool
+	}{
+		{"buy just inside the band", order.SideBuy, 90.0, false},
+		{"buy just outside the band", order.SideBuy, 89.99, true},
+		{"sell just inside the band", order.SideSell, 110.0, false},
+		{"sell just outside the band", order.SideSell, 110.01, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ob.AddOrder(mustNewOrder(t, tt.side, "BTC-USD", tt.price, 1.0))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AddOrder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Ordens a mercado não têm preço para comparar
+	market, err := order.NewOrderOfType(order.TypeMarket, order.SideBuy, "BTC-USD", 0, 0, 0.5, false)
+	if err != nil {
+		t.Fatalf("unexpected error creating market order: %v", err)
+	}
+	if err := ob.AddOrder(market); err != nil {
+		t.Errorf("unexpected error for market order: %v", err)
+	}
+}
+
+func TestOrderBook_PriceBand_ReferencePrice(t *testing.T) {
+	ob := NewOrderBookWithConfig(SymbolConfig{
+		Symbol:           "BTC-USD",
+		PriceBandPercent: 5,
+		ReferencePrice:   1000.0,
+	})
+
+	if err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 10.0, 1.0)); !errors.Is(err, ErrPriceOutOfBand) {
+		t.Errorf("expected the configured reference price to apply on an empty book, got %v", err)
+	}
+	if err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 960.0, 1.0)); err != nil {
+		t.Errorf("unexpected error inside the band: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package orderbook
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+type pricePoint struct {
+	price float64
+	at    time.Time
+}
+
+// recordTrade contabiliza um negócio, guarda-o como o último e alimenta o
+// circuit breaker. Retorna true se o negócio disparou o circuit breaker.
+func (ob *OrderBook) recordTrade(trade Trade) bool {
+	ob.tradeCount++
+	ob.lastTrade = &trade
+	return ob.checkCircuitBreaker(trade)
+}
+
+// checkCircuitBreaker compara o preço do negócio com os negócios da janela
+// configurada e suspende o livro se a variação exceder o limite
+func (ob *OrderBook) checkCircuitBreaker(trade Trade) bool {
+	percent, window := ob.config.CircuitBreakerPercent, ob.config.CircuitBreakerWindow
+	if percent <= 0 || window <= 0 {
+		return false
+	}
+
+	cutoff := trade.Timestamp.Add(-window)
+	i := 0
+	for i < len(ob.priceWindow) && ob.priceWindow[i].at.Before(cutoff) {
+		i++
+	}
+	ob.priceWindow = append(ob.priceWindow[i:], pricePoint{price: trade.Price, at: trade.Timestamp})
+
+	for _, p := range ob.priceWindow {
+		// Um preço zero não serve de base para uma variação percentual
+		if p.price == 0 {
+			continue
+		}
+		move := math.Abs(trade.Price-p.price) / math.Abs(p.price) * 100
+		if move <= percent {
+			continue
+		}
+
+		event := &HaltEvent{
+			Symbol: ob.symbol,
+			Reason: fmt.Sprintf("circuit breaker: price moved %.2f%% (%v to %v) within %v",
+				move, p.price, trade.Price, window),
+			HaltedAt: trade.Timestamp,
+		}
+		if ob.config.CircuitBreakerCooldown > 0 {
+			event.ResumeAt = trade.Timestamp.Add(ob.config.CircuitBreakerCooldown)
+		}
+
+		ob.halted = true
+		ob.resumeAt = event.ResumeAt
+		ob.priceWindow = nil
+		ob.pendingHalt = event
+		return true
+	}
+	return false
+}
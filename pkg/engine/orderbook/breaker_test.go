@@ -0,0 +1,125 @@
+package orderbook
+
+import (
+	"testing"
+	"time"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func newBreakerBook(t *testing.T, cooldown time.Duration) (*OrderBook, *time.Time) {
+	t.Helper()
+
+	ob := NewOrderBookWithConfig(SymbolConfig{
+		Symbol:                 "BTC-USD",
+		CircuitBreakerPercent:  5,
+		CircuitBreakerWindow:   time.Minute,
+		CircuitBreakerCooldown: cooldown,
+	})
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ob.now = func() time.Time { return now }
+	return ob, &now
+}
+
+// trade executa um negócio de 1.0 no preço informado
+func trade(t *testing.T, ob *OrderBook, price float64) error {
+	t.Helper()
+
+	ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", price, 1.0))
+	return ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", price, 1.0))
+}
+
+func TestOrderBook_CircuitBreaker_Trips(t *testing.T) {
+	ob, now := newBreakerBook(t, 0)
+
+	var events []HaltEvent
+	ob.SetHaltListener(func(e HaltEvent) { events = append(events, e) })
+
+	if err := trade(t, ob, 100.0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	*now = now.Add(30 * time.Second)
+	if err := trade(t, ob, 104.0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ob.IsHalted() {
+		t.Fatal("expected a 4% move not to trip the breaker")
+	}
+
+	*now = now.Add(20 * time.Second)
+	if err := trade(t, ob, 106.0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !ob.IsHalted() {
+		t.Fatal("expected a 6% move within the window to halt the symbol")
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected one halt event, got %d", len(events))
+	}
+	if !events[0].ResumeAt.IsZero() {
+		t.Error("expected manual resume without a cooldown")
+	}
+
+	// Sem cooldown, continua suspenso até Resume
+	*now = now.Add(time.Hour)
+	if err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 106.0, 1.0)); err == nil {
+		t.Error("expected orders to be rejected while halted")
+	}
+	ob.Resume()
+	if ob.IsHalted() {
+		t.Error("expected Resume to lift the halt")
+	}
+}
+
+func TestOrderBook_CircuitBreaker_WindowExpiry(t *testing.T) {
+	ob, now := newBreakerBook(t, 0)
+
+	trade(t, ob, 100.0)
+	*now = now.Add(2 * time.Minute)
+	trade(t, ob, 110.0)
+
+	if ob.IsHalted() {
+		t.Error("expected moves across windows not to trip the breaker")
+	}
+}
+
+func TestOrderBook_CircuitBreaker_StopsSweepAndAutoResumes(t *testing.T) {
+	ob, now := newBreakerBook(t, 5*time.Minute)
+
+	ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0))
+	ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 110.0, 1.0))
+	farAsk := mustNewOrder(t, order.SideSell, "BTC-USD", 120.0, 1.0)
+	ob.AddOrder(farAsk)
+
+	sweep := mustNewOrder(t, order.SideBuy, "BTC-USD", 120.0, 3.0)
+	if err := ob.AddOrder(sweep); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// O negócio a 110 dispara o breaker: a varredura para e o restante é cancelado
+	if sweep.Filled != 2.0 {
+		t.Errorf("expected sweep to stop after the tripping trade, filled %v", sweep.Filled)
+	}
+	if sweep.Status != order.StatusCancelled {
+		t.Errorf("expected remainder to be cancelled, got %v", sweep.Status)
+	}
+	if farAsk.Filled != 0 {
+		t.Error("expected levels beyond the trip not to trade")
+	}
+	if !ob.IsHalted() {
+		t.Fatal("expected symbol to be halted")
+	}
+
+	*now = now.Add(5 * time.Minute)
+	if ob.IsHalted() {
+		t.Error("expected symbol to resume after the cooldown")
+	}
+	resting := mustNewOrder(t, order.SideBuy, "BTC-USD", 119.0, 1.0)
+	if err := ob.AddOrder(resting); err != nil {
+		t.Fatalf("unexpected error after auto-resume: %v", err)
+	}
+	if _, err := ob.GetOrder(resting.ID); err != nil {
+		t.Error("expected orders to rest normally after auto-resume")
+	}
+}
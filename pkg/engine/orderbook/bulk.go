@@ -0,0 +1,200 @@
+package orderbook
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// BulkLoad coloca de uma vez ordens limitadas em repouso no livro, sem
+// passar pelo matching ordem a ordem: as ordens são ordenadas por preço e
+// intercaladas nos níveis existentes numa única passada, sob um único lock.
+// Ordens de mesmo preço entram no fim da fila do nível, na ordem recebida.
+//
+// Sem match, nenhuma ordem pode cruzar o lado oposto, do livro ou do lote,
+// e o lote é rejeitado com ErrBookCrossed; com match, o livro casa o que
+// cruzar depois de carregado, como descreve uncross. Na fase de leilão as ordens só repousam, como
+// em AddOrder. A carga é tudo ou nada: qualquer ordem inválida rejeita o
+// lote sem alterar o livro. Banda de preço, valor mínimo e limites de
+// profundidade não são verificados.
+func (ob *OrderBook) BulkLoad(orders []*order.Order, match bool) (err error) {
+	ob.mutex.Lock()
+	defer ob.unlock()
+	defer ob.recoverFailure(&err)
+
+	if ob.retired {
+		return fmt.Errorf("%w: %s", ErrBookRetired, ob.symbol)
+	}
+	if match && ob.haltActive() {
+		return fmt.Errorf("%w for %s", ErrTradingHalted, ob.symbol)
+	}
+
+	var bids, asks []*order.Order
+	seen := make(map[string]bool, len(orders))
+	for i, o := range orders {
+		if err := ob.checkBulkOrder(o, seen); err != nil {
+			return fmt.Errorf("order %d (%s): %w", i, o.ID, err)
+		}
+		seen[o.ID] = true
+
+		if o.Side == order.SideBuy {
+			bids = append(bids, o)
+		} else {
+			asks = append(asks, o)
+		}
+	}
+
+	sortByPriority(order.SideBuy, bids)
+	sortByPriority(order.SideSell, asks)
+
+	if !match && !ob.auction {
+		if err := ob.checkBulkCross(bids, asks); err != nil {
+			return err
+		}
+	}
+
+	ob.mergeLevels(order.SideBuy, bids)
+	ob.mergeLevels(order.SideSell, asks)
+
+	if match && !ob.auction {
+		ob.uncross()
+	}
+	return nil
+}
+
+// checkBulkOrder verifica uma ordem do lote; seen guarda os IDs já vistos
+// nele. Exige o lock de escrita.
+func (ob *OrderBook) checkBulkOrder(o *order.Order, seen map[string]bool) error {
+	if err := ob.acceptsOrder(o); err != nil {
+		return err
+	}
+	if o.Type != order.TypeLimit {
+		return fmt.Errorf("only limit orders can be bulk loaded, got %s", o.Type)
+	}
+	if !o.IsActive() || o.RemainingQuantity() <= 0 {
+		return fmt.Errorf("order is %s with nothing left to rest", o.Status)
+	}
+	if _, exists := ob.orders[o.ID]; exists || seen[o.ID] {
+		return errors.New("duplicate order ID")
+	}
+	return ob.config.Normalize(o)
+}
+
+// checkBulkCross rejeita o lote se o melhor preço de compra, do livro ou do
+// lote, alcança o melhor de venda. bids e asks vêm ordenados por prioridade.
+func (ob *OrderBook) checkBulkCross(bids, asks []*order.Order) error {
+	bid, _, hasBid := ob.bestBid()
+	if len(bids) > 0 && (!hasBid || bids[0].Price > bid) {
+		bid, hasBid = bids[0].Price, true
+	}
+	ask, _, hasAsk := ob.bestAsk()
+	if len(asks) > 0 && (!hasAsk || asks[0].Price < ask) {
+		ask, hasAsk = asks[0].Price, true
+	}
+
+	if hasBid && hasAsk && bid >= ask {
+		return fmt.Errorf("%w: bid %v reaches ask %v", ErrBookCrossed, bid, ask)
+	}
+	return nil
+}
+
+// sortByPriority ordena as ordens de um lado do melhor preço para o pior,
+// mantendo a ordem recebida entre as de mesmo preço
+func sortByPriority(side order.Side, orders []*order.Order) {
+	slices.SortStableFunc(orders, func(a, b *order.Order) int {
+		switch {
+		case better(side, a.Price, b.Price):
+			return -1
+		case better(side, b.Price, a.Price):
+			return 1
+		}
+		return 0
+	})
+}
+
+// mergeLevels intercala nos níveis de um lado ordens já ordenadas por
+// prioridade, percorrendo a lista de níveis uma única vez. Exige o lock de
+// escrita.
+func (ob *OrderBook) mergeLevels(side order.Side, orders []*order.Order) {
+	var previous *PriceLevel
+	current := ob.sideLevels(side)
+
+	for _, o := range orders {
+		for current != nil && better(side, current.Price, o.Price) {
+			previous, current = current, current.Next
+		}
+
+		if current == nil || current.Price != o.Price {
+			level := &PriceLevel{Price: o.Price, Next: current, Previous: previous}
+			if current != nil {
+				current.Previous = level
+			}
+			if previous != nil {
+				previous.Next = level
+			} else if side == order.SideBuy {
+				ob.buyLevels = level
+			} else {
+				ob.sellLevels = level
+			}
+			current = level
+		}
+
+		current.Orders = append(current.Orders, o)
+		ob.orders[o.ID] = o
+		if !o.ExpiresAt.IsZero() {
+			ob.deadlines[o.ID] = true
+		}
+		ob.emit(o, nil)
+	}
+}
+
+// uncross casa, em prioridade preço-tempo, as ordens de um livro cruzado até
+// que a melhor compra fique abaixo da melhor venda. Em cada negócio a ordem
+// que chegou por último é o taker e o preço é o da que chegou antes, como se
+// as ordens tivessem entrado uma a uma. Para se o circuit breaker disparar.
+// Exige o lock de escrita.
+func (ob *OrderBook) uncross() {
+	for ob.buyLevels != nil && ob.sellLevels != nil && ob.buyLevels.Price >= ob.sellLevels.Price {
+		buyLevel, sellLevel := ob.buyLevels, ob.sellLevels
+		buy, sell := buyLevel.Orders[0], sellLevel.Orders[0]
+
+		taker, maker := sell, buy
+		if buy.CreatedAt.After(sell.CreatedAt) {
+			taker, maker = buy, sell
+		}
+		price := maker.Price
+
+		matchQty := min(buy.RemainingQuantity(), sell.RemainingQuantity())
+		tripped := false
+		if matchQty > 0 && buy.IsActive() && sell.IsActive() {
+			if buy.ValidateFill(matchQty, price, ob.config.AllowNegativePrice) != nil || sell.ValidateFill(matchQty, price, ob.config.AllowNegativePrice) != nil {
+				break
+			}
+			buy.Fill(matchQty, price, ob.config.AllowNegativePrice)
+			sell.Fill(matchQty, price, ob.config.AllowNegativePrice)
+
+			trade := Trade{
+				Symbol:         ob.symbol,
+				Price:          price,
+				Quantity:       matchQty,
+				TakerOrderID:   taker.ID,
+				MakerOrderID:   maker.ID,
+				TakerAccountID: taker.AccountID,
+				MakerAccountID: maker.AccountID,
+				TakerSide:      taker.Side,
+				Timestamp:      ob.now(),
+			}
+			ob.chargeFees(&trade)
+			ob.emit(maker, &trade)
+			ob.emit(taker, &trade)
+			tripped = ob.recordTrade(trade)
+		}
+
+		ob.dropInactive(buyLevel, sellLevel)
+		if tripped {
+			break
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package orderbook
+
+import (
+	"errors"
+	"testing"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestOrderBook_BulkLoad(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	existing := mustNewOrder(t, order.SideBuy, "BTC-USD", 99.0, 1.0)
+	if err := ob.AddOrder(existing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sequence := ob.Sequence()
+
+	var updates []Update
+	ob.SetUpdateListener(func(u []Update) { updates = append(updates, u...) })
+
+	first := mustNewOrder(t, order.SideBuy, "BTC-USD", 99.0, 2.0)
+	second := mustNewOrder(t, order.SideBuy, "BTC-USD", 99.0, 3.0)
+	orders := []*order.Order{
+		mustNewOrder(t, order.SideSell, "BTC-USD", 102.0, 1.0),
+		first,
+		mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0),
+		mustNewOrder(t, order.SideSell, "BTC-USD", 101.0, 1.0),
+		second,
+		mustNewOrder(t, order.SideBuy, "BTC-USD", 98.0, 1.0),
+		mustNewOrder(t, order.SideSell, "BTC-USD", 105.0, 1.0),
+	}
+	if err := ob.BulkLoad(orders, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := ob.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if price, qty, _ := ob.GetBestBid(); price != 100.0 || qty != 1.0 {
+		t.Errorf("expected best bid 1 @ 100, got %v @ %v", qty, price)
+	}
+	if price, qty, _ := ob.GetBestAsk(); price != 101.0 || qty != 1.0 {
+		t.Errorf("expected best ask 1 @ 101, got %v @ %v", qty, price)
+	}
+
+	depth := ob.GetOrderBook()
+	wantBids := []float64{100.0, 99.0, 98.0}
+	wantAsks := []float64{101.0, 102.0, 105.0}
+	if len(depth.Bids) != len(wantBids) || len(depth.Asks) != len(wantAsks) {
+		t.Fatalf("expected 3 levels a side, got %+v", depth)
+	}
+	for i, price := range wantBids {
+		if depth.Bids[i].Price != price {
+			t.Errorf("bid level %d: expected %v, got %v", i, price, depth.Bids[i].Price)
+		}
+	}
+	for i, price := range wantAsks {
+		if depth.Asks[i].Price != price {
+			t.Errorf("ask level %d: expected %v, got %v", i, price, depth.Asks[i].Price)
+		}
+	}
+	if len(depth.Bids[1].Orders) != 3 {
+		t.Errorf("expected 3 orders resting at 99, got %d", len(depth.Bids[1].Orders))
+	}
+
+	// The loaded orders queue behind the one already at 99, in the order given
+	for rank, want := range []*order.Order{existing, first, second} {
+		position, err := ob.QueuePosition(want.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if position.Rank != rank+1 {
+			t.Errorf("expected order %s at rank %d, got %d", want.ID, rank+1, position.Rank)
+		}
+	}
+
+	if ob.Sequence() != sequence+1 {
+		t.Errorf("expected the load to advance the sequence once, got %d from %d", ob.Sequence(), sequence)
+	}
+	if len(updates) != len(orders) {
+		t.Errorf("expected an update per loaded order, got %d", len(updates))
+	}
+	if ob.TradeCount() != 0 {
+		t.Errorf("expected no trades, got %d", ob.TradeCount())
+	}
+}
+
+func TestOrderBook_BulkLoad_Crossing(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	if err := ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 101.0, 1.0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	before := ob.GetDepth()
+
+	// Against the book, and within the batch
+	for _, orders := range [][]*order.Order{
+		{mustNewOrder(t, order.SideBuy, "BTC-USD", 101.0, 1.0)},
+		{
+			mustNewOrder(t, order.SideSell, "BTC-USD", 95.0, 1.0),
+			mustNewOrder(t, order.SideBuy, "BTC-USD", 96.0, 1.0),
+		},
+	} {
+		if err := ob.BulkLoad(orders, false); !errors.Is(err, ErrBookCrossed) {
+			t.Errorf("expected ErrBookCrossed, got %v", err)
+		}
+	}
+	if after := ob.GetDepth(); after != before {
+		t.Fatalf("expected a rejected load to leave the book alone, got %+v", after)
+	}
+
+	// Asked to match, the crossing orders trade once loaded
+	buy := mustNewOrder(t, order.SideBuy, "BTC-USD", 102.0, 1.5)
+	if err := ob.BulkLoad([]*order.Order{buy}, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ob.TradeCount() != 1 || buy.Filled != 1.0 {
+		t.Errorf("expected one trade filling 1, got %d trades and %v filled", ob.TradeCount(), buy.Filled)
+	}
+	if _, _, err := ob.GetBestAsk(); err == nil {
+		t.Error("expected the ask side empty")
+	}
+	if err := ob.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestOrderBook_BulkLoad_Rejects(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	valid := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0)
+
+	market, err := order.NewOrderOfType(order.TypeMarket, order.SideBuy, "BTC-USD", 0, 0, 1.0, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	filled := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0)
+	filled.Fill(1.0, 100.0, false)
+
+	for name, bad := range map[string]*order.Order{
+		"other symbol": mustNewOrder(t, order.SideBuy, "ETH-USD", 100.0, 1.0),
+		"market":       market,
+		"filled":       filled,
+		"duplicate":    valid,
+	} {
+		if err := ob.BulkLoad([]*order.Order{valid, bad}, false); err == nil {
+			t.Errorf("%s: expected the load rejected", name)
+		}
+		if ob.ActiveOrderCount() != 0 {
+			t.Fatalf("%s: expected nothing loaded, got %d orders", name, ob.ActiveOrderCount())
+		}
+	}
+}
+
+// BenchmarkBulkLoad carrega 100 mil ordens, em 50 mil níveis por lado,
+// num livro vazio
+func BenchmarkBulkLoad(b *testing.B) {
+	const perSide = 50_000
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		ob := NewOrderBook("BTC-USD")
+		orders := make([]*order.Order, 0, 2*perSide)
+		for j := 0; j < perSide; j++ {
+			bid, _ := order.NewOrder(order.SideBuy, "BTC-USD", 50_000-float64(j)*0.5, 1)
+			ask, _ := order.NewOrder(order.SideSell, "BTC-USD", 50_001+float64(j)*0.5, 1)
+			orders = append(orders, bid, ask)
+		}
+		b.StartTimer()
+
+		if err := ob.BulkLoad(orders, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package orderbook
+
+import (
+	"math"
+	"time"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// DepthPolicy define o que acontece quando um lado do livro atinge o limite de profundidade
+type DepthPolicy string
+
+const (
+	// DepthPolicyReject rejeita novas ordens que precisariam repousar no livro
+	DepthPolicyReject DepthPolicy = "reject"
+	// DepthPolicyEvictWorst despeja as ordens de pior preço para abrir espaço
+	// para uma ordem com preço melhor
+	DepthPolicyEvictWorst DepthPolicy = "evict-worst"
+)
+
+// TradePricing define a que preço os negócios são impressos
+type TradePricing string
+
+const (
+	// TradePricingMaker imprime o negócio no preço da ordem em repouso
+	TradePricingMaker TradePricing = "maker"
+	// TradePricingMidpoint imprime no ponto médio entre o preço limite do
+	// agressor e o da ordem em repouso, dividindo a melhora de preço entre os
+	// dois. Ordens a mercado continuam executando no preço do maker.
+	TradePricingMidpoint TradePricing = "midpoint"
+)
+
+// TradeRounding define para que lado vai o preço de um negócio que cai entre
+// dois ticks
+type TradeRounding string
+
+const (
+	// TradeRoundingFavorMaker arredonda a favor de quem repousava: para cima
+	// quando o maker vende, para baixo quando compra
+	TradeRoundingFavorMaker TradeRounding = "favor-maker"
+	// TradeRoundingFavorTaker arredonda a favor do agressor
+	TradeRoundingFavorTaker TradeRounding = "favor-taker"
+)
+
+// CrossingPolicy define o que acontece com uma ordem limitada cujo preço
+// alcança o melhor preço do lado oposto, travando (mesmo preço) ou cruzando
+// o livro
+type CrossingPolicy string
+
+const (
+	// CrossingPolicyMatch executa a ordem contra o lado oposto, como
+	// qualquer agressora, e repousa o restante. É o padrão.
+	CrossingPolicyMatch CrossingPolicy = "match"
+	// CrossingPolicyReject rejeita a ordem, de modo que ordens limitadas só
+	// entram para repousar. Ordens a mercado continuam executando.
+	CrossingPolicyReject CrossingPolicy = "reject"
+)
+
+// SymbolConfig reúne os parâmetros de negociação de um símbolo
+type SymbolConfig struct {
+	Symbol string `json:"symbol"`
+
+	// MaxPriceLevels limita os níveis de preço de cada lado (0 = sem limite)
+	MaxPriceLevels int `json:"max_price_levels,omitempty"`
+	// MaxOrdersPerSide limita as ordens em repouso de cada lado (0 = sem limite)
+	MaxOrdersPerSide int `json:"max_orders_per_side,omitempty"`
+	// DepthPolicy escolhe entre rejeitar ou despejar ao atingir um limite
+	DepthPolicy DepthPolicy `json:"depth_policy,omitempty"`
+	// CrossingPolicy escolhe entre executar ou rejeitar ordens limitadas que
+	// travam ou cruzam o livro
+	CrossingPolicy CrossingPolicy `json:"crossing_policy,omitempty"`
+
+	// PriceBandPercent rejeita ordens com preço mais distante que este
+	// percentual do preço de referência (0 = sem banda)
+	PriceBandPercent float64 `json:"price_band_percent,omitempty"`
+	// ReferencePrice é usado como referência da banda quando o livro está vazio
+	ReferencePrice float64 `json:"reference_price,omitempty"`
+
+	// MinNotional rejeita ordens cujo preço × quantidade fica abaixo dele;
+	// ordens a mercado usam o melhor preço do lado oposto (0 = sem mínimo)
+	MinNotional float64 `json:"min_notional,omitempty"`
+
+	// CircuitBreakerPercent suspende o símbolo quando o preço dos negócios
+	// varia mais que este percentual dentro de CircuitBreakerWindow (0 = desligado)
+	CircuitBreakerPercent float64       `json:"circuit_breaker_percent,omitempty"`
+	CircuitBreakerWindow  time.Duration `json:"circuit_breaker_window,omitempty"`
+	// CircuitBreakerCooldown retoma a negociação automaticamente após o
+	// disparo (0 = exige Resume manual)
+	CircuitBreakerCooldown time.Duration `json:"circuit_breaker_cooldown,omitempty"`
+
+	// MakerFeeRate e TakerFeeRate são frações do valor negociado cobradas de
+	// quem repousava e de quem agrediu o livro. Taxas de maker negativas são
+	// rebates.
+	MakerFeeRate float64 `json:"maker_fee_rate,omitempty"`
+	TakerFeeRate float64 `json:"taker_fee_rate,omitempty"`
+	// FeeCurrency é a moeda das taxas (padrão: a moeda de cotação do símbolo)
+	FeeCurrency string `json:"fee_currency,omitempty"`
+
+	// TradePricing escolhe o preço dos negócios (padrão: TradePricingMaker)
+	TradePricing TradePricing `json:"trade_pricing,omitempty"`
+	// TradeRounding leva ao tick de preço do símbolo os negócios que caem
+	// entre ticks, como pode acontecer no ponto médio (padrão:
+	// TradeRoundingFavorMaker). Preços de maker já estão no tick.
+	TradeRounding TradeRounding `json:"trade_rounding,omitempty"`
+	// Matcher distribui as ordens agressoras pelas ordens em repouso
+	// (padrão: FIFOMatcher)
+	Matcher Matcher `json:"-"`
+
+	// OrderTTL é o tempo máximo que uma ordem pode repousar no livro; depois
+	// dele ExpireOrders a cancela (0 = sem limite)
+	OrderTTL time.Duration `json:"order_ttl,omitempty"`
+
+	// AllowNegativePrice aceita preços zero e negativos, como os de spreads
+	// e de alguns produtos de energia. NaN e infinito continuam rejeitados.
+	AllowNegativePrice bool `json:"allow_negative_price,omitempty"`
+
+	// Precision define as casas decimais de preços e quantidades. Quando
+	// configurada, as ordens são arredondadas para ela antes de entrar no
+	// livro; o JSON sempre a usa (padrão: order.DefaultPrecision).
+	Precision *order.Precision `json:"precision,omitempty"`
+	// Rounding escolhe como o excesso de casas é descartado (padrão:
+	// order.RoundHalfUp)
+	Rounding order.Rounding `json:"rounding,omitempty"`
+}
+
+// roundTradePrice leva price ao tick de preço do símbolo, a favor do maker
+// ou do agressor conforme TradeRounding. O tick é o menor passo da precisão
+// de preço, configurada ou padrão.
+func (c SymbolConfig) roundTradePrice(price float64, takerSide order.Side) float64 {
+	places := order.DefaultPrecision.Price
+	if c.Precision != nil {
+		places = c.Precision.Price
+	}
+	scale := math.Pow10(places)
+	ticks := price * scale
+
+	// Preços já no tick, a menos do erro de representação, ficam como estão
+	if nearest := math.Round(ticks); math.Abs(ticks-nearest) < 1e-6 {
+		return nearest / scale
+	}
+
+	// A favor do maker o preço sobe quando o agressor compra (o maker vende)
+	up := takerSide == order.SideBuy
+	if c.TradeRounding == TradeRoundingFavorTaker {
+		up = !up
+	}
+	if up {
+		return math.Ceil(ticks) / scale
+	}
+	return math.Floor(ticks) / scale
+}
+
+func (c SymbolConfig) limitsDepth() bool {
+	return c.MaxPriceLevels > 0 || c.MaxOrdersPerSide > 0
+}
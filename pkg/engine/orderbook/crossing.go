@@ -0,0 +1,25 @@
+package orderbook
+
+import (
+	"fmt"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// checkCrossing rejeita, com CrossingPolicyReject, ordens limitadas cujo
+// preço alcança o melhor do lado oposto. Ordens a mercado e a fase de leilão,
+// em que nada executa na chegada, não são afetadas.
+func (ob *OrderBook) checkCrossing(o *order.Order, price float64) error {
+	if ob.config.CrossingPolicy != CrossingPolicyReject || o.Type == order.TypeMarket || ob.auction {
+		return nil
+	}
+
+	best, _, ok := ob.bestAsk()
+	if o.Side == order.SideSell {
+		best, _, ok = ob.bestBid()
+	}
+	if ok && crosses(o.Side, price, best) {
+		return fmt.Errorf("%w: %s price %v reaches the opposite best %v", ErrWouldCross, o.Side, price, best)
+	}
+	return nil
+}
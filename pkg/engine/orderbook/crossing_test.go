@@ -0,0 +1,92 @@
+package orderbook
+
+import (
+	"errors"
+	"testing"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestOrderBook_CrossingPolicy(t *testing.T) {
+	for _, policy := range []CrossingPolicy{"", CrossingPolicyMatch, CrossingPolicyReject} {
+		t.Run(string(policy), func(t *testing.T) {
+			ob := NewOrderBookWithConfig(SymbolConfig{Symbol: "BTC-USD", CrossingPolicy: policy})
+			if err := ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 101.0, 1.0)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 99.0, 1.0)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Uma compra acima da melhor venda e uma venda no preço da melhor compra
+			for _, o := range []*order.Order{
+				mustNewOrder(t, order.SideBuy, "BTC-USD", 102.0, 0.5),
+				mustNewOrder(t, order.SideSell, "BTC-USD", 99.0, 0.5),
+			} {
+				err := ob.AddOrder(o)
+				if policy == CrossingPolicyReject {
+					if !errors.Is(err, ErrWouldCross) {
+						t.Errorf("expected ErrWouldCross for %s at %v, got %v", o.Side, o.Price, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if o.Filled != 0.5 {
+					t.Errorf("expected the %s at %v to fill 0.5, got %v", o.Side, o.Price, o.Filled)
+				}
+			}
+
+			wantTrades := uint64(2)
+			if policy == CrossingPolicyReject {
+				wantTrades = 0
+			}
+			if ob.TradeCount() != wantTrades {
+				t.Errorf("expected %d trades, got %d", wantTrades, ob.TradeCount())
+			}
+			if err := ob.Validate(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestOrderBook_CrossingPolicyReject(t *testing.T) {
+	ob := NewOrderBookWithConfig(SymbolConfig{Symbol: "BTC-USD", CrossingPolicy: CrossingPolicyReject})
+	if err := ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 101.0, 1.0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Abaixo da melhor venda a ordem repousa
+	bid := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0)
+	if err := ob.AddOrder(bid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Alterar o preço até a melhor venda também é rejeitado
+	if err := ob.AmendOrder(bid.ID, 101.0, 1.0); !errors.Is(err, ErrWouldCross) {
+		t.Errorf("expected ErrWouldCross, got %v", err)
+	}
+	if price, _, _ := ob.GetBestBid(); price != 100.0 {
+		t.Errorf("expected the bid left at 100, got %v", price)
+	}
+
+	// Ordens a mercado continuam executando
+	market, err := order.NewOrderOfType(order.TypeMarket, order.SideBuy, "BTC-USD", 0, 0, 0.5, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ob.AddOrder(market); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if market.Filled != 0.5 {
+		t.Errorf("expected the market order to fill 0.5, got %v", market.Filled)
+	}
+
+	// No leilão nada executa na chegada, então ordens cruzadas repousam
+	ob.PauseMatching()
+	if err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 102.0, 1.0)); err != nil {
+		t.Fatalf("unexpected error during the auction: %v", err)
+	}
+}
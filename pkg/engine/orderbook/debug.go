@@ -0,0 +1,142 @@
+package orderbook
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// DebugDump é a visão interna de um livro para diagnóstico de incidentes:
+// a lista de níveis como está encadeada, os campos crus de cada ordem, o
+// tamanho do mapa de ordens e as inconsistências encontradas. Não é o
+// snapshot público e não tem formato estável.
+type DebugDump struct {
+	Symbol        string       `json:"symbol"`
+	Sequence      uint64       `json:"sequence"`
+	OrdersMapSize int          `json:"orders_map_size"`
+	Bids          []DebugLevel `json:"bids"`
+	Asks          []DebugLevel `json:"asks"`
+	// Consistent indica que nenhuma verificação falhou; Problems descreve
+	// cada falha encontrada
+	Consistent bool     `json:"consistent"`
+	Problems   []string `json:"problems,omitempty"`
+}
+
+// DebugLevel é um nível da lista encadeada. Previous e Next trazem o preço
+// dos níveis vizinhos, ou nil quando o ponteiro é nil.
+type DebugLevel struct {
+	Price    float64       `json:"price"`
+	Previous *float64      `json:"previous"`
+	Next     *float64      `json:"next"`
+	Orders   []order.Order `json:"orders"`
+}
+
+// DebugDump percorre as duas listas de níveis sob o lock de leitura,
+// verificando o encadeamento, a ordenação dos preços e o mapa de ordens
+func (ob *OrderBook) DebugDump() DebugDump {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	dump := DebugDump{
+		Symbol:        ob.symbol,
+		Sequence:      ob.sequence,
+		OrdersMapSize: len(ob.orders),
+	}
+	dump.Bids, dump.Asks, dump.Problems = ob.inspect()
+	dump.Consistent = len(dump.Problems) == 0
+	return dump
+}
+
+// Validate verifica a estrutura do livro: preços decrescentes nas compras e
+// crescentes nas vendas, nenhum nível vazio, Next e Previous coerentes e
+// cada ordem dos níveis presente no mapa de ordens, e vice-versa. Devolve
+// ErrBookCorrupted descrevendo cada inconsistência, ou nil.
+func (ob *OrderBook) Validate() error {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	if _, _, problems := ob.inspect(); len(problems) > 0 {
+		return fmt.Errorf("%w: %s: %s", ErrBookCorrupted, ob.symbol, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// inspect copia e verifica os dois lados; exige o lock de leitura
+func (ob *OrderBook) inspect() (bids, asks []DebugLevel, problems []string) {
+	onLevels := make(map[string]bool, len(ob.orders))
+	var bidProblems, askProblems []string
+	bids, bidProblems = ob.dumpLevels(order.SideBuy, onLevels)
+	asks, askProblems = ob.dumpLevels(order.SideSell, onLevels)
+	problems = append(bidProblems, askProblems...)
+
+	var missing []string
+	for id := range ob.orders {
+		if !onLevels[id] {
+			missing = append(missing, id)
+		}
+	}
+	slices.Sort(missing)
+	for _, id := range missing {
+		problems = append(problems, fmt.Sprintf("order %s is in the orders map but on no level", id))
+	}
+	return bids, asks, problems
+}
+
+// dumpLevels copia os níveis de um lado e verifica cada elo, marcando em
+// onLevels as ordens encontradas. Um ciclo interrompe a leitura do lado.
+func (ob *OrderBook) dumpLevels(side order.Side, onLevels map[string]bool) ([]DebugLevel, []string) {
+	var (
+		levels   []DebugLevel
+		problems []string
+	)
+	report := func(format string, args ...any) {
+		problems = append(problems, string(side)+": "+fmt.Sprintf(format, args...))
+	}
+
+	head := ob.sideLevels(side)
+	if head != nil && head.Previous != nil {
+		report("head level %v has previous level %v", head.Price, head.Previous.Price)
+	}
+
+	visited := make(map[*PriceLevel]bool)
+	for level := head; level != nil; level = level.Next {
+		if visited[level] {
+			report("cycle back to level %v", level.Price)
+			break
+		}
+		visited[level] = true
+
+		dumped := DebugLevel{Price: level.Price, Orders: make([]order.Order, 0, len(level.Orders))}
+		if level.Previous != nil {
+			dumped.Previous = &level.Previous.Price
+		}
+		if next := level.Next; next != nil {
+			dumped.Next = &next.Price
+			if next.Previous != level {
+				report("level %v's next level %v does not point back to it", level.Price, next.Price)
+			}
+			if !better(side, level.Price, next.Price) {
+				report("level %v is followed by level %v out of price order", level.Price, next.Price)
+			}
+		}
+		if len(level.Orders) == 0 {
+			report("level %v is empty", level.Price)
+		}
+
+		for _, o := range level.Orders {
+			dumped.Orders = append(dumped.Orders, *o)
+			onLevels[o.ID] = true
+
+			if ob.orders[o.ID] != o {
+				report("order %s on level %v is not in the orders map", o.ID, level.Price)
+			}
+			if o.Side != side || o.Price != level.Price {
+				report("order %s (%s @ %v) is on level %v", o.ID, o.Side, o.Price, level.Price)
+			}
+		}
+		levels = append(levels, dumped)
+	}
+	return levels, problems
+}
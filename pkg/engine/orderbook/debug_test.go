@@ -0,0 +1,260 @@
+package orderbook
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestOrderBook_DebugDump(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+
+	var middle *order.Order
+	for _, price := range []float64{100, 102, 101, 99} {
+		o := mustNewOrder(t, order.SideBuy, "BTC-USD", price, 1)
+		if err := ob.AddOrder(o); err != nil {
+			t.Fatalf("AddOrder: %v", err)
+		}
+		if price == 101 {
+			middle = o
+		}
+	}
+	if err := ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 105, 1)); err != nil {
+		t.Fatalf("AddOrder: %v", err)
+	}
+	// Retirar um nível do meio precisa religar os dois vizinhos
+	if err := ob.CancelOrder(middle.ID); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+
+	dump := ob.DebugDump()
+	if !dump.Consistent {
+		t.Fatalf("consistent book reported problems: %v", dump.Problems)
+	}
+	if dump.OrdersMapSize != 4 || dump.Sequence != ob.Sequence() {
+		t.Errorf("orders map size %d, sequence %d", dump.OrdersMapSize, dump.Sequence)
+	}
+
+	var prices []float64
+	for _, level := range dump.Bids {
+		prices = append(prices, level.Price)
+	}
+	if want := []float64{102, 100, 99}; !slices.Equal(prices, want) {
+		t.Fatalf("bid levels %v, want %v", prices, want)
+	}
+	if dump.Bids[0].Previous != nil || *dump.Bids[0].Next != 100 {
+		t.Errorf("head level links %v/%v", dump.Bids[0].Previous, dump.Bids[0].Next)
+	}
+	if *dump.Bids[1].Previous != 102 || *dump.Bids[1].Next != 99 {
+		t.Errorf("middle level links %v/%v", *dump.Bids[1].Previous, *dump.Bids[1].Next)
+	}
+	if dump.Bids[2].Next != nil {
+		t.Errorf("last level has next %v", *dump.Bids[2].Next)
+	}
+	if len(dump.Asks) != 1 || dump.Asks[0].Orders[0].Price != 105 {
+		t.Errorf("asks %+v", dump.Asks)
+	}
+}
+
+func TestOrderBook_DebugDump_FlagsCorruption(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	for _, price := range []float64{100, 99, 98} {
+		if err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", price, 1)); err != nil {
+			t.Fatalf("AddOrder: %v", err)
+		}
+	}
+
+	// Corrompe a lista: o terceiro nível aponta de volta para o primeiro,
+	// e o segundo fica fora de ordem
+	first := ob.buyLevels
+	second := first.Next
+	third := second.Next
+	third.Previous = first
+	second.Price = 97
+	// Uma ordem some do mapa sem sair do nível
+	delete(ob.orders, first.Orders[0].ID)
+
+	dump := ob.DebugDump()
+	if dump.Consistent {
+		t.Fatal("corrupted book reported consistent")
+	}
+	for _, want := range []string{
+		"level 97's next level 98 does not point back to it",
+		"level 97 is followed by level 98 out of price order",
+		"on level 100 is not in the orders map",
+	} {
+		if !containsProblem(dump.Problems, want) {
+			t.Errorf("problems %q do not mention %q", dump.Problems, want)
+		}
+	}
+
+	// Um ciclo é reportado em vez de percorrido para sempre
+	third.Next = first
+	dump = ob.DebugDump()
+	if !containsProblem(dump.Problems, "cycle back to level 100") {
+		t.Errorf("problems %q do not mention the cycle", dump.Problems)
+	}
+}
+
+func containsProblem(problems []string, want string) bool {
+	for _, p := range problems {
+		if strings.Contains(p, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// validBook monta um livro por operações comuns: níveis inseridos no topo,
+// no meio e no fim, execuções, cancelamentos e uma alteração de preço. Fica
+// com compras em 100 e 98 e vendas em 101, 103, 105 e 106.
+func validBook(t *testing.T) *OrderBook {
+	t.Helper()
+
+	ob := NewOrderBook("BTC-USD")
+	add := func(side order.Side, price, quantity float64) *order.Order {
+		o := mustNewOrder(t, side, "BTC-USD", price, quantity)
+		if err := ob.AddOrder(o); err != nil {
+			t.Fatalf("AddOrder: %v", err)
+		}
+		return o
+	}
+
+	add(order.SideBuy, 100, 1)
+	add(order.SideBuy, 98, 1)
+	middle := add(order.SideBuy, 99, 1)
+	add(order.SideBuy, 101, 1)
+	add(order.SideSell, 103, 1)
+	add(order.SideSell, 105, 1)
+	amended := add(order.SideSell, 104, 2)
+	add(order.SideSell, 101, 1.5)
+
+	if err := ob.CancelOrder(middle.ID); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	if err := ob.AmendOrder(amended.ID, 106, 2); err != nil {
+		t.Fatalf("AmendOrder: %v", err)
+	}
+	return ob
+}
+
+func TestOrderBook_Validate(t *testing.T) {
+	if err := NewOrderBook("BTC-USD").Validate(); err != nil {
+		t.Errorf("empty book: %v", err)
+	}
+	if err := validBook(t).Validate(); err != nil {
+		t.Fatalf("valid book: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		corrupt func(ob *OrderBook)
+		want    string
+	}{
+		{
+			name:    "bids out of order",
+			corrupt: func(ob *OrderBook) { ob.buyLevels.Next.Price = 100.5 },
+			want:    "buy: level 100 is followed by level 100.5 out of price order",
+		},
+		{
+			name:    "asks out of order",
+			corrupt: func(ob *OrderBook) { ob.sellLevels.Next.Price = 100 },
+			want:    "sell: level 101 is followed by level 100 out of price order",
+		},
+		{
+			name: "empty level",
+			corrupt: func(ob *OrderBook) {
+				for _, o := range ob.buyLevels.Orders {
+					delete(ob.orders, o.ID)
+				}
+				ob.buyLevels.Orders = nil
+			},
+			want: "buy: level 100 is empty",
+		},
+		{
+			name:    "broken back link",
+			corrupt: func(ob *OrderBook) { ob.sellLevels.Next.Previous = nil },
+			want:    "sell: level 101's next level 103 does not point back to it",
+		},
+		{
+			name:    "head with a previous level",
+			corrupt: func(ob *OrderBook) { ob.buyLevels.Previous = ob.buyLevels.Next },
+			want:    "buy: head level 100 has previous level 98",
+		},
+		{
+			name:    "order missing from the map",
+			corrupt: func(ob *OrderBook) { delete(ob.orders, ob.sellLevels.Orders[0].ID) },
+			want:    "on level 101 is not in the orders map",
+		},
+		{
+			name: "order on no level",
+			corrupt: func(ob *OrderBook) {
+				ob.sellLevels.Next.Next = nil
+			},
+			want: "is in the orders map but on no level",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ob := validBook(t)
+			tt.corrupt(ob)
+
+			err := ob.Validate()
+			if !errors.Is(err, ErrBookCorrupted) {
+				t.Fatalf("Validate() = %v, want ErrBookCorrupted", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderBook_JoinsExistingInnerLevel(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+
+	// Um preço igual ao de um nível que não é o primeiro entra nesse nível,
+	// dos dois lados
+	var joined []*order.Order
+	for _, side := range []order.Side{order.SideBuy, order.SideSell} {
+		base := 90.0
+		if side == order.SideSell {
+			base = 110
+		}
+		for _, offset := range []float64{0, 2, 2} {
+			price := base + offset
+			if side == order.SideBuy {
+				price = base - offset
+			}
+			o := mustNewOrder(t, side, "BTC-USD", price, 1)
+			if err := ob.AddOrder(o); err != nil {
+				t.Fatalf("AddOrder: %v", err)
+			}
+			joined = append(joined, o)
+		}
+	}
+
+	snapshot := ob.GetOrderBook()
+	if len(snapshot.Bids) != 2 || len(snapshot.Bids[1].Orders) != 2 {
+		t.Errorf("bids %+v, want two levels with two orders at 88", snapshot.Bids)
+	}
+	if len(snapshot.Asks) != 2 || len(snapshot.Asks[1].Orders) != 2 {
+		t.Errorf("asks %+v, want two levels with two orders at 112", snapshot.Asks)
+	}
+
+	// Cancelar as ordens do nível compartilhado não deixa nada para trás
+	for _, o := range []*order.Order{joined[1], joined[2], joined[4], joined[5]} {
+		if err := ob.CancelOrder(o.ID); err != nil {
+			t.Fatalf("CancelOrder: %v", err)
+		}
+	}
+	if err := ob.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if depth := ob.GetDepth(); depth.BidLevels != 1 || depth.AskLevels != 1 {
+		t.Errorf("depth %+v, want one level a side", depth)
+	}
+}
@@ -0,0 +1,107 @@
+package orderbook
+
+import (
+	"fmt"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// checkDepth rejeita, antes de qualquer execução, uma ordem cujo restante
+// precisaria repousar num lado do livro que já atingiu o limite. O matching
+// só consome o lado oposto, então a ocupação do lado da ordem não muda até
+// ela repousar.
+func (ob *OrderBook) checkDepth(o *order.Order) error {
+	if !ob.config.limitsDepth() || o.Type == order.TypeMarket {
+		return nil
+	}
+	if !ob.auction && ob.fillableQuantity(o) >= o.RemainingQuantity() {
+		return nil
+	}
+	if !ob.sideFull(o.Side, o.Price) {
+		return nil
+	}
+
+	if ob.config.DepthPolicy == DepthPolicyEvictWorst {
+		if worst := ob.worstLevel(o.Side); worst != nil && better(o.Side, o.Price, worst.Price) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w for %s", ErrDepthLimitReached, ob.symbol)
+}
+
+// makeRoom despeja as ordens de pior preço até que a ordem caiba no seu lado.
+// checkDepth já garantiu que a ordem tem preço melhor que as despejadas.
+func (ob *OrderBook) makeRoom(o *order.Order) {
+	if !ob.config.limitsDepth() || ob.config.DepthPolicy != DepthPolicyEvictWorst {
+		return
+	}
+
+	for ob.sideFull(o.Side, o.Price) {
+		worst := ob.worstLevel(o.Side)
+		if worst == nil || !better(o.Side, o.Price, worst.Price) {
+			return
+		}
+
+		evicted := worst.Orders[len(worst.Orders)-1]
+		evicted.Cancel()
+		ob.emit(evicted, nil)
+		ob.removeOrder(evicted, worst.Price)
+		delete(ob.orders, evicted.ID)
+	}
+}
+
+// fillableQuantity soma a quantidade que a ordem executaria imediatamente
+func (ob *OrderBook) fillableQuantity(o *order.Order) float64 {
+	fillable := 0.0
+	ob.walkOpposing(o.Side, o.Price, func(_ *PriceLevel, restingOrder *order.Order) bool {
+		fillable += restingOrder.RemainingQuantity()
+		return fillable < o.RemainingQuantity()
+	})
+	return fillable
+}
+
+// sideFull indica se uma nova ordem no preço informado excederia os limites do lado
+func (ob *OrderBook) sideFull(side order.Side, price float64) bool {
+	levels, orders := 0, 0
+	newLevel := true
+	for level := ob.sideLevels(side); level != nil; level = level.Next {
+		levels++
+		orders += len(level.Orders)
+		if level.Price == price {
+			newLevel = false
+		}
+	}
+
+	if ob.config.MaxPriceLevels > 0 && newLevel && levels >= ob.config.MaxPriceLevels {
+		return true
+	}
+	return ob.config.MaxOrdersPerSide > 0 && orders >= ob.config.MaxOrdersPerSide
+}
+
+// worstLevel retorna o último nível de preço do lado informado
+func (ob *OrderBook) worstLevel(side order.Side) *PriceLevel {
+	level := ob.sideLevels(side)
+	for level != nil && level.Next != nil {
+		level = level.Next
+	}
+	return level
+}
+
+// sideLevels retorna o melhor nível do lado informado
+func (ob *OrderBook) sideLevels(side order.Side) *PriceLevel {
+	switch side {
+	case order.SideBuy:
+		return ob.buyLevels
+	case order.SideSell:
+		return ob.sellLevels
+	}
+	return nil
+}
+
+// better indica se price tem prioridade sobre other no lado informado
+func better(side order.Side, price, other float64) bool {
+	if side == order.SideBuy {
+		return price > other
+	}
+	return price < other
+}
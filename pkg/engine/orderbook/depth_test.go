@@ -0,0 +1,89 @@
+package orderbook
+
+import (
+	"errors"
+	"testing"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestOrderBook_DepthLimit_Reject(t *testing.T) {
+	ob := NewOrderBookWithConfig(SymbolConfig{
+		Symbol:         "BTC-USD",
+		MaxPriceLevels: 2,
+		DepthPolicy:    DepthPolicyReject,
+	})
+
+	if err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 99.0, 1.0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Um terceiro nível é rejeitado, mesmo com preço melhor
+	better := mustNewOrder(t, order.SideBuy, "BTC-USD", 101.0, 1.0)
+	if err := ob.AddOrder(better); !errors.Is(err, ErrDepthLimitReached) {
+		t.Errorf("expected new price level to be rejected when the side is full, got %v", err)
+	}
+	if better.Status != order.StatusNew {
+		t.Errorf("expected rejected order to be untouched, got %v", better.Status)
+	}
+
+	// Um nível já existente continua aceitando ordens
+	if err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 99.0, 1.0)); err != nil {
+		t.Errorf("unexpected error joining an existing level: %v", err)
+	}
+
+	// O outro lado tem seus próprios limites
+	if err := ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 105.0, 1.0)); err != nil {
+		t.Errorf("unexpected error on the opposite side: %v", err)
+	}
+
+	// Uma ordem que executa por completo não precisa repousar
+	aggressor := mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0)
+	if err := ob.AddOrder(aggressor); err != nil {
+		t.Errorf("unexpected error for an order that fills completely: %v", err)
+	}
+	if aggressor.Status != order.StatusFilled {
+		t.Errorf("expected aggressor to fill, got %v", aggressor.Status)
+	}
+}
+
+func TestOrderBook_DepthLimit_EvictWorst(t *testing.T) {
+	ob := NewOrderBookWithConfig(SymbolConfig{
+		Symbol:           "BTC-USD",
+		MaxOrdersPerSide: 2,
+		DepthPolicy:      DepthPolicyEvictWorst,
+	})
+
+	best := mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0)
+	worst := mustNewOrder(t, order.SideSell, "BTC-USD", 102.0, 1.0)
+	ob.AddOrder(best)
+	ob.AddOrder(worst)
+
+	// Um preço igual ou pior que o pior nível não desloca ninguém
+	if err := ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 102.0, 1.0)); err == nil {
+		t.Error("expected order at the worst price to be rejected")
+	}
+
+	middle := mustNewOrder(t, order.SideSell, "BTC-USD", 101.0, 1.0)
+	if err := ob.AddOrder(middle); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if worst.Status != order.StatusCancelled {
+		t.Errorf("expected worst-priced order to be evicted, got %v", worst.Status)
+	}
+	if _, err := ob.GetOrder(worst.ID); err == nil {
+		t.Error("expected evicted order to leave the book")
+	}
+
+	snapshot := ob.GetOrderBook()
+	if len(snapshot.Asks) != 2 || snapshot.Asks[0].Price != 100.0 || snapshot.Asks[1].Price != 101.0 {
+		t.Errorf("expected asks at 100 and 101, got %+v", snapshot.Asks)
+	}
+	if ob.ActiveOrderCount() != 2 {
+		t.Errorf("expected 2 active orders, got %d", ob.ActiveOrderCount())
+	}
+}
@@ -0,0 +1,57 @@
+package orderbook
+
+import (
+	"errors"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// Erros do livro que os chamadores podem distinguir com errors.Is
+var (
+	ErrOrderNotFound = errors.New("order not found")
+	ErrInvalidSymbol = errors.New("invalid symbol")
+	ErrTradingHalted = errors.New("trading is halted")
+
+	// ErrAuctionInProgress rejeita o que não pode entrar no livro durante a
+	// fase de leilão e ErrNoAuction, um RunAuction fora dela
+	ErrAuctionInProgress = errors.New("auction in progress")
+	ErrNoAuction         = errors.New("no auction in progress")
+
+	// ErrPriceOutOfBand, ErrInvalidTick e ErrDepthLimitReached rejeitam
+	// ordens fora da banda de preço, com preço ou quantidade que somem na
+	// precisão do símbolo e que precisariam repousar num lado já cheio
+	ErrPriceOutOfBand    = errors.New("price out of band")
+	ErrInvalidTick       = errors.New("invalid tick")
+	ErrDepthLimitReached = errors.New("order book depth limit reached")
+	// ErrBelowMinNotional rejeita ordens de valor abaixo do mínimo do símbolo
+	ErrBelowMinNotional = errors.New("order value below minimum notional")
+	// ErrWouldCross rejeita, com CrossingPolicyReject, ordens limitadas que
+	// travariam ou cruzariam o livro
+	ErrWouldCross = errors.New("order would cross the book")
+	// ErrBookCrossed rejeita uma carga em lote cujas ordens cruzariam o
+	// lado oposto sem que o matching tenha sido pedido
+	ErrBookCrossed = errors.New("orders cross the book")
+
+	// ErrBookNotEmpty impede que um livro com ordens em repouso seja
+	// aposentado e ErrBookRetired rejeita ordens de um livro aposentado
+	ErrBookNotEmpty = errors.New("order book is not empty")
+	ErrBookRetired  = errors.New("order book is retired")
+
+	// ErrBookFailed é devolvido, como FailureError, pela operação que entrou
+	// em pânico no meio do livro, que fica suspenso
+	ErrBookFailed = errors.New("order book failed")
+
+	// ErrBookCorrupted é devolvido por Validate quando a lista de níveis ou
+	// o mapa de ordens estão inconsistentes
+	ErrBookCorrupted = errors.New("order book corrupted")
+
+	// ErrOrderNotCancellable é devolvido ao cancelar uma ordem já executada
+	// ou cancelada
+	ErrOrderNotCancellable = order.ErrOrderNotCancellable
+	// ErrOrderChanged é devolvido por um cancelamento condicional quando a
+	// ordem mudou desde que o chamador a leu
+	ErrOrderChanged = order.ErrOrderChanged
+	// ErrOriginalNotCancelled rejeita, em ReplaceOrders, a substituta que
+	// reaproveita o ID de uma ordem que não saiu do livro
+	ErrOriginalNotCancelled = errors.New("original order not cancelled")
+)
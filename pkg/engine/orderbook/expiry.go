@@ -0,0 +1,60 @@
+package orderbook
+
+import (
+	"time"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// ExpireOrders cancela as ordens que em now repousam há OrderTTL ou mais,
+// contado da criação, e as que passaram do seu ExpiresAt, e devolve o estado
+// final de cada uma na ordem do livro. As mudanças saem marcadas como
+// Expired. Ordens executadas ou canceladas antes já saíram do livro e não
+// são afetadas.
+func (ob *OrderBook) ExpireOrders(now time.Time) []order.Order {
+	ob.mutex.Lock()
+	defer ob.unlock()
+
+	ttl := ob.config.OrderTTL
+	if ttl <= 0 && len(ob.deadlines) == 0 {
+		return nil
+	}
+
+	var stale []*order.Order
+	for _, levels := range []*PriceLevel{ob.buyLevels, ob.sellLevels} {
+		for level := levels; level != nil; level = level.Next {
+			for _, o := range level.Orders {
+				if _, active := ob.orders[o.ID]; active && expired(o, ttl, now) {
+					stale = append(stale, o)
+				}
+			}
+		}
+	}
+
+	expiredOrders := make([]order.Order, 0, len(stale))
+	for _, o := range stale {
+		if err := o.Cancel(); err != nil {
+			continue
+		}
+		ob.emitExpired(o)
+		ob.removeOrder(o, o.Price)
+		delete(ob.orders, o.ID)
+		expiredOrders = append(expiredOrders, *o)
+	}
+
+	for id := range ob.deadlines {
+		if _, active := ob.orders[id]; !active {
+			delete(ob.deadlines, id)
+		}
+	}
+	return expiredOrders
+}
+
+// expired diz se a ordem já passou do OrderTTL do símbolo ou do seu próprio
+// ExpiresAt em now
+func expired(o *order.Order, ttl time.Duration, now time.Time) bool {
+	if ttl > 0 && !now.Before(o.CreatedAt.Add(ttl)) {
+		return true
+	}
+	return !o.ExpiresAt.IsZero() && !now.Before(o.ExpiresAt)
+}
@@ -0,0 +1,102 @@
+package orderbook
+
+import (
+	"testing"
+	"time"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestOrderBook_ExpireOrders(t *testing.T) {
+	ob := NewOrderBookWithConfig(SymbolConfig{Symbol: "BTC-USD", OrderTTL: time.Minute})
+	var updates []Update
+	ob.SetUpdateListener(func(u []Update) { updates = append(updates, u...) })
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	add := func(side order.Side, price, quantity float64, age time.Duration) *order.Order {
+		t.Helper()
+		o := mustNewOrder(t, side, "BTC-USD", price, quantity)
+		o.CreatedAt = start.Add(-age)
+		if err := ob.AddOrder(o); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return o
+	}
+
+	old := add(order.SideBuy, 100.0, 1.0, 2*time.Minute)
+	exact := add(order.SideSell, 105.0, 1.0, time.Minute)
+	fresh := add(order.SideBuy, 99.0, 1.0, 30*time.Second)
+	filled := add(order.SideSell, 104.0, 1.0, 5*time.Minute)
+	add(order.SideBuy, 104.0, 1.0, 0)
+	updates = nil
+
+	expired := ob.ExpireOrders(start)
+	if len(expired) != 2 || expired[0].ID != old.ID || expired[1].ID != exact.ID {
+		t.Fatalf("expected the orders resting a minute or more to expire, got %+v", expired)
+	}
+	for _, o := range expired {
+		if o.Status != order.StatusCancelled {
+			t.Errorf("expected order %s cancelled, got %s", o.ID, o.Status)
+		}
+	}
+	if len(updates) != 2 || !updates[0].Expired || !updates[1].Expired {
+		t.Fatalf("expected two expired updates, got %+v", updates)
+	}
+
+	if _, err := ob.GetOrder(fresh.ID); err != nil {
+		t.Error("expected the fresh order to keep resting")
+	}
+	if _, err := ob.GetOrder(filled.ID); err == nil {
+		t.Error("expected the filled order to be gone already")
+	}
+	if err := ob.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if again := ob.ExpireOrders(start); len(again) != 0 {
+		t.Errorf("expected nothing left to expire, got %d orders", len(again))
+	}
+}
+
+func TestOrderBook_ExpireOrders_NoTTL(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	o := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0)
+	if err := ob.AddOrder(o); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expired := ob.ExpireOrders(o.CreatedAt.Add(24 * time.Hour)); len(expired) != 0 {
+		t.Errorf("expected no expiry without a TTL, got %d orders", len(expired))
+	}
+}
+
+func TestOrderBook_ExpireOrders_ExpiresAt(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	timed := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0)
+	timed.ExpiresAt = start.Add(30 * time.Second)
+	untimed := mustNewOrder(t, order.SideBuy, "BTC-USD", 99.0, 1.0)
+	for _, o := range []*order.Order{timed, untimed} {
+		if err := ob.AddOrder(o); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if expired := ob.ExpireOrders(start.Add(29 * time.Second)); len(expired) != 0 {
+		t.Fatalf("expected nothing to expire before the deadline, got %d orders", len(expired))
+	}
+	expired := ob.ExpireOrders(start.Add(30 * time.Second))
+	if len(expired) != 1 || expired[0].ID != timed.ID {
+		t.Fatalf("expected the timed order to expire at its deadline, got %+v", expired)
+	}
+	if expired[0].Status != order.StatusCancelled {
+		t.Errorf("expected order cancelled, got %s", expired[0].Status)
+	}
+	if _, err := ob.GetOrder(untimed.ID); err != nil {
+		t.Error("expected the order without a deadline to keep resting")
+	}
+	if again := ob.ExpireOrders(start.Add(time.Hour)); len(again) != 0 {
+		t.Errorf("expected nothing left to expire, got %d orders", len(again))
+	}
+}
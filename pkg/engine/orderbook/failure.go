@@ -0,0 +1,56 @@
+package orderbook
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// FailureError descreve o pânico que interrompeu uma operação do livro e o
+// deixou suspenso. errors.Is o reconhece como ErrBookFailed.
+type FailureError struct {
+	Symbol string
+	Panic  any
+	Stack  []byte
+}
+
+func (e *FailureError) Error() string {
+	return fmt.Sprintf("%v: %s: %v", ErrBookFailed, e.Symbol, e.Panic)
+}
+
+func (e *FailureError) Unwrap() error {
+	return ErrBookFailed
+}
+
+// recoverFailure é adiada depois de ob.unlock pelas operações que casam
+// ordens e transforma um pânico no meio delas, como o de um Matcher com
+// defeito, num FailureError em err. O livro pode ter ficado inconsistente:
+// é suspenso sem retomada automática e quem ouve suspensões é avisado,
+// enquanto os outros livros seguem negociando. Como em qualquer suspensão,
+// cancelamentos continuam aceitos. O lock é liberado por ob.unlock em seguida.
+func (ob *OrderBook) recoverFailure(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	failure := &FailureError{Symbol: ob.symbol, Panic: r, Stack: debug.Stack()}
+	ob.halted = true
+	ob.resumeAt = time.Time{}
+	ob.pendingHalt = &HaltEvent{
+		Symbol:   ob.symbol,
+		Reason:   fmt.Sprintf("order book failed: %v", r),
+		HaltedAt: ob.now(),
+	}
+	*err = failure
+}
+
+// addOrderGuarded é addOrder protegido por recoverFailure; exige o lock de
+// escrita
+func (ob *OrderBook) addOrderGuarded(ctx context.Context, o *order.Order) (err error) {
+	defer ob.recoverFailure(&err)
+	return ob.addOrder(ctx, o)
+}
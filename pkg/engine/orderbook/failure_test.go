@@ -0,0 +1,77 @@
+package orderbook
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// panicMatcher entra em pânico assim que uma ordem alcança o lado oposto
+type panicMatcher struct{}
+
+func (panicMatcher) Match(incoming *order.Order, opposing *PriceLevel, limit float64) []Fill {
+	if opposing != nil && crosses(incoming.Side, limit, opposing.Price) {
+		panic("matcher bug")
+	}
+	return nil
+}
+
+func TestOrderBook_PanicHaltsBook(t *testing.T) {
+	ob := NewOrderBookWithConfig(SymbolConfig{Symbol: "BTC-USD", Matcher: panicMatcher{}})
+
+	var events []HaltEvent
+	ob.SetHaltListener(func(e HaltEvent) { events = append(events, e) })
+
+	ask := mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0)
+	if err := ob.AddOrder(ask); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0))
+	if !errors.Is(err, ErrBookFailed) {
+		t.Fatalf("expected ErrBookFailed, got %v", err)
+	}
+	var failure *FailureError
+	if !errors.As(err, &failure) {
+		t.Fatalf("expected a FailureError, got %T", err)
+	}
+	if failure.Symbol != "BTC-USD" || failure.Panic != "matcher bug" || len(failure.Stack) == 0 {
+		t.Errorf("unexpected failure: %+v", failure)
+	}
+
+	if !ob.IsHalted() {
+		t.Fatal("expected the failed book to be halted")
+	}
+	if len(events) != 1 || !strings.Contains(events[0].Reason, "matcher bug") || !events[0].ResumeAt.IsZero() {
+		t.Fatalf("expected one halt event without a resume time, got %+v", events)
+	}
+
+	// O lock foi liberado: o livro segue consultável e aceita cancelamentos,
+	// mas não novas ordens
+	if err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 90.0, 1.0)); !errors.Is(err, ErrTradingHalted) {
+		t.Errorf("expected ErrTradingHalted, got %v", err)
+	}
+	if err := ob.CancelOrder(ask.ID); err != nil {
+		t.Errorf("expected the resting order to cancel, got %v", err)
+	}
+	if snapshot := ob.GetOrderBook(); len(snapshot.Asks) != 0 {
+		t.Errorf("expected an empty book, got %+v", snapshot.Asks)
+	}
+}
+
+func TestOrderBook_PanicInAmend(t *testing.T) {
+	ob := NewOrderBookWithConfig(SymbolConfig{Symbol: "BTC-USD", Matcher: panicMatcher{}})
+
+	ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0))
+	bid := mustNewOrder(t, order.SideBuy, "BTC-USD", 90.0, 1.0)
+	ob.AddOrder(bid)
+
+	if err := ob.AmendOrder(bid.ID, 100.0, 1.0); !errors.Is(err, ErrBookFailed) {
+		t.Fatalf("expected ErrBookFailed, got %v", err)
+	}
+	if !ob.IsHalted() {
+		t.Error("expected the failed book to be halted")
+	}
+}
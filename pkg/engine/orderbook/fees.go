@@ -0,0 +1,41 @@
+package orderbook
+
+import (
+	"fmt"
+	"strings"
+)
+
+// chargeFees preenche as taxas de maker e taker de um negócio conforme a
+// configuração do símbolo. O agressor é sempre o taker.
+func (ob *OrderBook) chargeFees(trade *Trade) {
+	notional := trade.Price * trade.Quantity
+
+	trade.MakerFee = notional * ob.config.MakerFeeRate
+	trade.TakerFee = notional * ob.config.TakerFeeRate
+	trade.FeeCurrency = ob.config.FeeCurrency
+	if trade.FeeCurrency == "" {
+		_, trade.FeeCurrency, _ = Assets(ob.symbol)
+	}
+}
+
+// Assets separa um símbolo como BTC-USD ou BTC/USD no ativo base e na moeda
+// de cotação
+func Assets(symbol string) (base, quote string, err error) {
+	for _, sep := range []string{"-", "/"} {
+		if base, quote, found := strings.Cut(symbol, sep); found && base != "" && quote != "" {
+			return base, quote, nil
+		}
+	}
+	return "", "", fmt.Errorf("cannot derive assets from symbol: %s", symbol)
+}
+
+// NormalizeSymbol escreve um símbolo na forma canônica: maiúsculas, sem
+// espaços nas pontas e com "-" separando base e cotação, qualquer que seja o
+// separador usado ("/", "_" ou espaço). Símbolos sem separador só mudam de
+// caixa.
+func NormalizeSymbol(symbol string) string {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	return symbolSeparators.Replace(symbol)
+}
+
+var symbolSeparators = strings.NewReplacer("/", "-", "_", "-", " ", "-")
@@ -0,0 +1,144 @@
+package orderbook
+
+import (
+	"math"
+	"testing"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestOrderBook_FeesOnSweep(t *testing.T) {
+	ob := NewOrderBookWithConfig(SymbolConfig{
+		Symbol:       "BTC-USD",
+		MakerFeeRate: -0.0001,
+		TakerFeeRate: 0.001,
+	})
+
+	makers := []*order.Order{
+		mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0),
+		mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 2.0),
+		mustNewOrder(t, order.SideSell, "BTC-USD", 101.0, 1.0),
+	}
+	for _, o := range makers {
+		if err := ob.AddOrder(o); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	var trades []Trade
+	ob.SetUpdateListener(func(updates []Update) {
+		for _, u := range updates {
+			if u.Trade != nil && u.Order.ID == u.Trade.TakerOrderID {
+				trades = append(trades, *u.Trade)
+			}
+		}
+	})
+
+	taker := mustNewOrder(t, order.SideBuy, "BTC-USD", 101.0, 4.0)
+	if err := ob.AddOrder(taker); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Taker paga 0,1% e o maker recebe um rebate de 0,01% do valor negociado
+	want := []struct {
+		maker    string
+		notional float64
+	}{
+		{makers[0].ID, 100.0},
+		{makers[1].ID, 200.0},
+		{makers[2].ID, 101.0},
+	}
+
+	if len(trades) != len(want) {
+		t.Fatalf("got %d trades, want %d", len(trades), len(want))
+	}
+	for i, w := range want {
+		trade := trades[i]
+		if trade.TakerOrderID != taker.ID || trade.MakerOrderID != w.maker {
+			t.Errorf("trade %d: taker %s maker %s, want taker %s maker %s",
+				i, trade.TakerOrderID, trade.MakerOrderID, taker.ID, w.maker)
+		}
+		if !approxEqual(trade.TakerFee, w.notional*0.001) {
+			t.Errorf("trade %d: taker fee = %v, want %v", i, trade.TakerFee, w.notional*0.001)
+		}
+		if !approxEqual(trade.MakerFee, -w.notional*0.0001) {
+			t.Errorf("trade %d: maker fee = %v, want %v", i, trade.MakerFee, -w.notional*0.0001)
+		}
+		if trade.FeeCurrency != "USD" {
+			t.Errorf("trade %d: fee currency = %q, want USD", i, trade.FeeCurrency)
+		}
+	}
+}
+
+func TestOrderBook_FeeCurrencyOverride(t *testing.T) {
+	ob := NewOrderBookWithConfig(SymbolConfig{
+		Symbol:       "BTC-USD",
+		TakerFeeRate: 0.001,
+		FeeCurrency:  "FEE",
+	})
+
+	var trade *Trade
+	ob.SetUpdateListener(func(updates []Update) {
+		for _, u := range updates {
+			if u.Trade != nil {
+				trade = u.Trade
+			}
+		}
+	})
+
+	ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0))
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0))
+
+	if trade == nil {
+		t.Fatal("expected a trade")
+	}
+	if trade.FeeCurrency != "FEE" {
+		t.Errorf("fee currency = %q, want FEE", trade.FeeCurrency)
+	}
+	if trade.MakerFee != 0 {
+		t.Errorf("maker fee = %v, want 0", trade.MakerFee)
+	}
+}
+
+func TestAssets(t *testing.T) {
+	tests := []struct {
+		symbol      string
+		base, quote string
+		wantErr     bool
+	}{
+		{"BTC-USD", "BTC", "USD", false},
+		{"ETH/BTC", "ETH", "BTC", false},
+		{"BTCUSD", "", "", true},
+		{"-USD", "", "", true},
+	}
+
+	for _, tt := range tests {
+		base, quote, err := Assets(tt.symbol)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Assets(%q) error = %v, wantErr %v", tt.symbol, err, tt.wantErr)
+		}
+		if base != tt.base || quote != tt.quote {
+			t.Errorf("Assets(%q) = %q, %q, want %q, %q", tt.symbol, base, quote, tt.base, tt.quote)
+		}
+	}
+}
+
+func TestNormalizeSymbol(t *testing.T) {
+	tests := map[string]string{
+		"BTC-USD":   "BTC-USD",
+		"btc-usd":   "BTC-USD",
+		" eth/btc ": "ETH-BTC",
+		"sol_usdt":  "SOL-USDT",
+		"btcusd":    "BTCUSD",
+	}
+
+	for symbol, want := range tests {
+		if got := NormalizeSymbol(symbol); got != want {
+			t.Errorf("NormalizeSymbol(%q) = %q, want %q", symbol, got, want)
+		}
+	}
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
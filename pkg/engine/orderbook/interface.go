@@ -0,0 +1,118 @@
+package orderbook
+
+import (
+	"context"
+	"time"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// Engine define a interface do motor de matching: opera sobre todos os
+// símbolos, por isso as consultas de mercado recebem o símbolo. É
+// implementada por matching.Service.
+type Engine interface {
+	// Order management
+	AddOrder(ctx context.Context, order *order.Order) error
+	CancelOrder(ctx context.Context, symbol, orderID string) error
+
+	// Query methods
+	GetOrder(ctx context.Context, orderID string) (*order.Order, error)
+	GetOrderBook(ctx context.Context, symbol string) (*OrderBookSnapshot, error)
+
+	// Market data
+	GetBestBid(ctx context.Context, symbol string) (price, quantity float64, err error)
+	GetBestAsk(ctx context.Context, symbol string) (price, quantity float64, err error)
+}
+
+// Book define a interface de um livro de um único símbolo, implementada por
+// OrderBook
+type Book interface {
+	// Order management
+	AddOrder(order *order.Order) error
+	CancelOrder(orderID string) error
+
+	// Query methods
+	GetOrder(orderID string) (*order.Order, error)
+	GetOrderBook() *OrderBookSnapshot
+
+	// Market data
+	GetBestBid() (price, quantity float64, err error)
+	GetBestAsk() (price, quantity float64, err error)
+}
+
+var _ Book = (*OrderBook)(nil)
+
+// OrderBookSnapshot representa um snapshot do order book. Sequence permite
+// ao cliente saber se perdeu mudanças desde o último snapshot.
+type OrderBookSnapshot struct {
+	Symbol   string       `json:"symbol"`
+	Sequence uint64       `json:"sequence"`
+	Bids     []PriceLevel `json:"bids"`
+	Asks     []PriceLevel `json:"asks"`
+}
+
+// FillSimulation representa o resultado estimado de uma execução a mercado
+type FillSimulation struct {
+	Symbol    string     `json:"symbol"`
+	Side      order.Side `json:"side"`
+	Requested float64    `json:"requested"`
+	Filled    float64    `json:"filled"`
+	Unfilled  float64    `json:"unfilled"`
+	AvgPrice  float64    `json:"avg_price"`
+}
+
+// Ticker representa o topo do livro, seus sinais de microestrutura e o
+// último negócio. Campos nulos indicam que o lado correspondente está vazio.
+type Ticker struct {
+	Symbol     string   `json:"symbol"`
+	BestBid    *float64 `json:"best_bid"`
+	BestBidQty *float64 `json:"best_bid_qty"`
+	BestAsk    *float64 `json:"best_ask"`
+	BestAskQty *float64 `json:"best_ask_qty"`
+	Imbalance  *float64 `json:"imbalance"`
+	Microprice *float64 `json:"microprice"`
+
+	// LastPrice, LastQty e LastTradeAt descrevem o negócio mais recente e
+	// são nulos enquanto o símbolo não negociou
+	LastPrice   *float64   `json:"last_price"`
+	LastQty     *float64   `json:"last_qty"`
+	LastTradeAt *time.Time `json:"last_trade_at"`
+}
+
+// Depth resume a profundidade de um livro
+type Depth struct {
+	Symbol       string  `json:"symbol"`
+	BidLevels    int     `json:"bid_levels"`
+	AskLevels    int     `json:"ask_levels"`
+	BidQuantity  float64 `json:"bid_quantity"`
+	AskQuantity  float64 `json:"ask_quantity"`
+	ActiveOrders int     `json:"active_orders"`
+}
+
+// Trade representa um negócio entre uma ordem agressora (taker) e uma ordem
+// em repouso (maker), executado ao preço do maker
+type Trade struct {
+	Symbol       string  `json:"symbol"`
+	Price        float64 `json:"price"`
+	Quantity     float64 `json:"quantity"`
+	TakerOrderID string  `json:"taker_order_id"`
+	MakerOrderID string  `json:"maker_order_id"`
+	// TakerAccountID e MakerAccountID atribuem o negócio às contas das
+	// ordens, quando informadas
+	TakerAccountID string     `json:"taker_account_id,omitempty"`
+	MakerAccountID string     `json:"maker_account_id,omitempty"`
+	TakerSide      order.Side `json:"taker_side"`
+	MakerFee       float64    `json:"maker_fee"`
+	TakerFee       float64    `json:"taker_fee"`
+	FeeCurrency    string     `json:"fee_currency,omitempty"`
+	Timestamp      time.Time  `json:"timestamp"`
+}
+
+// HaltEvent descreve uma suspensão automática pelo circuit breaker
+type HaltEvent struct {
+	Symbol   string    `json:"symbol"`
+	Reason   string    `json:"reason"`
+	HaltedAt time.Time `json:"halted_at"`
+	// ResumeAt é zero quando a retomada precisa ser manual
+	ResumeAt time.Time `json:"resume_at,omitempty"`
+}
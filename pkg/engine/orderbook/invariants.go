@@ -0,0 +1,77 @@
+package orderbook
+
+import (
+	"fmt"
+	"strings"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// InvariantViolation descreve o livro que um AddOrder deixou fora de ordem
+// ou cruzado, com um dump tirado no momento da verificação
+type InvariantViolation struct {
+	Symbol string
+	// OrderID é a ordem cuja entrada deixou o livro assim
+	OrderID  string
+	Problems []string
+	Dump     DebugDump
+}
+
+func (v InvariantViolation) Error() string {
+	return fmt.Sprintf("order book %s out of order after order %s: %s",
+		v.Symbol, v.OrderID, strings.Join(v.Problems, "; "))
+}
+
+// SetInvariantListener liga a verificação, depois de cada AddOrder, de que
+// os níveis de compra têm preços estritamente decrescentes, os de venda
+// estritamente crescentes e a melhor compra fica abaixo da melhor venda. Na
+// fase de leilão e com o livro suspenso ele pode ficar travado ou cruzado de
+// propósito, e só a ordenação é verificada. Cada violação é entregue a
+// listener fora do lock do livro. A verificação percorre os dois lados
+// inteiros, então é para depuração; nil a desliga.
+func (ob *OrderBook) SetInvariantListener(listener func(InvariantViolation)) {
+	ob.mutex.Lock()
+	defer ob.mutex.Unlock()
+
+	ob.invariantListener = listener
+}
+
+// checkInvariants verifica o livro depois da entrada de o, guardando a
+// violação para unlock; exige o lock de escrita
+func (ob *OrderBook) checkInvariants(o *order.Order) {
+	if ob.invariantListener == nil {
+		return
+	}
+
+	problems := append(ob.checkOrdering(order.SideBuy), ob.checkOrdering(order.SideSell)...)
+	if !ob.auction && !ob.haltActive() && ob.buyLevels != nil && ob.sellLevels != nil &&
+		ob.buyLevels.Price >= ob.sellLevels.Price {
+		problems = append(problems, fmt.Sprintf("best bid %v reaches best ask %v", ob.buyLevels.Price, ob.sellLevels.Price))
+	}
+	if len(problems) == 0 {
+		return
+	}
+
+	dump := DebugDump{Symbol: ob.symbol, Sequence: ob.sequence, OrdersMapSize: len(ob.orders)}
+	dump.Bids, dump.Asks, dump.Problems = ob.inspect()
+	dump.Consistent = len(dump.Problems) == 0
+	ob.pendingViolation = &InvariantViolation{
+		Symbol:   ob.symbol,
+		OrderID:  o.ID,
+		Problems: problems,
+		Dump:     dump,
+	}
+}
+
+// checkOrdering confere que cada nível de um lado tem preço estritamente
+// melhor que o seguinte; exige o lock de leitura
+func (ob *OrderBook) checkOrdering(side order.Side) []string {
+	var problems []string
+	for level := ob.sideLevels(side); level != nil && level.Next != nil; level = level.Next {
+		if !better(side, level.Price, level.Next.Price) {
+			problems = append(problems, fmt.Sprintf("%s: level %v is followed by level %v out of price order",
+				side, level.Price, level.Next.Price))
+		}
+	}
+	return problems
+}
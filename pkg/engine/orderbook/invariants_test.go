@@ -0,0 +1,93 @@
+package orderbook
+
+import (
+	"strings"
+	"testing"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestOrderBook_InvariantsHoldOnInsert(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	ob.SetInvariantListener(func(v InvariantViolation) {
+		t.Errorf("unexpected violation: %v", v)
+	})
+
+	// Preços fora de ordem, repetidos e que entram na cabeça, no meio e na
+	// cauda de cada lado, e ordens que cruzam e executam
+	for i := range 60 {
+		step := float64(i * 7 % 23)
+		ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 50+step, 1.0))
+		ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 100+step, 1.0))
+		if i%10 == 9 {
+			ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 105, 3.0))
+			ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 60, 3.0))
+		}
+	}
+
+	if problems := append(ob.checkOrdering(order.SideBuy), ob.checkOrdering(order.SideSell)...); len(problems) > 0 {
+		t.Errorf("unexpected problems: %v", problems)
+	}
+}
+
+func TestOrderBook_InvariantViolation(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	var violations []InvariantViolation
+	ob.SetInvariantListener(func(v InvariantViolation) { violations = append(violations, v) })
+
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0))
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 90.0, 1.0))
+	if len(violations) != 0 {
+		t.Fatalf("unexpected violations: %v", violations)
+	}
+
+	// Uma inserção que deixasse os níveis fora de ordem
+	ob.buyLevels.Price, ob.buyLevels.Next.Price = 90.0, 100.0
+	trigger := mustNewOrder(t, order.SideBuy, "BTC-USD", 80.0, 1.0)
+	ob.AddOrder(trigger)
+
+	if len(violations) != 1 {
+		t.Fatalf("expected one violation, got %d", len(violations))
+	}
+	v := violations[0]
+	if v.Symbol != "BTC-USD" || v.OrderID != trigger.ID {
+		t.Errorf("unexpected violation: %+v", v)
+	}
+	if len(v.Problems) != 1 || !strings.Contains(v.Problems[0], "out of price order") {
+		t.Errorf("expected the ordering problem, got %v", v.Problems)
+	}
+	if v.Dump.Consistent || len(v.Dump.Bids) != 3 {
+		t.Errorf("expected a dump of the inconsistent book, got %+v", v.Dump)
+	}
+}
+
+func TestOrderBook_InvariantCrossed(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	var violations []InvariantViolation
+	ob.SetInvariantListener(func(v InvariantViolation) { violations = append(violations, v) })
+
+	ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0))
+
+	// Uma compra que repousasse sem executar contra a venda. O matching do
+	// próximo AddOrder desfaria o cruzamento, então a verificação é chamada
+	// direto.
+	bid := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0)
+	ob.mutex.Lock()
+	ob.restOrder(bid)
+	ob.checkInvariants(bid)
+	ob.unlock()
+	if len(violations) != 1 || !strings.Contains(violations[0].Problems[0], "best bid 100 reaches best ask 100") {
+		t.Fatalf("expected the locked book to be reported, got %v", violations)
+	}
+
+	// No leilão o livro cruza de propósito
+	violations = nil
+	ob = NewOrderBook("BTC-USD")
+	ob.SetInvariantListener(func(v InvariantViolation) { violations = append(violations, v) })
+	ob.PauseMatching()
+	ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0))
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 105.0, 1.0))
+	if len(violations) != 0 {
+		t.Errorf("expected a crossed auction book to pass, got %v", violations)
+	}
+}
@@ -0,0 +1,83 @@
+package orderbook
+
+import (
+	"encoding/json"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// levelJSON é o formato de um nível de preço no snapshot, em JSON e MessagePack
+type levelJSON struct {
+	Price    order.Decimal  `json:"price" msgpack:"price"`
+	Quantity order.Decimal  `json:"quantity" msgpack:"quantity"`
+	Orders   []*order.Order `json:"orders" msgpack:"orders"`
+}
+
+type snapshotJSON struct {
+	Symbol   string      `json:"symbol" msgpack:"symbol"`
+	Sequence uint64      `json:"sequence" msgpack:"sequence"`
+	Bids     []levelJSON `json:"bids" msgpack:"bids"`
+	Asks     []levelJSON `json:"asks" msgpack:"asks"`
+}
+
+// MarshalJSON escreve preços e quantidades como strings decimais fixas com a
+// precisão do símbolo, sem os ponteiros da lista encadeada
+func (s OrderBookSnapshot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.wire())
+}
+
+// wire converte o snapshot para o formato usado por todas as codificações
+func (s OrderBookSnapshot) wire() snapshotJSON {
+	precision := order.PrecisionFor(s.Symbol)
+
+	levels := func(side []PriceLevel) []levelJSON {
+		out := make([]levelJSON, 0, len(side))
+		for _, level := range side {
+			quantity := 0.0
+			for _, o := range level.Orders {
+				quantity += o.RemainingQuantity()
+			}
+			out = append(out, levelJSON{
+				Price:    order.Decimal{Value: level.Price, Places: precision.Price},
+				Quantity: order.Decimal{Value: quantity, Places: precision.Quantity},
+				Orders:   level.Orders,
+			})
+		}
+		return out
+	}
+
+	return snapshotJSON{
+		Symbol:   s.Symbol,
+		Sequence: s.Sequence,
+		Bids:     levels(s.Bids),
+		Asks:     levels(s.Asks),
+	}
+}
+
+func (s *OrderBookSnapshot) UnmarshalJSON(data []byte) error {
+	var wire snapshotJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	*s = wire.snapshot()
+	return nil
+}
+
+// snapshot converte o formato de transporte de volta para um snapshot
+func (wire snapshotJSON) snapshot() OrderBookSnapshot {
+	levels := func(side []levelJSON) []PriceLevel {
+		out := make([]PriceLevel, 0, len(side))
+		for _, level := range side {
+			out = append(out, PriceLevel{Price: level.Price.Value, Orders: level.Orders})
+		}
+		return out
+	}
+
+	return OrderBookSnapshot{
+		Symbol:   wire.Symbol,
+		Sequence: wire.Sequence,
+		Bids:     levels(wire.Bids),
+		Asks:     levels(wire.Asks),
+	}
+}
@@ -0,0 +1,54 @@
+package orderbook
+
+import (
+	"encoding/json"
+	"testing"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestOrderBookSnapshot_JSON(t *testing.T) {
+	order.SetPrecision("SNAP-USD", order.Precision{Price: 2, Quantity: 3})
+
+	ob := NewOrderBook("SNAP-USD")
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "SNAP-USD", 99.5, 1.0))
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "SNAP-USD", 99.5, 0.25))
+	ob.AddOrder(mustNewOrder(t, order.SideSell, "SNAP-USD", 100.0, 2.0))
+
+	data, err := json.Marshal(ob.GetOrderBook())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var wire struct {
+		Bids []struct {
+			Price    string            `json:"price"`
+			Quantity string            `json:"quantity"`
+			Orders   []json.RawMessage `json:"orders"`
+		} `json:"bids"`
+	}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(wire.Bids) != 1 {
+		t.Fatalf("expected 1 bid level, got %d", len(wire.Bids))
+	}
+	if wire.Bids[0].Price != "99.50" || wire.Bids[0].Quantity != "1.250" {
+		t.Errorf("bid level = %s @ %s, want 1.250 @ 99.50", wire.Bids[0].Quantity, wire.Bids[0].Price)
+	}
+	if len(wire.Bids[0].Orders) != 2 {
+		t.Errorf("expected 2 orders on the level, got %d", len(wire.Bids[0].Orders))
+	}
+
+	var snapshot OrderBookSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(snapshot.Bids) != 1 || len(snapshot.Asks) != 1 {
+		t.Fatalf("round trip lost levels: %+v", snapshot)
+	}
+	if snapshot.Asks[0].Price != 100.0 || snapshot.Asks[0].Orders[0].Quantity != 2.0 {
+		t.Errorf("round trip ask = %v x %v, want 2 @ 100",
+			snapshot.Asks[0].Orders[0].Quantity, snapshot.Asks[0].Price)
+	}
+}
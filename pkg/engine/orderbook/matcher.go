@@ -0,0 +1,65 @@
+package orderbook
+
+import "company.com/matchengine/pkg/engine/order"
+
+// Fill é a parte de uma ordem em repouso que um Matcher atribui à ordem
+// agressora
+type Fill struct {
+	Maker    *order.Order
+	Price    float64 // preço do nível do maker
+	Quantity float64
+}
+
+// Matcher distribui uma ordem agressora pelas ordens em repouso do lado
+// oposto. Recebe o melhor nível oposto e o preço limite da agressora (±Inf
+// para ordens a mercado) e devolve as execuções na ordem em que devem
+// acontecer, sem alterar nada: o livro valida e aplica cada uma, gera os
+// negócios e mantém os níveis.
+//
+// Ordens por valor em moeda de cotação (QuoteQuantity > 0) ainda não têm
+// quantidade base; o Matcher deve limitar as execuções ao orçamento.
+type Matcher interface {
+	Match(incoming *order.Order, opposing *PriceLevel, limit float64) []Fill
+}
+
+// FIFOMatcher casa por prioridade preço-tempo: percorre os níveis do melhor
+// para o pior e, dentro de cada nível, as ordens por ordem de chegada. É o
+// Matcher padrão.
+type FIFOMatcher struct{}
+
+func (FIFOMatcher) Match(incoming *order.Order, opposing *PriceLevel, limit float64) []Fill {
+	var fills []Fill
+	remaining := incoming.RemainingQuantity()
+	budget := incoming.QuoteQuantity
+
+	for level := opposing; level != nil && crosses(incoming.Side, limit, level.Price); level = level.Next {
+		for _, restingOrder := range level.Orders {
+			if !restingOrder.IsActive() || restingOrder.RemainingQuantity() <= 0 {
+				continue
+			}
+
+			var matchQty float64
+			if incoming.QuoteQuantity > 0 {
+				matchQty = min(restingOrder.RemainingQuantity(), affordable(budget, level.Price))
+				budget -= matchQty * level.Price
+			} else {
+				matchQty = min(remaining, restingOrder.RemainingQuantity())
+				remaining -= matchQty
+			}
+			if matchQty <= 0 {
+				return fills
+			}
+
+			fills = append(fills, Fill{Maker: restingOrder, Price: level.Price, Quantity: matchQty})
+		}
+	}
+	return fills
+}
+
+// matcher retorna o Matcher configurado para o símbolo, ou o FIFOMatcher
+func (c SymbolConfig) matcher() Matcher {
+	if c.Matcher == nil {
+		return FIFOMatcher{}
+	}
+	return c.Matcher
+}
@@ -0,0 +1,159 @@
+package orderbook
+
+import (
+	"math"
+	"testing"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// lifoMatcher casa só com a última ordem a chegar no melhor nível
+type lifoMatcher struct {
+	calls int
+}
+
+func (m *lifoMatcher) Match(incoming *order.Order, opposing *PriceLevel, limit float64) []Fill {
+	m.calls++
+	if opposing == nil || !crosses(incoming.Side, limit, opposing.Price) {
+		return nil
+	}
+	last := opposing.Orders[len(opposing.Orders)-1]
+	return []Fill{{
+		Maker:    last,
+		Price:    opposing.Price,
+		Quantity: min(incoming.RemainingQuantity(), last.RemainingQuantity()),
+	}}
+}
+
+func TestOrderBook_CustomMatcher(t *testing.T) {
+	matcher := &lifoMatcher{}
+	ob := NewOrderBookWithConfig(SymbolConfig{Symbol: "BTC-USD", Matcher: matcher})
+
+	first := mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0)
+	second := mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0)
+	for _, o := range []*order.Order{first, second} {
+		if err := ob.AddOrder(o); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	buy := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0)
+	if err := ob.AddOrder(buy); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if matcher.calls != 3 {
+		t.Errorf("expected the matcher to be asked for every order, got %d calls", matcher.calls)
+	}
+	if buy.Status != order.StatusFilled {
+		t.Errorf("expected the buy to fill, got %v", buy.Status)
+	}
+
+	// A fila FIFO executaria a primeira venda; o matcher escolheu a segunda
+	if second.Status != order.StatusFilled {
+		t.Errorf("expected the last sell to fill, got %v", second.Status)
+	}
+	if first.Status != order.StatusNew {
+		t.Errorf("expected the first sell to keep resting, got %v", first.Status)
+	}
+
+	// A ordem executada saiu do meio da fila sem levar as demais
+	snapshot := ob.GetOrderBook()
+	if len(snapshot.Asks) != 1 || len(snapshot.Asks[0].Orders) != 1 || snapshot.Asks[0].Orders[0].ID != first.ID {
+		t.Errorf("expected only the first sell to rest, got %+v", snapshot.Asks)
+	}
+}
+
+func TestFIFOMatcher_IsDefault(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+
+	first := mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0)
+	second := mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0)
+	ob.AddOrder(first)
+	ob.AddOrder(second)
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.5))
+
+	if first.Status != order.StatusFilled {
+		t.Errorf("expected the first sell to fill, got %v", first.Status)
+	}
+	if second.Filled != 0.5 {
+		t.Errorf("expected the second sell to fill 0.5, got %v", second.Filled)
+	}
+}
+
+// sweepBook monta um livro com dois níveis de venda, cada um com uma ordem
+// pequena que chegou primeiro e uma grande depois
+func sweepBook(t *testing.T, matcher Matcher) (*OrderBook, []*order.Order) {
+	t.Helper()
+
+	ob := NewOrderBookWithConfig(SymbolConfig{Symbol: "BTC-USD", Matcher: matcher})
+	var asks []*order.Order
+	for _, ask := range []struct{ price, qty float64 }{{100, 1}, {100, 3}, {101, 1}, {101, 3}} {
+		o := mustNewOrder(t, order.SideSell, "BTC-USD", ask.price, ask.qty)
+		if err := ob.AddOrder(o); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		asks = append(asks, o)
+	}
+	return ob, asks
+}
+
+func TestProRataMatcher_Sweep(t *testing.T) {
+	tests := []struct {
+		name       string
+		precedence SweepPrecedence
+		qty        float64
+		filled     []float64
+	}{
+		// Dentro do melhor nível, o rateio vale nos dois modos
+		{"every level, best level only", SweepProRataEveryLevel, 2, []float64{0.5, 1.5, 0, 0}},
+		{"best level, best level only", SweepProRataBestLevel, 2, []float64{0.5, 1.5, 0, 0}},
+		// O melhor nível se esgota; o segundo é rateado ou casado em FIFO
+		{"every level, sweep", SweepProRataEveryLevel, 6, []float64{1, 3, 0.5, 1.5}},
+		{"best level, sweep", SweepProRataBestLevel, 6, []float64{1, 3, 1, 1}},
+		{"default precedence, sweep", "", 6, []float64{1, 3, 0.5, 1.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ob, asks := sweepBook(t, ProRataMatcher{Precedence: tt.precedence, Lot: 0.1})
+
+			buy, err := order.NewOrderOfType(order.TypeMarket, order.SideBuy, "BTC-USD", 0, 0, tt.qty, false)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := ob.AddOrder(buy); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if buy.Status != order.StatusFilled {
+				t.Errorf("expected the market order to fill, got %v", buy.Status)
+			}
+			for i, ask := range asks {
+				if math.Abs(ask.Filled-tt.filled[i]) > 1e-9 {
+					t.Errorf("ask %d: expected %v filled, got %v", i, tt.filled[i], ask.Filled)
+				}
+			}
+		})
+	}
+}
+
+func TestProRataMatcher_Residue(t *testing.T) {
+	ob := NewOrderBookWithConfig(SymbolConfig{Symbol: "BTC-USD", Matcher: ProRataMatcher{Lot: 1}})
+	var asks []*order.Order
+	for range 3 {
+		o := mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0)
+		ob.AddOrder(o)
+		asks = append(asks, o)
+	}
+
+	// Um terço de lote para cada não dá lote nenhum: a sobra vai para as
+	// mais antigas
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 2.0))
+
+	for i, want := range []float64{1, 1, 0} {
+		if asks[i].Filled != want {
+			t.Errorf("ask %d: expected %v filled, got %v", i, want, asks[i].Filled)
+		}
+	}
+}
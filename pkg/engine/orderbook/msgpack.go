@@ -0,0 +1,18 @@
+package orderbook
+
+import "github.com/vmihailenco/msgpack/v5"
+
+// EncodeMsgpack escreve o snapshot no mesmo formato do JSON
+func (s OrderBookSnapshot) EncodeMsgpack(enc *msgpack.Encoder) error {
+	return enc.Encode(s.wire())
+}
+
+func (s *OrderBookSnapshot) DecodeMsgpack(dec *msgpack.Decoder) error {
+	var wire snapshotJSON
+	if err := dec.Decode(&wire); err != nil {
+		return err
+	}
+
+	*s = wire.snapshot()
+	return nil
+}
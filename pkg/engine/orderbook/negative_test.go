@@ -0,0 +1,163 @@
+package orderbook
+
+import (
+	"testing"
+	"time"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestOrderBook_NegativePrices(t *testing.T) {
+	ob := NewOrderBookWithConfig(SymbolConfig{Symbol: "CAL-SPREAD", AllowNegativePrice: true})
+
+	for _, price := range []float64{-2.0, 0.0, -1.0, -3.5} {
+		if err := ob.AddOrder(mustNewSpreadOrder(t, order.SideBuy, price, 1.0)); err != nil {
+			t.Fatalf("unexpected error adding bid at %v: %v", price, err)
+		}
+	}
+	for _, price := range []float64{1.5, 0.5, 3.0} {
+		if err := ob.AddOrder(mustNewSpreadOrder(t, order.SideSell, price, 1.0)); err != nil {
+			t.Fatalf("unexpected error adding ask at %v: %v", price, err)
+		}
+	}
+
+	// Compras do maior para o menor preço, vendas do menor para o maior,
+	// com zero e negativos no lugar certo
+	snapshot := ob.GetOrderBook()
+	assertLevelPrices(t, "bids", snapshot.Bids, []float64{0.0, -1.0, -2.0, -3.5})
+	assertLevelPrices(t, "asks", snapshot.Asks, []float64{0.5, 1.5, 3.0})
+
+	var trades []Trade
+	ob.SetUpdateListener(func(updates []Update) {
+		for _, u := range updates {
+			if u.Trade != nil && u.Order.ID == u.Trade.TakerOrderID {
+				trades = append(trades, *u.Trade)
+			}
+		}
+	})
+
+	// Uma venda a -1.5 cruza com as compras a 0 e -1, no preço de cada maker
+	if err := ob.AddOrder(mustNewSpreadOrder(t, order.SideSell, -1.5, 2.5)); err != nil {
+		t.Fatalf("unexpected error adding crossing sell: %v", err)
+	}
+	if len(trades) != 2 {
+		t.Fatalf("expected 2 trades, got %d", len(trades))
+	}
+	if trades[0].Price != 0.0 || trades[1].Price != -1.0 {
+		t.Errorf("trade prices = %v, %v, want 0, -1", trades[0].Price, trades[1].Price)
+	}
+
+	// O restante repousa como a melhor venda
+	snapshot = ob.GetOrderBook()
+	assertLevelPrices(t, "bids", snapshot.Bids, []float64{-2.0, -3.5})
+	assertLevelPrices(t, "asks", snapshot.Asks, []float64{-1.5, 0.5, 1.5, 3.0})
+	if got := snapshot.Asks[0].Orders[0].RemainingQuantity(); got != 0.5 {
+		t.Errorf("resting remainder = %v, want 0.5", got)
+	}
+
+	// Uma compra a -1.5 leva o restante
+	trades = nil
+	if err := ob.AddOrder(mustNewSpreadOrder(t, order.SideBuy, -1.5, 0.5)); err != nil {
+		t.Fatalf("unexpected error adding crossing buy: %v", err)
+	}
+	if len(trades) != 1 || trades[0].Price != -1.5 {
+		t.Errorf("trades = %+v, want one at -1.5", trades)
+	}
+}
+
+func TestOrderBook_NegativePricesRejectedByDefault(t *testing.T) {
+	if _, err := order.NewOrder(order.SideBuy, "BTC-USD", -1.0, 1.0); err == nil {
+		t.Error("expected an error for a negative price on a symbol that doesn't allow it")
+	}
+
+	// O livro confere o preço pela própria configuração, mesmo que a ordem
+	// tenha sido criada aceitando negativos
+	ob := NewOrderBook("BTC-USD")
+	if err := ob.AddOrder(mustNewSpreadOrder(t, order.SideBuy, -1.0, 1.0)); err == nil {
+		t.Error("expected the book to reject a negative price")
+	}
+}
+
+func TestOrderBook_NegativePricesPerBook(t *testing.T) {
+	ob := NewOrderBookWithConfig(SymbolConfig{Symbol: "CAL-SPREAD", AllowNegativePrice: true})
+
+	// Um livro descartável do mesmo símbolo, como os de validação, não muda
+	// a política do livro em uso
+	NewOrderBook("CAL-SPREAD")
+
+	if err := ob.AddOrder(mustNewSpreadOrder(t, order.SideBuy, -2.0, 1.0)); err != nil {
+		t.Fatalf("unexpected error adding bid: %v", err)
+	}
+	if err := ob.AmendOrder(ob.GetOrderBook().Bids[0].Orders[0].ID, -3.0, 1.0); err != nil {
+		t.Errorf("unexpected error amending to a negative price: %v", err)
+	}
+}
+
+func TestOrderBook_NegativePricesBandAndBreaker(t *testing.T) {
+	ob := NewOrderBookWithConfig(SymbolConfig{
+		Symbol:                "CAL-SPREAD",
+		AllowNegativePrice:    true,
+		PriceBandPercent:      10,
+		ReferencePrice:        -10.0,
+		CircuitBreakerPercent: 4,
+		CircuitBreakerWindow:  time.Minute,
+	})
+
+	// A banda é medida pelo tamanho da referência: de -11 a -9
+	if err := ob.AddOrder(mustNewSpreadOrder(t, order.SideSell, -8.5, 1.0)); err == nil {
+		t.Error("expected an error outside the band")
+	}
+	for _, price := range []float64{-10.0, -10.5} {
+		if err := ob.AddOrder(mustNewSpreadOrder(t, order.SideBuy, price, 1.0)); err != nil {
+			t.Fatalf("unexpected error inside the band: %v", err)
+		}
+	}
+
+	// Negócios a -10 e depois a -10.5 movem 5%, acima do disjuntor
+	for _, price := range []float64{-10.0, -10.5} {
+		if err := ob.AddOrder(mustNewSpreadOrder(t, order.SideSell, price, 1.0)); err != nil {
+			t.Fatalf("unexpected error adding crossing sell: %v", err)
+		}
+	}
+	if !ob.IsHalted() {
+		t.Error("expected a 5% move to halt the book")
+	}
+}
+
+func TestOrderBook_NegativePricesRejectQuoteQuantity(t *testing.T) {
+	ob := NewOrderBookWithConfig(SymbolConfig{Symbol: "CAL-SPREAD", AllowNegativePrice: true})
+	if err := ob.AddOrder(mustNewSpreadOrder(t, order.SideSell, -1.0, 1.0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	buy, err := order.NewQuoteMarketBuy("CAL-SPREAD", 100)
+	if err != nil {
+		t.Fatalf("unexpected error creating order: %v", err)
+	}
+	if err := ob.AddOrder(buy); err == nil {
+		t.Error("expected quote quantity orders to be rejected")
+	}
+}
+
+// mustNewSpreadOrder cria uma ordem limitada de CAL-SPREAD aceitando preços
+// zero e negativos
+func mustNewSpreadOrder(t *testing.T, side order.Side, price, quantity float64) *order.Order {
+	t.Helper()
+	o, err := order.NewOrderOfType(order.TypeLimit, side, "CAL-SPREAD", price, 0, quantity, true)
+	if err != nil {
+		t.Fatalf("unexpected error creating order: %v", err)
+	}
+	return o
+}
+
+func assertLevelPrices(t *testing.T, side string, levels []PriceLevel, want []float64) {
+	t.Helper()
+	if len(levels) != len(want) {
+		t.Fatalf("%s: got %d levels, want %d", side, len(levels), len(want))
+	}
+	for i, level := range levels {
+		if level.Price != want[i] {
+			t.Errorf("%s[%d] = %v, want %v", side, i, level.Price, want[i])
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package orderbook
+
+import (
+	"fmt"
+	"math"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// checkMinNotional rejeita ordens cujo valor, preço × quantidade, fica
+// abaixo do mínimo do símbolo. Ordens a mercado são avaliadas pelo melhor
+// preço do lado oposto; sem ele, não há como estimar e a ordem passa.
+func (ob *OrderBook) checkMinNotional(o *order.Order) error {
+	if ob.config.MinNotional <= 0 {
+		return nil
+	}
+
+	notional, ok := ob.estimatedNotional(o)
+	if !ok || notional >= ob.config.MinNotional {
+		return nil
+	}
+	return fmt.Errorf("%w: order value %v is under the minimum of %v for %s",
+		ErrBelowMinNotional, notional, ob.config.MinNotional, ob.symbol)
+}
+
+// estimatedNotional devolve o valor da ordem no seu preço limite ou, a
+// mercado, no melhor preço que ela encontraria. Compras por valor já dizem
+// quanto vão gastar.
+func (ob *OrderBook) estimatedNotional(o *order.Order) (float64, bool) {
+	if o.QuoteQuantity > 0 {
+		return o.QuoteQuantity, true
+	}
+
+	price := o.Price
+	if o.Type == order.TypeMarket {
+		var ok bool
+		if o.Side == order.SideBuy {
+			price, _, ok = ob.bestAsk()
+		} else {
+			price, _, ok = ob.bestBid()
+		}
+		if !ok {
+			return 0, false
+		}
+	}
+	return math.Abs(price) * o.Quantity, true
+}
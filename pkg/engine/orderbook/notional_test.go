@@ -0,0 +1,86 @@
+package orderbook
+
+import (
+	"errors"
+	"testing"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestOrderBook_MinNotional(t *testing.T) {
+	newBook := func(t *testing.T) *OrderBook {
+		t.Helper()
+		ob := NewOrderBookWithConfig(SymbolConfig{Symbol: "BTC-USD", MinNotional: 10})
+
+		// Liquidez dos dois lados para as ordens a mercado: compra a 100,
+		// venda a 200
+		if err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0)); err != nil {
+			t.Fatalf("unexpected error adding bid: %v", err)
+		}
+		if err := ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 200.0, 1.0)); err != nil {
+			t.Fatalf("unexpected error adding ask: %v", err)
+		}
+		return ob
+	}
+
+	tests := []struct {
+		name      string
+		orderType order.Type
+		side      order.Side
+		price     float64
+		quantity  float64
+		wantErr   bool
+	}{
+		{"limit at the minimum", order.TypeLimit, order.SideBuy, 50.0, 0.2, false},
+		{"limit just above", order.TypeLimit, order.SideBuy, 50.0, 0.21, false},
+		{"limit just below", order.TypeLimit, order.SideBuy, 50.0, 0.19, true},
+		{"market buy just above, at the best ask", order.TypeMarket, order.SideBuy, 0, 0.051, false},
+		{"market buy just below, at the best ask", order.TypeMarket, order.SideBuy, 0, 0.049, true},
+		{"market sell just above, at the best bid", order.TypeMarket, order.SideSell, 0, 0.11, false},
+		{"market sell just below, at the best bid", order.TypeMarket, order.SideSell, 0, 0.09, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ob := newBook(t)
+			o, err := order.NewOrderOfType(tt.orderType, tt.side, "BTC-USD", tt.price, 0, tt.quantity, false)
+			if err != nil {
+				t.Fatalf("unexpected error creating order: %v", err)
+			}
+
+			err = ob.AddOrder(o)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AddOrder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				return
+			}
+			if !errors.Is(err, ErrBelowMinNotional) {
+				t.Errorf("expected ErrBelowMinNotional, got %v", err)
+			}
+			if err := ob.ValidateOrder(o); !errors.Is(err, ErrBelowMinNotional) {
+				t.Errorf("expected ValidateOrder to report ErrBelowMinNotional, got %v", err)
+			}
+		})
+	}
+
+	// Compras por valor contam o que gastam
+	ob := newBook(t)
+	dust, err := order.NewQuoteMarketBuy("BTC-USD", 9.99)
+	if err != nil {
+		t.Fatalf("unexpected error creating order: %v", err)
+	}
+	if err := ob.AddOrder(dust); !errors.Is(err, ErrBelowMinNotional) {
+		t.Errorf("expected ErrBelowMinNotional for a 9.99 quote buy, got %v", err)
+	}
+
+	// Sem lado oposto não há como avaliar uma ordem a mercado
+	empty := NewOrderBookWithConfig(SymbolConfig{Symbol: "BTC-USD", MinNotional: 10})
+	market, err := order.NewOrderOfType(order.TypeMarket, order.SideBuy, "BTC-USD", 0, 0, 0.001, false)
+	if err != nil {
+		t.Fatalf("unexpected error creating order: %v", err)
+	}
+	if err := empty.AddOrder(market); err != nil {
+		t.Errorf("unexpected error for a market order on an empty book: %v", err)
+	}
+}
@@ -0,0 +1,1256 @@
+package orderbook
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"company.com/matchengine/pkg/engine/order"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// PriceLevel representa um nível de preço no order book
+type PriceLevel struct {
+	Price    float64
+	Orders   []*order.Order
+	Next     *PriceLevel
+	Previous *PriceLevel
+
+	// view é a última cópia publicada do nível, refeita quando ele muda
+	view *levelView
+}
+
+// OrderBook representa o livro de ordens usando uma lista duplamente encadeada
+type OrderBook struct {
+	symbol     string
+	config     SymbolConfig
+	buyLevels  *PriceLevel
+	sellLevels *PriceLevel
+	orders     map[string]*order.Order
+	// deadlines guarda os IDs das ordens que repousaram com ExpiresAt; as
+	// que já saíram do livro são descartadas na varredura seguinte
+	deadlines  map[string]bool
+	tradeCount uint64
+	// lastTrade é o negócio mais recente, mantido até o próximo
+	lastTrade *Trade
+	mutex     sync.RWMutex
+	// configMutex ordena as trocas de configuração com quem segura a
+	// configuração atual por HoldConfig; é sempre obtido antes de mutex
+	configMutex sync.RWMutex
+
+	// sequence avança uma vez por operação que altera o livro; changed marca
+	// a operação corrente como alteradora até o unlock
+	sequence uint64
+	changed  bool
+
+	halted       bool
+	retired      bool
+	resumeAt     time.Time
+	priceWindow  []pricePoint
+	auction      bool
+	pendingHalt  *HaltEvent
+	haltListener func(HaltEvent)
+
+	pendingViolation  *InvariantViolation
+	invariantListener func(InvariantViolation)
+
+	pendingUpdates []Update
+	updateListener func([]Update)
+
+	// view é a versão do livro lida pelos snapshots, republicada a cada
+	// operação que o altera; touched são os níveis alterados desde então
+	view    atomic.Pointer[bookView]
+	touched map[levelKey]bool
+
+	now func() time.Time
+}
+
+func NewOrderBook(symbol string) *OrderBook {
+	return NewOrderBookWithConfig(SymbolConfig{Symbol: symbol})
+}
+
+// NewOrderBookWithConfig cria um livro com os parâmetros do símbolo
+func NewOrderBookWithConfig(config SymbolConfig) *OrderBook {
+	return &OrderBook{
+		symbol:    config.Symbol,
+		config:    config,
+		orders:    make(map[string]*order.Order),
+		deadlines: make(map[string]bool),
+		now:       time.Now,
+	}
+}
+
+// Config retorna os parâmetros atuais do símbolo
+func (ob *OrderBook) Config() SymbolConfig {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	return ob.config
+}
+
+// Halt suspende a negociação: novas ordens e alterações são rejeitadas e
+// nada é casado, mas cancelamentos continuam permitidos
+func (ob *OrderBook) Halt() {
+	ob.mutex.Lock()
+	defer ob.mutex.Unlock()
+
+	ob.halted = true
+	ob.resumeAt = time.Time{}
+}
+
+// Resume retoma a negociação suspensa por Halt ou pelo circuit breaker
+func (ob *OrderBook) Resume() {
+	ob.mutex.Lock()
+	defer ob.mutex.Unlock()
+
+	ob.halted = false
+	ob.resumeAt = time.Time{}
+}
+
+// IsHalted indica se a negociação está suspensa
+func (ob *OrderBook) IsHalted() bool {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	return ob.haltActive()
+}
+
+// SetHaltListener registra quem deve ser avisado quando o circuit breaker
+// suspende o livro. O aviso é feito fora do lock do livro.
+func (ob *OrderBook) SetHaltListener(listener func(HaltEvent)) {
+	ob.mutex.Lock()
+	defer ob.mutex.Unlock()
+
+	ob.haltListener = listener
+}
+
+// SetClock substitui a fonte de tempo do livro, usada nos horários dos
+// negócios e na retomada após o circuit breaker
+func (ob *OrderBook) SetClock(now func() time.Time) {
+	ob.mutex.Lock()
+	defer ob.mutex.Unlock()
+
+	ob.now = now
+}
+
+// haltActive indica se a suspensão está em vigor, considerando a retomada
+// automática após o cooldown do circuit breaker
+func (ob *OrderBook) haltActive() bool {
+	if !ob.halted {
+		return false
+	}
+	if !ob.resumeAt.IsZero() && !ob.now().Before(ob.resumeAt) {
+		return false
+	}
+	return true
+}
+
+// SetConfig substitui os parâmetros do símbolo. Ordens já no livro não são afetadas.
+func (ob *OrderBook) SetConfig(config SymbolConfig) {
+	ob.Reconfigure(config, nil)
+}
+
+// Reconfigure substitui os parâmetros do símbolo e, quando halted não é
+// nil, suspende ou retoma a negociação, tudo de uma vez: nenhuma ordem é
+// validada com parte da configuração nova. Espera quem segura a
+// configuração atual com HoldConfig.
+func (ob *OrderBook) Reconfigure(config SymbolConfig, halted *bool) {
+	ob.configMutex.Lock()
+	defer ob.configMutex.Unlock()
+	ob.mutex.Lock()
+	defer ob.mutex.Unlock()
+
+	config.Symbol = ob.symbol
+	ob.config = config
+	if halted != nil {
+		ob.halted = *halted
+		ob.resumeAt = time.Time{}
+	}
+}
+
+// HoldConfig devolve a configuração atual e a mantém até que release seja
+// chamada. Quem normaliza e verifica uma ordem fora do lock do livro antes
+// de adicioná-la usa HoldConfig para ver uma só configuração do começo ao
+// fim. Não chame Reconfigure nem HoldConfig antes de release.
+func (ob *OrderBook) HoldConfig() (config SymbolConfig, release func()) {
+	ob.configMutex.RLock()
+	return ob.Config(), ob.configMutex.RUnlock
+}
+
+// AddOrder adiciona uma ordem ao livro
+func (ob *OrderBook) AddOrder(o *order.Order) error {
+	return ob.AddOrderContext(context.Background(), o)
+}
+
+// AddOrderContext adiciona uma ordem ao livro, registrando a operação e o
+// matching como spans filhos do span em ctx. Uma ordem aceita leva em
+// MatchLatencyMicros o tempo, no relógio monotônico, de quando o lock é
+// obtido até o fim do matching; a espera pelo lock fica de fora.
+func (ob *OrderBook) AddOrderContext(ctx context.Context, o *order.Order) (err error) {
+	ctx, span := startSpan(ctx, "orderbook.AddOrder", o)
+	defer func() { endSpan(span, err) }()
+
+	if err := ob.acceptsOrder(o); err != nil {
+		return err
+	}
+
+	ob.mutex.Lock()
+	defer ob.unlock()
+	start := time.Now()
+
+	err = ob.addOrderGuarded(ctx, o)
+	if err == nil {
+		o.MatchLatencyMicros = float64(time.Since(start)) / float64(time.Microsecond)
+	}
+	ob.checkInvariants(o)
+	return err
+}
+
+// acceptsOrder verifica o que não depende do estado do livro
+func (ob *OrderBook) acceptsOrder(o *order.Order) error {
+	if o.Symbol != ob.symbol {
+		return fmt.Errorf("%w: %s", ErrInvalidSymbol, o.Symbol)
+	}
+	if o.Type == order.TypeStop || o.Type == order.TypeStopLimit {
+		return fmt.Errorf("order type not supported by the order book: %s", o.Type)
+	}
+	return nil
+}
+
+// ValidateOrder submete a ordem às mesmas verificações de AddOrder sem
+// alterar o livro. A ordem é normalizada como seria ao entrar.
+func (ob *OrderBook) ValidateOrder(o *order.Order) error {
+	if err := ob.acceptsOrder(o); err != nil {
+		return err
+	}
+
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	return ob.checkOrder(o)
+}
+
+// checkOrder verifica o que depende do estado do livro: aposentadoria,
+// suspensão, precisão, banda de preço, cruzamento, valor mínimo, profundidade
+// e fase de leilão; exige o lock
+func (ob *OrderBook) checkOrder(o *order.Order) error {
+	if ob.retired {
+		return fmt.Errorf("%w: %s", ErrBookRetired, ob.symbol)
+	}
+	if ob.haltActive() {
+		return fmt.Errorf("%w for %s", ErrTradingHalted, ob.symbol)
+	}
+	if err := o.ValidatePrices(ob.config.AllowNegativePrice); err != nil {
+		return err
+	}
+	if err := ob.config.Normalize(o); err != nil {
+		return err
+	}
+	if err := ob.checkPriceBand(o, o.Price); err != nil {
+		return err
+	}
+	if err := ob.checkCrossing(o, o.Price); err != nil {
+		return err
+	}
+	if err := ob.checkMinNotional(o); err != nil {
+		return err
+	}
+	if err := ob.checkDepth(o); err != nil {
+		return err
+	}
+	if ob.auction && o.Type == order.TypeMarket {
+		return fmt.Errorf("%w: market orders are not accepted for %s", ErrAuctionInProgress, ob.symbol)
+	}
+	// Com preço zero ou negativo não há quantidade que caiba num orçamento
+	if ob.config.AllowNegativePrice && o.QuoteQuantity > 0 {
+		return fmt.Errorf("quote quantity orders are not accepted for %s, whose prices may be negative", ob.symbol)
+	}
+	return nil
+}
+
+// addOrder casa e repousa a ordem; exige o lock de escrita
+func (ob *OrderBook) addOrder(ctx context.Context, o *order.Order) error {
+	if err := ob.checkOrder(o); err != nil {
+		return err
+	}
+
+	// Na fase de leilão a ordem só repousa; o casamento fica para RunAuction
+	if ob.auction {
+		ob.emit(o, nil)
+		ob.restOrder(o)
+		return nil
+	}
+	ob.emit(o, nil)
+
+	// Try to match the order first
+	if err := ob.tracedMatch(ctx, o); err != nil {
+		return err
+	}
+
+	// O circuit breaker interrompeu a varredura: o restante não repousa
+	// cruzando o livro, é cancelado
+	if ob.pendingHalt != nil {
+		ob.cancelRemainder(o)
+		return nil
+	}
+
+	// Ordens a mercado nunca ficam no livro: o que sobrar é cancelado
+	if o.Type == order.TypeMarket {
+		ob.cancelRemainder(o)
+		return nil
+	}
+
+	// If order is not fully filled, add to book
+	if o.Status != order.StatusFilled {
+		ob.restOrder(o)
+	}
+
+	// Process the match after adding the order
+	ob.match()
+
+	return nil
+}
+
+// AmendOrder altera preço e quantidade de uma ordem do livro. Reduzir a
+// quantidade no mesmo preço preserva a prioridade; mudar o preço ou aumentar
+// a quantidade reenfileira a ordem no fim do nível de destino.
+func (ob *OrderBook) AmendOrder(orderID string, price, quantity float64) (err error) {
+	ob.mutex.Lock()
+	defer ob.unlock()
+	defer ob.recoverFailure(&err)
+
+	if ob.haltActive() {
+		return fmt.Errorf("%w for %s", ErrTradingHalted, ob.symbol)
+	}
+
+	o, exists := ob.orders[orderID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+
+	price, err = ob.config.roundPrice(price)
+	if err != nil {
+		return err
+	}
+	quantity, err = ob.config.roundQuantity(quantity)
+	if err != nil {
+		return err
+	}
+
+	oldPrice := o.Price
+	keepsPriority := price == oldPrice && quantity <= o.Quantity
+
+	if price != oldPrice {
+		if err := ob.checkPriceBand(o, price); err != nil {
+			return err
+		}
+		if err := ob.checkCrossing(o, price); err != nil {
+			return err
+		}
+	}
+
+	if err := o.Amend(price, quantity, ob.config.AllowNegativePrice); err != nil {
+		return err
+	}
+	ob.touch(o.Side, oldPrice)
+	ob.emitAmended(o)
+	if keepsPriority {
+		return nil
+	}
+
+	// Perde a prioridade: sai do nível atual e entra como uma ordem nova
+	ob.removeOrder(o, oldPrice)
+	delete(ob.orders, o.ID)
+
+	if ob.auction {
+		ob.restOrder(o)
+		return nil
+	}
+	if err := ob.tryMatch(o); err != nil {
+		return err
+	}
+	if ob.pendingHalt != nil {
+		ob.cancelRemainder(o)
+		return nil
+	}
+	if o.Status != order.StatusFilled {
+		ob.restOrder(o)
+	}
+	ob.match()
+
+	return nil
+}
+
+// ReduceOrder diminui em reduceBy a quantidade de uma ordem do livro sem
+// mexer no preço nem na prioridade, e devolve o estado resultante. A
+// quantidade nunca fica abaixo do que já foi executado: uma redução que não
+// deixa nada a executar cancela a ordem. Como um cancelamento, é aceita com
+// a negociação suspensa.
+func (ob *OrderBook) ReduceOrder(orderID string, reduceBy float64) (order.Order, error) {
+	if math.IsNaN(reduceBy) || math.IsInf(reduceBy, 0) || reduceBy <= 0 {
+		return order.Order{}, fmt.Errorf("reduction must be positive")
+	}
+
+	ob.mutex.Lock()
+	defer ob.unlock()
+
+	o, exists := ob.orders[orderID]
+	if !exists {
+		return order.Order{}, fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+
+	quantity, err := ob.config.roundQuantity(o.Quantity - reduceBy)
+	if err != nil {
+		return order.Order{}, err
+	}
+	if quantity <= o.Filled {
+		if err := ob.cancelOrder(orderID); err != nil {
+			return order.Order{}, err
+		}
+		return *o, nil
+	}
+
+	if err := o.Amend(o.Price, quantity, ob.config.AllowNegativePrice); err != nil {
+		return order.Order{}, err
+	}
+	ob.emitAmended(o)
+	return *o, nil
+}
+
+// restOrder coloca a ordem no fim da fila do seu nível de preço
+func (ob *OrderBook) restOrder(o *order.Order) {
+	ob.makeRoom(o)
+	ob.touch(o.Side, o.Price)
+
+	switch o.Side {
+	case order.SideBuy:
+		ob.addBuyOrder(o)
+	case order.SideSell:
+		ob.addSellOrder(o)
+	}
+	ob.orders[o.ID] = o
+	if !o.ExpiresAt.IsZero() {
+		ob.deadlines[o.ID] = true
+	}
+}
+
+func (ob *OrderBook) addBuyOrder(o *order.Order) {
+	level := ob.findOrCreateBuyLevel(o.Price)
+	level.Orders = append(level.Orders, o)
+}
+
+func (ob *OrderBook) addSellOrder(o *order.Order) {
+	level := ob.findOrCreateSellLevel(o.Price)
+	level.Orders = append(level.Orders, o)
+}
+
+// findOrCreateBuyLevel encontra ou cria um nível de preço de compra
+func (ob *OrderBook) findOrCreateBuyLevel(price float64) *PriceLevel {
+	if ob.buyLevels == nil || price > ob.buyLevels.Price {
+		level := &PriceLevel{
+			Price: price,
+			Next:  ob.buyLevels,
+		}
+		if level.Next != nil {
+			level.Next.Previous = level
+		}
+		ob.buyLevels = level
+		return level
+	}
+
+	current := ob.buyLevels
+	for current.Next != nil && price < current.Next.Price {
+		current = current.Next
+	}
+
+	// O laço para antes de um nível com o mesmo preço, que deve ser
+	// reaproveitado em vez de duplicado
+	if current.Price == price {
+		return current
+	}
+	if current.Next != nil && current.Next.Price == price {
+		return current.Next
+	}
+
+	newLevel := &PriceLevel{
+		Price:    price,
+		Next:     current.Next,
+		Previous: current,
+	}
+	if newLevel.Next != nil {
+		newLevel.Next.Previous = newLevel
+	}
+	current.Next = newLevel
+	return newLevel
+}
+
+// findOrCreateSellLevel encontra ou cria um nível de preço de venda
+func (ob *OrderBook) findOrCreateSellLevel(price float64) *PriceLevel {
+	if ob.sellLevels == nil || price < ob.sellLevels.Price {
+		level := &PriceLevel{
+			Price: price,
+			Next:  ob.sellLevels,
+		}
+		if level.Next != nil {
+			level.Next.Previous = level
+		}
+		ob.sellLevels = level
+		return level
+	}
+
+	current := ob.sellLevels
+	for current.Next != nil && price > current.Next.Price {
+		current = current.Next
+	}
+
+	// O laço para antes de um nível com o mesmo preço, que deve ser
+	// reaproveitado em vez de duplicado
+	if current.Price == price {
+		return current
+	}
+	if current.Next != nil && current.Next.Price == price {
+		return current.Next
+	}
+
+	newLevel := &PriceLevel{
+		Price:    price,
+		Next:     current.Next,
+		Previous: current,
+	}
+	if newLevel.Next != nil {
+		newLevel.Next.Previous = newLevel
+	}
+	current.Next = newLevel
+	return newLevel
+}
+
+// match tenta casar ordens compatíveis
+func (ob *OrderBook) match() {
+	for ob.buyLevels != nil && ob.sellLevels != nil {
+		bestBuy := ob.buyLevels
+		bestSell := ob.sellLevels
+
+		// Se não há match possível, para
+		if bestBuy.Price < bestSell.Price {
+			break
+		}
+
+		// Processa ordens neste nível de preço; um fill rejeitado interrompe
+		// o matching em vez de repetir o mesmo par indefinidamente
+		if !ob.processLevelMatch(bestBuy, bestSell) {
+			break
+		}
+
+		// Remove níveis vazios
+		ob.cleanupEmptyLevels()
+	}
+}
+
+// processLevelMatch casa as ordens dos dois níveis e retorna false se um
+// fill foi rejeitado
+func (ob *OrderBook) processLevelMatch(buyLevel, sellLevel *PriceLevel) bool {
+	for len(buyLevel.Orders) > 0 && len(sellLevel.Orders) > 0 {
+		buy := buyLevel.Orders[0]
+		sell := sellLevel.Orders[0]
+
+		// Calculate match quantity
+		matchQty := min(buy.RemainingQuantity(), sell.RemainingQuantity())
+
+		// O negócio sai ao preço da ordem que chegou primeiro
+		price := sell.Price
+		if buy.CreatedAt.Before(sell.CreatedAt) {
+			price = buy.Price
+		}
+
+		// Valida os dois lados antes de alterar qualquer um deles
+		if matchQty > 0 && (buy.ValidateFill(matchQty, price, ob.config.AllowNegativePrice) != nil || sell.ValidateFill(matchQty, price, ob.config.AllowNegativePrice) != nil) {
+			return false
+		}
+
+		// Execute the match
+		if matchQty > 0 {
+			buy.Fill(matchQty, price, ob.config.AllowNegativePrice)
+			sell.Fill(matchQty, price, ob.config.AllowNegativePrice)
+			ob.tradeCount++
+			ob.emit(buy, nil)
+			ob.emit(sell, nil)
+		}
+
+		// Remove filled orders
+		if buy.Status == order.StatusFilled {
+			buyLevel.Orders = buyLevel.Orders[1:]
+		}
+		if sell.Status == order.StatusFilled {
+			sellLevel.Orders = sellLevel.Orders[1:]
+		}
+	}
+	return true
+}
+
+func (ob *OrderBook) cleanupEmptyLevels() {
+	// Limpa níveis vazios de compra
+	for ob.buyLevels != nil && len(ob.buyLevels.Orders) == 0 {
+		ob.buyLevels = ob.buyLevels.Next
+		if ob.buyLevels != nil {
+			ob.buyLevels.Previous = nil
+		}
+	}
+
+	// Limpa níveis vazios de venda
+	for ob.sellLevels != nil && len(ob.sellLevels.Orders) == 0 {
+		ob.sellLevels = ob.sellLevels.Next
+		if ob.sellLevels != nil {
+			ob.sellLevels.Previous = nil
+		}
+	}
+}
+
+func min(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// ActiveOrderCount retorna o número de ordens ativas no livro
+func (ob *OrderBook) ActiveOrderCount() int {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	return len(ob.orders)
+}
+
+// Orders retorna cópias das ordens ativas do livro numa ordem estável: as
+// compras e depois as vendas, do melhor preço para o pior e por ordem de
+// chegada dentro de cada nível
+func (ob *OrderBook) Orders() []order.Order {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	orders := make([]order.Order, 0, len(ob.orders))
+	ob.eachActive(func(o *order.Order) bool {
+		orders = append(orders, *o)
+		return true
+	})
+	return orders
+}
+
+// IterateActiveOrders chama fn para cada ordem ativa, na ordem de Orders,
+// até fn retornar false. Cada chamada recebe uma cópia da ordem, sem alocar
+// a lista inteira. fn roda sob o lock de leitura do livro e não pode chamar
+// métodos que o alteram, como CancelOrder: quem quer agir sobre as ordens
+// guarda os IDs e age depois da iteração.
+func (ob *OrderBook) IterateActiveOrders(fn func(*order.Order) bool) {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	ob.eachActive(func(o *order.Order) bool {
+		copied := *o
+		return fn(&copied)
+	})
+}
+
+// eachActive percorre as ordens ativas dos níveis até fn retornar false;
+// exige o lock
+func (ob *OrderBook) eachActive(fn func(*order.Order) bool) {
+	for _, levels := range []*PriceLevel{ob.buyLevels, ob.sellLevels} {
+		for level := levels; level != nil; level = level.Next {
+			for _, o := range level.Orders {
+				if _, active := ob.orders[o.ID]; !active {
+					continue
+				}
+				if !fn(o) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// GetDepth retorna a profundidade atual do livro
+func (ob *OrderBook) GetDepth() Depth {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	depth := Depth{
+		Symbol:       ob.symbol,
+		ActiveOrders: len(ob.orders),
+	}
+	for level := ob.buyLevels; level != nil; level = level.Next {
+		depth.BidLevels++
+		depth.BidQuantity += levelQuantity(level)
+	}
+	for level := ob.sellLevels; level != nil; level = level.Next {
+		depth.AskLevels++
+		depth.AskQuantity += levelQuantity(level)
+	}
+	return depth
+}
+
+// Sequence retorna o número de sequência atual do livro, que avança uma vez
+// por operação que o altera
+func (ob *OrderBook) Sequence() uint64 {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	return ob.sequence
+}
+
+// TradeCount retorna o número de negócios executados no livro
+func (ob *OrderBook) TradeCount() uint64 {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	return ob.tradeCount
+}
+
+// GetOrder retorna uma cópia de uma ordem em repouso pelo ID, tirada sob o
+// lock: a ordem do livro continua mudando com os fills e não pode ser lida
+// fora dele
+func (ob *OrderBook) GetOrder(orderID string) (*order.Order, error) {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	o, exists := ob.orders[orderID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+	copied := *o
+	return &copied, nil
+}
+
+// Snapshot retorna uma cópia de uma ordem entregue a AddOrder, tirada sob o
+// lock. Quem adicionou a ordem a lê por aqui enquanto ela pode estar no
+// livro, recebendo fills de outras goroutines.
+func (ob *OrderBook) Snapshot(o *order.Order) order.Order {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	return *o
+}
+
+// CancelOrder cancela uma ordem existente
+func (ob *OrderBook) CancelOrder(orderID string) error {
+	return ob.CancelOrderContext(context.Background(), orderID)
+}
+
+// CancelOrderContext cancela uma ordem existente, registrando a operação
+// como span filho do span em ctx
+func (ob *OrderBook) CancelOrderContext(ctx context.Context, orderID string) (err error) {
+	_, span := startSpan(ctx, "orderbook.CancelOrder", nil,
+		attribute.String("symbol", ob.symbol),
+		attribute.String("order_id", orderID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	ob.mutex.Lock()
+	defer ob.unlock()
+
+	return ob.cancelOrder(orderID)
+}
+
+// CancelOrderIfMatch cancela a ordem somente se ela ainda tiver a ETag
+// informada, devolvendo ErrOrderChanged caso contrário. A comparação e o
+// cancelamento acontecem sob o mesmo lock, então nenhuma execução cabe entre
+// eles.
+func (ob *OrderBook) CancelOrderIfMatch(ctx context.Context, orderID, etag string) (err error) {
+	_, span := startSpan(ctx, "orderbook.CancelOrder", nil,
+		attribute.String("symbol", ob.symbol),
+		attribute.String("order_id", orderID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	ob.mutex.Lock()
+	defer ob.unlock()
+
+	if o, exists := ob.orders[orderID]; exists && o.ETag() != etag {
+		return fmt.Errorf("%w: %s", ErrOrderChanged, orderID)
+	}
+	return ob.cancelOrder(orderID)
+}
+
+// CancelAccountOrders cancela todas as ordens da conta numa única operação,
+// sem que nenhuma execução caiba entre os cancelamentos, e devolve o estado
+// final de cada uma na ordem do livro
+func (ob *OrderBook) CancelAccountOrders(accountID string) []order.Order {
+	ob.mutex.Lock()
+	defer ob.unlock()
+
+	var ids []string
+	for _, levels := range []*PriceLevel{ob.buyLevels, ob.sellLevels} {
+		for level := levels; level != nil; level = level.Next {
+			for _, o := range level.Orders {
+				if _, active := ob.orders[o.ID]; active && o.AccountID == accountID {
+					ids = append(ids, o.ID)
+				}
+			}
+		}
+	}
+
+	cancelled := make([]order.Order, 0, len(ids))
+	for _, id := range ids {
+		o := ob.orders[id]
+		if ob.cancelOrder(id) == nil {
+			cancelled = append(cancelled, *o)
+		}
+	}
+	return cancelled
+}
+
+// ReplaceOrders cancela e adiciona ordens numa única operação: nenhuma
+// leitura do livro vê os cancelamentos sem as novas ordens. Os erros são
+// devolvidos na ordem das requisições, nil para as que foram aplicadas.
+//
+// Uma nova ordem pode reaproveitar o ID de uma das canceladas; ela só entra
+// se esse cancelamento deu certo, e é rejeitada com ErrOriginalNotCancelled
+// se o ID falhou ao cancelar ou continua no livro. A substituta vai para o
+// fim da fila do seu preço, como qualquer ordem nova.
+func (ob *OrderBook) ReplaceOrders(cancelIDs []string, orders []*order.Order) (cancelErrs, addErrs []error) {
+	cancelErrs = make([]error, len(cancelIDs))
+	addErrs = make([]error, len(orders))
+
+	ob.mutex.Lock()
+	defer ob.unlock()
+
+	failed := make(map[string]bool)
+	for i, orderID := range cancelIDs {
+		if cancelErrs[i] = ob.cancelOrder(orderID); cancelErrs[i] != nil {
+			failed[orderID] = true
+		}
+	}
+	for i, o := range orders {
+		if _, live := ob.orders[o.ID]; live || failed[o.ID] {
+			addErrs[i] = fmt.Errorf("%w: %s", ErrOriginalNotCancelled, o.ID)
+			continue
+		}
+		if addErrs[i] = ob.acceptsOrder(o); addErrs[i] == nil {
+			addErrs[i] = ob.addOrderGuarded(context.Background(), o)
+		}
+	}
+	return cancelErrs, addErrs
+}
+
+// cancelOrder cancela e retira a ordem do livro; exige o lock de escrita
+func (ob *OrderBook) cancelOrder(orderID string) error {
+	o, exists := ob.orders[orderID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+
+	if err := o.Cancel(); err != nil {
+		return err
+	}
+	ob.emit(o, nil)
+
+	ob.removeOrder(o, o.Price)
+	delete(ob.orders, orderID)
+	return nil
+}
+
+// removeOrder retira a ordem do nível de preço informado, descartando o nível se ficar vazio
+func (ob *OrderBook) removeOrder(o *order.Order, price float64) {
+	ob.touch(o.Side, price)
+	link := &ob.buyLevels
+	if o.Side == order.SideSell {
+		link = &ob.sellLevels
+	}
+
+	for ; *link != nil; link = &(*link).Next {
+		level := *link
+		if level.Price != price {
+			continue
+		}
+
+		for i, resting := range level.Orders {
+			if resting == o {
+				level.Orders = append(level.Orders[:i:i], level.Orders[i+1:]...)
+				break
+			}
+		}
+		if len(level.Orders) == 0 {
+			*link = level.Next
+			if level.Next != nil {
+				level.Next.Previous = level.Previous
+			}
+		}
+		return
+	}
+}
+
+// GetBestBid retorna o melhor preço de compra
+func (ob *OrderBook) GetBestBid() (price, quantity float64, err error) {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	price, quantity, ok := ob.bestBid()
+	if !ok {
+		return 0, 0, fmt.Errorf("no bids available")
+	}
+	return price, quantity, nil
+}
+
+// GetBestAsk retorna o melhor preço de venda
+func (ob *OrderBook) GetBestAsk() (price, quantity float64, err error) {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	price, quantity, ok := ob.bestAsk()
+	if !ok {
+		return 0, 0, fmt.Errorf("no asks available")
+	}
+	return price, quantity, nil
+}
+
+// Imbalance retorna o desequilíbrio do topo do livro, bidQty / (bidQty + askQty).
+// Retorna false se algum dos lados estiver vazio.
+func (ob *OrderBook) Imbalance() (float64, bool) {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	_, bidQty, hasBid := ob.bestBid()
+	_, askQty, hasAsk := ob.bestAsk()
+	if !hasBid || !hasAsk {
+		return 0, false
+	}
+	return imbalance(bidQty, askQty)
+}
+
+// Microprice retorna o preço médio ponderado pelas quantidades do topo do livro.
+// Retorna false se algum dos lados estiver vazio.
+func (ob *OrderBook) Microprice() (float64, bool) {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	bid, bidQty, hasBid := ob.bestBid()
+	ask, askQty, hasAsk := ob.bestAsk()
+	if !hasBid || !hasAsk {
+		return 0, false
+	}
+	return microprice(bid, bidQty, ask, askQty)
+}
+
+// GetTicker retorna o topo do livro com os sinais de microestrutura e o
+// último negócio
+func (ob *OrderBook) GetTicker() *Ticker {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	ticker := &Ticker{Symbol: ob.symbol}
+
+	bid, bidQty, hasBid := ob.bestBid()
+	if hasBid {
+		ticker.BestBid, ticker.BestBidQty = &bid, &bidQty
+	}
+	ask, askQty, hasAsk := ob.bestAsk()
+	if hasAsk {
+		ticker.BestAsk, ticker.BestAskQty = &ask, &askQty
+	}
+
+	if hasBid && hasAsk {
+		if v, ok := imbalance(bidQty, askQty); ok {
+			ticker.Imbalance = &v
+		}
+		if v, ok := microprice(bid, bidQty, ask, askQty); ok {
+			ticker.Microprice = &v
+		}
+	}
+
+	if last := ob.lastTrade; last != nil {
+		price, quantity, at := last.Price, last.Quantity, last.Timestamp
+		ticker.LastPrice, ticker.LastQty, ticker.LastTradeAt = &price, &quantity, &at
+	}
+
+	return ticker
+}
+
+func (ob *OrderBook) bestBid() (price, quantity float64, ok bool) {
+	return bestLevel(ob.buyLevels)
+}
+
+func (ob *OrderBook) bestAsk() (price, quantity float64, ok bool) {
+	return bestLevel(ob.sellLevels)
+}
+
+// bestLevel retorna o primeiro nível com quantidade ativa, pulando níveis
+// cujas ordens estão todas canceladas ou executadas mas ainda não removidas
+func bestLevel(head *PriceLevel) (price, quantity float64, ok bool) {
+	for level := head; level != nil; level = level.Next {
+		if qty := levelQuantity(level); qty > 0 {
+			return level.Price, qty, true
+		}
+	}
+	return 0, 0, false
+}
+
+// levelQuantity soma a quantidade remanescente das ordens ativas de um nível
+func levelQuantity(level *PriceLevel) float64 {
+	totalQty := 0.0
+	for _, o := range level.Orders {
+		if o.IsActive() {
+			totalQty += o.RemainingQuantity()
+		}
+	}
+	return totalQty
+}
+
+func imbalance(bidQty, askQty float64) (float64, bool) {
+	total := bidQty + askQty
+	if total <= 0 {
+		return 0, false
+	}
+	return bidQty / total, true
+}
+
+func microprice(bid, bidQty, ask, askQty float64) (float64, bool) {
+	total := bidQty + askQty
+	if total <= 0 {
+		return 0, false
+	}
+	return (bid*askQty + ask*bidQty) / total, true
+}
+
+// tryMatch executa a ordem contra o lado oposto conforme as execuções que o
+// Matcher do símbolo atribui a ela
+func (ob *OrderBook) tryMatch(o *order.Order) error {
+	limit := o.Price
+	if o.Type == order.TypeMarket {
+		limit = marketLimit(o.Side)
+	}
+
+	// Ordens por valor em moeda de cotação acumulam a quantidade base
+	// comprada a cada execução
+	byQuote := o.QuoteQuantity > 0
+
+	var matchErr error
+	for _, fill := range ob.config.matcher().Match(o, ob.opposingLevels(o.Side), limit) {
+		restingOrder, matchQty := fill.Maker, fill.Quantity
+		if matchQty <= 0 || !restingOrder.IsActive() {
+			continue
+		}
+		if byQuote {
+			o.Quantity += matchQty
+		}
+
+		// Execute the match, checking both sides first so a rejected fill
+		// leaves neither order changed
+		price := ob.tradePrice(o, fill.Price)
+		if err := o.ValidateFill(matchQty, price, ob.config.AllowNegativePrice); err != nil {
+			matchErr = err
+			break
+		}
+		if err := restingOrder.ValidateFill(matchQty, price, ob.config.AllowNegativePrice); err != nil {
+			matchErr = err
+			break
+		}
+		o.Fill(matchQty, price, ob.config.AllowNegativePrice)
+		restingOrder.Fill(matchQty, price, ob.config.AllowNegativePrice)
+
+		if restingOrder.Status == order.StatusFilled {
+			delete(ob.orders, restingOrder.ID)
+		}
+
+		trade := Trade{
+			Symbol:         ob.symbol,
+			Price:          price,
+			Quantity:       matchQty,
+			TakerOrderID:   o.ID,
+			MakerOrderID:   restingOrder.ID,
+			TakerAccountID: o.AccountID,
+			MakerAccountID: restingOrder.AccountID,
+			TakerSide:      o.Side,
+			Timestamp:      ob.now(),
+		}
+		ob.chargeFees(&trade)
+		ob.emit(restingOrder, &trade)
+		ob.emit(o, &trade)
+
+		if ob.recordTrade(trade) {
+			break
+		}
+	}
+
+	ob.dropFilled(o.Side)
+
+	return matchErr
+}
+
+// tradePrice decide o preço de um negócio entre o agressor e uma ordem em
+// repouso no preço makerPrice, conforme a política do símbolo. O ponto médio
+// é levado ao tick; o preço do maker já está nele.
+func (ob *OrderBook) tradePrice(taker *order.Order, makerPrice float64) float64 {
+	if ob.config.TradePricing != TradePricingMidpoint || taker.Type == order.TypeMarket {
+		return makerPrice
+	}
+	return ob.config.roundTradePrice((taker.Price+makerPrice)/2, taker.Side)
+}
+
+// affordable devolve a maior quantidade que cabe em budget ao preço dado,
+// sem que quantidade × preço ultrapasse o orçamento por arredondamento
+func affordable(budget, price float64) float64 {
+	if budget <= 0 {
+		return 0
+	}
+	qty := budget / price
+	for qty > 0 && qty*price > budget {
+		qty = math.Nextafter(qty, 0)
+	}
+	return qty
+}
+
+// dropFilled retira do lado oposto as ordens já executadas e os níveis que
+// ficarem vazios. O Matcher percorre os níveis do melhor para o pior, então
+// a limpeza para no primeiro nível sem ordens executadas; dentro de um nível
+// elas podem estar em qualquer posição da fila.
+func (ob *OrderBook) dropFilled(side order.Side) {
+	for level := ob.opposingLevels(side); level != nil; level = level.Next {
+		filled := 0
+		for _, o := range level.Orders {
+			if o.Status == order.StatusFilled {
+				filled++
+			}
+		}
+		if filled == 0 {
+			break
+		}
+
+		// Uma fila nova, para não alterar a que snapshots já entregues
+		// compartilham
+		kept := make([]*order.Order, 0, len(level.Orders)-filled)
+		for _, o := range level.Orders {
+			if o.Status != order.StatusFilled {
+				kept = append(kept, o)
+			}
+		}
+		level.Orders = kept
+	}
+	ob.cleanupEmptyLevels()
+}
+
+// SimulateFill estima a execução de uma ordem a mercado sem alterar o livro
+func (ob *OrderBook) SimulateFill(side order.Side, quantity float64) *FillSimulation {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	remaining := quantity
+	notional := 0.0
+
+	ob.walkOpposing(side, marketLimit(side), func(level *PriceLevel, restingOrder *order.Order) bool {
+		matchQty := min(remaining, restingOrder.RemainingQuantity())
+		if matchQty <= 0 {
+			return true
+		}
+
+		notional += matchQty * level.Price
+		remaining -= matchQty
+		return remaining > 0
+	})
+
+	sim := &FillSimulation{
+		Symbol:    ob.symbol,
+		Side:      side,
+		Requested: quantity,
+		Filled:    quantity - remaining,
+		Unfilled:  remaining,
+	}
+	if sim.Filled > 0 {
+		sim.AvgPrice = notional / sim.Filled
+	}
+	return sim
+}
+
+// SimulateOrder executa a ordem contra o lado oposto como AddOrder faria,
+// pelo Matcher do símbolo, mas sem alterar o livro: as ordens em repouso não
+// são tocadas e nada repousa. Só a ordem recebe as execuções; os negócios
+// devolvidos não identificam a contraparte. A ordem deve ter passado por
+// ValidateOrder.
+func (ob *OrderBook) SimulateOrder(o *order.Order) []Trade {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	limit := o.Price
+	if o.Type == order.TypeMarket {
+		limit = marketLimit(o.Side)
+	}
+	byQuote := o.QuoteQuantity > 0
+
+	var trades []Trade
+	for _, fill := range ob.config.matcher().Match(o, ob.opposingLevels(o.Side), limit) {
+		if fill.Quantity <= 0 || !fill.Maker.IsActive() {
+			continue
+		}
+		if byQuote {
+			o.Quantity += fill.Quantity
+		}
+
+		price := ob.tradePrice(o, fill.Price)
+		if err := o.Fill(fill.Quantity, price, ob.config.AllowNegativePrice); err != nil {
+			if byQuote {
+				o.Quantity -= fill.Quantity
+			}
+			break
+		}
+
+		trade := Trade{
+			Symbol:         ob.symbol,
+			Price:          price,
+			Quantity:       fill.Quantity,
+			TakerOrderID:   o.ID,
+			TakerAccountID: o.AccountID,
+			TakerSide:      o.Side,
+			Timestamp:      ob.now(),
+		}
+		ob.chargeFees(&trade)
+		trades = append(trades, trade)
+	}
+	return trades
+}
+
+// walkOpposing percorre, em prioridade preço-tempo, as ordens ativas do lado
+// oposto cujo preço cruza o limite informado. O percurso é somente leitura;
+// visit retorna false para interrompê-lo.
+func (ob *OrderBook) walkOpposing(side order.Side, limit float64, visit func(level *PriceLevel, restingOrder *order.Order) bool) {
+	for level := ob.opposingLevels(side); level != nil; level = level.Next {
+		if !crosses(side, limit, level.Price) {
+			return
+		}
+
+		for _, restingOrder := range level.Orders {
+			if restingOrder.Status == order.StatusCancelled {
+				continue
+			}
+			if !visit(level, restingOrder) {
+				return
+			}
+		}
+	}
+}
+
+// opposingLevels retorna o melhor nível do lado oposto ao informado
+func (ob *OrderBook) opposingLevels(side order.Side) *PriceLevel {
+	switch side {
+	case order.SideBuy:
+		return ob.sellLevels
+	case order.SideSell:
+		return ob.buyLevels
+	}
+	return nil
+}
+
+// crosses indica se um preço limite alcança o preço de um nível oposto
+func crosses(side order.Side, limit, levelPrice float64) bool {
+	if side == order.SideBuy {
+		return limit >= levelPrice
+	}
+	return limit <= levelPrice
+}
+
+// marketLimit retorna um preço limite que cruza qualquer nível oposto
+func marketLimit(side order.Side) float64 {
+	if side == order.SideBuy {
+		return math.Inf(1)
+	}
+	return math.Inf(-1)
+}
@@ -0,0 +1,1080 @@
+package orderbook
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestOrderBook_AddOrder(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+
+	tests := []struct {
+		name    string
+		order   *order.Order
+		wantErr bool
+	}{
+		{
+			name: "valid buy order",
+			order: mustNewOrder(
+				t,
+				order.SideBuy,
+				"BTC-USD",
+				50000.0,
+				1.0,
+			),
+			wantErr: false,
+		},
+		{
+			name: "valid sell order",
+			order: mustNewOrder(
+				t,
+				order.SideSell,
+				"BTC-USD",
+				50100.0,
+				1.0,
+			),
+			wantErr: false,
+		},
+		{
+			name: "invalid symbol",
+			order: mustNewOrder(
+				t,
+				order.SideBuy,
+				"ETH-USD",
+				50000.0,
+				1.0,
+			),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ob.AddOrder(tt.order)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("OrderBook.AddOrder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOrderBook_Match(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+
+	// Adiciona ordem de compra
+	buyOrder := mustNewOrder(
+		t,
+		order.SideBuy,
+		"BTC-USD",
+		50000.0,
+		2.0,
+	)
+	ob.AddOrder(buyOrder)
+
+	// Adiciona ordem de venda que deve casar parcialmente
+	sellOrder := mustNewOrder(
+		t,
+		order.SideSell,
+		"BTC-USD",
+		50000.0,
+		1.0,
+	)
+	ob.AddOrder(sellOrder)
+
+	// Verifica se o matching ocorreu corretamente
+	if buyOrder.Status != order.StatusPartial {
+		t.Errorf("expected buy order status to be %v, got %v", order.StatusPartial, buyOrder.Status)
+	}
+	if sellOrder.Status != order.StatusFilled {
+		t.Errorf("expected sell order status to be %v, got %v", order.StatusFilled, sellOrder.Status)
+	}
+	if buyOrder.Filled != 1.0 {
+		t.Errorf("expected buy order filled quantity to be 1.0, got %v", buyOrder.Filled)
+	}
+	if sellOrder.Filled != 1.0 {
+		t.Errorf("expected sell order filled quantity to be 1.0, got %v", sellOrder.Filled)
+	}
+}
+
+func TestOrderBook_CancelOrder(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+
+	// Adiciona ordem de compra
+	o := mustNewOrder(
+		t,
+		order.SideBuy,
+		"BTC-USD",
+		50000.0,
+		1.0,
+	)
+	ob.AddOrder(o)
+
+	// Tenta cancelar
+	err := ob.CancelOrder(o.ID)
+	if err != nil {
+		t.Errorf("unexpected error canceling order: %v", err)
+	}
+
+	// Verifica se a ordem foi cancelada
+	if o.Status != order.StatusCancelled {
+		t.Errorf("expected order status to be %v, got %v", order.StatusCancelled, o.Status)
+	}
+
+	// Tenta cancelar ordem inexistente
+	err = ob.CancelOrder("invalid-id")
+	if err == nil {
+		t.Error("expected error canceling invalid order")
+	}
+}
+
+func TestOrderBook_CancelFilledOrder(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+
+	resting := mustNewOrder(t, order.SideBuy, "BTC-USD", 50000.0, 1.0)
+	ob.AddOrder(resting)
+
+	aggressor := mustNewOrder(t, order.SideSell, "BTC-USD", 50000.0, 1.0)
+	ob.AddOrder(aggressor)
+
+	for _, o := range []*order.Order{resting, aggressor} {
+		if o.Status != order.StatusFilled {
+			t.Fatalf("expected order to be filled, got %v", o.Status)
+		}
+		updatedAt := o.UpdatedAt
+
+		if err := ob.CancelOrder(o.ID); err == nil {
+			t.Error("expected error cancelling a filled order")
+		}
+		if o.Status != order.StatusFilled {
+			t.Errorf("expected status to remain %v, got %v", order.StatusFilled, o.Status)
+		}
+		if !o.UpdatedAt.Equal(updatedAt) {
+			t.Error("expected UpdatedAt to be unchanged by a rejected cancel")
+		}
+	}
+}
+
+func TestOrderBook_GetBestPrices(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+
+	// Inicialmente não deve ter preços
+	_, _, err := ob.GetBestBid()
+	if err == nil {
+		t.Error("expected error when no bids available")
+	}
+
+	_, _, err = ob.GetBestAsk()
+	if err == nil {
+		t.Error("expected error when no asks available")
+	}
+
+	// Adiciona ordens
+	buyOrder := mustNewOrder(
+		t,
+		order.SideBuy,
+		"BTC-USD",
+		50000.0,
+		1.0,
+	)
+	ob.AddOrder(buyOrder)
+
+	sellOrder := mustNewOrder(
+		t,
+		order.SideSell,
+		"BTC-USD",
+		50100.0,
+		1.0,
+	)
+	ob.AddOrder(sellOrder)
+
+	// Verifica melhor bid
+	price, qty, err := ob.GetBestBid()
+	if err != nil {
+		t.Errorf("unexpected error getting best bid: %v", err)
+	}
+	if price != 50000.0 {
+		t.Errorf("expected best bid price to be 50000.0, got %v", price)
+	}
+	if qty != 1.0 {
+		t.Errorf("expected best bid quantity to be 1.0, got %v", qty)
+	}
+
+	// Verifica melhor ask
+	price, qty, err = ob.GetBestAsk()
+	if err != nil {
+		t.Errorf("unexpected error getting best ask: %v", err)
+	}
+	if price != 50100.0 {
+		t.Errorf("expected best ask price to be 50100.0, got %v", price)
+	}
+	if qty != 1.0 {
+		t.Errorf("expected best ask quantity to be 1.0, got %v", qty)
+	}
+}
+
+func TestOrderBook_GetBestPrices_SkipsInactiveLevels(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+
+	staleBid := mustNewOrder(t, order.SideBuy, "BTC-USD", 50000.0, 1.0)
+	ob.AddOrder(staleBid)
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 49900.0, 2.0))
+
+	staleAsk := mustNewOrder(t, order.SideSell, "BTC-USD", 50100.0, 1.0)
+	ob.AddOrder(staleAsk)
+	ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 50200.0, 3.0))
+
+	// Marca as ordens do topo como inativas sem removê-las do nível
+	staleBid.Status = order.StatusCancelled
+	staleAsk.Status = order.StatusFilled
+
+	price, qty, err := ob.GetBestBid()
+	if err != nil {
+		t.Fatalf("unexpected error getting best bid: %v", err)
+	}
+	if price != 49900.0 || qty != 2.0 {
+		t.Errorf("expected best bid 49900.0 x 2.0, got %v x %v", price, qty)
+	}
+
+	price, qty, err = ob.GetBestAsk()
+	if err != nil {
+		t.Fatalf("unexpected error getting best ask: %v", err)
+	}
+	if price != 50200.0 || qty != 3.0 {
+		t.Errorf("expected best ask 50200.0 x 3.0, got %v x %v", price, qty)
+	}
+}
+
+func TestOrderBook_ImbalanceAndMicroprice(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+
+	// Livro vazio não produz sinais
+	if _, ok := ob.Imbalance(); ok {
+		t.Error("expected no imbalance for an empty book")
+	}
+	if _, ok := ob.Microprice(); ok {
+		t.Error("expected no microprice for an empty book")
+	}
+
+	// Duas ordens no melhor bid (3.0 no total) e uma no melhor ask (1.0)
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 99.0, 2.0))
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 99.0, 1.0))
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 98.0, 5.0))
+
+	// Livro com apenas um lado também não produz sinais
+	if _, ok := ob.Imbalance(); ok {
+		t.Error("expected no imbalance for a one-sided book")
+	}
+	ticker := ob.GetTicker()
+	if ticker.BestBid == nil || *ticker.BestBid != 99.0 {
+		t.Errorf("expected best bid 99.0, got %v", ticker.BestBid)
+	}
+	if ticker.BestAsk != nil || ticker.Imbalance != nil || ticker.Microprice != nil {
+		t.Error("expected null ask, imbalance and microprice for a one-sided book")
+	}
+
+	ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 101.0, 1.0))
+
+	imb, ok := ob.Imbalance()
+	if !ok {
+		t.Fatal("expected imbalance for a two-sided book")
+	}
+	if imb != 0.75 {
+		t.Errorf("expected imbalance 0.75, got %v", imb)
+	}
+
+	// (99*1 + 101*3) / 4
+	mp, ok := ob.Microprice()
+	if !ok {
+		t.Fatal("expected microprice for a two-sided book")
+	}
+	if mp != 100.5 {
+		t.Errorf("expected microprice 100.5, got %v", mp)
+	}
+
+	ticker = ob.GetTicker()
+	if ticker.Imbalance == nil || *ticker.Imbalance != imb {
+		t.Errorf("expected ticker imbalance %v, got %v", imb, ticker.Imbalance)
+	}
+	if ticker.Microprice == nil || *ticker.Microprice != mp {
+		t.Errorf("expected ticker microprice %v, got %v", mp, ticker.Microprice)
+	}
+}
+
+func TestOrderBook_LastTrade(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ob.now = func() time.Time { return now }
+
+	ticker := ob.GetTicker()
+	if ticker.LastPrice != nil || ticker.LastQty != nil || ticker.LastTradeAt != nil {
+		t.Fatal("expected no last trade before any trade")
+	}
+
+	ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 101.0, 2.0))
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 102.0, 0.5))
+
+	ticker = ob.GetTicker()
+	if ticker.LastPrice == nil || *ticker.LastPrice != 101.0 {
+		t.Errorf("expected last price 101.0, got %v", ticker.LastPrice)
+	}
+	if ticker.LastQty == nil || *ticker.LastQty != 0.5 {
+		t.Errorf("expected last quantity 0.5, got %v", ticker.LastQty)
+	}
+	if ticker.LastTradeAt == nil || !ticker.LastTradeAt.Equal(now) {
+		t.Errorf("expected last trade at %v, got %v", now, ticker.LastTradeAt)
+	}
+
+	// O último negócio permanece após operações que não negociam
+	later := now.Add(time.Minute)
+	ob.now = func() time.Time { return later }
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 99.0, 1.0))
+
+	ticker = ob.GetTicker()
+	if ticker.LastPrice == nil || *ticker.LastPrice != 101.0 || !ticker.LastTradeAt.Equal(now) {
+		t.Errorf("expected the last trade to survive a resting order, got %v at %v", ticker.LastPrice, ticker.LastTradeAt)
+	}
+
+	ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 99.0, 0.25))
+
+	ticker = ob.GetTicker()
+	if *ticker.LastPrice != 99.0 || *ticker.LastQty != 0.25 || !ticker.LastTradeAt.Equal(later) {
+		t.Errorf("expected the next trade to replace the last one, got %v x %v at %v",
+			*ticker.LastPrice, *ticker.LastQty, *ticker.LastTradeAt)
+	}
+}
+
+func TestOrderBook_AmendOrder(t *testing.T) {
+	t.Run("amend down keeps queue position", func(t *testing.T) {
+		ob := NewOrderBook("BTC-USD")
+
+		a := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 2.0)
+		b := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0)
+		ob.AddOrder(a)
+		ob.AddOrder(b)
+
+		if err := ob.AmendOrder(a.ID, 100.0, 1.0); err != nil {
+			t.Fatalf("unexpected error amending order: %v", err)
+		}
+
+		ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0))
+
+		if a.Status != order.StatusFilled {
+			t.Errorf("expected amended order to fill first, got status %v", a.Status)
+		}
+		if b.Filled != 0 {
+			t.Errorf("expected later order to be untouched, got filled %v", b.Filled)
+		}
+	})
+
+	t.Run("amend up goes to the back", func(t *testing.T) {
+		ob := NewOrderBook("BTC-USD")
+
+		a := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0)
+		b := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0)
+		ob.AddOrder(a)
+		ob.AddOrder(b)
+
+		if err := ob.AmendOrder(a.ID, 100.0, 2.0); err != nil {
+			t.Fatalf("unexpected error amending order: %v", err)
+		}
+
+		ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0))
+
+		if b.Status != order.StatusFilled {
+			t.Errorf("expected untouched order to fill first, got status %v", b.Status)
+		}
+		if a.Filled != 0 {
+			t.Errorf("expected amended order to lose priority, got filled %v", a.Filled)
+		}
+	})
+
+	t.Run("price change moves to the target level", func(t *testing.T) {
+		ob := NewOrderBook("BTC-USD")
+
+		a := mustNewOrder(t, order.SideBuy, "BTC-USD", 99.0, 1.0)
+		b := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0)
+		ob.AddOrder(a)
+		ob.AddOrder(b)
+
+		if err := ob.AmendOrder(a.ID, 100.0, 1.0); err != nil {
+			t.Fatalf("unexpected error amending order: %v", err)
+		}
+
+		snapshot := ob.GetOrderBook()
+		if len(snapshot.Bids) != 1 {
+			t.Fatalf("expected a single bid level, got %d", len(snapshot.Bids))
+		}
+		level := snapshot.Bids[0]
+		if len(level.Orders) != 2 || level.Orders[0].ID != b.ID || level.Orders[1].ID != a.ID {
+			t.Error("expected amended order at the tail of the 100.0 level")
+		}
+	})
+
+	t.Run("invalid amendments", func(t *testing.T) {
+		ob := NewOrderBook("BTC-USD")
+
+		a := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 2.0)
+		ob.AddOrder(a)
+		ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0))
+
+		if err := ob.AmendOrder(a.ID, 100.0, 1.0); err == nil {
+			t.Error("expected error amending quantity down to the filled amount")
+		}
+		if err := ob.AmendOrder("invalid-id", 100.0, 1.0); err == nil {
+			t.Error("expected error amending unknown order")
+		}
+		if a.Quantity != 2.0 {
+			t.Errorf("expected rejected amendment to leave quantity unchanged, got %v", a.Quantity)
+		}
+	})
+}
+
+func TestOrderBook_AmendEmitsUpdates(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	var updates []Update
+	ob.SetUpdateListener(func(batch []Update) { updates = append(updates, batch...) })
+
+	a := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 2.0)
+	ob.AddOrder(a)
+	sequence := ob.Sequence()
+	updates = nil
+
+	if err := ob.AmendOrder(a.ID, 100.0, 1.0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ob.AmendOrder(a.ID, 99.0, 1.0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(updates) != 2 {
+		t.Fatalf("expected an update per amendment, got %+v", updates)
+	}
+	for i, want := range []float64{100.0, 99.0} {
+		u := updates[i]
+		if !u.Amended || u.Order.Price != want || u.Order.Quantity != 1.0 {
+			t.Errorf("update %d: expected amended order at %v for 1, got %+v", i, want, u)
+		}
+		if u.Sequence != sequence+uint64(i)+1 {
+			t.Errorf("update %d: expected sequence %d, got %d", i, sequence+uint64(i)+1, u.Sequence)
+		}
+	}
+
+	updates = nil
+	if _, err := ob.ReduceOrder(a.ID, 0.5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(updates) != 1 || !updates[0].Amended || updates[0].Order.Quantity != 0.5 {
+		t.Errorf("expected the reduction as an amended update, got %+v", updates)
+	}
+}
+
+func TestOrderBook_ReduceOrder(t *testing.T) {
+	t.Run("partial reduction keeps queue position", func(t *testing.T) {
+		ob := NewOrderBook("BTC-USD")
+
+		a := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 3.0)
+		b := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0)
+		ob.AddOrder(a)
+		ob.AddOrder(b)
+
+		reduced, err := ob.ReduceOrder(a.ID, 2.0)
+		if err != nil {
+			t.Fatalf("unexpected error reducing order: %v", err)
+		}
+		if reduced.Quantity != 1.0 || reduced.Status != order.StatusNew {
+			t.Errorf("expected quantity 1 and status new, got %v and %v", reduced.Quantity, reduced.Status)
+		}
+
+		snapshot := ob.GetOrderBook()
+		if len(snapshot.Bids) != 1 || len(snapshot.Bids[0].Orders) != 2 || snapshot.Bids[0].Orders[0].ID != a.ID {
+			t.Fatal("expected reduced order to stay at the head of its level")
+		}
+
+		ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.5))
+		if a.Status != order.StatusFilled {
+			t.Errorf("expected reduced order to fill first, got status %v", a.Status)
+		}
+		if b.Filled != 0.5 {
+			t.Errorf("expected later order to get the rest, got filled %v", b.Filled)
+		}
+	})
+
+	t.Run("reducing to zero cancels", func(t *testing.T) {
+		ob := NewOrderBook("BTC-USD")
+
+		a := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 2.0)
+		ob.AddOrder(a)
+		ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 0.5))
+
+		// More than remains only takes it down to what has filled
+		reduced, err := ob.ReduceOrder(a.ID, 5.0)
+		if err != nil {
+			t.Fatalf("unexpected error reducing order: %v", err)
+		}
+		if reduced.Status != order.StatusCancelled || reduced.Filled != 0.5 {
+			t.Errorf("expected cancelled with 0.5 filled, got %v with %v", reduced.Status, reduced.Filled)
+		}
+		if len(ob.GetOrderBook().Bids) != 0 || ob.ActiveOrderCount() != 0 {
+			t.Error("expected the cancelled order to leave the book")
+		}
+		if err := ob.Validate(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("invalid reductions", func(t *testing.T) {
+		ob := NewOrderBook("BTC-USD")
+
+		a := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 2.0)
+		ob.AddOrder(a)
+
+		for _, by := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+			if _, err := ob.ReduceOrder(a.ID, by); err == nil {
+				t.Errorf("expected error reducing by %v", by)
+			}
+		}
+		if _, err := ob.ReduceOrder("invalid-id", 1.0); !errors.Is(err, ErrOrderNotFound) {
+			t.Errorf("expected ErrOrderNotFound, got %v", err)
+		}
+		if a.Quantity != 2.0 {
+			t.Errorf("expected rejected reductions to leave quantity unchanged, got %v", a.Quantity)
+		}
+	})
+}
+
+func TestOrderBook_MarketOrder(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+
+	ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0))
+	ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 105.0, 1.0))
+
+	market, err := order.NewOrderOfType(order.TypeMarket, order.SideBuy, "BTC-USD", 0, 0, 3.0, false)
+	if err != nil {
+		t.Fatalf("unexpected error creating market order: %v", err)
+	}
+	if err := ob.AddOrder(market); err != nil {
+		t.Fatalf("unexpected error adding market order: %v", err)
+	}
+
+	// Varre todos os níveis e cancela o restante em vez de ficar no livro
+	if market.Filled != 2.0 {
+		t.Errorf("expected market order to fill 2.0, got %v", market.Filled)
+	}
+	if market.Status != order.StatusCancelled {
+		t.Errorf("expected remainder to be cancelled, got %v", market.Status)
+	}
+	if _, _, err := ob.GetBestBid(); err == nil {
+		t.Error("expected market order not to rest on the book")
+	}
+}
+
+func TestOrderBook_AvgFillPrice(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+
+	ask1 := mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0)
+	ask2 := mustNewOrder(t, order.SideSell, "BTC-USD", 103.0, 2.0)
+	ob.AddOrder(ask1)
+	ob.AddOrder(ask2)
+
+	// Atravessa os dois níveis: 1 a 100 e 2 a 103
+	buy := mustNewOrder(t, order.SideBuy, "BTC-USD", 105.0, 3.0)
+	if err := ob.AddOrder(buy); err != nil {
+		t.Fatalf("unexpected error adding order: %v", err)
+	}
+
+	if want := (100.0*1 + 103.0*2) / 3; math.Abs(buy.AvgFillPrice-want) > 1e-9 {
+		t.Errorf("expected average fill price %v, got %v", want, buy.AvgFillPrice)
+	}
+	if ask1.AvgFillPrice != 100 || ask2.AvgFillPrice != 103 {
+		t.Errorf("expected makers to fill at their own prices, got %v and %v", ask1.AvgFillPrice, ask2.AvgFillPrice)
+	}
+}
+
+func TestOrderBook_QuoteMarketBuy(t *testing.T) {
+	tests := []struct {
+		name       string
+		budget     float64
+		wantFilled float64
+		wantSpent  float64
+	}{
+		{"budget smaller than the first lot", 50.0, 0.5, 50.0},
+		{"last fill is a partial lot", 250.0, 1.0 + 150.0/101.0, 250.0},
+		{"budget larger than the book", 1000.0, 3.0, 302.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ob := NewOrderBook("BTC-USD")
+			ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0))
+			ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 101.0, 2.0))
+
+			var spent float64
+			ob.SetUpdateListener(func(updates []Update) {
+				for _, u := range updates {
+					if u.Trade != nil && u.Order.ID == u.Trade.TakerOrderID {
+						spent += u.Trade.Price * u.Trade.Quantity
+					}
+				}
+			})
+
+			buy, err := order.NewQuoteMarketBuy("BTC-USD", tt.budget)
+			if err != nil {
+				t.Fatalf("unexpected error creating order: %v", err)
+			}
+			if err := ob.AddOrder(buy); err != nil {
+				t.Fatalf("unexpected error adding order: %v", err)
+			}
+
+			if spent > tt.budget {
+				t.Errorf("spent %v, more than the budget of %v", spent, tt.budget)
+			}
+			if !approxEqual(spent, tt.wantSpent) {
+				t.Errorf("spent = %v, want %v", spent, tt.wantSpent)
+			}
+			if !approxEqual(buy.Filled, tt.wantFilled) {
+				t.Errorf("filled = %v, want %v", buy.Filled, tt.wantFilled)
+			}
+			if buy.Quantity != buy.Filled {
+				t.Errorf("quantity = %v, want it to match filled %v", buy.Quantity, buy.Filled)
+			}
+			if buy.Status != order.StatusFilled {
+				t.Errorf("status = %v, want %v", buy.Status, order.StatusFilled)
+			}
+		})
+	}
+}
+
+func TestOrderBook_QuoteMarketBuyEmptyBook(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+
+	buy, err := order.NewQuoteMarketBuy("BTC-USD", 100.0)
+	if err != nil {
+		t.Fatalf("unexpected error creating order: %v", err)
+	}
+	if err := ob.AddOrder(buy); err != nil {
+		t.Fatalf("unexpected error adding order: %v", err)
+	}
+
+	if buy.Status != order.StatusCancelled {
+		t.Errorf("expected unfilled order to be cancelled, got %v", buy.Status)
+	}
+}
+
+func TestAffordable(t *testing.T) {
+	// 0.3 / 0.1 arredonda para cima em ponto flutuante
+	for _, tt := range []struct{ budget, price float64 }{
+		{0.3, 0.1},
+		{250.0, 101.0},
+		{1.0, 3.0},
+	} {
+		qty := affordable(tt.budget, tt.price)
+		if qty*tt.price > tt.budget {
+			t.Errorf("affordable(%v, %v) = %v overspends: %v", tt.budget, tt.price, qty, qty*tt.price)
+		}
+		if !approxEqual(qty, tt.budget/tt.price) {
+			t.Errorf("affordable(%v, %v) = %v, want about %v", tt.budget, tt.price, qty, tt.budget/tt.price)
+		}
+	}
+}
+
+func TestOrderBook_TradePricing(t *testing.T) {
+	tests := []struct {
+		name      string
+		pricing   TradePricing
+		orderType order.Type
+		want      float64
+	}{
+		{"maker price by default", "", order.TypeLimit, 100.0},
+		{"explicit maker price", TradePricingMaker, order.TypeLimit, 100.0},
+		{"midpoint splits the improvement", TradePricingMidpoint, order.TypeLimit, 105.0},
+		{"market orders keep the maker price", TradePricingMidpoint, order.TypeMarket, 100.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ob := NewOrderBookWithConfig(SymbolConfig{Symbol: "BTC-USD", TradePricing: tt.pricing})
+			ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0))
+
+			var trades []Trade
+			ob.SetUpdateListener(func(updates []Update) {
+				for _, u := range updates {
+					if u.Trade != nil && u.Order.ID == u.Trade.TakerOrderID {
+						trades = append(trades, *u.Trade)
+					}
+				}
+			})
+
+			price := 110.0
+			if tt.orderType == order.TypeMarket {
+				price = 0
+			}
+			taker, err := order.NewOrderOfType(tt.orderType, order.SideBuy, "BTC-USD", price, 0, 1.0, false)
+			if err != nil {
+				t.Fatalf("unexpected error creating order: %v", err)
+			}
+			if err := ob.AddOrder(taker); err != nil {
+				t.Fatalf("unexpected error adding order: %v", err)
+			}
+
+			if len(trades) != 1 {
+				t.Fatalf("expected 1 trade, got %d", len(trades))
+			}
+			if trades[0].Price != tt.want {
+				t.Errorf("trade price = %v, want %v", trades[0].Price, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderBook_TradeRounding(t *testing.T) {
+	tests := []struct {
+		name      string
+		rounding  TradeRounding
+		takerSide order.Side
+		want      float64
+	}{
+		{"favors the selling maker by default", "", order.SideBuy, 100.06},
+		{"favors the buying maker", TradeRoundingFavorMaker, order.SideSell, 100.05},
+		{"favors the buying taker", TradeRoundingFavorTaker, order.SideBuy, 100.05},
+		{"favors the selling taker", TradeRoundingFavorTaker, order.SideSell, 100.06},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ob := NewOrderBookWithConfig(SymbolConfig{
+				Symbol:        "BTC-USD",
+				TradePricing:  TradePricingMidpoint,
+				TradeRounding: tt.rounding,
+				Precision:     &order.Precision{Price: 2, Quantity: 8},
+			})
+
+			// The midpoint of 100.00 and 100.11 is 100.055, between ticks
+			makerSide, makerPrice, takerPrice := order.SideSell, 100.0, 100.11
+			if tt.takerSide == order.SideSell {
+				makerSide, makerPrice, takerPrice = order.SideBuy, 100.11, 100.0
+			}
+			ob.AddOrder(mustNewOrder(t, makerSide, "BTC-USD", makerPrice, 1.0))
+
+			var trades []Trade
+			ob.SetUpdateListener(func(updates []Update) {
+				for _, u := range updates {
+					if u.Trade != nil && u.Order.ID == u.Trade.TakerOrderID {
+						trades = append(trades, *u.Trade)
+					}
+				}
+			})
+			if err := ob.AddOrder(mustNewOrder(t, tt.takerSide, "BTC-USD", takerPrice, 1.0)); err != nil {
+				t.Fatalf("unexpected error adding order: %v", err)
+			}
+
+			if len(trades) != 1 {
+				t.Fatalf("expected 1 trade, got %d", len(trades))
+			}
+			if trades[0].Price != tt.want {
+				t.Errorf("trade price = %v, want %v", trades[0].Price, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderBook_RejectsStopOrders(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+
+	stop, err := order.NewOrderOfType(order.TypeStop, order.SideSell, "BTC-USD", 0, 90.0, 1.0, false)
+	if err != nil {
+		t.Fatalf("unexpected error creating stop order: %v", err)
+	}
+	if err := ob.AddOrder(stop); err == nil {
+		t.Error("expected stop order to be rejected by the book")
+	}
+}
+
+func TestOrderBook_TypedErrors(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+
+	if err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "ETH-USD", 100.0, 1.0)); !errors.Is(err, ErrInvalidSymbol) {
+		t.Errorf("expected ErrInvalidSymbol, got %v", err)
+	}
+	if err := ob.CancelOrder("missing"); !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("expected ErrOrderNotFound on cancel, got %v", err)
+	}
+	if _, err := ob.GetOrder("missing"); !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("expected ErrOrderNotFound on get, got %v", err)
+	}
+
+	ob.Halt()
+	if err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0)); !errors.Is(err, ErrTradingHalted) {
+		t.Errorf("expected ErrTradingHalted, got %v", err)
+	}
+}
+
+func mustNewOrder(t *testing.T, side order.Side, symbol string, price, quantity float64) *order.Order {
+	t.Helper()
+	o, err := order.NewOrder(side, symbol, price, quantity)
+	if err != nil {
+		t.Fatalf("unexpected error creating order: %v", err)
+	}
+	return o
+}
+
+func TestOrderBook_GetOrderReturnsCopy(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	resting := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 2.0)
+	if err := ob.AddOrder(resting); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found, err := ob.GetOrder(resting.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found == resting {
+		t.Fatal("expected a copy, got the order resting on the book")
+	}
+
+	if err := ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found.Filled != 0 {
+		t.Errorf("expected the copy to keep its state, got filled %v", found.Filled)
+	}
+	if snapshot := ob.Snapshot(resting); snapshot.Filled != 1.0 {
+		t.Errorf("expected the snapshot to see the fill, got filled %v", snapshot.Filled)
+	}
+	if again, _ := ob.GetOrder(resting.ID); again.Filled != 1.0 {
+		t.Errorf("expected a new copy to see the fill, got filled %v", again.Filled)
+	}
+}
+
+// TestOrderBook_GetOrderWhileFilling lê a ordem devolvida por GetOrder
+// enquanto outra goroutine a executa; com -race, falha se GetOrder devolver
+// a ordem do livro em vez de uma cópia
+func TestOrderBook_GetOrderWhileFilling(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	resting := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 100.0)
+	if err := ob.AddOrder(resting); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	found, err := ob.GetOrder(resting.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0))
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			if snapshot := ob.Snapshot(resting); snapshot.Filled != 50.0 {
+				t.Errorf("expected 50 filled, got %v", snapshot.Filled)
+			}
+			return
+		default:
+			if found.Filled != 0 {
+				t.Fatalf("expected the copy to keep its state, got filled %v", found.Filled)
+			}
+		}
+	}
+}
+
+func TestOrderBook_Sequence(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+
+	expect := func(want uint64, step string) {
+		t.Helper()
+		if got := ob.Sequence(); got != want {
+			t.Errorf("%s: expected sequence %d, got %d", step, want, got)
+		}
+		if got := ob.GetOrderBook().Sequence; got != want {
+			t.Errorf("%s: expected snapshot sequence %d, got %d", step, want, got)
+		}
+	}
+	expect(0, "empty book")
+
+	sell := mustNewOrder(t, order.SideSell, "BTC-USD", 50000.0, 2.0)
+	ob.AddOrder(sell)
+	expect(1, "add")
+
+	// Um casamento é uma única operação, mesmo alterando duas ordens
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 50000.0, 1.0))
+	expect(2, "match")
+
+	if err := ob.AmendOrder(sell.ID, 50000.0, 1.5); err != nil {
+		t.Fatalf("unexpected error amending order: %v", err)
+	}
+	expect(3, "amend")
+
+	ob.CancelOrder(sell.ID)
+	expect(4, "cancel")
+
+	// Leituras e operações rejeitadas não avançam a sequência
+	ob.GetBestAsk()
+	ob.GetDepth()
+	ob.SimulateFill(order.SideBuy, 1.0)
+	if err := ob.CancelOrder(sell.ID); err == nil {
+		t.Error("expected error cancelling a removed order")
+	}
+	if err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "ETH-USD", 3000.0, 1.0)); err == nil {
+		t.Error("expected error adding an order for another symbol")
+	}
+	expect(4, "reads and rejections")
+}
+
+func TestOrderBook_UpdatesCarrySequence(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+
+	var updates []Update
+	ob.SetUpdateListener(func(u []Update) {
+		updates = append(updates, u...)
+	})
+
+	ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 50000.0, 1.0))
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 50000.0, 1.0))
+
+	want := []uint64{1, 2, 2, 2}
+	if len(updates) != len(want) {
+		t.Fatalf("expected %d updates, got %d", len(want), len(updates))
+	}
+	for i, u := range updates {
+		if u.Sequence != want[i] {
+			t.Errorf("update %d: expected sequence %d, got %d", i, want[i], u.Sequence)
+		}
+	}
+}
+
+func TestOrderBook_SimulateOrder(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	for _, price := range []float64{100.0, 101.0, 103.0} {
+		maker := mustNewOrder(t, order.SideSell, "BTC-USD", price, 1.0)
+		maker.AccountID = "mm"
+		if err := ob.AddOrder(maker); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	before := ob.GetDepth()
+	sequence := ob.Sequence()
+
+	o := mustNewOrder(t, order.SideBuy, "BTC-USD", 102.0, 2.5)
+	o.AccountID = "paper"
+	if err := ob.ValidateOrder(o); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	trades := ob.SimulateOrder(o)
+
+	if len(trades) != 2 || trades[0].Price != 100.0 || trades[1].Price != 101.0 {
+		t.Fatalf("expected fills at 100 and 101, the levels within the limit, got %+v", trades)
+	}
+	for _, trade := range trades {
+		if trade.MakerOrderID != "" || trade.MakerAccountID != "" {
+			t.Errorf("expected the counterparty left out, got %+v", trade)
+		}
+		if trade.TakerAccountID != "paper" {
+			t.Errorf("expected the trade attributed to the paper account, got %q", trade.TakerAccountID)
+		}
+	}
+	if o.Filled != 2.0 || o.Status != order.StatusPartial {
+		t.Errorf("expected 2 filled and the order partial, got %v %s", o.Filled, o.Status)
+	}
+
+	if after := ob.GetDepth(); !reflect.DeepEqual(before, after) {
+		t.Errorf("expected the book untouched, got %+v, was %+v", after, before)
+	}
+	if ob.Sequence() != sequence {
+		t.Errorf("expected the sequence to stay at %d, got %d", sequence, ob.Sequence())
+	}
+	if _, err := ob.GetOrder(o.ID); err == nil {
+		t.Error("expected the simulated order not to rest")
+	}
+}
+
+func TestOrderBook_IterateActiveOrders(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	for _, o := range []*order.Order{
+		mustNewOrder(t, order.SideBuy, "BTC-USD", 99.0, 1.0),
+		mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0),
+		mustNewOrder(t, order.SideSell, "BTC-USD", 102.0, 1.0),
+		mustNewOrder(t, order.SideSell, "BTC-USD", 101.0, 1.0),
+	} {
+		if err := ob.AddOrder(o); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// A iteração segue a ordem de Orders e entrega cópias
+	var visited []order.Order
+	ob.IterateActiveOrders(func(o *order.Order) bool {
+		visited = append(visited, *o)
+		o.Quantity = 0
+		return true
+	})
+	if !reflect.DeepEqual(visited, ob.Orders()) {
+		t.Errorf("expected the orders of Orders, got %+v", visited)
+	}
+	for _, o := range ob.Orders() {
+		if o.Quantity != 1.0 {
+			t.Errorf("expected the book untouched by the callback, got quantity %v", o.Quantity)
+		}
+	}
+
+	count := 0
+	ob.IterateActiveOrders(func(*order.Order) bool {
+		count++
+		return count < 2
+	})
+	if count != 2 {
+		t.Errorf("expected the walk to stop after 2 orders, got %d", count)
+	}
+
+	// Iterar enquanto outras goroutines alteram o livro não entra em pânico
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			o, _ := order.NewOrder(order.SideBuy, "BTC-USD", 90.0+float64(i%5), 1.0)
+			ob.AddOrder(o)
+			ob.CancelOrder(o.ID)
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		ob.IterateActiveOrders(func(o *order.Order) bool {
+			return o.IsActive()
+		})
+	}
+	wg.Wait()
+}
+
+func TestOrderBook_MatchLatencyExcludesLockWait(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	buy := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0)
+
+	// Segura o lock para que a ordem espere por ele antes do matching
+	ob.mutex.Lock()
+	done := make(chan error, 1)
+	go func() { done <- ob.AddOrder(buy) }()
+	wait := 50 * time.Millisecond
+	time.Sleep(wait)
+	ob.mutex.Unlock()
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buy.MatchLatencyMicros <= 0 {
+		t.Errorf("MatchLatencyMicros = %v, want positive", buy.MatchLatencyMicros)
+	}
+	if limit := float64(wait / time.Microsecond); buy.MatchLatencyMicros >= limit {
+		t.Errorf("MatchLatencyMicros = %v, want less than the %v lock wait", buy.MatchLatencyMicros, wait)
+	}
+}
@@ -0,0 +1,54 @@
+package orderbook
+
+import (
+	"fmt"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// Normalize arredonda preço, preço de stop e quantidade da ordem para a
+// precisão do símbolo, conforme o modo de arredondamento configurado. Sem
+// precisão configurada a ordem não é alterada. Rejeita ordens que ficariam
+// com quantidade ou preço zero.
+func (c SymbolConfig) Normalize(o *order.Order) error {
+	if c.Precision == nil {
+		return nil
+	}
+
+	price, err := c.roundPrice(o.Price)
+	if err != nil {
+		return err
+	}
+	stopPrice, err := c.roundPrice(o.StopPrice)
+	if err != nil {
+		return err
+	}
+	quantity, err := c.roundQuantity(o.Quantity)
+	if err != nil {
+		return err
+	}
+
+	if o.Price > 0 && price <= 0 && !c.AllowNegativePrice {
+		return fmt.Errorf("%w: price %v rounds to zero at %d decimals", ErrInvalidTick, o.Price, c.Precision.Price)
+	}
+	if o.Quantity > 0 && quantity <= 0 {
+		return fmt.Errorf("%w: quantity %v rounds to zero at %d decimals", ErrInvalidTick, o.Quantity, c.Precision.Quantity)
+	}
+
+	o.Price, o.StopPrice, o.Quantity = price, stopPrice, quantity
+	return nil
+}
+
+func (c SymbolConfig) roundPrice(price float64) (float64, error) {
+	if c.Precision == nil {
+		return price, nil
+	}
+	return order.Round(price, c.Precision.Price, c.Rounding)
+}
+
+func (c SymbolConfig) roundQuantity(quantity float64) (float64, error) {
+	if c.Precision == nil {
+		return quantity, nil
+	}
+	return order.Round(quantity, c.Precision.Quantity, c.Rounding)
+}
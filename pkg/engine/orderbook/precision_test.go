@@ -0,0 +1,104 @@
+package orderbook
+
+import (
+	"errors"
+	"testing"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestOrderBook_NormalizesToSymbolPrecision(t *testing.T) {
+	tests := []struct {
+		name         string
+		rounding     order.Rounding
+		price        float64
+		quantity     float64
+		wantPrice    float64
+		wantQuantity float64
+	}{
+		{"half-up", order.RoundHalfUp, 100.125, 1.23456, 100.13, 1.235},
+		{"down", order.RoundDown, 100.129, 1.23499, 100.12, 1.234},
+		{"already at scale", order.RoundDown, 100.1, 2, 100.1, 2},
+		{"default mode is half-up", "", 99.995, 0.0005, 100.0, 0.001},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ob := NewOrderBookWithConfig(SymbolConfig{
+				Symbol:    "BTC-USD",
+				Precision: &order.Precision{Price: 2, Quantity: 3},
+				Rounding:  tt.rounding,
+			})
+
+			o := mustNewOrder(t, order.SideBuy, "BTC-USD", tt.price, tt.quantity)
+			if err := ob.AddOrder(o); err != nil {
+				t.Fatalf("unexpected error adding order: %v", err)
+			}
+			if o.Price != tt.wantPrice {
+				t.Errorf("expected price %v, got %v", tt.wantPrice, o.Price)
+			}
+			if o.Quantity != tt.wantQuantity {
+				t.Errorf("expected quantity %v, got %v", tt.wantQuantity, o.Quantity)
+			}
+		})
+	}
+}
+
+func TestOrderBook_NormalizationMergesLevels(t *testing.T) {
+	ob := NewOrderBookWithConfig(SymbolConfig{
+		Symbol:    "BTC-USD",
+		Precision: &order.Precision{Price: 2, Quantity: 8},
+		Rounding:  order.RoundDown,
+	})
+
+	// Preços que só diferem além da precisão caem no mesmo nível
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 100.10, 1.0))
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 100.1000001, 1.0))
+	ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 100.109, 1.0))
+
+	if depth := ob.GetDepth(); depth.BidLevels != 1 {
+		t.Errorf("expected a single bid level, got %d", depth.BidLevels)
+	}
+
+	// Uma venda com casas a mais casa no preço arredondado
+	sell := mustNewOrder(t, order.SideSell, "BTC-USD", 100.1049, 3.0)
+	ob.AddOrder(sell)
+	if sell.Status != order.StatusFilled {
+		t.Errorf("expected the rounded sell to fill, got %v", sell.Status)
+	}
+}
+
+func TestOrderBook_NormalizationRejectsZero(t *testing.T) {
+	ob := NewOrderBookWithConfig(SymbolConfig{
+		Symbol:    "BTC-USD",
+		Precision: &order.Precision{Price: 2, Quantity: 2},
+		Rounding:  order.RoundDown,
+	})
+
+	if err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 0.009)); !errors.Is(err, ErrInvalidTick) {
+		t.Errorf("expected ErrInvalidTick for a quantity that rounds to zero, got %v", err)
+	}
+	if err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 0.001, 1.0)); !errors.Is(err, ErrInvalidTick) {
+		t.Errorf("expected ErrInvalidTick for a price that rounds to zero, got %v", err)
+	}
+	if ob.ActiveOrderCount() != 0 {
+		t.Errorf("expected no resting orders, got %d", ob.ActiveOrderCount())
+	}
+}
+
+func TestOrderBook_AmendRoundsToPrecision(t *testing.T) {
+	ob := NewOrderBookWithConfig(SymbolConfig{
+		Symbol:    "BTC-USD",
+		Precision: &order.Precision{Price: 2, Quantity: 2},
+	})
+
+	o := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0)
+	ob.AddOrder(o)
+
+	if err := ob.AmendOrder(o.ID, 100.256, 2.004); err != nil {
+		t.Fatalf("unexpected error amending order: %v", err)
+	}
+	if o.Price != 100.26 || o.Quantity != 2.0 {
+		t.Errorf("expected 100.26 x 2.0 after amend, got %v x %v", o.Price, o.Quantity)
+	}
+}
@@ -0,0 +1,145 @@
+package orderbook
+
+import (
+	"math"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// SweepPrecedence define, no ProRataMatcher, se o rateio ou a prioridade de
+// tempo decide quem executa nos níveis que a agressora alcança depois do
+// melhor. Entre níveis vale sempre a prioridade de preço: um nível só é
+// tocado depois que os melhores se esgotaram.
+type SweepPrecedence string
+
+const (
+	// SweepProRataEveryLevel rateia a quantidade levada de cada nível entre
+	// as ordens dele, em todos os níveis da varredura (padrão)
+	SweepProRataEveryLevel SweepPrecedence = "every-level"
+	// SweepProRataBestLevel rateia só o melhor nível; nos seguintes, que a
+	// agressora só alcança esgotando-o, as ordens executam por ordem de
+	// chegada, como no FIFOMatcher
+	SweepProRataBestLevel SweepPrecedence = "best-level"
+)
+
+// ProRataMatcher reparte entre as ordens de um nível a quantidade que a
+// agressora leva dele, na proporção do que resta a cada uma. Um nível que a
+// agressora esgota executa inteiro, então o rateio só decide algo no último
+// nível alcançado. As alocações são múltiplos de Lot; a sobra do
+// arredondamento vai para as ordens mais antigas do nível, um Lot por vez.
+type ProRataMatcher struct {
+	// Precedence escolhe quais níveis são rateados (padrão:
+	// SweepProRataEveryLevel)
+	Precedence SweepPrecedence
+	// Lot é o passo das alocações (padrão: o passo da precisão de quantidade
+	// padrão)
+	Lot float64
+}
+
+func (m ProRataMatcher) Match(incoming *order.Order, opposing *PriceLevel, limit float64) []Fill {
+	var fills []Fill
+	remaining := incoming.RemainingQuantity()
+	budget := incoming.QuoteQuantity
+
+	for level := opposing; level != nil && crosses(incoming.Side, limit, level.Price); level = level.Next {
+		take := remaining
+		if incoming.QuoteQuantity > 0 {
+			take = affordable(budget, level.Price)
+		}
+		if take <= 0 {
+			break
+		}
+
+		resting, total := activeOrders(level)
+		var levelFills []Fill
+		switch {
+		case total <= take:
+			levelFills = fifoAllocate(resting, level.Price, total)
+		case level == opposing || m.Precedence != SweepProRataBestLevel:
+			levelFills = m.allocate(resting, total, level.Price, take)
+		default:
+			levelFills = fifoAllocate(resting, level.Price, take)
+		}
+		for _, f := range levelFills {
+			remaining -= f.Quantity
+			budget -= f.Quantity * level.Price
+		}
+		fills = append(fills, levelFills...)
+
+		// Um nível que não se esgotou encerra a varredura
+		if total > take {
+			break
+		}
+	}
+	return fills
+}
+
+// allocate rateia take, menor que total, entre as ordens em repouso
+func (m ProRataMatcher) allocate(resting []*order.Order, total, price, take float64) []Fill {
+	lot := m.lot()
+	shares := make([]float64, len(resting))
+	left := take
+	for i, o := range resting {
+		shares[i] = math.Floor(take*o.RemainingQuantity()/total/lot+1e-9) * lot
+		left -= shares[i]
+	}
+
+	// A sobra vai, um lote por vez, para as mais antigas que ainda cabem
+	for left > lot/2 {
+		given := false
+		for i, o := range resting {
+			if left <= lot/2 {
+				break
+			}
+			if extra := min(min(lot, left), o.RemainingQuantity()-shares[i]); extra > 0 {
+				shares[i] += extra
+				left -= extra
+				given = true
+			}
+		}
+		if !given {
+			break
+		}
+	}
+
+	var fills []Fill
+	for i, o := range resting {
+		if shares[i] > 0 {
+			fills = append(fills, Fill{Maker: o, Price: price, Quantity: shares[i]})
+		}
+	}
+	return fills
+}
+
+func (m ProRataMatcher) lot() float64 {
+	if m.Lot > 0 {
+		return m.Lot
+	}
+	return math.Pow10(-order.DefaultPrecision.Quantity)
+}
+
+// activeOrders devolve, por ordem de chegada, as ordens do nível que ainda
+// podem executar e o total que resta a elas
+func activeOrders(level *PriceLevel) (resting []*order.Order, total float64) {
+	for _, o := range level.Orders {
+		if o.IsActive() && o.RemainingQuantity() > 0 {
+			resting = append(resting, o)
+			total += o.RemainingQuantity()
+		}
+	}
+	return resting, total
+}
+
+// fifoAllocate distribui take entre as ordens por ordem de chegada
+func fifoAllocate(resting []*order.Order, price, take float64) []Fill {
+	var fills []Fill
+	for _, o := range resting {
+		if take <= 0 {
+			break
+		}
+		qty := min(take, o.RemainingQuantity())
+		take -= qty
+		fills = append(fills, Fill{Maker: o, Price: price, Quantity: qty})
+	}
+	return fills
+}
@@ -0,0 +1,66 @@
+package orderbook
+
+import (
+	"fmt"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// QueuePosition diz onde uma ordem em repouso está na fila do seu nível de
+// preço
+type QueuePosition struct {
+	OrderID string     `json:"order_id"`
+	Symbol  string     `json:"symbol"`
+	Side    order.Side `json:"side"`
+	Price   float64    `json:"price"`
+	// Rank é a posição da ordem na fila, a partir de 1 para a primeira
+	Rank int `json:"rank"`
+	// QuantityAhead soma o que falta executar das ordens à frente dela
+	QuantityAhead float64 `json:"quantity_ahead"`
+	// LevelQuantity soma o que falta executar de todas as ordens do nível,
+	// a dela inclusive
+	LevelQuantity float64 `json:"level_quantity"`
+}
+
+// QueuePosition percorre a fila do nível da ordem até ela, contando as
+// ordens ativas à frente e a quantidade que elas ainda têm a executar
+func (ob *OrderBook) QueuePosition(orderID string) (QueuePosition, error) {
+	ob.mutex.RLock()
+	defer ob.mutex.RUnlock()
+
+	o, exists := ob.orders[orderID]
+	if !exists {
+		return QueuePosition{}, fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+
+	head := ob.buyLevels
+	if o.Side == order.SideSell {
+		head = ob.sellLevels
+	}
+	level := head
+	for level != nil && level.Price != o.Price {
+		level = level.Next
+	}
+	if level == nil {
+		return QueuePosition{}, fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+
+	position := QueuePosition{OrderID: o.ID, Symbol: ob.symbol, Side: o.Side, Price: o.Price}
+	reached := false
+	for _, queued := range level.Orders {
+		if _, active := ob.orders[queued.ID]; !active {
+			continue
+		}
+		remaining := queued.RemainingQuantity()
+		position.LevelQuantity += remaining
+		if queued == o {
+			reached = true
+		}
+		if !reached {
+			position.Rank++
+			position.QuantityAhead += remaining
+		}
+	}
+	position.Rank++
+	return position, nil
+}
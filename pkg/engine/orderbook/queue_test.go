@@ -0,0 +1,61 @@
+package orderbook
+
+import (
+	"errors"
+	"testing"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestOrderBook_QueuePosition(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+
+	var queued []*order.Order
+	for _, quantity := range []float64{1.0, 2.0, 3.0, 4.0} {
+		o := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, quantity)
+		if err := ob.AddOrder(o); err != nil {
+			t.Fatalf("unexpected error adding order: %v", err)
+		}
+		queued = append(queued, o)
+	}
+	// Outro nível não entra na conta
+	if err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 101.0, 5.0)); err != nil {
+		t.Fatalf("unexpected error adding order: %v", err)
+	}
+
+	assertQueue := func(o *order.Order, wantRank int, wantAhead, wantLevel float64) {
+		t.Helper()
+		position, err := ob.QueuePosition(o.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if position.Rank != wantRank || position.QuantityAhead != wantAhead || position.LevelQuantity != wantLevel {
+			t.Errorf("order %s: rank %d, ahead %v, level %v; want %d, %v, %v", o.ID,
+				position.Rank, position.QuantityAhead, position.LevelQuantity, wantRank, wantAhead, wantLevel)
+		}
+	}
+
+	assertQueue(queued[0], 1, 0.0, 10.0)
+	assertQueue(queued[1], 2, 1.0, 10.0)
+	assertQueue(queued[2], 3, 3.0, 10.0)
+	assertQueue(queued[3], 4, 6.0, 10.0)
+
+	// Uma venda esgota o nível de 101 e executa parte da primeira ordem de
+	// 100; quem estava atrás conta só o que falta dela
+	if err := ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 5.5)); err != nil {
+		t.Fatalf("unexpected error adding sell: %v", err)
+	}
+	assertQueue(queued[0], 1, 0.0, 9.5)
+	assertQueue(queued[1], 2, 0.5, 9.5)
+
+	// Cancelar a segunda adianta as de trás
+	if err := ob.CancelOrder(queued[1].ID); err != nil {
+		t.Fatalf("unexpected error cancelling: %v", err)
+	}
+	assertQueue(queued[2], 2, 0.5, 7.5)
+	assertQueue(queued[3], 3, 3.5, 7.5)
+
+	if _, err := ob.QueuePosition(queued[1].ID); !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("expected ErrOrderNotFound for a cancelled order, got %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+package orderbook
+
+import "fmt"
+
+// Retire fecha o livro para novas ordens, que passam a ser rejeitadas com
+// ErrBookRetired, desde que não haja ordens em repouso; com ordens no livro
+// devolve ErrBookNotEmpty e nada muda. Espera quem segura a configuração com
+// HoldConfig, de modo que uma ordem já em verificação entra antes e impede a
+// aposentadoria, ou é rejeitada depois dela.
+func (ob *OrderBook) Retire() error {
+	ob.configMutex.Lock()
+	defer ob.configMutex.Unlock()
+	ob.mutex.Lock()
+	defer ob.mutex.Unlock()
+
+	if len(ob.orders) > 0 {
+		return fmt.Errorf("%w: %d orders resting on %s", ErrBookNotEmpty, len(ob.orders), ob.symbol)
+	}
+	ob.retired = true
+	return nil
+}
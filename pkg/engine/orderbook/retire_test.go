@@ -0,0 +1,44 @@
+package orderbook
+
+import (
+	"errors"
+	"testing"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestOrderBook_Retire(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	resting := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 1.0)
+	if err := ob.AddOrder(resting); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Com ordens em repouso o livro segue aberto
+	if err := ob.Retire(); !errors.Is(err, ErrBookNotEmpty) {
+		t.Fatalf("expected ErrBookNotEmpty, got %v", err)
+	}
+	if err := ob.AddOrder(mustNewOrder(t, order.SideBuy, "BTC-USD", 99.0, 1.0)); err != nil {
+		t.Fatalf("expected the book still open, got %v", err)
+	}
+
+	for _, o := range ob.Orders() {
+		if err := ob.CancelOrder(o.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := ob.Retire(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 101.0, 1.0)); !errors.Is(err, ErrBookRetired) {
+		t.Errorf("expected ErrBookRetired, got %v", err)
+	}
+	bulk := []*order.Order{mustNewOrder(t, order.SideSell, "BTC-USD", 101.0, 1.0)}
+	if err := ob.BulkLoad(bulk, false); !errors.Is(err, ErrBookRetired) {
+		t.Errorf("expected ErrBookRetired from a bulk load, got %v", err)
+	}
+	if ob.ActiveOrderCount() != 0 {
+		t.Errorf("expected nothing on a retired book, got %d orders", ob.ActiveOrderCount())
+	}
+}
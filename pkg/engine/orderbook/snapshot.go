@@ -0,0 +1,159 @@
+package orderbook
+
+import (
+	"slices"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// levelKey identifica um nível pelo lado e pelo preço
+type levelKey struct {
+	side  order.Side
+	price float64
+}
+
+// levelView é a cópia imutável de um nível, com as ordens copiadas por valor
+type levelView struct {
+	price  float64
+	orders []order.Order
+}
+
+// viewNode encadeia as cópias dos níveis de um lado. Como nenhum nó muda
+// depois de publicado, versões seguidas compartilham a cauda que não mudou.
+type viewNode struct {
+	level *levelView
+	next  *viewNode
+}
+
+// bookView é o livro publicado para os snapshots. Depois de publicada nunca
+// é alterada, e por isso é lida sem lock algum.
+type bookView struct {
+	sequence uint64
+	bids     *viewNode
+	asks     *viewNode
+}
+
+// touch marca o nível de um lado como alterado pela operação corrente, para
+// que publish refaça a sua cópia
+func (ob *OrderBook) touch(side order.Side, price float64) {
+	if ob.touched == nil {
+		ob.touched = make(map[levelKey]bool)
+	}
+	ob.touched[levelKey{side, price}] = true
+}
+
+// publish troca a versão publicada pela do livro atual. Só os níveis marcados
+// por touch, ou criados desde a última publicação, são copiados de novo; os
+// demais reaproveitam a cópia anterior. Exige o lock de escrita.
+func (ob *OrderBook) publish() {
+	next := &bookView{sequence: ob.sequence}
+	if current := ob.view.Load(); current != nil {
+		next.bids, next.asks = current.bids, current.asks
+	}
+
+	var bids, asks []float64
+	for key := range ob.touched {
+		if key.side == order.SideBuy {
+			bids = append(bids, key.price)
+		} else {
+			asks = append(asks, key.price)
+		}
+	}
+	if len(bids) > 0 {
+		next.bids = ob.sideView(order.SideBuy, next.bids, bids)
+	}
+	if len(asks) > 0 {
+		next.asks = ob.sideView(order.SideSell, next.asks, asks)
+	}
+
+	clear(ob.touched)
+	ob.view.Store(next)
+}
+
+// sideView monta a lista publicada de um lado a partir da anterior. Percorre
+// o livro só até passar do último preço alterado; dali em diante nada mudou,
+// e a nova lista aponta para a cauda da anterior. Como as mudanças se
+// concentram no topo, o custo não cresce com a profundidade do livro.
+func (ob *OrderBook) sideView(side order.Side, previous *viewNode, touched []float64) *viewNode {
+	slices.SortFunc(touched, func(a, b float64) int {
+		switch {
+		case better(side, a, b):
+			return -1
+		case better(side, b, a):
+			return 1
+		}
+		return 0
+	})
+
+	var (
+		head *viewNode
+		link = &head
+		next int
+	)
+	for level := ob.sideLevels(side); level != nil; level = level.Next {
+		// Preços alterados e níveis publicados que já ficaram para trás
+		for next < len(touched) && better(side, touched[next], level.Price) {
+			next++
+		}
+		for previous != nil && better(side, previous.level.price, level.Price) {
+			previous = previous.next
+		}
+
+		if next == len(touched) && previous != nil && previous.level == level.view && level.view != nil {
+			*link = previous
+			return head
+		}
+		if next < len(touched) && touched[next] == level.Price {
+			level.view = nil
+		}
+		if level.view == nil {
+			view := &levelView{price: level.Price, orders: make([]order.Order, len(level.Orders))}
+			for i, o := range level.Orders {
+				view.orders[i] = *o
+			}
+			level.view = view
+		}
+		node := &viewNode{level: level.view}
+		*link = node
+		link = &node.next
+	}
+	return head
+}
+
+// GetOrderBook retorna um snapshot do order book na versão publicada pela
+// última operação que o alterou. Não toma o lock do livro: o custo de copiar
+// um livro profundo fica com quem lê, sem atrasar o matching. As ordens do
+// snapshot são cópias, que o chamador pode alterar à vontade.
+func (ob *OrderBook) GetOrderBook() *OrderBookSnapshot {
+	snapshot := &OrderBookSnapshot{
+		Symbol: ob.symbol,
+		Bids:   make([]PriceLevel, 0),
+		Asks:   make([]PriceLevel, 0),
+	}
+	view := ob.view.Load()
+	if view == nil {
+		return snapshot
+	}
+
+	snapshot.Sequence = view.sequence
+	snapshot.Bids = levelsOf(view.bids)
+	snapshot.Asks = levelsOf(view.asks)
+	return snapshot
+}
+
+// levelsOf copia os níveis publicados para o formato do snapshot
+func levelsOf(node *viewNode) []PriceLevel {
+	levels := make([]PriceLevel, 0)
+	for ; node != nil; node = node.next {
+		view := node.level
+		orders := make([]order.Order, len(view.orders))
+		copy(orders, view.orders)
+
+		level := PriceLevel{Price: view.price, Orders: make([]*order.Order, len(orders))}
+		for i := range orders {
+			level.Orders[i] = &orders[i]
+		}
+		levels = append(levels, level)
+	}
+	return levels
+}
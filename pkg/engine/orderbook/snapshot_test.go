@@ -0,0 +1,151 @@
+package orderbook
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+// liveLevels descreve os níveis do livro lidos sob o lock, para comparar com
+// o snapshot publicado
+func liveLevels(ob *OrderBook) string {
+	dump := ob.DebugDump()
+	describe := func(levels []DebugLevel) string {
+		var out string
+		for _, level := range levels {
+			out += fmt.Sprintf("%v:", level.Price)
+			for _, o := range level.Orders {
+				out += fmt.Sprintf(" %s/%v", o.ID, o.RemainingQuantity())
+			}
+			out += "; "
+		}
+		return out
+	}
+	return fmt.Sprintf("%d bids %s asks %s", dump.Sequence, describe(dump.Bids), describe(dump.Asks))
+}
+
+func snapshotLevels(snapshot *OrderBookSnapshot) string {
+	describe := func(levels []PriceLevel) string {
+		var out string
+		for _, level := range levels {
+			out += fmt.Sprintf("%v:", level.Price)
+			for _, o := range level.Orders {
+				out += fmt.Sprintf(" %s/%v", o.ID, o.RemainingQuantity())
+			}
+			out += "; "
+		}
+		return out
+	}
+	return fmt.Sprintf("%d bids %s asks %s", snapshot.Sequence, describe(snapshot.Bids), describe(snapshot.Asks))
+}
+
+func TestOrderBook_SnapshotFollowsEveryChange(t *testing.T) {
+	ob := NewOrderBookWithConfig(SymbolConfig{Symbol: "BTC-USD", OrderTTL: time.Minute})
+	random := rand.New(rand.NewSource(1))
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var placed []*order.Order
+	for i := 0; i < 2000; i++ {
+		var step string
+		switch n := random.Intn(10); {
+		case n < 5 || len(placed) == 0:
+			side := order.SideBuy
+			if random.Intn(2) == 0 {
+				side = order.SideSell
+			}
+			o := mustNewOrder(t, side, "BTC-USD", float64(95+random.Intn(11)), float64(1+random.Intn(3)))
+			o.CreatedAt = start.Add(time.Duration(i) * time.Second)
+			ob.AddOrder(o)
+			placed = append(placed, o)
+			step = "add"
+		case n < 7:
+			ob.CancelOrder(placed[random.Intn(len(placed))].ID)
+			step = "cancel"
+		case n < 8:
+			o := placed[random.Intn(len(placed))]
+			ob.AmendOrder(o.ID, float64(95+random.Intn(11)), o.Quantity+1)
+			step = "amend"
+		case n < 9:
+			ob.ReduceOrder(placed[random.Intn(len(placed))].ID, 0.5)
+			step = "reduce"
+		default:
+			ob.ExpireOrders(start.Add(time.Duration(i) * time.Second))
+			step = "expire"
+		}
+
+		if got, want := snapshotLevels(ob.GetOrderBook()), liveLevels(ob); got != want {
+			t.Fatalf("step %d (%s): snapshot\n%s\ndiffers from the book\n%s", i, step, got, want)
+		}
+	}
+}
+
+func TestOrderBook_SnapshotIsACopy(t *testing.T) {
+	ob := NewOrderBook("BTC-USD")
+	o := mustNewOrder(t, order.SideBuy, "BTC-USD", 100.0, 2.0)
+	ob.AddOrder(o)
+
+	snapshot := ob.GetOrderBook()
+	snapshot.Bids[0].Orders[0].Quantity = 50
+
+	ob.AddOrder(mustNewOrder(t, order.SideSell, "BTC-USD", 100.0, 1.0))
+	if remaining := snapshot.Bids[0].Orders[0].RemainingQuantity(); remaining != 50 {
+		t.Errorf("expected the snapshot to keep its own copy, got remaining %v", remaining)
+	}
+	if got := ob.GetOrderBook().Bids[0].Orders[0].RemainingQuantity(); got != 1 {
+		t.Errorf("expected the book to ignore changes to a snapshot, got remaining %v", got)
+	}
+}
+
+// BenchmarkMatchingDeepBook mede um ciclo de matching (uma ordem que executa
+// contra o topo e a reposição do nível) num livro de 5000 níveis por lado,
+// sozinho e com leitores tirando snapshots do livro inteiro sem parar
+func BenchmarkMatchingDeepBook(b *testing.B) {
+	for _, readers := range []int{0, 2} {
+		b.Run(fmt.Sprintf("snapshot readers=%d", readers), func(b *testing.B) {
+			ob := NewOrderBook("BTC-USD")
+			for i := 0; i < 5000; i++ {
+				for _, side := range []order.Side{order.SideBuy, order.SideSell} {
+					price := 10000.0 - float64(i)
+					if side == order.SideSell {
+						price = 10001.0 + float64(i)
+					}
+					o, _ := order.NewOrder(side, "BTC-USD", price, 2)
+					ob.AddOrder(o)
+				}
+			}
+
+			stop := make(chan struct{})
+			var wg sync.WaitGroup
+			for r := 0; r < readers; r++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for {
+						select {
+						case <-stop:
+							return
+						default:
+							ob.GetOrderBook()
+						}
+					}
+				}()
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				taker, _ := order.NewOrder(order.SideBuy, "BTC-USD", 10001, 1)
+				ob.AddOrder(taker)
+				maker, _ := order.NewOrder(order.SideSell, "BTC-USD", 10001, 1)
+				ob.AddOrder(maker)
+			}
+			b.StopTimer()
+
+			close(stop)
+			wg.Wait()
+		})
+	}
+}
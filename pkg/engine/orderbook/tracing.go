@@ -0,0 +1,49 @@
+package orderbook
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+const tracerName = "company.com/matchengine/pkg/engine/orderbook"
+
+// startSpan abre um span filho do span em ctx com o símbolo e, se houver, a
+// ordem como atributos. Sem um tracer provider configurado nada é registrado.
+func startSpan(ctx context.Context, name string, o *order.Order, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if o != nil {
+		attrs = append(attrs,
+			attribute.String("symbol", o.Symbol),
+			attribute.String("order_id", o.ID),
+		)
+	}
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan marca o span com o erro, se houver, e o encerra
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// tracedMatch executa tryMatch num span próprio, com os negócios gerados
+func (ob *OrderBook) tracedMatch(ctx context.Context, o *order.Order) (err error) {
+	_, span := startSpan(ctx, "orderbook.tryMatch", o)
+	defer func() { endSpan(span, err) }()
+
+	trades := ob.tradeCount
+	err = ob.tryMatch(o)
+	span.SetAttributes(
+		attribute.Int64("trades", int64(ob.tradeCount-trades)),
+		attribute.Float64("filled", o.Filled),
+	)
+	return err
+}
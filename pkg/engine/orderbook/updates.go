@@ -0,0 +1,100 @@
+package orderbook
+
+import "company.com/matchengine/pkg/engine/order"
+
+// Update registra uma mudança de estado de ordem ocorrida no livro
+type Update struct {
+	// Order é uma cópia do estado da ordem logo após a mudança
+	Order order.Order
+	// Trade é o negócio que causou a mudança, quando houver
+	Trade *Trade
+	// Sequence é o número de sequência do livro após a operação que causou
+	// a mudança; todas as mudanças de uma mesma operação o compartilham
+	Sequence uint64
+	// Expired marca o cancelamento de uma ordem que passou do OrderTTL ou do
+	// seu ExpiresAt
+	Expired bool
+	// Amended marca a alteração de preço ou quantidade de uma ordem em
+	// repouso, por AmendOrder ou ReduceOrder
+	Amended bool
+}
+
+// SetUpdateListener registra quem recebe as mudanças de estado das ordens.
+// As mudanças de cada operação são entregues em ordem, fora do lock do livro.
+func (ob *OrderBook) SetUpdateListener(listener func([]Update)) {
+	ob.mutex.Lock()
+	defer ob.mutex.Unlock()
+
+	ob.updateListener = listener
+}
+
+// emit guarda uma cópia do estado atual da ordem para entrega após o unlock
+func (ob *OrderBook) emit(o *order.Order, trade *Trade) {
+	ob.changed = true
+	ob.touch(o.Side, o.Price)
+	if ob.updateListener == nil {
+		return
+	}
+	ob.pendingUpdates = append(ob.pendingUpdates, Update{Order: *o, Trade: trade})
+}
+
+// emitExpired é o emit do cancelamento de uma ordem expirada
+func (ob *OrderBook) emitExpired(o *order.Order) {
+	ob.changed = true
+	ob.touch(o.Side, o.Price)
+	if ob.updateListener == nil {
+		return
+	}
+	ob.pendingUpdates = append(ob.pendingUpdates, Update{Order: *o, Expired: true})
+}
+
+// emitAmended é o emit da alteração de uma ordem em repouso
+func (ob *OrderBook) emitAmended(o *order.Order) {
+	ob.changed = true
+	ob.touch(o.Side, o.Price)
+	if ob.updateListener == nil {
+		return
+	}
+	ob.pendingUpdates = append(ob.pendingUpdates, Update{Order: *o, Amended: true})
+}
+
+// cancelRemainder cancela o que sobrou de uma ordem que não pode repousar no livro
+func (ob *OrderBook) cancelRemainder(o *order.Order) {
+	if o.Status == order.StatusFilled {
+		return
+	}
+	o.Cancel()
+	ob.emit(o, nil)
+}
+
+// unlock avança a sequência e publica a nova versão do livro se a operação o
+// alterou, libera o lock de escrita e só então entrega as mudanças de estado e um eventual disparo do
+// circuit breaker
+func (ob *OrderBook) unlock() {
+	if ob.changed {
+		ob.sequence++
+		ob.changed = false
+		for i := range ob.pendingUpdates {
+			ob.pendingUpdates[i].Sequence = ob.sequence
+		}
+		ob.publish()
+	}
+
+	updates, updateListener := ob.pendingUpdates, ob.updateListener
+	halt, haltListener := ob.pendingHalt, ob.haltListener
+	violation, invariantListener := ob.pendingViolation, ob.invariantListener
+	ob.pendingUpdates = nil
+	ob.pendingHalt = nil
+	ob.pendingViolation = nil
+	ob.mutex.Unlock()
+
+	if len(updates) > 0 && updateListener != nil {
+		updateListener(updates)
+	}
+	if halt != nil && haltListener != nil {
+		haltListener(*halt)
+	}
+	if violation != nil && invariantListener != nil {
+		invariantListener(*violation)
+	}
+}
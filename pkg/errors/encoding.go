@@ -0,0 +1,83 @@
+package errors
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Content types responses can be encoded in
+const (
+	ContentTypeJSON    = "application/json"
+	ContentTypeMsgpack = "application/msgpack"
+)
+
+// Encoding writes response bodies in one content type
+type Encoding interface {
+	ContentType() string
+	Encode(w io.Writer, v interface{}) error
+}
+
+var (
+	// JSON is the default encoding
+	JSON Encoding = jsonEncoding{}
+	// MessagePack encodes the same fields as JSON, named by their json tags
+	MessagePack Encoding = msgpackEncoding{}
+)
+
+type jsonEncoding struct{}
+
+func (jsonEncoding) ContentType() string { return ContentTypeJSON }
+
+func (jsonEncoding) Encode(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+type msgpackEncoding struct{}
+
+func (msgpackEncoding) ContentType() string { return ContentTypeMsgpack }
+
+func (msgpackEncoding) Encode(w io.Writer, v interface{}) error {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	return enc.Encode(v)
+}
+
+// encodings maps the media types clients ask for to their encoding
+var encodings = map[string]Encoding{
+	ContentTypeJSON:         JSON,
+	ContentTypeMsgpack:      MessagePack,
+	"application/x-msgpack": MessagePack,
+}
+
+// Negotiate picks the supported encoding the request's Accept header ranks
+// highest, or JSON when it names none
+func Negotiate(r *http.Request) Encoding {
+	best, bestQ := JSON, 0.0
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		encoding, supported := encodings[mediaType]
+		if !supported {
+			continue
+		}
+
+		q := 1.0
+		if value, exists := params["q"]; exists {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
@@ -3,18 +3,69 @@ package errors
 import (
 	"fmt"
 	"net/http"
+	"time"
 )
 
 type APIError struct {
 	Status  int    `json:"-"`
 	Code    string `json:"code"`
 	Message string `json:"message"`
+	// Reason is set on rejected orders, telling why in a form clients can
+	// branch on
+	Reason RejectReason `json:"reason,omitempty"`
+	// RetryAfter, when set, is sent as the Retry-After header, telling
+	// throttled and turned-away clients when to try again
+	RetryAfter time.Duration `json:"-"`
 }
 
 func (e *APIError) Error() string {
 	return e.Message
 }
 
+// WithReason returns a copy of the error carrying a rejection reason
+func (e *APIError) WithReason(reason RejectReason) *APIError {
+	withReason := *e
+	withReason.Reason = reason
+	return &withReason
+}
+
+// WithRetryAfter returns a copy of the error telling clients to retry after
+// d
+func (e *APIError) WithRetryAfter(d time.Duration) *APIError {
+	withRetry := *e
+	withRetry.RetryAfter = d
+	return &withRetry
+}
+
+// RejectReason is a machine-readable code for why an order was rejected
+type RejectReason string
+
+// Reasons an order is rejected
+const (
+	ReasonSymbolHalted        RejectReason = "SYMBOL_HALTED"
+	ReasonPriceOutOfBand      RejectReason = "PRICE_OUT_OF_BAND"
+	ReasonInvalidTick         RejectReason = "INVALID_TICK"
+	ReasonDepthLimitReached   RejectReason = "DEPTH_LIMIT_REACHED"
+	ReasonInsufficientBalance RejectReason = "INSUFFICIENT_BALANCE"
+	ReasonReduceOnly          RejectReason = "REDUCE_ONLY"
+	ReasonQuotaExceeded       RejectReason = "QUOTA_EXCEEDED"
+	ReasonBelowMinNotional    RejectReason = "BELOW_MIN_NOTIONAL"
+	ReasonWouldCross          RejectReason = "WOULD_CROSS"
+)
+
+// RejectReasons lists every RejectReason
+var RejectReasons = []RejectReason{
+	ReasonSymbolHalted,
+	ReasonPriceOutOfBand,
+	ReasonInvalidTick,
+	ReasonDepthLimitReached,
+	ReasonInsufficientBalance,
+	ReasonReduceOnly,
+	ReasonQuotaExceeded,
+	ReasonBelowMinNotional,
+	ReasonWouldCross,
+}
+
 // Common errors
 var (
 	ErrBadRequest = &APIError{
@@ -23,6 +74,12 @@ var (
 		Message: "Invalid request",
 	}
 
+	ErrUnauthorized = &APIError{
+		Status:  http.StatusUnauthorized,
+		Code:    "UNAUTHORIZED",
+		Message: "Unauthorized",
+	}
+
 	ErrNotFound = &APIError{
 		Status:  http.StatusNotFound,
 		Code:    "NOT_FOUND",
@@ -34,6 +91,42 @@ var (
 		Code:    "INTERNAL_ERROR",
 		Message: "Internal server error",
 	}
+
+	ErrInsufficientBalance = &APIError{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "INSUFFICIENT_BALANCE",
+		Message: "Insufficient balance",
+	}
+
+	ErrOrderNotCancellable = &APIError{
+		Status:  http.StatusConflict,
+		Code:    "ORDER_NOT_CANCELLABLE",
+		Message: "Order not cancellable",
+	}
+
+	ErrPayloadTooLarge = &APIError{
+		Status:  http.StatusRequestEntityTooLarge,
+		Code:    "PAYLOAD_TOO_LARGE",
+		Message: "Request body too large",
+	}
+
+	ErrPreconditionFailed = &APIError{
+		Status:  http.StatusPreconditionFailed,
+		Code:    "PRECONDITION_FAILED",
+		Message: "Precondition failed",
+	}
+
+	ErrTooManyRequests = &APIError{
+		Status:  http.StatusTooManyRequests,
+		Code:    "TOO_MANY_REQUESTS",
+		Message: "Too many requests",
+	}
+
+	ErrServiceUnavailable = &APIError{
+		Status:  http.StatusServiceUnavailable,
+		Code:    "SERVICE_UNAVAILABLE",
+		Message: "Service unavailable",
+	}
 )
 
 // Error constructors
@@ -45,6 +138,14 @@ func NewBadRequest(message string) *APIError {
 	}
 }
 
+func NewUnauthorized(message string) *APIError {
+	return &APIError{
+		Status:  http.StatusUnauthorized,
+		Code:    "UNAUTHORIZED",
+		Message: message,
+	}
+}
+
 func NewNotFound(resource string) *APIError {
 	return &APIError{
 		Status:  http.StatusNotFound,
@@ -60,3 +161,67 @@ func NewInternal(err error) *APIError {
 		Message: "Internal server error",
 	}
 }
+
+func NewServiceUnavailable(message string) *APIError {
+	return &APIError{
+		Status:  http.StatusServiceUnavailable,
+		Code:    "SERVICE_UNAVAILABLE",
+		Message: message,
+	}
+}
+
+func NewInsufficientBalance(message string) *APIError {
+	return &APIError{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "INSUFFICIENT_BALANCE",
+		Message: message,
+	}
+}
+
+func NewOrderNotCancellable(message string) *APIError {
+	return &APIError{
+		Status:  http.StatusConflict,
+		Code:    "ORDER_NOT_CANCELLABLE",
+		Message: message,
+	}
+}
+
+func NewPayloadTooLarge(limit int64) *APIError {
+	return &APIError{
+		Status:  http.StatusRequestEntityTooLarge,
+		Code:    "PAYLOAD_TOO_LARGE",
+		Message: fmt.Sprintf("request body exceeds %d bytes", limit),
+	}
+}
+
+func NewPreconditionFailed(message string) *APIError {
+	return &APIError{
+		Status:  http.StatusPreconditionFailed,
+		Code:    "PRECONDITION_FAILED",
+		Message: message,
+	}
+}
+
+func NewTooManyRequests(message string) *APIError {
+	return &APIError{
+		Status:  http.StatusTooManyRequests,
+		Code:    "TOO_MANY_REQUESTS",
+		Message: message,
+	}
+}
+
+func NewConflict(message string) *APIError {
+	return &APIError{
+		Status:  http.StatusConflict,
+		Code:    "CONFLICT",
+		Message: message,
+	}
+}
+
+func NewOrderNotResting(message string) *APIError {
+	return &APIError{
+		Status:  http.StatusConflict,
+		Code:    "ORDER_NOT_RESTING",
+		Message: message,
+	}
+}
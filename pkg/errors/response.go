@@ -1,8 +1,9 @@
 package errors
 
 import (
-	"encoding/json"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 // Response represents a standard API response
@@ -14,6 +15,29 @@ type Response struct {
 
 // WriteJSON writes a JSON response
 func WriteJSON(w http.ResponseWriter, data interface{}) {
+	WriteJSONWithStatus(w, http.StatusOK, data)
+}
+
+// WriteJSONWithStatus writes a JSON response using the given status code for
+// successful payloads. Errors always use their own status.
+func WriteJSONWithStatus(w http.ResponseWriter, status int, data interface{}) {
+	writeResponse(w, JSON, status, data)
+}
+
+// Write writes a response in the encoding the request's Accept header
+// prefers, JSON unless it asks for another supported one
+func Write(w http.ResponseWriter, r *http.Request, data interface{}) {
+	WriteWithStatus(w, r, http.StatusOK, data)
+}
+
+// WriteWithStatus is Write with the given status code for successful
+// payloads. Errors always use their own status.
+func WriteWithStatus(w http.ResponseWriter, r *http.Request, status int, data interface{}) {
+	w.Header().Add("Vary", "Accept")
+	writeResponse(w, Negotiate(r), status, data)
+}
+
+func writeResponse(w http.ResponseWriter, encoding Encoding, status int, data interface{}) {
 	var resp Response
 	switch v := data.(type) {
 	case *APIError:
@@ -21,7 +45,10 @@ func WriteJSON(w http.ResponseWriter, data interface{}) {
 			Success: false,
 			Error:   v,
 		}
-		w.WriteHeader(v.Status)
+		status = v.Status
+		if v.RetryAfter > 0 {
+			w.Header().Set("Retry-After", RetryAfterSeconds(v.RetryAfter))
+		}
 	default:
 		resp = Response{
 			Success: true,
@@ -29,6 +56,13 @@ func WriteJSON(w http.ResponseWriter, data interface{}) {
 		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
+	w.Header().Set("Content-Type", encoding.ContentType())
+	w.WriteHeader(status)
+	encoding.Encode(w, resp)
+}
+
+// RetryAfterSeconds renders d as Retry-After's whole seconds, rounded up so
+// clients never come back early
+func RetryAfterSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64((d+time.Second-1)/time.Second), 10)
 }
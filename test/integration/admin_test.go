@@ -0,0 +1,236 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	httphandler "company.com/matchengine/internal/handler/http"
+	"company.com/matchengine/internal/position"
+	"company.com/matchengine/internal/service/matching"
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestHaltResumeEndpoints(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := matching.NewService(matching.WithLogger(logger))
+	api := httphandler.NewHandler(service, logger)
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux)
+	api.RegisterAdminRoutes(mux, "s3cret")
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	admin := func(path, token string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, server.URL+path, nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := admin("/api/v1/admin/symbols/BTC-USD/halt", "s3cret")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	o, err := order.NewOrder(order.SideBuy, "BTC-USD", 100.0, 1.0)
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), o))
+
+	// Without the admin token nobody can stop trading
+	assert.Equal(t, http.StatusUnauthorized, admin("/api/v1/admin/symbols/BTC-USD/halt", "").StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, admin("/api/v1/admin/symbols/BTC-USD/halt", "wrong").StatusCode)
+	info, err := service.Symbol("BTC-USD")
+	require.NoError(t, err)
+	assert.False(t, info.Halted)
+
+	resp = admin("/api/v1/admin/symbols/BTC-USD/halt", "s3cret")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"sell","price":100,"quantity":1}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	assert.Equal(t, http.StatusUnauthorized, admin("/api/v1/admin/symbols/BTC-USD/resume", "").StatusCode)
+	resp = admin("/api/v1/admin/symbols/BTC-USD/resume", "s3cret")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"sell","price":100,"quantity":1}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, order.StatusFilled, o.Status)
+}
+
+func TestFlattenEndpoint(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := matching.NewService(matching.WithLogger(logger))
+	api := httphandler.NewHandler(service, logger)
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux)
+	api.RegisterAdminRoutes(mux, "s3cret")
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	place := func(body string) {
+		resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders", body)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+	flatten := func(token, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/api/v1/admin/flatten", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	positions := func(account string) []position.Position {
+		resp := doRequest(t, http.MethodGet, server.URL+"/api/v1/accounts/"+account+"/positions", "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var body struct {
+			Data []position.Position `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return body.Data
+	}
+
+	// alice goes long 3, has a bid resting, and there is a bid to sell into
+	place(`{"account_id":"mm","symbol":"BTC-USD","side":"sell","price":100,"quantity":3}`)
+	place(`{"account_id":"alice","symbol":"BTC-USD","side":"buy","price":100,"quantity":3}`)
+	place(`{"account_id":"alice","symbol":"BTC-USD","side":"buy","price":90,"quantity":1}`)
+	place(`{"account_id":"mm","symbol":"BTC-USD","side":"buy","price":95,"quantity":5}`)
+	require.Len(t, positions("alice"), 1)
+
+	assert.Equal(t, http.StatusUnauthorized, flatten("", `{"account_id":"alice"}`).StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, flatten("wrong", `{"account_id":"alice"}`).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, flatten("s3cret", `{}`).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, flatten("s3cret", `{"account_id":"alice","all":true}`).StatusCode)
+
+	resp := flatten("s3cret", `{"account_id":"alice"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var env struct {
+		Data httphandler.FlattenResponse `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&env))
+	require.Len(t, env.Data.Accounts, 1)
+	alice := env.Data.Accounts[0]
+	assert.Equal(t, "alice", alice.AccountID)
+	require.Len(t, alice.Cancelled, 1)
+	assert.Equal(t, order.StatusCancelled, alice.Cancelled[0].Status)
+	require.Len(t, alice.Closing, 1)
+	assert.Equal(t, order.SideSell, alice.Closing[0].Side)
+	assert.Equal(t, order.StatusFilled, alice.Closing[0].Status)
+	assert.Empty(t, alice.Failed)
+	assert.Empty(t, positions("alice"))
+
+	// Nothing left to do the second time
+	resp = flatten("s3cret", `{"account_id":"alice"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&env))
+	assert.Empty(t, env.Data.Accounts[0].Cancelled)
+	assert.Empty(t, env.Data.Accounts[0].Closing)
+
+	// The route is only there when registered
+	plain, _ := newTestServer(t)
+	assert.Equal(t, http.StatusNotFound, doRequest(t, http.MethodPost, plain.URL+"/api/v1/admin/flatten", `{"all":true}`).StatusCode)
+}
+
+func TestAdminSymbolEndpoints(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := matching.NewService(matching.WithLogger(logger))
+	api := httphandler.NewHandler(service, logger)
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux)
+	api.RegisterAdminRoutes(mux, "s3cret")
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	admin := func(method, path, token, body string) *http.Response {
+		req, err := http.NewRequest(method, server.URL+path, bytes.NewBufferString(body))
+		require.NoError(t, err)
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	list := func() []httphandler.AdminSymbol {
+		resp := admin(http.MethodGet, "/api/v1/admin/symbols", "s3cret", "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var env struct {
+			Data []httphandler.AdminSymbol `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&env))
+		return env.Data
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, admin(http.MethodGet, "/api/v1/admin/symbols", "", "").StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, admin(http.MethodPost, "/api/v1/admin/symbols", "wrong", `{"symbol":"ETH-USD"}`).StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, admin(http.MethodDelete, "/api/v1/admin/symbols/ETH-USD", "", "").StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, admin(http.MethodPost, "/api/v1/admin/symbols/ETH-USD/halt", "", "").StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, admin(http.MethodPost, "/api/v1/admin/symbols/ETH-USD/resume", "wrong", "").StatusCode)
+	assert.Empty(t, list())
+
+	resp := admin(http.MethodPost, "/api/v1/admin/symbols", "s3cret",
+		`{"symbol":"eth/usd","min_notional":10,"order_ttl":"1h","halted":true}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var created struct {
+		Data httphandler.AdminSymbol `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	assert.Equal(t, "ETH-USD", created.Data.Symbol)
+	assert.Equal(t, 10.0, created.Data.MinNotional)
+	assert.Equal(t, time.Hour, created.Data.Config().OrderTTL)
+	require.NotNil(t, created.Data.Halted)
+	assert.True(t, *created.Data.Halted)
+
+	assert.Equal(t, http.StatusConflict, admin(http.MethodPost, "/api/v1/admin/symbols", "s3cret", `{"symbol":"ETH-USD"}`).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, admin(http.MethodPost, "/api/v1/admin/symbols", "s3cret", `{"min_notional":10}`).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, admin(http.MethodPost, "/api/v1/admin/symbols", "s3cret", `{"symbol":"SOL-USD","bogus":1}`).StatusCode)
+
+	resp = doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"buy","price":100,"quantity":1}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	resting := decodeOrder(t, resp).Data
+
+	symbols := list()
+	require.Len(t, symbols, 2)
+	assert.Equal(t, "BTC-USD", symbols[0].Symbol)
+	assert.Equal(t, 1, symbols[0].ActiveOrders)
+	assert.False(t, *symbols[0].Halted)
+	assert.Equal(t, "ETH-USD", symbols[1].Symbol)
+	assert.True(t, *symbols[1].Halted)
+
+	// A book with orders on it can't be retired
+	assert.Equal(t, http.StatusConflict, admin(http.MethodDelete, "/api/v1/admin/symbols/BTC-USD", "s3cret", "").StatusCode)
+	require.Len(t, list(), 2)
+
+	resp = doRequest(t, http.MethodDelete, server.URL+"/api/v1/orders/"+resting.ID, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.StatusNoContent, admin(http.MethodDelete, "/api/v1/admin/symbols/BTC-USD", "s3cret", "").StatusCode)
+	assert.Equal(t, http.StatusNotFound, admin(http.MethodDelete, "/api/v1/admin/symbols/BTC-USD", "s3cret", "").StatusCode)
+
+	symbols = list()
+	require.Len(t, symbols, 1)
+	assert.Equal(t, "ETH-USD", symbols[0].Symbol)
+}
@@ -0,0 +1,60 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"company.com/matchengine/internal/candle"
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestCandlesEndpoint(t *testing.T) {
+	server, service := newTestServer(t)
+
+	for _, o := range []struct {
+		side     order.Side
+		price    float64
+		quantity float64
+	}{
+		{order.SideSell, 100.0, 1.0},
+		{order.SideBuy, 100.0, 1.0},
+		{order.SideSell, 105.0, 2.0},
+		{order.SideBuy, 105.0, 2.0},
+	} {
+		placed, err := order.NewOrder(o.side, "BTC-USD", o.price, o.quantity)
+		require.NoError(t, err)
+		require.NoError(t, service.AddOrder(context.Background(), placed))
+	}
+
+	resp := doRequest(t, http.MethodGet, server.URL+"/api/v1/candles/BTC-USD?interval=1h", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data []candle.Candle `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	// The trades may straddle an hour boundary, so count them across candles
+	var volume float64
+	var trades int
+	for _, c := range body.Data {
+		volume += c.Volume
+		trades += c.Trades
+	}
+	require.NotEmpty(t, body.Data)
+	last := body.Data[len(body.Data)-1]
+	assert.Equal(t, 105.0, last.Close)
+	assert.Equal(t, 3.0, volume)
+	assert.Equal(t, 2, trades)
+
+	resp = doRequest(t, http.MethodGet, server.URL+"/api/v1/candles/BTC-USD?interval=90s", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = doRequest(t, http.MethodGet, server.URL+"/api/v1/candles/ETH-USD", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
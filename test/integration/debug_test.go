@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	httphandler "company.com/matchengine/internal/handler/http"
+	"company.com/matchengine/internal/service/matching"
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+func TestDebugOrderBookEndpoint(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := matching.NewService(matching.WithLogger(logger))
+	api := httphandler.NewHandler(service, logger)
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux)
+	api.RegisterDebugRoutes(mux, "s3cret")
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	for _, price := range []float64{100, 101} {
+		o, err := order.NewOrder(order.SideBuy, "BTC-USD", price, 1)
+		require.NoError(t, err)
+		require.NoError(t, service.AddOrder(context.Background(), o))
+	}
+
+	get := func(token string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/debug/orderbook/btc-usd", nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, get("").StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, get("wrong").StatusCode)
+
+	resp := get("s3cret")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var env struct {
+		Data orderbook.DebugDump `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&env))
+	assert.Equal(t, "BTC-USD", env.Data.Symbol)
+	assert.True(t, env.Data.Consistent)
+	assert.Equal(t, 2, env.Data.OrdersMapSize)
+	require.Len(t, env.Data.Bids, 2)
+	assert.Equal(t, 101.0, env.Data.Bids[0].Price)
+	assert.Equal(t, 100.0, *env.Data.Bids[0].Next)
+	assert.Equal(t, 101.0, *env.Data.Bids[1].Previous)
+
+	// The debug routes are only there when registered
+	plain, _ := newTestServer(t)
+	assert.Equal(t, http.StatusNotFound, doRequest(t, http.MethodGet, plain.URL+"/debug/orderbook/BTC-USD", "").StatusCode)
+}
@@ -0,0 +1,120 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+func getWithAccept(t *testing.T, url, accept string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func decodeMsgpack(t *testing.T, resp *http.Response, v interface{}) {
+	t.Helper()
+
+	dec := msgpack.NewDecoder(resp.Body)
+	dec.SetCustomStructTag("json")
+	require.NoError(t, dec.Decode(v))
+}
+
+func TestContentNegotiation(t *testing.T) {
+	server, service := newTestServer(t)
+
+	resting, err := order.NewOrder(order.SideBuy, "BTC-USD", 50000.5, 1.25)
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), resting))
+
+	type orderEnvelope struct {
+		Success bool        `json:"success"`
+		Data    order.Order `json:"data"`
+	}
+	type snapshotEnvelope struct {
+		Success bool                        `json:"success"`
+		Data    orderbook.OrderBookSnapshot `json:"data"`
+	}
+
+	for _, tc := range []struct {
+		name        string
+		accept      string
+		contentType string
+	}{
+		{"default", "", "application/json"},
+		{"json", "application/json", "application/json"},
+		{"msgpack", "application/msgpack", "application/msgpack"},
+		{"x-msgpack", "application/x-msgpack", "application/msgpack"},
+		{"preference", "application/json;q=0.5, application/msgpack", "application/msgpack"},
+		{"unsupported", "text/html", "application/json"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			decode := func(resp *http.Response, v interface{}) {
+				assert.Equal(t, tc.contentType, resp.Header.Get("Content-Type"))
+				if tc.contentType == "application/msgpack" {
+					decodeMsgpack(t, resp, v)
+					return
+				}
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+			}
+
+			resp := getWithAccept(t, server.URL+"/api/v1/orders/"+resting.ID, tc.accept)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var o orderEnvelope
+			decode(resp, &o)
+			assert.True(t, o.Success)
+			assert.Equal(t, resting.ID, o.Data.ID)
+			assert.Equal(t, 50000.5, o.Data.Price)
+			assert.Equal(t, 1.25, o.Data.Quantity)
+			assert.Equal(t, order.StatusNew, o.Data.Status)
+
+			resp = getWithAccept(t, server.URL+"/api/v1/orderbook/BTC-USD", tc.accept)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var snapshot snapshotEnvelope
+			decode(resp, &snapshot)
+			assert.Equal(t, "BTC-USD", snapshot.Data.Symbol)
+			require.Len(t, snapshot.Data.Bids, 1)
+			assert.Equal(t, 50000.5, snapshot.Data.Bids[0].Price)
+			require.Len(t, snapshot.Data.Bids[0].Orders, 1)
+			assert.Equal(t, resting.ID, snapshot.Data.Bids[0].Orders[0].ID)
+			assert.Empty(t, snapshot.Data.Asks)
+		})
+	}
+}
+
+func TestContentNegotiation_Errors(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	resp := getWithAccept(t, server.URL+"/api/v1/orderbook/UNKNOWN", "application/msgpack")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "application/msgpack", resp.Header.Get("Content-Type"))
+
+	var body struct {
+		Success bool `json:"success"`
+		Error   struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	decodeMsgpack(t, resp, &body)
+	assert.False(t, body.Success)
+	assert.Equal(t, "NOT_FOUND", body.Error.Code)
+}
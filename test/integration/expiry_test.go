@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	httphandler "company.com/matchengine/internal/handler/http"
+	"company.com/matchengine/internal/service/matching"
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestCreateOrder_ExpireAfterSeconds(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	service := matching.NewService(matching.WithClock(func() time.Time { return now }))
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mux := http.NewServeMux()
+	httphandler.NewHandler(service, logger).RegisterRoutes(mux)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"buy","price":50000,"quantity":1,"expire_after_seconds":30}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var created struct {
+		Data struct {
+			ID        string    `json:"id"`
+			ExpiresAt time.Time `json:"expires_at"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	assert.True(t, created.Data.ExpiresAt.Equal(now.Add(30*time.Second)),
+		"expected expiry at now+30s, got %s", created.Data.ExpiresAt)
+
+	resp = doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"buy","price":49000,"quantity":1}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	gtc := decodeOrder(t, resp)
+
+	ctx := context.Background()
+	now = now.Add(29 * time.Second)
+	expired, err := service.ExpireOrders(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, expired)
+
+	now = now.Add(time.Second)
+	expired, err = service.ExpireOrders(ctx)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, created.Data.ID, expired[0].ID)
+	assert.Equal(t, order.StatusCancelled, expired[0].Status)
+
+	resp = doRequest(t, http.MethodGet, server.URL+"/api/v1/orders/"+created.Data.ID, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "an expired order leaves the book")
+
+	resp = doRequest(t, http.MethodGet, server.URL+"/api/v1/orders/"+gtc.Data.ID, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, order.StatusNew, decodeOrder(t, resp).Data.Status, "an order without an expiry rests until cancelled")
+
+	resp = doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"buy","price":50000,"quantity":1,"expire_after_seconds":-5}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
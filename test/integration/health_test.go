@@ -1 +1,75 @@
- 
\ No newline at end of file
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	httphandler "company.com/matchengine/internal/handler/http"
+	"company.com/matchengine/internal/service/matching"
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestHealthCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(httphandler.HealthCheck))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var body struct {
+		Success bool              `json:"success"`
+		Data    map[string]string `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.True(t, body.Success)
+	assert.Equal(t, "ok", body.Data["status"])
+}
+
+func TestReadiness(t *testing.T) {
+	server, service := newTestServer(t)
+
+	o, err := order.NewOrder(order.SideBuy, "BTC-USD", 50000.0, 1.0)
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), o))
+
+	resp, err := http.Get(server.URL + "/health/live")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/health/ready")
+	require.NoError(t, err)
+	var ready struct {
+		Data matching.Readiness `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&ready))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, ready.Data.Ready)
+	assert.Equal(t, 1, ready.Data.Symbols)
+	assert.Equal(t, 1, ready.Data.ActiveOrders)
+
+	service.Drain()
+
+	resp, err = http.Get(server.URL + "/health/ready")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+
+	// Liveness is unaffected by draining
+	resp, err = http.Get(server.URL + "/health/live")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,83 @@
+package integration
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+
+	"company.com/matchengine/internal/config"
+	httphandler "company.com/matchengine/internal/handler/http"
+	apiserver "company.com/matchengine/internal/server"
+	"company.com/matchengine/internal/service/matching"
+)
+
+func newConfiguredServer(t *testing.T, cfg config.ServerConfig) *httptest.Server {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mux := http.NewServeMux()
+	httphandler.NewHandler(matching.NewService(matching.WithLogger(logger)), logger).RegisterRoutes(mux)
+
+	srv, err := apiserver.New(cfg, mux, nil)
+	require.NoError(t, err)
+	server := httptest.NewUnstartedServer(srv.Handler)
+	server.Config = srv
+	server.Start()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestServer_H2C(t *testing.T) {
+	server := newConfiguredServer(t, config.ServerConfig{
+		IdleTimeout:               time.Minute,
+		KeepAlives:                true,
+		H2C:                       true,
+		HTTP2MaxConcurrentStreams: 10,
+	})
+
+	// HTTP/2 from the first byte, without TLS
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}}
+
+	for range 3 {
+		resp, err := client.Post(server.URL+"/api/v1/orders", "application/json",
+			strings.NewReader(`{"symbol":"BTC-USD","side":"buy","price":50000,"quantity":1}`))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, 2, resp.ProtoMajor)
+	}
+
+	// HTTP/1.1 clients are still served
+	resp := doRequest(t, http.MethodGet, server.URL+"/api/v1/orderbook/BTC-USD", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, resp.ProtoMajor)
+}
+
+func TestServer_MaxHeaderBytes(t *testing.T) {
+	server := newConfiguredServer(t, config.ServerConfig{MaxHeaderBytes: 1024, KeepAlives: true})
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/orderbook/BTC-USD", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Padding", strings.Repeat("x", 16<<10))
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, resp.StatusCode)
+}
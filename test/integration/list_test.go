@@ -0,0 +1,170 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderPageEnvelope struct {
+	Success bool `json:"success"`
+	Data    struct {
+		Orders []struct {
+			ID     string `json:"id"`
+			Symbol string `json:"symbol"`
+			Side   string `json:"side"`
+		} `json:"orders"`
+		Total  int `json:"total"`
+		Limit  int `json:"limit"`
+		Offset int `json:"offset"`
+	} `json:"data"`
+}
+
+func TestListOrders(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	for _, body := range []string{
+		`{"symbol":"BTC-USD","side":"buy","price":100,"quantity":1}`,
+		`{"symbol":"BTC-USD","side":"sell","price":110,"quantity":1}`,
+		`{"symbol":"ETH-USD","side":"buy","price":10,"quantity":1}`,
+	} {
+		resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders", body)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+
+	list := func(query string) orderPageEnvelope {
+		resp := doRequest(t, http.MethodGet, server.URL+"/api/v1/orders"+query, "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var page orderPageEnvelope
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		return page
+	}
+
+	page := list("")
+	assert.Equal(t, 3, page.Data.Total)
+	assert.Len(t, page.Data.Orders, 3)
+
+	page = list("?symbol=BTC-USD&side=sell")
+	require.Len(t, page.Data.Orders, 1)
+	assert.Equal(t, "sell", page.Data.Orders[0].Side)
+
+	page = list("?limit=2&offset=2")
+	assert.Equal(t, 3, page.Data.Total)
+	assert.Len(t, page.Data.Orders, 1)
+	assert.Equal(t, 2, page.Data.Limit)
+	assert.Equal(t, 2, page.Data.Offset)
+
+	page = list("?from=2000-01-01T00:00:00Z&to=2001-01-01T00:00:00Z")
+	assert.Zero(t, page.Data.Total)
+}
+
+func TestListOrders_InvalidQuery(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	for _, query := range []string{
+		"?side=hold",
+		"?status=open",
+		"?from=yesterday",
+		"?limit=-1",
+		"?offset=abc",
+	} {
+		t.Run(query, func(t *testing.T) {
+			resp := doRequest(t, http.MethodGet, server.URL+"/api/v1/orders"+query, "")
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	}
+}
+
+func TestGetOrderByClientID(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	do := func(method, path, account, body string) *http.Response {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("X-Account-ID", account)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := do(http.MethodPost, "/api/v1/orders", "alice",
+		`{"client_order_id":"mine","symbol":"BTC-USD","side":"buy","price":100,"quantity":1}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	created := decodeOrder(t, resp)
+
+	resp = do(http.MethodGet, "/api/v1/orders?clientOrderId=mine", "alice", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, created.Data.ID, decodeOrder(t, resp).Data.ID)
+
+	resp = do(http.MethodGet, "/api/v1/orders?clientOrderId=other", "alice", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// Bob can't see Alice's client order IDs
+	resp = do(http.MethodGet, "/api/v1/orders?clientOrderId=mine", "bob", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestAccountScopedReads(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	do := func(method, path, account, body string) *http.Response {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		if account != "" {
+			req.Header.Set("X-Account-ID", account)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := do(http.MethodPost, "/api/v1/orders", "alice", `{"symbol":"BTC-USD","side":"buy","price":100,"quantity":1}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	alice := decodeOrder(t, resp).Data.ID
+	resp = do(http.MethodPost, "/api/v1/orders", "bob", `{"symbol":"BTC-USD","side":"buy","price":99,"quantity":1}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	bob := decodeOrder(t, resp).Data.ID
+
+	t.Run("order by ID", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/orders/"+alice, "alice", "").StatusCode)
+		assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/v1/orders/"+alice, "bob", "").StatusCode)
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/orders/"+alice, "", "").StatusCode)
+	})
+
+	t.Run("queue position", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/orders/"+alice+"/queue", "alice", "").StatusCode)
+		assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/v1/orders/"+alice+"/queue", "bob", "").StatusCode)
+	})
+
+	t.Run("listing", func(t *testing.T) {
+		list := func(account string) []string {
+			resp := do(http.MethodGet, "/api/v1/orders", account, "")
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			var page orderPageEnvelope
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+			var ids []string
+			for _, o := range page.Data.Orders {
+				ids = append(ids, o.ID)
+			}
+			return ids
+		}
+
+		assert.Equal(t, []string{alice}, list("alice"))
+		assert.Equal(t, []string{bob}, list("bob"))
+		assert.Equal(t, []string{alice, bob}, list(""))
+	})
+
+	t.Run("positions", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/accounts/alice/positions", "alice", "").StatusCode)
+		assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/v1/accounts/alice/positions", "bob", "").StatusCode)
+	})
+}
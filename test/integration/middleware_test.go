@@ -0,0 +1,200 @@
+package integration
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	httphandler "company.com/matchengine/internal/handler/http"
+	"company.com/matchengine/internal/middleware"
+	"company.com/matchengine/internal/service/matching"
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := matching.NewService()
+	mux := http.NewServeMux()
+	httphandler.NewHandler(service, logger).RegisterRoutes(mux)
+
+	server := httptest.NewServer(middleware.Chain(mux, middleware.MaxBodySize(256)))
+	t.Cleanup(server.Close)
+
+	oversized := fmt.Sprintf(`{"symbol":"BTC-USD","side":"buy","price":100,"quantity":1,"client_order_id":"%s"}`,
+		strings.Repeat("x", 512))
+
+	assertTooLarge := func(t *testing.T, resp *http.Response) {
+		t.Helper()
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+		var body struct {
+			Error struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, "PAYLOAD_TOO_LARGE", body.Error.Code)
+	}
+
+	t.Run("declared length", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders", oversized)
+		assertTooLarge(t, resp)
+	})
+
+	t.Run("chunked", func(t *testing.T) {
+		// Without a Content-Length the body is cut off while it's decoded
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/api/v1/orders",
+			io.MultiReader(strings.NewReader(oversized)))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assertTooLarge(t, resp)
+	})
+
+	t.Run("within limit", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+			`{"symbol":"BTC-USD","side":"buy","price":100,"quantity":1}`)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	})
+
+	assert.Equal(t, 1, service.Stats().ActiveOrders)
+}
+
+func TestTimeout(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+			w.WriteHeader(http.StatusOK)
+		case <-r.Context().Done():
+		}
+	})
+	mux.HandleFunc("GET /fast", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("GET /busy", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	server := httptest.NewServer(middleware.Chain(mux, middleware.Timeout(20*time.Millisecond, 1500*time.Millisecond)))
+	t.Cleanup(server.Close)
+
+	t.Run("slow handler", func(t *testing.T) {
+		start := time.Now()
+		resp := doRequest(t, http.MethodGet, server.URL+"/slow", "")
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "2", resp.Header.Get("Retry-After"))
+
+		var body struct {
+			Success bool `json:"success"`
+			Error   struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.False(t, body.Success)
+		assert.Equal(t, "SERVICE_UNAVAILABLE", body.Error.Code)
+	})
+
+	t.Run("fast handler", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, server.URL+"/fast", "")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+		assert.Empty(t, resp.Header.Get("Retry-After"))
+	})
+
+	t.Run("handler's own Retry-After", func(t *testing.T) {
+		resp := doRequest(t, http.MethodGet, server.URL+"/busy", "")
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, "7", resp.Header.Get("Retry-After"))
+	})
+}
+
+func TestGzip(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := matching.NewService()
+	mux := http.NewServeMux()
+	httphandler.NewHandler(service, logger).RegisterRoutes(mux)
+
+	server := httptest.NewServer(middleware.Chain(mux, middleware.Gzip(middleware.DefaultGzipMinSize)))
+	t.Cleanup(server.Close)
+
+	// Keep the client from decompressing transparently
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+	get := func(t *testing.T, path, acceptEncoding string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	for i := 0; i < 200; i++ {
+		o, err := order.NewOrder(order.SideBuy, "BTC-USD", 40000.0+float64(i), 1.0)
+		require.NoError(t, err)
+		require.NoError(t, service.AddOrder(context.Background(), o))
+	}
+
+	plain := get(t, "/api/v1/orderbook/BTC-USD", "")
+	assert.Empty(t, plain.Header.Get("Content-Encoding"))
+	assert.Contains(t, plain.Header.Values("Vary"), "Accept-Encoding")
+	expected, err := io.ReadAll(plain.Body)
+	require.NoError(t, err)
+	require.Greater(t, len(expected), middleware.DefaultGzipMinSize)
+
+	t.Run("large snapshot", func(t *testing.T) {
+		resp := get(t, "/api/v1/orderbook/BTC-USD", "gzip, deflate")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+		assert.Contains(t, resp.Header.Values("Vary"), "Accept-Encoding")
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+		gz, err := gzip.NewReader(resp.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(gz)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(expected), string(body))
+	})
+
+	t.Run("small response", func(t *testing.T) {
+		resp := get(t, "/health/live", "gzip")
+		assert.Empty(t, resp.Header.Get("Content-Encoding"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, true, body["success"])
+	})
+
+	t.Run("gzip refused", func(t *testing.T) {
+		resp := get(t, "/api/v1/orderbook/BTC-USD", "gzip;q=0")
+		assert.Empty(t, resp.Header.Get("Content-Encoding"))
+	})
+
+	t.Run("error status kept", func(t *testing.T) {
+		resp := get(t, "/api/v1/orderbook/UNKNOWN", "gzip")
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
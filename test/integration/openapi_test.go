@@ -0,0 +1,110 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+type openAPIOperation struct {
+	OperationID string `json:"operationId"`
+	RequestBody struct {
+		Content map[string]struct {
+			Schema openAPISchema `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]struct {
+			Schema openAPISchema `json:"schema"`
+		} `json:"content"`
+	} `json:"responses"`
+}
+
+type openAPISchema struct {
+	Ref        string                   `json:"$ref"`
+	Type       string                   `json:"type"`
+	Properties map[string]openAPISchema `json:"properties"`
+	Required   []string                 `json:"required"`
+	Enum       []string                 `json:"enum"`
+}
+
+func TestOpenAPI(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	resp := doRequest(t, http.MethodGet, server.URL+"/openapi.json", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var spec struct {
+		OpenAPI string `json:"openapi"`
+		Paths   map[string]struct {
+			Get    *openAPIOperation `json:"get"`
+			Post   *openAPIOperation `json:"post"`
+			Delete *openAPIOperation `json:"delete"`
+			Patch  *openAPIOperation `json:"patch"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]openAPISchema `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&spec))
+	assert.Equal(t, "3.0.3", spec.OpenAPI)
+
+	create := spec.Paths["/api/v1/orders"].Post
+	require.NotNil(t, create, "order creation path missing")
+	assert.Equal(t, "createOrder", create.OperationID)
+	assert.Equal(t, "#/components/schemas/CreateOrderRequest",
+		create.RequestBody.Content["application/json"].Schema.Ref)
+	created := create.Responses["201"].Content["application/json"].Schema
+	assert.Equal(t, "#/components/schemas/Order", created.Properties["data"].Ref)
+
+	request := spec.Components.Schemas["CreateOrderRequest"]
+	for _, field := range []string{"symbol", "side", "price", "quantity", "type", "client_order_id", "quote_quantity"} {
+		assert.Contains(t, request.Properties, field)
+	}
+	assert.ElementsMatch(t, []string{"symbol", "side", "price", "quantity"}, request.Required)
+	assert.ElementsMatch(t, []string{"buy", "sell"}, request.Properties["side"].Enum)
+
+	for _, name := range []string{"Order", "OrderBookSnapshot", "APIError", "OrderPage"} {
+		assert.Contains(t, spec.Components.Schemas, name)
+	}
+	assert.NotNil(t, spec.Paths["/api/v1/orders/{id}"].Delete)
+	assert.NotNil(t, spec.Paths["/api/v1/orders/{id}"].Patch)
+	assert.NotNil(t, spec.Paths["/api/v1/orderbook/{symbol}"].Get)
+
+	// The Order schema must describe the fields orders are actually sent with
+	o, err := order.NewOrderOfType(order.TypeStopLimit, order.SideBuy, "BTC-USD", 100, 90, 1, false)
+	require.NoError(t, err)
+	o.ClientOrderID, o.AccountID, o.QuoteQuantity, o.ReduceOnly = "c-1", "acct-1", 10, true
+	o.MatchLatencyMicros = 12.5
+	require.NoError(t, o.Fill(0.5, 100, false))
+	data, err := json.Marshal(o)
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+
+	var sent, described []string
+	for field := range fields {
+		sent = append(sent, field)
+	}
+	for field := range spec.Components.Schemas["Order"].Properties {
+		described = append(described, field)
+	}
+	sort.Strings(sent)
+	sort.Strings(described)
+	assert.Equal(t, sent, described)
+}
+
+func TestSwaggerUI(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	resp := doRequest(t, http.MethodGet, server.URL+"/docs", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+}
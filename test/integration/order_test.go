@@ -0,0 +1,523 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	httphandler "company.com/matchengine/internal/handler/http"
+	"company.com/matchengine/internal/service/matching"
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+type orderEnvelope struct {
+	Success bool `json:"success"`
+	Data    struct {
+		ID        string       `json:"id"`
+		Symbol    string       `json:"symbol"`
+		Status    order.Status `json:"status"`
+		Quantity  float64      `json:"quantity,string"`
+		Filled    float64      `json:"filled,string"`
+		Remaining float64      `json:"remaining,string"`
+
+		MatchLatencyMicros float64 `json:"match_latency_micros"`
+	} `json:"data"`
+}
+
+func doRequest(t *testing.T, method, url, body string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, bytes.NewBufferString(body))
+	require.NoError(t, err)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func decodeOrder(t *testing.T, resp *http.Response) orderEnvelope {
+	t.Helper()
+
+	var env orderEnvelope
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&env))
+	return env
+}
+
+func TestOrderLifecycle(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"buy","price":50000,"quantity":1}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	created := decodeOrder(t, resp)
+	require.True(t, created.Success)
+	assert.Equal(t, order.StatusNew, created.Data.Status)
+	assert.Greater(t, created.Data.MatchLatencyMicros, 0.0)
+
+	resp = doRequest(t, http.MethodGet, server.URL+"/api/v1/orders/"+created.Data.ID, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, created.Data.ID, decodeOrder(t, resp).Data.ID)
+
+	// Partially fill the resting buy
+	resp = doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"sell","price":50000,"quantity":0.25}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// Cancelling reports the final state and keeps the executed amount
+	resp = doRequest(t, http.MethodDelete, server.URL+"/api/v1/orders/"+created.Data.ID, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	cancelled := decodeOrder(t, resp)
+	assert.Equal(t, order.StatusCancelled, cancelled.Data.Status)
+	assert.Equal(t, 0.25, cancelled.Data.Filled)
+	assert.Equal(t, 0.75, cancelled.Data.Remaining)
+
+	resp = doRequest(t, http.MethodGet, server.URL+"/api/v1/orders/"+created.Data.ID, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestCreateOrder_Location(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"buy","price":50000,"quantity":1}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	created := decodeOrder(t, resp)
+
+	location := resp.Header.Get("Location")
+	assert.Equal(t, "/api/v1/orders/"+created.Data.ID, location)
+
+	resp = doRequest(t, http.MethodGet, server.URL+location, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, created.Data.ID, decodeOrder(t, resp).Data.ID)
+}
+
+// TestOrders_ReadWhileFilling creates and reads an order while other
+// requests fill it, for the race detector to watch the responses being
+// encoded
+func TestOrders_ReadWhileFilling(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"buy","price":50000,"quantity":40}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	resting := decodeOrder(t, resp).Data.ID
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 20 {
+			resp, err := http.Post(server.URL+"/api/v1/orders", "application/json",
+				strings.NewReader(`{"symbol":"BTC-USD","side":"sell","price":50000,"quantity":1}`))
+			if assert.NoError(t, err) {
+				resp.Body.Close()
+				assert.Equal(t, http.StatusCreated, resp.StatusCode)
+			}
+		}
+	}()
+
+	// Each ETag describes the body it came with, however the fills land
+	for range 20 {
+		resp := doRequest(t, http.MethodGet, server.URL+"/api/v1/orders/"+resting, "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var env struct {
+			Data order.Order `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&env))
+		assert.Equal(t, resting, env.Data.ID)
+		assert.Equal(t, env.Data.ETag(), resp.Header.Get("ETag"))
+	}
+	wg.Wait()
+
+	resp = doRequest(t, http.MethodGet, server.URL+"/api/v1/orders/"+resting, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 20.0, decodeOrder(t, resp).Data.Filled)
+}
+
+func TestCancelOrder_Terminal(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"buy","price":50000,"quantity":1}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	buy := decodeOrder(t, resp)
+
+	resp = doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"sell","price":50000,"quantity":1}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp = doRequest(t, http.MethodDelete, server.URL+"/api/v1/orders/"+buy.Data.ID, "")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	var body struct {
+		Success bool `json:"success"`
+		Error   struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "ORDER_NOT_CANCELLABLE", body.Error.Code)
+
+	resp = doRequest(t, http.MethodDelete, server.URL+"/api/v1/orders/does-not-exist", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestCancelOrder_IfMatch(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	cancelIfMatch := func(id, etag string) *http.Response {
+		req, err := http.NewRequest(http.MethodDelete, server.URL+"/api/v1/orders/"+id, nil)
+		require.NoError(t, err)
+		req.Header.Set("If-Match", etag)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"buy","price":50000,"quantity":1}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	buy := decodeOrder(t, resp)
+
+	resp = doRequest(t, http.MethodGet, server.URL+"/api/v1/orders/"+buy.Data.ID, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	stale := resp.Header.Get("ETag")
+	require.NotEmpty(t, stale)
+
+	// A partial fill changes the order, so the ETag read before it is stale
+	resp = doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"sell","price":50000,"quantity":0.25}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp = cancelIfMatch(buy.Data.ID, stale)
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+	resp = doRequest(t, http.MethodGet, server.URL+"/api/v1/orders/"+buy.Data.ID, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, order.StatusPartial, decodeOrder(t, resp).Data.Status)
+	current := resp.Header.Get("ETag")
+	assert.NotEqual(t, stale, current)
+
+	resp = cancelIfMatch(buy.Data.ID, current)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, order.StatusCancelled, decodeOrder(t, resp).Data.Status)
+}
+
+func TestPatchOrder_Reduce(t *testing.T) {
+	server, service := newTestServer(t)
+
+	create := func(body string) orderEnvelope {
+		resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders", body)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		return decodeOrder(t, resp)
+	}
+	first := create(`{"symbol":"BTC-USD","side":"buy","price":50000,"quantity":2}`)
+	second := create(`{"symbol":"BTC-USD","side":"buy","price":50000,"quantity":1}`)
+
+	// A partial reduction keeps the order at the head of its level
+	resp := doRequest(t, http.MethodPatch, server.URL+"/api/v1/orders/"+first.Data.ID,
+		`{"op":"reduce","reduce_by":"1.5"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("ETag"))
+	reduced := decodeOrder(t, resp)
+	assert.Equal(t, 0.5, reduced.Data.Quantity)
+	assert.Equal(t, order.StatusNew, reduced.Data.Status)
+
+	book, err := service.GetOrderBook(context.Background(), "BTC-USD")
+	require.NoError(t, err)
+	require.Len(t, book.Bids, 1)
+	require.Len(t, book.Bids[0].Orders, 2)
+	assert.Equal(t, first.Data.ID, book.Bids[0].Orders[0].ID)
+	assert.Equal(t, second.Data.ID, book.Bids[0].Orders[1].ID)
+
+	// Reducing to zero cancels it, and a cancelled order can't be reduced
+	resp = doRequest(t, http.MethodPatch, server.URL+"/api/v1/orders/"+first.Data.ID,
+		`{"op":"reduce","reduce_by":0.5}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, order.StatusCancelled, decodeOrder(t, resp).Data.Status)
+
+	resp = doRequest(t, http.MethodPatch, server.URL+"/api/v1/orders/"+first.Data.ID,
+		`{"op":"reduce","reduce_by":0.5}`)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	book, err = service.GetOrderBook(context.Background(), "BTC-USD")
+	require.NoError(t, err)
+	require.Len(t, book.Bids, 1)
+	require.Len(t, book.Bids[0].Orders, 1)
+	assert.Equal(t, second.Data.ID, book.Bids[0].Orders[0].ID)
+
+	for _, body := range []string{`{"op":"cancel","reduce_by":1}`, `{"op":"reduce","reduce_by":0}`, `{"op":"reduce"}`} {
+		resp = doRequest(t, http.MethodPatch, server.URL+"/api/v1/orders/"+second.Data.ID, body)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, body)
+	}
+	resp = doRequest(t, http.MethodPatch, server.URL+"/api/v1/orders/unknown", `{"op":"reduce","reduce_by":1}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestOrderHistory(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"buy","price":50000,"quantity":2}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	buy := decodeOrder(t, resp)
+
+	resp = doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"sell","price":50000,"quantity":0.5}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp = doRequest(t, http.MethodDelete, server.URL+"/api/v1/orders/"+buy.Data.ID, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = doRequest(t, http.MethodGet, server.URL+"/api/v1/orders/"+buy.Data.ID+"/history", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data []struct {
+			Event        string  `json:"event"`
+			Status       string  `json:"status"`
+			Filled       string  `json:"filled"`
+			FillPrice    *string `json:"fill_price"`
+			FillQuantity *string `json:"fill_quantity"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Data, 3)
+
+	assert.Equal(t, "order.created", body.Data[0].Event)
+	assert.Nil(t, body.Data[0].FillPrice)
+	assert.Equal(t, "order.partially_filled", body.Data[1].Event)
+	require.NotNil(t, body.Data[1].FillPrice)
+	assert.Equal(t, "50000.00000000", *body.Data[1].FillPrice)
+	assert.Equal(t, "0.50000000", *body.Data[1].FillQuantity)
+	assert.Equal(t, "order.cancelled", body.Data[2].Event)
+	assert.Equal(t, "cancelled", body.Data[2].Status)
+	assert.Equal(t, "0.50000000", body.Data[2].Filled)
+
+	resp = doRequest(t, http.MethodGet, server.URL+"/api/v1/orders/does-not-exist/history", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestQueuePosition(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	var ids []string
+	for _, quantity := range []string{"1", "2", "3"} {
+		resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+			`{"symbol":"BTC-USD","side":"buy","price":50000,"quantity":`+quantity+`}`)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		ids = append(ids, decodeOrder(t, resp).Data.ID)
+	}
+
+	queue := func(id string) (*http.Response, orderbook.QueuePosition) {
+		resp := doRequest(t, http.MethodGet, server.URL+"/api/v1/orders/"+id+"/queue", "")
+		var body struct {
+			Data orderbook.QueuePosition `json:"data"`
+		}
+		if resp.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		}
+		return resp, body.Data
+	}
+
+	for i, want := range []struct {
+		rank  int
+		ahead float64
+	}{{1, 0}, {2, 1}, {3, 3}} {
+		resp, position := queue(ids[i])
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, ids[i], position.OrderID)
+		assert.Equal(t, want.rank, position.Rank)
+		assert.Equal(t, want.ahead, position.QuantityAhead)
+		assert.Equal(t, 6.0, position.LevelQuantity)
+	}
+
+	resp := doRequest(t, http.MethodDelete, server.URL+"/api/v1/orders/"+ids[0], "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, _ = queue(ids[0])
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	var body struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "ORDER_NOT_RESTING", body.Error.Code)
+	assert.Equal(t, "order is not resting: order is cancelled", body.Error.Message)
+
+	_, position := queue(ids[2])
+	assert.Equal(t, 2, position.Rank)
+
+	resp, _ = queue("does-not-exist")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestCreateOrder_Validation(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"malformed body", `{`},
+		{"missing symbol", `{"side":"buy","price":1,"quantity":1}`},
+		{"invalid side", `{"symbol":"BTC-USD","side":"hold","price":1,"quantity":1}`},
+		{"non-positive price", `{"symbol":"BTC-USD","side":"buy","price":0,"quantity":1}`},
+		{"non-positive quantity", `{"symbol":"BTC-USD","side":"buy","price":1,"quantity":-1}`},
+		{"quote quantity on a limit order", `{"symbol":"BTC-USD","side":"buy","price":1,"quote_quantity":100}`},
+		{"quote quantity on a sell", `{"symbol":"BTC-USD","side":"sell","type":"market","quote_quantity":100}`},
+		{"quantity and quote quantity", `{"symbol":"BTC-USD","side":"buy","type":"market","quantity":1,"quote_quantity":100}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders", tt.body)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		})
+	}
+}
+
+func TestCreateOrder_BodyErrors(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	tests := []struct {
+		name    string
+		body    string
+		message string
+	}{
+		{"empty body", ``, "request body is required"},
+		{"truncated object", `{`, "malformed JSON: body ends before the object does"},
+		{"syntax error", `{"symbol":"BTC-USD",}`, "malformed JSON at position 21"},
+		{"unknown field", `{"symbol":"BTC-USD","side":"buy","price":1,"quanity":1}`, `unknown field "quanity"`},
+		{"wrong type", `{"symbol":"BTC-USD","side":5,"price":1,"quantity":1}`, `field "side" must be a string`},
+		{"not an object", `[1]`, "request body must be an object"},
+		{"two objects", `{"symbol":"BTC-USD","side":"buy","price":1,"quantity":1}{"symbol":"BTC-USD","side":"buy","price":2,"quantity":1}`,
+			"request body must hold a single JSON object, found more after position 56"},
+		{"trailing garbage", `{"symbol":"BTC-USD","side":"buy","price":1,"quantity":1} x`,
+			"request body must hold a single JSON object, found more after position 56"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders", tt.body)
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+			var env struct {
+				Error struct {
+					Code    string `json:"code"`
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&env))
+			assert.Equal(t, "BAD_REQUEST", env.Error.Code)
+			assert.Equal(t, tt.message, env.Error.Message)
+		})
+	}
+}
+
+func TestCreateOrder_ConcatenatedObjects(t *testing.T) {
+	server, service := newTestServer(t)
+
+	// Neither order is placed: the request is ambiguous, not half-valid
+	resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"buy","price":1,"quantity":1}`+"\n"+
+			`{"symbol":"BTC-USD","side":"buy","price":2,"quantity":1}`)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	_, err := service.GetOrderBook(context.Background(), "BTC-USD")
+	assert.ErrorIs(t, err, matching.ErrSymbolNotFound)
+}
+
+func TestCreateOrder_IdempotencyKey(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	post := func() *http.Response {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/api/v1/orders",
+			bytes.NewBufferString(`{"symbol":"BTC-USD","side":"buy","price":50000,"quantity":1}`))
+		require.NoError(t, err)
+		req.Header.Set("Idempotency-Key", "retry-me")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	first := post()
+	require.Equal(t, http.StatusCreated, first.StatusCode)
+	created := decodeOrder(t, first)
+
+	second := post()
+	require.Equal(t, http.StatusOK, second.StatusCode)
+	assert.Equal(t, created.Data.ID, decodeOrder(t, second).Data.ID)
+}
+
+func TestCreateOrder_OutsidePriceBand(t *testing.T) {
+	server, service := newTestServer(t)
+	require.NoError(t, service.RegisterSymbol(orderbook.SymbolConfig{
+		Symbol:           "BTC-USD",
+		PriceBandPercent: 5,
+		ReferencePrice:   100,
+	}))
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"buy","price":200,"quantity":1}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var body struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "BAD_REQUEST", body.Error.Code)
+	assert.Contains(t, body.Error.Message, "outside the 5% band")
+}
+
+func TestCreateOrderRequest_DecimalStrings(t *testing.T) {
+	var numeric, quoted httphandler.CreateOrderRequest
+	require.NoError(t, json.Unmarshal([]byte(
+		`{"symbol":"BTC-USD","side":"buy","type":"stop-limit","price":0.1,"stop_price":0.09,"quantity":1.5}`), &numeric))
+	require.NoError(t, json.Unmarshal([]byte(
+		`{"symbol":"BTC-USD","side":"buy","type":"stop-limit","price":"0.1","stop_price":"0.09","quantity":"1.50"}`), &quoted))
+	assert.Equal(t, numeric, quoted)
+	assert.Equal(t, 0.1, quoted.Price)
+	assert.Equal(t, 0.09, quoted.StopPrice)
+	assert.Equal(t, 1.5, quoted.Quantity)
+
+	var invalid httphandler.CreateOrderRequest
+	assert.Error(t, json.Unmarshal([]byte(`{"symbol":"BTC-USD","side":"buy","price":"abc","quantity":1}`), &invalid))
+
+	// Both forms place the same order
+	server, _ := newTestServer(t)
+	resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"buy","price":"50000.00","quantity":"0.1"}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	fromStrings := decodeOrder(t, resp)
+
+	resp = doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"buy","price":50000,"quantity":0.1}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	fromNumbers := decodeOrder(t, resp)
+
+	fromStrings.Data.ID, fromNumbers.Data.ID = "", ""
+	fromStrings.Data.MatchLatencyMicros, fromNumbers.Data.MatchLatencyMicros = 0, 0
+	assert.Equal(t, fromNumbers, fromStrings)
+	assert.Equal(t, 0.1, fromStrings.Data.Quantity)
+}
@@ -0,0 +1,43 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"company.com/matchengine/internal/position"
+)
+
+func TestPositionsEndpoint(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	trade := func(side, counterSide string, price, quantity string) {
+		resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+			`{"account_id":"bob","symbol":"BTC-USD","side":"`+counterSide+`","price":`+price+`,"quantity":`+quantity+`}`)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		resp = doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+			`{"account_id":"alice","symbol":"BTC-USD","side":"`+side+`","price":`+price+`,"quantity":`+quantity+`}`)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+	positions := func(account string) []position.Position {
+		resp := doRequest(t, http.MethodGet, server.URL+"/api/v1/accounts/"+account+"/positions", "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body struct {
+			Data []position.Position `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return body.Data
+	}
+
+	trade("buy", "sell", "100", "1")
+	trade("buy", "sell", "200", "3")
+	trade("sell", "buy", "300", "1")
+
+	assert.Equal(t, []position.Position{{Symbol: "BTC-USD", Quantity: 3, AvgEntryPrice: 175}}, positions("alice"))
+	assert.Equal(t, []position.Position{{Symbol: "BTC-USD", Quantity: -3, AvgEntryPrice: 175}}, positions("bob"))
+	assert.Empty(t, positions("carol"))
+}
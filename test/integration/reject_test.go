@@ -0,0 +1,191 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"company.com/matchengine/internal/event"
+	httphandler "company.com/matchengine/internal/handler/http"
+	"company.com/matchengine/internal/risk"
+	"company.com/matchengine/internal/service/matching"
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+	"company.com/matchengine/pkg/errors"
+)
+
+func TestCreateOrder_RejectReasons(t *testing.T) {
+	server, service := newTestServer(t)
+
+	require.NoError(t, service.RegisterSymbol(orderbook.SymbolConfig{Symbol: "DOGE-USD", CrossingPolicy: orderbook.CrossingPolicyReject}))
+	ask, err := order.NewOrder(order.SideSell, "DOGE-USD", 1, 10)
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), ask))
+
+	balances := risk.NewBalances()
+	balances.Deposit("alice", "USD", 1000)
+	service.SetRiskChecker(balances)
+	require.NoError(t, service.RegisterSymbol(orderbook.SymbolConfig{Symbol: "BTC-USD", PriceBandPercent: 10, ReferencePrice: 100}))
+	require.NoError(t, service.RegisterSymbol(orderbook.SymbolConfig{Symbol: "ETH-USD"}))
+	require.NoError(t, service.HaltSymbol("ETH-USD"))
+	require.NoError(t, service.RegisterSymbol(orderbook.SymbolConfig{Symbol: "SOL-USD", Precision: &order.Precision{Price: 2, Quantity: 2}}))
+	require.NoError(t, service.RegisterSymbol(orderbook.SymbolConfig{Symbol: "XRP-USD", MaxOrdersPerSide: 1}))
+	require.NoError(t, service.RegisterSymbol(orderbook.SymbolConfig{Symbol: "ADA-USD", MinNotional: 10}))
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"account_id":"alice","symbol":"XRP-USD","side":"buy","price":1,"quantity":1}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	for _, tc := range []struct {
+		name   string
+		body   string
+		status int
+		reason errors.RejectReason
+	}{
+		{"halted symbol", `{"account_id":"alice","symbol":"ETH-USD","side":"buy","price":10,"quantity":1}`,
+			http.StatusBadRequest, errors.ReasonSymbolHalted},
+		{"outside price band", `{"account_id":"alice","symbol":"BTC-USD","side":"buy","price":150,"quantity":1}`,
+			http.StatusBadRequest, errors.ReasonPriceOutOfBand},
+		{"quantity below the lot", `{"account_id":"alice","symbol":"SOL-USD","side":"buy","price":10,"quantity":0.001}`,
+			http.StatusBadRequest, errors.ReasonInvalidTick},
+		{"price below the tick", `{"account_id":"alice","symbol":"SOL-USD","side":"buy","price":0.001,"quantity":1}`,
+			http.StatusBadRequest, errors.ReasonInvalidTick},
+		{"side full", `{"account_id":"alice","symbol":"XRP-USD","side":"buy","price":1,"quantity":1}`,
+			http.StatusBadRequest, errors.ReasonDepthLimitReached},
+		{"below the minimum notional", `{"account_id":"alice","symbol":"ADA-USD","side":"buy","price":1,"quantity":9}`,
+			http.StatusBadRequest, errors.ReasonBelowMinNotional},
+		{"crossing the book", `{"account_id":"alice","symbol":"DOGE-USD","side":"buy","price":1.5,"quantity":1}`,
+			http.StatusBadRequest, errors.ReasonWouldCross},
+		{"insufficient balance", `{"account_id":"alice","symbol":"BTC-USD","side":"buy","price":100,"quantity":11}`,
+			http.StatusUnprocessableEntity, errors.ReasonInsufficientBalance},
+		{"reduce-only without a position", `{"account_id":"alice","symbol":"BTC-USD","side":"sell","price":100,"quantity":1,"reduce_only":true}`,
+			http.StatusBadRequest, errors.ReasonReduceOnly},
+		{"malformed order", `{"symbol":"BTC-USD","side":"buy","price":100,"quantity":0}`,
+			http.StatusBadRequest, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, path := range []string{"/api/v1/orders", "/api/v1/orders/validate"} {
+				resp := doRequest(t, http.MethodPost, server.URL+path, tc.body)
+				assert.Equal(t, tc.status, resp.StatusCode, path)
+
+				var body struct {
+					Error errors.APIError `json:"error"`
+				}
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+				assert.Equal(t, tc.reason, body.Error.Reason, path)
+				assert.NotEmpty(t, body.Error.Message)
+			}
+		})
+	}
+}
+
+func TestCreateOrder_QuotaExceeded(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := matching.NewService(matching.WithLogger(logger), matching.WithMessageQuota(2))
+	mux := http.NewServeMux()
+	httphandler.NewHandler(service, logger).RegisterRoutes(mux)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	place := func(symbol string) *http.Response {
+		return doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+			`{"account_id":"alice","symbol":"`+symbol+`","side":"buy","price":100,"quantity":1}`)
+	}
+	require.Equal(t, http.StatusCreated, place("BTC-USD").StatusCode)
+	require.Equal(t, http.StatusCreated, place("BTC-USD").StatusCode)
+
+	// The burst may land across a second boundary, which resets the quota
+	var resp *http.Response
+	for range 10 {
+		if resp = place("BTC-USD"); resp.StatusCode != http.StatusCreated {
+			break
+		}
+	}
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	// The quota frees up when the current second ends
+	assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+	var body struct {
+		Error errors.APIError `json:"error"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "TOO_MANY_REQUESTS", body.Error.Code)
+	assert.Equal(t, errors.ReasonQuotaExceeded, body.Error.Reason)
+
+	assert.Equal(t, http.StatusCreated, place("ETH-USD").StatusCode)
+}
+
+// blockingPublisher holds up the first order it is handed until released
+type blockingPublisher struct {
+	once     sync.Once
+	reached  chan struct{}
+	released chan struct{}
+}
+
+func (p *blockingPublisher) Publish(event.Event) {
+	p.once.Do(func() {
+		close(p.reached)
+		<-p.released
+	})
+}
+
+func TestCreateOrder_Overloaded(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		option matching.Option
+	}{
+		{"in flight", matching.WithMaxInFlight(1)},
+		{"ingestion queue", matching.WithIngestQueue(1, 1)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			publisher := &blockingPublisher{reached: make(chan struct{}), released: make(chan struct{})}
+			service := matching.NewService(
+				matching.WithLogger(logger),
+				matching.WithEventPublisher(publisher),
+				matching.WithRetryBackoff(2500*time.Millisecond),
+				tt.option,
+			)
+			mux := http.NewServeMux()
+			httphandler.NewHandler(service, logger).RegisterRoutes(mux)
+			server := httptest.NewServer(mux)
+			t.Cleanup(server.Close)
+
+			place := func() *http.Response {
+				return doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+					`{"symbol":"BTC-USD","side":"buy","price":100,"quantity":1}`)
+			}
+
+			// Hold the first order in matching, and with a queue fill it
+			// behind
+			done := make(chan *http.Response, 2)
+			go func() { done <- place() }()
+			<-publisher.reached
+			pending := 1
+			if tt.name == "ingestion queue" {
+				go func() { done <- place() }()
+				require.Eventually(t, func() bool { return service.Stats().Queue.Depth == 1 }, time.Second, time.Millisecond)
+				pending++
+			}
+
+			resp := place()
+			assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+			// Rounded up to whole seconds
+			assert.Equal(t, "3", resp.Header.Get("Retry-After"))
+
+			close(publisher.released)
+			for range pending {
+				assert.Equal(t, http.StatusCreated, (<-done).StatusCode)
+			}
+			assert.Empty(t, place().Header.Get("Retry-After"))
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowHeader(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	tests := []struct {
+		path       string
+		disallowed string
+		allow      string
+	}{
+		{"/api/v1/orders", http.MethodDelete, "GET, HEAD, OPTIONS, POST"},
+		{"/api/v1/orders/some-id", http.MethodPut, "DELETE, GET, HEAD, OPTIONS, PATCH"},
+		{"/api/v1/orders/some-id/history", http.MethodPost, "GET, HEAD, OPTIONS"},
+		{"/api/v1/orderbook/BTC-USD", http.MethodDelete, "GET, HEAD, OPTIONS"},
+		{"/api/v1/simulate", http.MethodGet, "OPTIONS, POST"},
+		{"/api/v1/stats", http.MethodPost, "GET, HEAD, OPTIONS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp := doRequest(t, http.MethodOptions, server.URL+tt.path, "")
+			assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+			assert.Equal(t, tt.allow, resp.Header.Get("Allow"))
+
+			resp = doRequest(t, tt.disallowed, server.URL+tt.path, "")
+			assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+			assert.Equal(t, tt.allow, resp.Header.Get("Allow"))
+		})
+	}
+}
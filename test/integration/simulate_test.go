@@ -0,0 +1,93 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	httphandler "company.com/matchengine/internal/handler/http"
+	"company.com/matchengine/internal/service/matching"
+	"company.com/matchengine/pkg/engine/order"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, *matching.Service) {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := matching.NewService()
+	service.SetLogger(logger)
+	mux := http.NewServeMux()
+	httphandler.NewHandler(service, logger).RegisterRoutes(mux)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, service
+}
+
+func TestSimulateFillEndpoint(t *testing.T) {
+	server, service := newTestServer(t)
+
+	for _, price := range []float64{100.0, 101.0} {
+		o, err := order.NewOrder(order.SideSell, "BTC-USD", price, 1.0)
+		require.NoError(t, err)
+		require.NoError(t, service.AddOrder(context.Background(), o))
+	}
+
+	body, err := json.Marshal(httphandler.SimulateFillRequest{
+		Symbol:   "BTC-USD",
+		Side:     order.SideBuy,
+		Quantity: 1.5,
+	})
+	require.NoError(t, err)
+
+	resp, err := http.Post(server.URL+"/api/v1/simulate", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Success bool                     `json:"success"`
+		Data    orderbook.FillSimulation `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.True(t, result.Success)
+	assert.InDelta(t, 1.5, result.Data.Filled, 1e-9)
+	assert.InDelta(t, 0.0, result.Data.Unfilled, 1e-9)
+	assert.InDelta(t, (100.0+0.5*101.0)/1.5, result.Data.AvgPrice, 1e-9)
+}
+
+func TestSimulateFillEndpoint_Errors(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"malformed body", `{`, http.StatusBadRequest},
+		{"invalid side", `{"symbol":"BTC-USD","side":"hold","quantity":1}`, http.StatusBadRequest},
+		{"non-positive quantity", `{"symbol":"BTC-USD","side":"buy","quantity":0}`, http.StatusBadRequest},
+		{"unknown symbol", `{"symbol":"ETH-USD","side":"buy","quantity":1}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(server.URL+"/api/v1/simulate", "application/json", bytes.NewBufferString(tt.body))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+}
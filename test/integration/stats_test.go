@@ -0,0 +1,33 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"company.com/matchengine/internal/service/matching"
+)
+
+func TestStatsEndpoint(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"sell","price":100,"quantity":1}`)
+	doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"buy","price":100,"quantity":1}`)
+
+	resp := doRequest(t, http.MethodGet, server.URL+"/api/v1/stats", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data matching.Stats `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, 1, body.Data.Symbols)
+	assert.Equal(t, uint64(2), body.Data.OrdersAdded)
+	assert.Equal(t, uint64(1), body.Data.TradesExecuted)
+	assert.Equal(t, 0, body.Data.ActiveOrders)
+}
@@ -0,0 +1,107 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestTickerEndpoint(t *testing.T) {
+	server, service := newTestServer(t)
+
+	buy, err := order.NewOrder(order.SideBuy, "BTC-USD", 99.0, 3.0)
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), buy))
+
+	getTicker := func() map[string]interface{} {
+		resp, err := http.Get(server.URL + "/api/v1/ticker/BTC-USD")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return body.Data
+	}
+
+	// One-sided book reports nulls for the missing side and derived signals
+	ticker := getTicker()
+	assert.Equal(t, 99.0, ticker["best_bid"])
+	assert.Nil(t, ticker["best_ask"])
+	assert.Nil(t, ticker["imbalance"])
+	assert.Nil(t, ticker["microprice"])
+
+	sell, err := order.NewOrder(order.SideSell, "BTC-USD", 101.0, 1.0)
+	require.NoError(t, err)
+	require.NoError(t, service.AddOrder(context.Background(), sell))
+
+	ticker = getTicker()
+	assert.Equal(t, 0.75, ticker["imbalance"])
+	assert.Equal(t, 100.5, ticker["microprice"])
+
+	resp, err := http.Get(server.URL + "/api/v1/ticker/ETH-USD")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestTickersEndpoint(t *testing.T) {
+	server, service := newTestServer(t)
+
+	place := func(side order.Side, symbol string, price, quantity float64) {
+		o, err := order.NewOrder(side, symbol, price, quantity)
+		require.NoError(t, err)
+		require.NoError(t, service.AddOrder(context.Background(), o))
+	}
+
+	// Two-sided, bid-only and ask-only books, and one emptied by a trade
+	place(order.SideBuy, "BTC-USD", 99.0, 1.0)
+	place(order.SideSell, "BTC-USD", 101.0, 1.0)
+	place(order.SideBuy, "ETH-USD", 10.0, 2.0)
+	place(order.SideSell, "SOL-USD", 5.0, 3.0)
+	place(order.SideBuy, "ADA-USD", 1.0, 1.0)
+	place(order.SideSell, "ADA-USD", 1.0, 1.0)
+
+	resp, err := http.Get(server.URL + "/api/v1/tickers")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Data, 4)
+
+	bySymbol := make(map[string]map[string]interface{})
+	var symbols []string
+	for _, ticker := range body.Data {
+		symbol := ticker["symbol"].(string)
+		symbols = append(symbols, symbol)
+		bySymbol[symbol] = ticker
+	}
+	assert.Equal(t, []string{"ADA-USD", "BTC-USD", "ETH-USD", "SOL-USD"}, symbols)
+
+	assert.Nil(t, bySymbol["ADA-USD"]["best_bid"])
+	assert.Nil(t, bySymbol["ADA-USD"]["best_ask"])
+	assert.Equal(t, 99.0, bySymbol["BTC-USD"]["best_bid"])
+	assert.Equal(t, 101.0, bySymbol["BTC-USD"]["best_ask"])
+	assert.Equal(t, 10.0, bySymbol["ETH-USD"]["best_bid"])
+	assert.Nil(t, bySymbol["ETH-USD"]["best_ask"])
+	assert.Nil(t, bySymbol["SOL-USD"]["best_bid"])
+	assert.Equal(t, 5.0, bySymbol["SOL-USD"]["best_ask"])
+
+	// Only the book that traded has a last trade
+	assert.Equal(t, 1.0, bySymbol["ADA-USD"]["last_price"])
+	assert.Equal(t, 1.0, bySymbol["ADA-USD"]["last_qty"])
+	assert.NotNil(t, bySymbol["ADA-USD"]["last_trade_at"])
+	assert.Nil(t, bySymbol["BTC-USD"]["last_price"])
+}
@@ -0,0 +1,150 @@
+package integration
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"company.com/matchengine/internal/config"
+	httphandler "company.com/matchengine/internal/handler/http"
+	apiserver "company.com/matchengine/internal/server"
+	"company.com/matchengine/internal/service/matching"
+)
+
+// writeSelfSignedCert writes a certificate for 127.0.0.1 with the given
+// serial and its key to dir, returning the parsed certificate
+func writeSelfSignedCert(t *testing.T, dir string, serial int64) *x509.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "matchengine test"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cert.pem"), certPEM, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "key.pem"), keyPEM, 0o600))
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func TestServer_TLS(t *testing.T) {
+	dir := t.TempDir()
+	first := writeSelfSignedCert(t, dir, 1)
+	certs, err := apiserver.NewCertReloader(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
+	require.NoError(t, err)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mux := http.NewServeMux()
+	httphandler.NewHandler(matching.NewService(matching.WithLogger(logger)), logger).RegisterRoutes(mux)
+	cfg := config.ServerConfig{KeepAlives: true, TLS: config.TLSConfig{MinVersion: "1.2"}}
+	server, err := apiserver.New(cfg, mux, certs)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.ServeTLS(listener, "", "")
+	t.Cleanup(func() { server.Close() })
+	url := "https://" + listener.Addr().String()
+
+	clientFor := func(certs ...*x509.Certificate) *http.Client {
+		roots := x509.NewCertPool()
+		for _, cert := range certs {
+			roots.AddCert(cert)
+		}
+		return &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: roots},
+			ForceAttemptHTTP2: true,
+		}}
+	}
+	post := func(client *http.Client) *http.Response {
+		resp, err := client.Post(url+"/api/v1/orders", "application/json",
+			strings.NewReader(`{"symbol":"BTC-USD","side":"buy","price":50000,"quantity":1}`))
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	kept := clientFor(first)
+	resp := post(kept)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, 2, resp.ProtoMajor)
+	assert.Equal(t, first.SerialNumber, resp.TLS.PeerCertificates[0].SerialNumber)
+
+	// A renewed certificate is served to new connections, while the one
+	// already open goes on with the old
+	second := writeSelfSignedCert(t, dir, 2)
+	require.NoError(t, certs.Reload())
+
+	resp = post(clientFor(second))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, second.SerialNumber, resp.TLS.PeerCertificates[0].SerialNumber)
+
+	resp = post(kept)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, first.SerialNumber, resp.TLS.PeerCertificates[0].SerialNumber)
+
+	// Broken files leave the current certificate in place
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "key.pem"), []byte("not a key"), 0o600))
+	assert.Error(t, certs.Reload())
+	resp = post(clientFor(second))
+	assert.Equal(t, second.SerialNumber, resp.TLS.PeerCertificates[0].SerialNumber)
+}
+
+func TestServer_TLSSettings(t *testing.T) {
+	dir := t.TempDir()
+	writeSelfSignedCert(t, dir, 1)
+	certs, err := apiserver.NewCertReloader(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
+	require.NoError(t, err)
+
+	server, err := apiserver.New(config.ServerConfig{TLS: config.TLSConfig{
+		MinVersion:   "1.3",
+		CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", " TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+	}}, http.NotFoundHandler(), certs)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), server.TLSConfig.MinVersion)
+	assert.Len(t, server.TLSConfig.CipherSuites, 2)
+
+	for _, bad := range []config.TLSConfig{
+		{MinVersion: "1.0"},
+		{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+		{CipherSuites: []string{"NOT_A_SUITE"}},
+	} {
+		_, err := apiserver.New(config.ServerConfig{TLS: bad}, http.NotFoundHandler(), certs)
+		assert.Error(t, err, "%+v", bad)
+	}
+
+	_, err = apiserver.NewCertReloader(filepath.Join(dir, "missing.pem"), filepath.Join(dir, "key.pem"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	httphandler "company.com/matchengine/internal/handler/http"
+	"company.com/matchengine/internal/middleware"
+	"company.com/matchengine/internal/service/matching"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := matching.NewService()
+	mux := http.NewServeMux()
+	httphandler.NewHandler(service, logger).RegisterRoutes(mux)
+
+	server := httptest.NewServer(middleware.Chain(mux, middleware.Tracing()))
+	t.Cleanup(server.Close)
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"buy","price":100,"quantity":1}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	created := decodeOrder(t, resp)
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+
+	root, ok := spans["HTTP POST"]
+	require.True(t, ok, "missing HTTP span")
+	assert.Equal(t, trace.SpanKindServer, root.SpanKind)
+	assert.False(t, root.Parent.IsValid())
+
+	// Each span is a child of the one before it
+	parent := root
+	for _, name := range []string{"matching.AddOrder", "orderbook.AddOrder", "orderbook.tryMatch"} {
+		span, ok := spans[name]
+		require.True(t, ok, "missing span %s", name)
+		assert.Equal(t, root.SpanContext.TraceID(), span.SpanContext.TraceID(), name)
+		assert.Equal(t, parent.SpanContext.SpanID(), span.Parent.SpanID(), "parent of %s", name)
+
+		attrs := make(map[string]string)
+		for _, kv := range span.Attributes {
+			attrs[string(kv.Key)] = kv.Value.Emit()
+		}
+		assert.Equal(t, "BTC-USD", attrs["symbol"], name)
+		assert.Equal(t, created.Data.ID, attrs["order_id"], name)
+
+		parent = span
+	}
+}
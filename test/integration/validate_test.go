@@ -0,0 +1,79 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	httphandler "company.com/matchengine/internal/handler/http"
+	"company.com/matchengine/internal/risk"
+	"company.com/matchengine/internal/service/matching"
+	"company.com/matchengine/pkg/engine/orderbook"
+)
+
+func TestValidateOrderEndpoint(t *testing.T) {
+	server, service := newTestServer(t)
+
+	balances := risk.NewBalances()
+	balances.Deposit("alice", "USD", 1000)
+	service.SetRiskChecker(balances)
+	require.NoError(t, service.RegisterSymbol(orderbook.SymbolConfig{Symbol: "BTC-USD", PriceBandPercent: 10, ReferencePrice: 100}))
+	require.NoError(t, service.RegisterSymbol(orderbook.SymbolConfig{Symbol: "ETH-USD"}))
+	require.NoError(t, service.HaltSymbol("ETH-USD"))
+
+	t.Run("valid order", func(t *testing.T) {
+		resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders/validate",
+			`{"account_id":"alice","symbol":"BTC-USD","side":"buy","price":100,"quantity":1}`)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body struct {
+			Data httphandler.OrderValidation `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.True(t, body.Data.Valid)
+
+		// Nothing was placed or held
+		snapshot, err := service.GetOrderBook(context.Background(), "BTC-USD")
+		require.NoError(t, err)
+		assert.Empty(t, snapshot.Bids)
+		assert.Equal(t, risk.Balance{Available: 1000}, balances.Balance("alice", "USD"))
+	})
+
+	for _, tc := range []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"missing symbol", `{"side":"buy","price":100,"quantity":1}`, http.StatusBadRequest},
+		{"unknown side", `{"symbol":"BTC-USD","side":"hold","price":100,"quantity":1}`, http.StatusBadRequest},
+		{"non-positive quantity", `{"symbol":"BTC-USD","side":"buy","price":100,"quantity":0}`, http.StatusBadRequest},
+		{"outside price band", `{"account_id":"alice","symbol":"BTC-USD","side":"buy","price":150,"quantity":1}`, http.StatusBadRequest},
+		{"halted symbol", `{"account_id":"alice","symbol":"ETH-USD","side":"buy","price":10,"quantity":1}`, http.StatusBadRequest},
+		{"insufficient balance", `{"account_id":"alice","symbol":"BTC-USD","side":"buy","price":100,"quantity":11}`, http.StatusUnprocessableEntity},
+		{"reduce-only without a position", `{"account_id":"alice","symbol":"BTC-USD","side":"sell","price":100,"quantity":1,"reduce_only":true}`, http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders/validate", tc.body)
+			assert.Equal(t, tc.status, resp.StatusCode)
+			validated, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			// The real endpoint turns the order down the same way
+			resp = doRequest(t, http.MethodPost, server.URL+"/api/v1/orders", tc.body)
+			assert.Equal(t, tc.status, resp.StatusCode)
+			created, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(created), string(validated))
+		})
+	}
+
+	// Only the real endpoint's attempts reached the service, and all failed
+	page, err := service.ListOrders(context.Background(), matching.OrderFilter{})
+	require.NoError(t, err)
+	assert.Zero(t, page.Total)
+}
@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"company.com/matchengine/internal/event"
+	httphandler "company.com/matchengine/internal/handler/http"
+	"company.com/matchengine/internal/service/matching"
+	"company.com/matchengine/pkg/engine/order"
+)
+
+func TestFillWebhook(t *testing.T) {
+	received := make(chan event.FillNotification, 4)
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n event.FillNotification
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&n))
+		received <- n
+	}))
+	t.Cleanup(callback.Close)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	// The callback server listens on loopback, which the guard refuses
+	webhooks := event.NewWebhookPublisher(event.WebhookConfig{AllowPrivateAddresses: true}, logger)
+	t.Cleanup(webhooks.Close)
+	service := matching.NewService(matching.WithLogger(logger), matching.WithEventPublisher(webhooks))
+	mux := http.NewServeMux()
+	httphandler.NewHandler(service, logger).RegisterRoutes(mux)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	maker, err := order.NewOrder(order.SideSell, "BTC-USD", 50000, 2.0)
+	require.NoError(t, err)
+	maker.CallbackURL = callback.URL + "/fills"
+	require.NoError(t, service.AddOrder(context.Background(), maker))
+
+	resp := doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"buy","price":50000,"quantity":0.5}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	select {
+	case n := <-received:
+		assert.Equal(t, event.OrderPartiallyFilled, n.Type)
+		require.NotNil(t, n.Order)
+		assert.Equal(t, maker.ID, n.Order.ID)
+		assert.Equal(t, 0.5, n.Order.Filled)
+		require.NotNil(t, n.Trade)
+		assert.Equal(t, 50000.0, n.Trade.Price)
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback not received after the fill")
+	}
+
+	// Callbacks to internal addresses are refused up front
+	resp = doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"buy","price":49000,"quantity":1,"callback_url":"http://169.254.169.254/latest"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp = doRequest(t, http.MethodPost, server.URL+"/api/v1/orders",
+		`{"symbol":"BTC-USD","side":"buy","price":49000,"quantity":1,"callback_url":"ftp://example.com"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}